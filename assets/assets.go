@@ -0,0 +1,27 @@
+// Package assets embeds static resources so they travel with the binary
+package assets
+
+import (
+	_ "embed"
+)
+
+// IconIdle is the menu bar icon shown while waiting for the hotkey
+//
+//go:embed icon/speech_to_text_32dp_E3E3E3_FILL0_wght400_GRAD0_opsz40.png
+var IconIdle []byte
+
+// IconRecording is the menu bar icon shown while recording
+//
+//go:embed icon/graphic_eq_32dp_F19E39_FILL0_wght400_GRAD0_opsz40.png
+var IconRecording []byte
+
+// IconProcessing is the menu bar icon shown while transcribing
+//
+//go:embed icon/hourglass_empty_32dp_75FB4C_FILL0_wght400_GRAD0_opsz40.png
+var IconProcessing []byte
+
+// IconDegraded is the menu bar icon shown while idle with a missing prerequisite
+// (no model, no permission)
+//
+//go:embed icon/speech_to_text_32dp_EA3323_FILL0_wght400_GRAD0_opsz40.png
+var IconDegraded []byte
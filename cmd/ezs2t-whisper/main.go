@@ -1,55 +1,193 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"reflect"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/yok-tottii/EzS2T-Whisper/internal/api"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio/fakeaudio"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audiofile"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/clipboard"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/frontapp"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/history"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/hotkey"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/logger"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/metrics"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/notification"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/permissions"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/power"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/recognition"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/screenlock"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/server"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/tray"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/update"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/version"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/wizard"
 	hk "golang.design/x/hotkey"
 )
 
-const version = "0.3.0"
+const (
+	updateCheckInterval = 24 * time.Hour   // アップデート確認の間隔
+	updateCheckTimeout  = 10 * time.Second // マニフェスト取得のタイムアウト
+
+	permissionWatchInterval = 2 * time.Second // 権限の変化をトレイの警告表示に反映する間隔
+)
+
+// speechRecognizer は App が利用する音声認識の機能（テストではフェイクに差し替える）
+type speechRecognizer interface {
+	LoadModel(modelPath string) error
+	SelfTest() error
+	TranscribeFloat32(samples []float32, sampleRate int, options recognition.TranscribeOptions) (recognition.Result, error)
+	SetLanguage(language string)
+	GetLanguage() string
+	SetTuning(tuning recognition.Tuning)
+	SetThreads(threads int)
+	GetTuning() recognition.Tuning
+	SetInitialPrompt(prompt string)
+	SetTranslate(translate bool)
+	SetFallbackLanguages(languages []string)
+	SetSilenceThreshold(threshold float64)
+	Unload()
+	Close() error
+}
+
+// streamingRecognizer は録音中の音声を途中まで文字起こしできる音声認識（streaming で使用）
+type streamingRecognizer interface {
+	TranscribeStream(ctx context.Context, sampleRate int, chunks <-chan []byte) <-chan recognition.Partial
+}
+
+// streamChunkBuffer は途中結果の文字起こしが追いつくまで溜めておく音声チャンクの数
+// これを超えた分は途中結果から落とす（最終結果には影響しない）
+const streamChunkBuffer = 256
+
+// timerHandle は停止できるタイマー（*time.Timer、テストではフェイクに差し替える）
+type timerHandle interface {
+	Stop() bool
+}
+
+// textPaster は文字起こし結果をアクティブなアプリに貼り付ける（テストではフェイクに差し替える）
+type textPaster interface {
+	SafePasteWithSplitContext(ctx context.Context, text string) error
+	CopyText(text string) error
+	SetConfig(config clipboard.Config)
+}
+
+// trayUI は App が利用するシステムトレイの機能（テストではフェイクに差し替える）
+type trayUI interface {
+	Run()
+	Quit()
+	SetState(state tray.State)
+	SetProblems(problems []string)
+	SetPartialText(text string)
+	UpdateDeviceMenu(devices []tray.Device)
+	ShowNotification(title, message string)
+	ShowError(message string)
+	ShowSuccess(message string)
+}
 
 // App holds all application state
 type App struct {
 	logger      *logger.Logger
 	config      *config.Config
-	trayMgr     *tray.Manager
+	trayMgr     trayUI
 	httpServer  *server.Server
 	apiHandler  *api.Handler
 	hotkeyMgr   *hotkey.Manager
+	clipHotkey  *hotkey.Manager // クリップボードの音声ファイルを文字起こしするホットキー（未設定の場合は nil）
+	secHotkey   *hotkey.Manager // 2つ目の録音用ホットキー（second_hotkey、未設定の場合は nil）
 	audioDriver audio.AudioDriver
 	audioConfig audio.Config
-	recognizer  *recognition.WhisperRecognizer
-	clipboard   *clipboard.Manager
+	recognizer  speechRecognizer
+	clipboard   textPaster
 	wizard      *wizard.SetupWizard
 
-	micGranted  bool
-	accGranted  bool
-	modelLoaded bool
+	fakeAudioSource string // 空でない場合はマイクの代わりにフェイクオーディオを使用（WAVパス / "sine" / "silence"）
+	noBrowser       bool   // ブラウザを開かずURLをターミナルに表示（--no-browser またはGUIセッションがない場合）
+
+	micGranted  atomic.Bool // マイク権限の許可ダイアログやポーリングで別のgoroutineから更新される
+	accGranted  atomic.Bool // 貼り付け時に権限の取り消しを検出すると false に戻る
+	modelLoaded atomic.Bool // モデルの再読み込みで別のgoroutineから更新される
 	isFirstRun  bool
 
-	shutdownOnce       sync.Once      // 終了処理が一度だけ実行されることを保証
-	hotkeyEventLoopWg  sync.WaitGroup // ホットキーイベントループの終了を待つ
-	reloadHotkeyMutex  sync.Mutex     // ReloadHotkey() の並行実行を防止
+	shutdownOnce      sync.Once          // 終了処理が一度だけ実行されることを保証
+	hotkeyEventLoopWg sync.WaitGroup     // ホットキーイベントループの終了を待つ
+	reloadHotkeyMutex sync.Mutex         // ReloadHotkey() の並行実行を防止
+	transcribeMutex   sync.Mutex         // 言語の一時切り替えを含む文字起こしとモデルの再読み込みを直列化
+	audioMutex        sync.RWMutex       // audioDriver と audioConfig を保護（マイク権限の許可やデバイス変更で別のgoroutineから置き換えられる）
+	reloadModelMutex  sync.Mutex         // モデル再読み込みの並行実行を防止
+	pasteMutex        sync.Mutex         // 貼り付けを直列化（ホットキーとテスト貼り付けが混ざらないように）
+	lifecycleCtx      context.Context    // 終了時にキャンセルされ、分割貼り付けやモデルのダウンロードを中断する（nilの場合は中断しない）
+	cancelLifecycle   context.CancelFunc // lifecycleCtx をキャンセルする
+
+	openAccessibilitySettings func() error           // システム設定のアクセシビリティ画面を開く（テストでは差し替え）
+	playStartBeep             func() error           // 録音開始の合図音を鳴らす（テストでは差し替え）
+	frontmostApp              func() string          // 最前面のアプリ名を返す（テストでは差し替え）
+	frontmostBundleID         func() string          // 最前面のアプリのバンドルIDを返す（テストでは差し替え）
+	activateApp               func(string) error     // バンドルIDのアプリを最前面にする（テストでは差し替え、nilの場合はフォーカスを戻さない）
+	screenLocked              func() bool            // 画面がロックされているかを返す（テストでは差し替え、nilの場合はロックなしとみなす）
+	checkPermissions          func() map[string]bool // 現在の権限状態を返す（テストでは差し替え）
+	clipboardFiles            func() []string        // クリップボード上のファイルパスを返す（テストでは差し替え）
+	clipboardText             func() (string, error) // クリップボードのテキストを返す（テストでは差し替え）
+	clipTranscribing          atomic.Bool            // クリップボードの音声ファイルを文字起こし中か（連打で重複実行しない）
+	startBeepPlayed           bool                   // 現在のホットキー録音で合図音を鳴らしたか（ホットキーイベントループからのみ参照）
+	startBackoff              time.Duration          // 録音開始を再試行するまでの最初の待ち時間（audio.StartWithRetry に渡す、テストでは 0）
+	notifiedUpdate            string                 // 通知済みの最新バージョン（同じバージョンを毎日通知しない）
+	stats                     *metrics.Stats         // 直近の文字起こしの計測値（/api/stats で公開、nilの場合は集計しない）
+	history                   *history.Store         // 文字起こし履歴（/api/history で公開、nilの場合は記録しない）
+	recordingsDir             string                 // save_recordings で録音を保存するフォルダ（空の場合は保存しない）
+	whisperInfo               recognition.BuildInfo  // リンクされている whisper.cpp のバージョンと機能（起動時に取得）
+	autoThreads               atomic.Int32           // 読み込んだモデルに推奨されるスレッド数（threads が 0 の場合に使用、0 は全コア）
+
+	microphoneStatus  func() permissions.PermissionStatus // マイク権限の状態を返す（テストでは差し替え）
+	requestMicrophone func() permissions.PermissionStatus // マイク権限の許可ダイアログを表示し、回答を待つ（テストでは差し替え）
+	micPrompting      atomic.Bool                         // マイク権限の許可ダイアログを表示中か（連打で重複表示しない）
+	micSetupMutex     sync.Mutex                          // マイク権限の許可後のオーディオ初期化を一度だけ行う
+
+	afterFunc     func(d time.Duration, f func()) timerHandle // time.AfterFunc（テストでは差し替え、nilの場合はアイドル解放・最大録音時間での停止をしない）
+	idleMutex     sync.Mutex                                  // 以下のアイドル解放の状態を保護（transcribeMutex の後にロックする）
+	idleTimer     timerHandle                                 // 最後の文字起こしから idle_unload_minutes 後にモデルを解放する
+	modelIdle     bool                                        // アイドル解放でモデルを解放済みか（次の文字起こしの前に読み込み直す）
+	idleModelPath string                                      // アイドル解放後に読み込み直すモデルのパス
+	heldAudio     []float32                                   // モデルを読み込み直せなかった録音（hotkeyEventMutex を保持して参照）
+	heldFormat    audio.Config                                // heldAudio の形式（hotkeyEventMutex を保持して参照）
+
+	preventSleep func(reason string) (release func(), err error) // 録音中のアイドルスリープを防ぐ（テストでは差し替え、nilの場合は防がない）
+	awakeMutex   sync.Mutex                                      // releaseAwake を保護
+	releaseAwake func()                                          // 録音中に保持しているスリープ防止を解除する（保持していない場合は nil）
+
+	hotkeyEventMutex sync.Mutex   // 複数の録音用ホットキーのイベント処理を直列化
+	hotkeySession    hotkeySource // 録音中のセッションを開始したホットキー（hotkeyEventMutex を保持して参照）
+	sessionLocked    bool         // 録音中のセッションを画面ロック中に開始したか（hotkeyEventMutex を保持して参照）
+	sessionBundleID  string       // 録音開始時に最前面だったアプリのバンドルID（hotkeyEventMutex を保持して参照）
+	recordLimitTimer timerHandle  // max_record_time に達したら録音を停止する（hotkeyEventMutex を保持して参照）
+
+	streamChunks chan []byte        // 録音中の音声を途中結果の文字起こしに渡す（hotkeyEventMutex を保持して参照、streaming でない場合は nil）
+	streamCancel context.CancelFunc // 途中結果の文字起こしを中断する
+	streamDone   chan struct{}      // 途中結果の文字起こしが終わると閉じられる
+
+	apiRecordingMutex sync.Mutex // API経由の録音セッション状態を保護
+	apiRecording      bool       // API経由で録音中かどうか
+	apiLanguage       string     // API経由の録音セッションで使う言語（空の場合は設定値）
 }
 
 func init() {
@@ -58,7 +196,19 @@ func init() {
 }
 
 func main() {
-	app := &App{}
+	fakeAudio := flag.String("fake-audio", "", "マイクの代わりに使う音声ソース（WAVファイルのパス / sine / silence）")
+	noBrowser := flag.Bool("no-browser", false, "設定画面をブラウザで開かず、URLをターミナルに表示する")
+	frontendDir := flag.String("frontend-dir", os.Getenv("EZS2T_FRONTEND_DIR"), "埋め込みの代わりに設定画面を配信するディレクトリ（開発用、環境変数 EZS2T_FRONTEND_DIR でも指定可）")
+	check := flag.Bool("check", false, "メニューバーと設定画面を起動せずに診断（権限・デバイス・モデル・文字起こし）を実行し、結果を表示して終了する")
+	flag.Parse()
+
+	app := &App{
+		startBackoff:  audio.StartBackoff,
+		stats:         metrics.NewStats(metrics.DefaultWindow),
+		history:       history.NewStore(history.DefaultPath()),
+		recordingsDir: audio.DefaultRecordingsDir(),
+	}
+	app.lifecycleCtx, app.cancelLifecycle = context.WithCancel(context.Background())
 
 	// ロガーの初期化
 	loggerConfig := logger.DefaultConfig()
@@ -69,7 +219,7 @@ func main() {
 	}
 	defer app.logger.Close()
 
-	app.logger.Info("EzS2T-Whisper v%s 起動", version)
+	app.logger.Info("EzS2T-Whisper v%s 起動", version.Version)
 
 	// 設定ファイルの読み込み
 	configPath := config.GetConfigPath()
@@ -80,6 +230,26 @@ func main() {
 	}
 	app.logger.Info("設定ファイルを読み込みました: %s", configPath)
 
+	// フェイクオーディオ（コマンドライン引数が設定ファイルより優先）
+	if *fakeAudio != "" {
+		app.fakeAudioSource = *fakeAudio
+	} else if app.config.AudioBackend == "fake" {
+		app.fakeAudioSource = app.config.FakeAudioSource
+		if app.fakeAudioSource == "" {
+			app.fakeAudioSource = "sine"
+		}
+	}
+	if app.fakeAudioSource != "" {
+		app.logger.Info("フェイクオーディオを使用します: %s", app.fakeAudioSource)
+	}
+
+	// SSH接続などGUIのないセッションでは open コマンドが失敗するため、ブラウザを開かない
+	app.noBrowser = *noBrowser
+	if !app.noBrowser && isHeadlessSession(os.Getenv, launchdManagerName) {
+		app.noBrowser = true
+		app.logger.Info("GUIセッションが検出されないため、ブラウザを開かずに設定画面URLを表示します")
+	}
+
 	// セットアップウィザード初期化
 	app.wizard, err = wizard.NewSetupWizard()
 	if err != nil {
@@ -90,16 +260,96 @@ func main() {
 	app.isFirstRun = app.wizard != nil && app.wizard.ShouldShowWizard()
 
 	// Clipboard Managerの初期化
-	app.clipboard = clipboard.NewManager(clipboard.DefaultConfig())
+	app.clipboard = clipboard.NewManager(clipboardConfig(app.config))
+	app.clipboardFiles = clipboard.FilePaths
+	app.clipboardText = clipboard.GetClipboardContent
 	app.logger.Info("Clipboard Manager初期化完了")
 
+	// 録音開始の合図音（設定で有効な場合のみ鳴らす）
+	app.playStartBeep = audio.NewBeepPlayer().Play
+
+	// idle_unload_minutes のタイマー
+	app.afterFunc = func(d time.Duration, f func()) timerHandle {
+		return time.AfterFunc(d, f)
+	}
+
+	// 初期プロンプトの {app} に使う最前面のアプリ名
+	app.frontmostApp = frontapp.Name
+
+	// app_languages で認識言語を切り替えるための最前面のアプリのバンドルID
+	app.frontmostBundleID = frontapp.BundleID
+
+	// restore_focus_before_paste で録音開始時のアプリを最前面に戻す
+	app.activateApp = frontapp.Activate
+
+	// screen_locked_policy で画面ロック中の音声入力を抑止するためのロック状態
+	app.screenLocked = screenlock.Locked
+
+	// prevent_sleep_while_recording で長い録音の途中にスリープしないようにする
+	app.preventSleep = power.PreventSleep
+
 	// Whisper Recognizerの初期化
-	app.recognizer = recognition.NewWhisperRecognizer(recognition.DefaultConfig())
+	// use_gpu はモデルの読み込み方法を変えるため、再起動後に反映する
+	// 認識言語は文字起こしのたびに設定から決め直すが、初期値も設定に合わせておく
+	startupConfig := app.config.Clone()
+	recognitionConfig := recognition.DefaultConfig()
+	recognitionConfig.Language = startupConfig.Language
+	recognitionConfig.UseGPU = startupConfig.UseGPU
+	recognitionConfig.SilenceThreshold = startupConfig.SilenceThreshold
+	if !recognitionConfig.UseGPU {
+		app.logger.Info("use_gpu が無効のため、モデルを CPU のみで読み込みます")
+	}
+	app.recognizer = recognition.NewWhisperRecognizer(recognitionConfig)
 	defer app.recognizer.Close()
 
+	// ヘッダーとビルド済みライブラリの食い違いは出力の不正として現れるため、起動時に記録して警告する
+	app.whisperInfo = recognition.GetBuildInfo()
+	app.logger.Info("whisper.cpp: バージョン=%s 想定=%s 機能=%s", app.whisperInfo.Version, app.whisperInfo.ExpectedVersion, app.whisperInfo.SystemInfo)
+	if app.whisperInfo.Mismatch {
+		app.logger.Warn("whisper.cpp のバージョン %s がビルド時の想定 %s と異なります。文字起こし結果が不正になる可能性があります。whisper.cpp とアプリを同じバージョンでビルドし直してください。", app.whisperInfo.Version, app.whisperInfo.ExpectedVersion)
+	}
+
+	// --check: メニューバーとHTTPサーバーを起動せずに診断を実行して終了する
+	if *check {
+		app.checkPermissions = permissions.NewPermissionChecker().CheckAllPermissions
+		code := app.runSelfCheck(os.Stdout)
+		app.recognizer.Close()
+		app.logger.Close()
+		os.Exit(code)
+	}
+
 	// HTTPサーバーの初期化
-	app.httpServer = server.New(server.DefaultConfig())
+	serverConfig := server.DefaultConfig()
+	if *frontendDir != "" {
+		if server.DevFrontendAllowed {
+			serverConfig.FrontendDir = *frontendDir
+			app.logger.Warn("========================================")
+			app.logger.Warn("開発用フロントエンドを使用します: %s（キャッシュ無効）", *frontendDir)
+			app.logger.Warn("========================================")
+		} else {
+			app.logger.Warn("リリースビルドのため --frontend-dir を無視します: %s", *frontendDir)
+		}
+	}
+	app.httpServer = server.New(serverConfig)
 	app.apiHandler = api.New(app.config, app.wizard, app.ReloadHotkey, app.DisableHotkey, app.EnableHotkey)
+	app.apiHandler.SetStatusProvider(app.status)
+	app.apiHandler.SetLogDir(loggerConfig.LogDir)
+	app.apiHandler.SetLogSource(app.logger)
+	app.apiHandler.SetRecordingControls(app.StartAPIRecording, app.StopAPIRecording)
+	app.apiHandler.SetPasteTest(app.testPaste)
+	app.apiHandler.SetTestTranscriber(app.modelLoaded.Load, app.transcribeTest)
+	app.apiHandler.SetModelReload(app.handleReloadModel)
+	app.apiHandler.SetStats(app.stats)
+	app.apiHandler.SetSettingsSaved(app.handleSettingsSaved)
+	app.apiHandler.SetHistory(app.history)
+	app.apiHandler.SetWhisperInfo(api.WhisperInfo{
+		Version:         app.whisperInfo.Version,
+		ExpectedVersion: app.whisperInfo.ExpectedVersion,
+		SystemInfo:      app.whisperInfo.SystemInfo,
+		Mismatch:        app.whisperInfo.Mismatch,
+	})
+	app.apiHandler.SetStreamRegistry(app.httpServer.Streams())
+	app.apiHandler.SetLifecycleContext(app.lifecycleCtx)
 
 	// APIルートを登録
 	app.apiHandler.RegisterRoutes(app.httpServer.GetMux())
@@ -110,8 +360,17 @@ func main() {
 		OnReady:        app.onReady,
 		OnSettings:     app.handleOpenSettings,
 		OnRecordTest:   app.handleRecordTest,
+		OnReloadModel:  app.handleReloadModel,
+		OnResetHotkey:  app.handleResetHotkey,
 		OnDeviceChange: app.handleDeviceChange,
+		OnAbout:        app.handleAbout,
+		OnHistory:      app.handleExportHistory,
+		OnDiagnostics:  app.handleExportDiagnostics,
 		OnQuit:         app.handleQuit,
+		ShowText: func() bool {
+			// 設定画面での変更を即時反映するため毎回参照する
+			return app.config.Clone().TrayShowText
+		},
 	})
 
 	app.logger.Info("systray初期化開始")
@@ -128,17 +387,29 @@ func (a *App) onReady() {
 	permChecker := permissions.NewPermissionChecker()
 	perms := permChecker.CheckAllPermissions()
 
-	a.micGranted = perms["microphone"]
-	a.accGranted = perms["accessibility"]
+	a.micGranted.Store(perms["microphone"])
+	a.accGranted.Store(perms["accessibility"])
+	a.openAccessibilitySettings = permChecker.RequestAccessibilityPermission
+	a.checkPermissions = permChecker.CheckAllPermissions
+	a.microphoneStatus = permChecker.CheckMicrophonePermission
+	a.requestMicrophone = permChecker.RequestMicrophoneAccess
 
-	if a.micGranted {
+	if a.micGranted.Load() {
 		a.logger.Info("マイク権限: 許可済み")
+	} else if a.fakeAudioSource != "" {
+		// フェイクオーディオはマイクを使わないため権限なしでも録音を許可
+		a.logger.Warn("マイク権限: 未許可 - フェイクオーディオを使用するため続行します")
+		a.micGranted.Store(true)
+	} else if a.microphoneStatus() == permissions.PermissionNotDetermined {
+		// まだ一度も許可を求めていないアプリはシステム設定に表示されないため、ユーザーの操作を待って許可ダイアログを出す
+		a.logger.Warn("マイク権限: 未確認 - ホットキー押下時に許可を求めます")
+		a.trayMgr.ShowNotification("マイク権限", "ホットキーを押すとマイクの使用許可を求めるダイアログが表示されます。")
 	} else {
 		a.logger.Warn("マイク権限: 未許可 - 録音機能が無効化されます")
 		a.trayMgr.ShowError("マイク権限が未許可です。システム設定で許可してください。")
 	}
 
-	if a.accGranted {
+	if a.accGranted.Load() {
 		a.logger.Info("アクセシビリティ権限: 許可済み")
 	} else {
 		a.logger.Warn("アクセシビリティ権限: 未許可 - ホットキーと貼り付け機能が無効化されます")
@@ -158,8 +429,16 @@ func (a *App) onReady() {
 				a.logger.Warn("モデルのロードに失敗: %v", err)
 				a.trayMgr.ShowError(fmt.Sprintf("モデルのロードに失敗: %v", err))
 			} else {
-				a.logger.Info("モデルロード完了")
-				a.modelLoaded = true
+				tuning := a.applyModelTuning(modelPath)
+				if a.verifyModel(modelPath) {
+					a.logger.Info("モデルロード完了")
+
+					message := fmt.Sprintf("スレッド数: %d / プリセット: %s", tuning.Threads, tuning.Preset)
+					if tuning.Warning != "" {
+						message += "\n警告: モデルサイズが物理メモリに対して大きすぎます。より小さいモデルを検討してください。"
+					}
+					a.trayMgr.ShowNotification("モデルロード完了", message)
+				}
 			}
 		}
 	} else {
@@ -167,66 +446,56 @@ func (a *App) onReady() {
 	}
 
 	// オーディオドライバの初期化（マイク権限がある場合のみ）
-	if a.micGranted {
-		var err error
-		a.audioDriver, err = audio.NewPortAudioDriver()
-		if err != nil {
-			a.logger.Error("PortAudioドライバの作成に失敗: %v", err)
-			a.audioDriver = nil
-		} else {
-			a.audioConfig = audio.DefaultConfig()
-			// 設定ファイルのデバイスIDを反映（-1の場合はシステムデフォルト）
-			a.audioConfig.DeviceID = a.config.AudioDeviceID
-			a.logger.Info("設定からオーディオデバイスIDを適用: %d", a.config.AudioDeviceID)
-			if err := a.audioDriver.Initialize(a.audioConfig); err != nil {
-				a.logger.Error("オーディオドライバの初期化に失敗: %v", err)
-				// Initialize失敗時はドライバをクローズしてnilに設定
-				if closeErr := a.audioDriver.Close(); closeErr != nil {
-					a.logger.Error("ドライバのクローズに失敗: %v", closeErr)
-				}
-				a.audioDriver = nil
-				a.trayMgr.ShowError(fmt.Sprintf("オーディオデバイスの初期化に失敗しました。設定画面でデバイスを変更してください。\nエラー: %v", err))
-			} else {
-				a.logger.Info("オーディオドライバ初期化完了")
-				// API HandlerにAudioDriverを設定
-				a.apiHandler.SetAudioDriver(a.audioDriver)
-			}
-		}
+	if a.micGranted.Load() {
+		a.initAudioDriver()
 	}
 
 	// ホットキーマネージャーの初期化（アクセシビリティ権限がある場合のみ）
-	if a.accGranted {
+	if a.accGranted.Load() {
 		a.hotkeyMgr = hotkey.New()
 
 		// 設定ファイルからホットキー設定を読み込み
-		hotkeyConfig := hotkey.Config{
-			Modifiers: configToModifiers(a.config.Hotkey),
-			Key:       stringToKey(a.config.Hotkey.Key),
-			Mode:      hotkey.PressToHold, // TODO: RecordingModeから決定
-		}
+		hotkeyConfig := buildHotkeyConfig(a.config)
 
 		// ホットキーの登録
 		if err := a.hotkeyMgr.Register(hotkeyConfig); err != nil {
 			a.logger.Error("ホットキーの登録に失敗: %v", err)
-			a.trayMgr.ShowError(fmt.Sprintf("ホットキーの登録に失敗: %v", err))
+			if errors.Is(err, hotkey.ErrConflict) {
+				a.trayMgr.ShowError(hotkeyConflictMessage(err, hotkeyConfig))
+			} else {
+				a.trayMgr.ShowError(fmt.Sprintf("ホットキーの登録に失敗: %v", err))
+			}
 		} else {
 			hotkeyFormatted := hotkey.FormatHotkey(hotkeyConfig.Modifiers, hotkeyConfig.Key)
-			a.logger.Info("ホットキー登録完了: %s", hotkeyFormatted)
+			a.logger.Info("ホットキー登録完了: %s (%s)", hotkeyFormatted, hotkeyConfig.Mode)
 
 			// ホットキーイベントループを開始
 			go a.hotkeyEventLoop()
 		}
 	}
 
+	// クリップボードの音声ファイルを文字起こしするホットキーと2つ目の録音用ホットキー（設定されている場合のみ）
+	a.registerClipboardHotkey()
+	a.registerSecondHotkey()
+
 	// 初回起動時は自動的にセットアップ画面を開く
 	if a.isFirstRun && a.wizard != nil {
-		a.logger.Info("初回起動検出 - セットアップ画面を開きます")
-		a.handleOpenSettings()
+		if a.noBrowser {
+			// URLは起動メッセージで表示する
+			a.logger.Info("初回起動検出 - ブラウザを開かずに設定画面URLを表示します")
+		} else {
+			a.logger.Info("初回起動検出 - セットアップ画面を開きます")
+			a.handleOpenSettings()
+		}
 		// MarkSetupCompleted()はAPIハンドラで設定保存時に呼ばれる
 	}
 
 	a.logger.Info("アプリケーション初期化完了")
 
+	// モデル未設定・権限なしの場合はトレイアイコンを警告表示にし、回復したら戻す
+	a.updateHealth(perms)
+	go a.watchPermissions()
+
 	// デバイスメニューを初期化
 	a.updateDeviceMenu()
 
@@ -236,6 +505,9 @@ func (a *App) onReady() {
 		a.trayMgr.ShowError("設定画面の起動に失敗しました")
 	}
 
+	// アップデート確認（設定で有効な場合のみ、起動時と1日ごと）
+	go a.runUpdateChecks()
+
 	// シグナルハンドリングを設定（Ctrl+Cでの適切な終了処理）
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -250,6 +522,9 @@ func (a *App) onReady() {
 	fmt.Println("[起動] EzS2T-Whisper が起動しました")
 	fmt.Println("==========================================================")
 	fmt.Printf("[設定] 設定画面URL: %s\n", a.httpServer.URL())
+	if a.isFirstRun && a.noBrowser {
+		fmt.Printf("[初回] 上記URLをブラウザで開いてセットアップしてください\n")
+	}
 	fmt.Printf("[操作] メニューバーのアイコンをクリックしてメニューを開けます\n")
 
 	// 現在のホットキー設定を表示
@@ -265,6 +540,174 @@ func (a *App) onReady() {
 	fmt.Println("==========================================================")
 }
 
+// checkResult は --check の診断項目1つの結果
+type checkResult struct {
+	name   string
+	ok     bool
+	detail string
+}
+
+// runSelfCheck は --check の診断を実行して結果を w に表示し、終了コード（すべて合格なら0）を返す
+// SSH 経由でのインストール確認や環境の問題の再現に使う
+func (a *App) runSelfCheck(w io.Writer) int {
+	fmt.Fprintf(w, "EzS2T-Whisper v%s 診断\n", version.Version)
+
+	failed := 0
+	results := a.selfCheck()
+	for _, result := range results {
+		mark := "[OK]  "
+		if !result.ok {
+			mark = "[FAIL]"
+			failed++
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", mark, result.name, result.detail)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "結果: %d 項目中 %d 項目が失敗しました\n", len(results), failed)
+		return 1
+	}
+	fmt.Fprintf(w, "結果: %d 項目すべて合格しました\n", len(results))
+	return 0
+}
+
+// selfCheck は権限・入力デバイス・モデルの読み込み・サンプル音声の文字起こしを順に確認する
+// 通常の起動と同じ権限チェック・オーディオドライバ・認識器を使う
+func (a *App) selfCheck() []checkResult {
+	var results []checkResult
+	add := func(name string, err error, detail string) {
+		if err != nil {
+			results = append(results, checkResult{name: name, detail: err.Error()})
+			return
+		}
+		results = append(results, checkResult{name: name, ok: true, detail: detail})
+	}
+
+	// 権限（フェイクオーディオではマイクを使わないためマイク権限は不要）
+	perms := a.checkPermissions()
+	switch {
+	case perms["microphone"]:
+		add("マイク権限", nil, "許可済み")
+	case a.fakeAudioSource != "":
+		add("マイク権限", nil, "未許可（フェイクオーディオを使用するため不要）")
+	default:
+		add("マイク権限", errors.New("未許可です。システム設定 > プライバシーとセキュリティ > マイク で許可してください"), "")
+	}
+	if perms["accessibility"] {
+		add("アクセシビリティ権限", nil, "許可済み")
+	} else {
+		add("アクセシビリティ権限", errors.New("未許可です。ホットキーと貼り付けに必要です"), "")
+	}
+
+	// whisper.cpp のライブラリがビルド時のヘッダーと同じバージョンか
+	if a.whisperInfo.Mismatch {
+		add("whisper.cpp", fmt.Errorf("ライブラリのバージョン %s がビルド時の想定 %s と異なります", a.whisperInfo.Version, a.whisperInfo.ExpectedVersion), "")
+	} else {
+		whisperVersion := a.whisperInfo.Version
+		if whisperVersion == "" {
+			whisperVersion = "バージョン不明"
+		}
+		add("whisper.cpp", nil, whisperVersion)
+	}
+
+	// 入力デバイス
+	devices, err := a.checkDevices()
+	add("入力デバイス", err, devices)
+
+	// モデルの読み込みと動作確認
+	modelErr := a.checkModel()
+	modelPath, _ := a.config.GetModelPath()
+	add("モデル", modelErr, filepath.Base(modelPath))
+
+	// サンプル音声の文字起こし（フェイクオーディオの音声ファイルがあればそれを使う）
+	if modelErr != nil {
+		add("文字起こし", errors.New("モデルが読み込めないため実行できません"), "")
+	} else {
+		result, err := a.checkTranscription()
+		add("文字起こし", err, fmt.Sprintf("%q（音声 %dms / 推論 %dms）", result.Text, result.DurationMS, result.InferenceMS))
+	}
+
+	return results
+}
+
+// checkDevices は入力デバイスの一覧を「名前, 名前（既定）」の形式で返す
+func (a *App) checkDevices() (string, error) {
+	driver, err := a.newAudioDriver()
+	if err != nil {
+		return "", fmt.Errorf("オーディオドライバを作成できません: %w", err)
+	}
+	defer driver.Close()
+
+	devices, err := driver.ListDevices()
+	if err != nil {
+		return "", fmt.Errorf("デバイス一覧を取得できません: %w", err)
+	}
+	if len(devices) == 0 {
+		return "", errors.New("入力デバイスが見つかりません")
+	}
+
+	names := make([]string, 0, len(devices))
+	for _, device := range devices {
+		name := device.Name
+		if device.IsDefault {
+			name += "（既定）"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// checkModel は設定されたモデルを読み込み、無音で一度推論できることを確認する
+func (a *App) checkModel() error {
+	if err := a.config.ValidateModelPath(); err != nil {
+		return err
+	}
+	modelPath, err := a.config.GetModelPath()
+	if err != nil {
+		return err
+	}
+
+	if err := a.recognizer.LoadModel(modelPath); err != nil {
+		return fmt.Errorf("読み込みに失敗: %w", err)
+	}
+	a.applyModelTuning(modelPath)
+	if err := a.recognizer.SelfTest(); err != nil {
+		return fmt.Errorf("動作確認に失敗: %w", err)
+	}
+	return nil
+}
+
+// checkTranscription はサンプル音声（フェイクオーディオの音源、未指定の場合はサイン波）を
+// 通常の録音と同じ前処理で文字起こしする
+func (a *App) checkTranscription() (recognition.Result, error) {
+	source := a.fakeAudioSource
+	if source == "" {
+		source = "sine"
+	}
+
+	driver, err := fakeaudio.NewFromSource(source, 0)
+	if err != nil {
+		return recognition.Result{}, fmt.Errorf("サンプル音声を読み込めません: %w", err)
+	}
+	defer driver.Close()
+
+	// 文字起こしは audioConfig のサンプルレートを参照する
+	audioConfig := audio.DefaultConfig()
+	a.setAudioState(nil, audioConfig)
+	if err := driver.Initialize(audioConfig); err != nil {
+		return recognition.Result{}, err
+	}
+	if err := driver.StartRecording(); err != nil {
+		return recognition.Result{}, err
+	}
+	audioData, err := driver.StopRecording()
+	if err != nil {
+		return recognition.Result{}, err
+	}
+
+	return a.transcribe(audioData, audio.RecordingFormat(driver, audioConfig), "")
+}
+
 // hotkeyEventLoop はホットキーイベントを処理するループ
 func (a *App) hotkeyEventLoop() {
 	a.hotkeyEventLoopWg.Add(1)
@@ -272,137 +715,1496 @@ func (a *App) hotkeyEventLoop() {
 
 	a.logger.Info("ホットキーイベントループ開始")
 
-	eventChan := a.hotkeyMgr.Events()
+	a.processHotkeyEvents(a.hotkeyMgr.Events(), primaryHotkey)
+
+	a.logger.Info("ホットキーイベントループ終了")
+}
+
+// processHotkeyEvents はチャネルが閉じられるまでホットキーイベントを処理する
+// 複数のホットキーのイベントは1つずつ順に処理する
+func (a *App) processHotkeyEvents(eventChan <-chan hotkey.Event, source hotkeySource) {
+	for event := range eventChan {
+		a.hotkeyEventMutex.Lock()
+		a.handleHotkeyEvent(event, source)
+		a.hotkeyEventMutex.Unlock()
+	}
+}
+
+// handleHotkeyEvent は source のホットキーのイベントを1つ処理する（hotkeyEventMutex を保持して呼ぶ）
+// 押下で録音開始、解放で録音停止 → 文字起こし → 貼り付けを行う
+// 録音中は、その録音を開始したホットキー以外の解放・取り消しは無視する
+func (a *App) handleHotkeyEvent(event hotkey.Event, source hotkeySource) {
+	driver, audioConfig := a.audioState()
+	switch event.Type {
+	case hotkey.Pressed:
+		if a.isAPIRecording() {
+			a.logger.Warn("ホットキー押下検出しましたが、API経由で録音中のため無視します")
+			return
+		}
+		if !a.micGranted.Load() {
+			// 未確認の場合はこの押下をきっかけに macOS の許可ダイアログを表示する（録音は許可後の次の押下から）
+			if a.microphoneStatus != nil && a.microphoneStatus() == permissions.PermissionNotDetermined {
+				a.logger.Info("ホットキー押下検出 - マイク権限が未確認のため許可を求めます")
+				go a.promptMicrophone()
+				return
+			}
+			a.logger.Warn("ホットキー押下検出しましたが、マイク権限がないため無視します")
+			return
+		}
+		if driver == nil {
+			a.logger.Warn("ホットキー押下検出しましたが、オーディオデバイスが初期化されていないため無視します")
+			a.trayMgr.ShowError("オーディオデバイスが初期化されていません。設定画面でデバイスを確認してください。")
+			return
+		}
+
+		if a.hotkeySession != noHotkey {
+			a.logger.Warn("ホットキー押下検出しましたが、別のホットキーで録音中のため無視します")
+			return
+		}
+
+		// 画面ロック中にキーボードマクロなどから押された場合は、ポリシーに従って録音しない
+		locked := a.isScreenLocked()
+		if screenLockAction(a.config.Clone().ScreenLockedPolicy, locked) == lockIgnore {
+			a.logger.Debug("ホットキー押下検出しましたが、画面ロック中のため無視します")
+			return
+		}
+
+		a.logger.Info("ホットキー押下検出 - 録音開始")
+		a.trayMgr.SetState(tray.StateRecording)
+
+		// 録音の先頭から途中結果に渡せるよう、録音開始前に始める
+		a.startStreaming()
+		// スリープ解除や Bluetooth の切り替え直後の一時的なエラーは、メニューバーを録音中のまま短く再試行する
+		// 再試行中の音声はストリームが動いていないため録音されない。合図音は録音が始まってから鳴らす
+		if err := audio.StartWithRetry(driver, a.startBackoff); err != nil {
+			a.logger.Error("録音開始エラー: %v", err)
+			a.stopStreaming()
+			a.trayMgr.ShowError(recordingStartErrorMessage(err))
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+		a.hotkeySession = source
+		a.sessionLocked = locked
+		a.sessionBundleID = a.frontmostBundle()
+		a.startRecordLimit(source)
+		a.keepAwake()
+
+		// 録音が始まった瞬間に合図音を鳴らし、話し始めるタイミングを知らせる
+		a.startBeepPlayed = false
+		if a.config.Clone().StartBeep && a.playStartBeep != nil {
+			if err := a.playStartBeep(); err != nil {
+				a.logger.Warn("合図音の再生に失敗: %v", err)
+			} else {
+				a.startBeepPlayed = true
+			}
+		}
+
+	case hotkey.Cancelled:
+		if !a.micGranted.Load() || driver == nil || a.isAPIRecording() || a.hotkeySession != source {
+			return
+		}
+		a.hotkeySession = noHotkey
+		a.stopRecordLimit()
+		a.allowSleep()
+		a.stopStreaming()
+		a.startBeepPlayed = false
+
+		// トグルモードで開始直後に停止された場合は誤操作（ダブルタップ）として破棄
+		a.logger.Info("トグル開始直後の停止を検出 - 録音を破棄します")
+
+		if _, err := driver.StopRecording(); err != nil {
+			a.logger.Warn("録音停止エラー: %v", err)
+		}
+		a.trayMgr.SetState(tray.StateIdle)
+
+	case hotkey.Released:
+		if !a.micGranted.Load() || driver == nil || a.isAPIRecording() || a.hotkeySession != source {
+			return
+		}
+		a.hotkeySession = noHotkey
+		a.stopRecordLimit()
+		a.allowSleep()
+		a.stopStreaming()
+
+		a.logger.Info("ホットキー解放検出 - 録音停止")
+		a.trayMgr.SetState(tray.StateProcessing)
+
+		samples, err := a.stopRecordingSamples(driver)
+		if err != nil {
+			a.logger.Error("録音停止エラー: %v", err)
+			a.trayMgr.ShowError(fmt.Sprintf("録音停止に失敗: %v", err))
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		format := audio.RecordingFormat(driver, audioConfig)
+		a.logger.Info("録音データ受信: %d サンプル (%d Hz)", len(samples), format.SampleRate)
+
+		// 内蔵マイクが合図音を拾っていても文字起こしされないよう、先頭を無音に置き換える
+		if a.startBeepPlayed {
+			samples = audio.MuteLeadingFloat32(samples, format.SampleRate, format.Channels, audio.StartBeepGuard)
+			a.startBeepPlayed = false
+		}
+
+		// データが空の場合はスキップ
+		if len(samples) == 0 {
+			a.logger.Warn("録音データが空です")
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		// ホットキーに触れただけの短い録音は、存在しない文が生成されないよう通知なしで破棄する
+		// 押していた時間ではなく、実際に録音されたサンプル数で判定する
+		if minRecord := time.Duration(a.config.Clone().MinRecordMs) * time.Millisecond; minRecord > 0 {
+			if length := format.SamplesDuration(len(samples)); length < minRecord {
+				a.logger.Debug("録音が短すぎるため破棄します (%dms < %dms)", length.Milliseconds(), minRecord.Milliseconds())
+				a.trayMgr.SetState(tray.StateIdle)
+				return
+			}
+		}
+
+		// 無音や空の結果の原因を調べられるよう、文字起こしの前に録音を保存する
+		a.saveRecordingSamples(samples, format)
+
+		// マイクがミュートされている場合は文字起こしせずに通知
+		if audio.IsSilentFloat32(samples) {
+			a.logger.Warn("録音データが無音です（マイクのミュートまたは故障の可能性）")
+			a.trayMgr.ShowError(silentMicMessage)
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		// モデルがない場合はスキップ
+		if !a.modelLoaded.Load() {
+			a.logger.Warn("モデル未読み込みのため文字起こしをスキップ")
+			a.trayMgr.ShowError("モデルが読み込まれていません。設定画面でモデルを選択してください。")
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		// 前回モデルを読み込み直せずに保持していた録音があれば、続けて文字起こしする
+		if len(a.heldAudio) > 0 {
+			a.logger.Info("保持していた録音を先頭に追加: %d サンプル", len(a.heldAudio))
+			held := a.heldAudio
+			if a.heldFormat.SampleRate != format.SampleRate {
+				// 保持した後にデバイスが変わった場合は、今回の録音のレートに揃える
+				held = audio.NewPipeline(audio.PipelineConfig{InputRate: a.heldFormat.SampleRate, OutputRate: format.SampleRate}).ProcessFloat32(held)
+			}
+			samples = append(held, samples...)
+			a.heldAudio = nil
+		}
+
+		// 文字起こし処理
+		a.logger.Info("文字起こし処理開始")
+
+		// ホットキーごとの上書き（翻訳・言語）を適用する
+		options := a.hotkeyOptions(source)
+		if options.Language == "" {
+			options.Language = a.recognitionLanguage()
+		}
+		timing := metrics.Timing{Audio: format.SamplesDuration(len(samples))}
+		transcribeStart := time.Now()
+		result, task, err := a.transcribeWith(samples, format, options)
+		timing.Transcribe = time.Since(transcribeStart)
+		if errors.Is(err, errModelWake) {
+			// 録音は破棄せず、モデルを読み込み直せた次の録音と合わせて文字起こしする
+			a.logger.Error("文字起こしエラー: %v (録音を保持します)", err)
+			a.heldAudio = samples
+			a.heldFormat = format
+			a.trayMgr.ShowError(fmt.Sprintf("モデルを読み込み直せませんでした。録音は保持され、次の録音と合わせて文字起こしされます。\nエラー: %v", err))
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+		if err != nil {
+			a.logger.Error("文字起こしエラー: %v", err)
+			a.trayMgr.ShowError(fmt.Sprintf("文字起こしに失敗: %v", err))
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		transcription := result.Text
+		a.logTranscription("文字起こし完了", transcription)
+
+		// 遅いのがモデルか貼り付けかを切り分けられるよう、成功した文字起こしは貼り付けまで含めて計測値を記録する
+		defer func() { a.recordTiming(timing) }()
+
+		// ハルシネーションによる繰り返し出力を検出した場合は通知
+		if result.Suspect {
+			a.logger.Warn("出力に繰り返しを検出 (圧縮率: %.2f)", result.CompressionRatio)
+			a.trayMgr.ShowNotification("文字起こし", "出力に繰り返しが検出されました")
+		}
+
+		// 文字起こし結果が空の場合はスキップ
+		if transcription == "" {
+			a.logger.Warn("文字起こし結果が空です")
+			// 無音と判定した場合は、何も起きなかったように見えないよう通知する
+			if result.Silent {
+				a.trayMgr.ShowNotification("文字起こし", "無音と判定したため文字起こししませんでした。マイクの入力レベルか silence_threshold を確認してください。")
+			}
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		// 誤ったウィンドウに貼り付けても後から取り出せるよう、貼り付ける前に履歴に残す
+		a.recordHistory(transcription, timing.Audio, result.Language, task, result.Suspect)
+
+		// 録音開始時か現在、画面がロックされている場合は、ロック解除時の最前面のウィンドウに貼り付けない
+		locked := a.sessionLocked || a.isScreenLocked()
+		if screenLockAction(a.config.Clone().ScreenLockedPolicy, locked) != lockNormal {
+			a.copyLockedTranscription(transcription)
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
+		message := hotkeyModeLabel(task, completionLanguage(result, task, options.Language, a.config.Clone().Language))
+		if a.config.Clone().ShowTimings {
+			message = strings.TrimSpace(message + "\n" + timing.Summary())
+		}
+		if message != "" {
+			a.trayMgr.ShowNotification("文字起こし", message)
+		}
+
+		pasteStart := time.Now()
+		if a.config.Clone().RestoreFocusBeforePaste {
+			a.restoreFocus(a.sessionBundleID)
+		}
+		a.pasteTranscription(transcription)
+		timing.Paste = time.Since(pasteStart)
+		a.trayMgr.SetState(tray.StateIdle)
+	}
+}
+
+// startRecordLimit は source のホットキーで始めた録音を、録音モードの最大録音時間の後に停止するタイマーを設定する
+// トグルモードで停止を押し忘れても、録音が続き続けないようにする（hotkeyEventMutex を保持して呼ぶ）
+func (a *App) startRecordLimit(source hotkeySource) {
+	a.stopRecordLimit()
+
+	cfg := a.config.Clone()
+	limit := time.Duration(cfg.MaxRecordTimeFor(cfg.RecordingMode)) * time.Second
+	if limit <= 0 || a.afterFunc == nil {
+		return
+	}
+
+	var timer timerHandle
+	timer = a.afterFunc(limit, func() {
+		a.stopAtRecordLimit(timer, source, limit)
+	})
+	a.recordLimitTimer = timer
+}
+
+// stopRecordLimit は最大録音時間のタイマーを止める（hotkeyEventMutex を保持して呼ぶ）
+func (a *App) stopRecordLimit() {
+	if a.recordLimitTimer != nil {
+		a.recordLimitTimer.Stop()
+		a.recordLimitTimer = nil
+	}
+}
+
+// stopAtRecordLimit はタイマーの満了時に、ホットキーを離した（トグルでは再度押した）ときと同じく録音を停止して文字起こしする
+// 既に停止した録音や、その後に始めた別の録音のタイマーでは何もしない
+func (a *App) stopAtRecordLimit(timer timerHandle, source hotkeySource, limit time.Duration) {
+	a.hotkeyEventMutex.Lock()
+	defer a.hotkeyEventMutex.Unlock()
+
+	if a.recordLimitTimer != timer || a.hotkeySession != source {
+		return
+	}
+	a.recordLimitTimer = nil
+
+	a.logger.Warn("最大録音時間 (%d秒) に達したため録音を停止します", int(limit.Seconds()))
+	a.trayMgr.ShowNotification("録音", notification.RecordingTimeExceededMessage(int(limit.Seconds())))
+
+	// トグルモードでは次の押下が停止ではなく新しい録音の開始になるよう、ホットキー側の状態も戻す
+	if mgr := a.recordingHotkey(source); mgr != nil {
+		mgr.ResetToggle()
+	}
+	a.handleHotkeyEvent(hotkey.Event{Type: hotkey.Released}, source)
+}
+
+// keepAwake は prevent_sleep_while_recording が有効な場合、allowSleep が呼ばれるまでシステムのアイドルスリープを防ぐ
+// バッテリー駆動のノートで長く話している間にスリープして録音が途切れないようにする
+func (a *App) keepAwake() {
+	if a.preventSleep == nil || !a.config.Clone().PreventSleepWhileRecording {
+		return
+	}
+
+	a.awakeMutex.Lock()
+	defer a.awakeMutex.Unlock()
+
+	if a.releaseAwake != nil {
+		return
+	}
+	release, err := a.preventSleep("EzS2T-Whisper で録音中")
+	if err != nil {
+		// スリープを防げなくても録音は続ける
+		a.logger.Warn("スリープ防止の設定に失敗: %v", err)
+		return
+	}
+	a.releaseAwake = release
+}
+
+// allowSleep は keepAwake で防いでいたスリープを元に戻す
+func (a *App) allowSleep() {
+	a.awakeMutex.Lock()
+	defer a.awakeMutex.Unlock()
+
+	if a.releaseAwake != nil {
+		a.releaseAwake()
+		a.releaseAwake = nil
+	}
+}
+
+// startStreaming は streaming が有効な場合、録音中の音声を途中まで文字起こしし、途中結果をトレイのツールチップに表示する
+// 貼り付けるテキストは録音停止後に録音全体を通常どおり文字起こしするため、streaming でない場合と同じになる（hotkeyEventMutex を保持して呼ぶ）
+func (a *App) startStreaming() {
+	if !a.config.Clone().Streaming {
+		return
+	}
+	recognizer, ok := a.recognizer.(streamingRecognizer)
+	if !ok {
+		return
+	}
+	driver, audioConfig := a.audioState()
+	notifier, ok := driver.(audio.ChunkNotifier)
+	if !ok {
+		a.logger.Warn("オーディオドライバが途中結果に対応していないため、streaming を使用しません")
+		return
+	}
+
+	chunks := make(chan []byte, streamChunkBuffer)
+	notifier.SetChunkHandler(func(pcm []byte) {
+		// 音声コールバックを止めないよう、文字起こしが追いつかない分は落とす
+		select {
+		case chunks <- pcm:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	partials := recognizer.TranscribeStream(ctx, audioConfig.SampleRate, chunks)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for partial := range partials {
+			a.trayMgr.SetPartialText(partial.Text)
+		}
+	}()
+	a.streamChunks = chunks
+	a.streamCancel = cancel
+	a.streamDone = done
+}
+
+// stopStreaming は途中結果の文字起こしを中断して終了を待ち、ツールチップの途中結果を消す（hotkeyEventMutex を保持して呼ぶ）
+// 録音全体の文字起こしを待たせないよう、実行中の文字起こしも溜まっているチャンクも破棄する
+func (a *App) stopStreaming() {
+	if a.streamChunks == nil {
+		return
+	}
+	driver, _ := a.audioState()
+	if notifier, ok := driver.(audio.ChunkNotifier); ok {
+		notifier.SetChunkHandler(nil)
+	}
+	a.streamCancel()
+	close(a.streamChunks)
+	<-a.streamDone
+	a.streamChunks = nil
+	a.streamCancel = nil
+	a.streamDone = nil
+	a.trayMgr.SetPartialText("")
+}
+
+// recordingHotkey は source の録音用ホットキーのマネージャーを返す（登録されていない場合は nil）
+func (a *App) recordingHotkey(source hotkeySource) *hotkey.Manager {
+	switch source {
+	case primaryHotkey:
+		return a.hotkeyMgr
+	case secondHotkey:
+		return a.secHotkey
+	}
+	return nil
+}
+
+// focusRestoreDelay はアプリを最前面に戻してから貼り付けるまでの待ち時間
+// osascript の activate は切り替えの完了を待たずに戻るため
+const focusRestoreDelay = 200 * time.Millisecond
+
+// frontmostBundle は最前面のアプリのバンドルIDを返す（取得できない場合は空文字列）
+func (a *App) frontmostBundle() string {
+	if a.frontmostBundleID == nil {
+		return ""
+	}
+	return a.frontmostBundleID()
+}
+
+// restoreFocus は録音開始時に最前面だった bundleID のアプリが最前面でなくなっていれば、最前面に戻す
+// トレイのクリックなど、録音の操作自体でフォーカスが移った場合に別のウィンドウへ貼り付けないようにする
+// 戻せなかった場合は警告をログに残し、現在の最前面のアプリに貼り付ける
+func (a *App) restoreFocus(bundleID string) {
+	if bundleID == "" || a.activateApp == nil || a.frontmostBundle() == bundleID {
+		return
+	}
+
+	a.logger.Info("貼り付け前にフォーカスを戻します: %s", bundleID)
+	if err := a.activateApp(bundleID); err != nil {
+		a.logger.Warn("フォーカスを戻せませんでした: %v", err)
+		return
+	}
+	time.Sleep(focusRestoreDelay)
+}
+
+// recordTiming は1回の文字起こしの計測値をログに1行で記録し、/api/stats の集計に加える
+func (a *App) recordTiming(timing metrics.Timing) {
+	a.logger.Info("文字起こし計測: %s", timing)
+	if a.stats != nil {
+		a.stats.Add(timing)
+	}
+}
+
+// saveRecording は save_recordings が有効な場合に録音データを WAV で保存し、保存先のパスを返す（保存しなかった場合は ""）
+// 保存のたびに、recordings_retention_days を過ぎた録音を削除する
+func (a *App) saveRecording(audioData []byte, format audio.Config) string {
+	cfg := a.config.Clone()
+	if !cfg.SaveRecordings || a.recordingsDir == "" {
+		return ""
+	}
+
+	now := time.Now()
+	path, err := audio.SaveRecording(a.recordingsDir, audioData, format.SampleRate, format.Channels, now)
+	if err != nil {
+		a.logger.Warn("録音の保存に失敗: %v", err)
+		return ""
+	}
+	a.logger.Info("録音を保存しました: %s", path)
+
+	if err := audio.CleanOldRecordings(a.recordingsDir, cfg.RecordingsRetentionDays, now); err != nil {
+		a.logger.Warn("古い録音の削除に失敗: %v", err)
+	}
+	return path
+}
+
+// saveRecordingSamples は float32 の録音を saveRecording で保存する
+// 16bit PCM への変換は save_recordings が有効な場合にだけ行う
+func (a *App) saveRecordingSamples(samples []float32, format audio.Config) string {
+	if !a.config.Clone().SaveRecordings {
+		return ""
+	}
+	return a.saveRecording(audio.Float32ToPCM(samples), format)
+}
+
+// stopRecordingSamples は録音を停止し、Whisper にそのまま渡せる float32 のサンプルを返す
+// ドライバが float32 で返せる場合は 16bit PCM を経由せず、省略した変換の推定 CPU 時間をログに残す
+func (a *App) stopRecordingSamples(driver audio.AudioDriver) ([]float32, error) {
+	if recorder, ok := driver.(audio.Float32Recorder); ok {
+		samples, err := recorder.StopRecordingFloat32()
+		if err != nil {
+			return nil, err
+		}
+		a.logger.Info("float32 で録音を受け取り PCM 変換を省略: %d サンプル (推定 CPU 時間 %v 削減)", len(samples), pcmRoundTripCost(len(samples)))
+		return samples, nil
+	}
+
+	audioData, err := driver.StopRecording()
+	if err != nil {
+		return nil, err
+	}
+	return audio.PCMToFloat32(audioData), nil
+}
+
+// pcmSampleCost は 1 サンプルを 16bit PCM に変換して float32 に戻すのにかかる時間（ナノ秒）
+// 最初に使うときに 1 秒分の無音を往復させて計測する
+var pcmSampleCost = sync.OnceValue(func() float64 {
+	samples := make([]float32, 16000)
+	start := time.Now()
+	audio.PCMToFloat32(audio.Float32ToPCM(samples))
+	return float64(time.Since(start)) / float64(len(samples))
+})
+
+// pcmRoundTripCost は n サンプルを 16bit PCM 経由で受け渡した場合にかかる推定 CPU 時間を返す
+func pcmRoundTripCost(n int) time.Duration {
+	return time.Duration(pcmSampleCost() * float64(n))
+}
+
+// recordingNote は保存した録音のパスを通知の末尾に付ける文字列にする（保存していない場合は ""）
+func recordingNote(path string) string {
+	if path == "" {
+		return ""
+	}
+	return "\n録音: " + path
+}
+
+// recordHistory は文字起こし結果を、認識した言語と実際のタスク（翻訳したか）、繰り返しを検出したかとともに履歴に追加する
+// history_enabled が false の場合は記録しない
+func (a *App) recordHistory(text string, audioLength time.Duration, language string, task recognition.Task, suspect bool) {
+	cfg := a.config.Clone()
+	if !cfg.HistoryEnabled || a.history == nil {
+		return
+	}
+
+	mode := history.ModeTranscribe
+	if task == recognition.TaskTranslate {
+		mode = history.ModeTranslate
+	}
+	entry := history.Entry{
+		Text:       text,
+		DurationMS: audioLength.Milliseconds(),
+		Model:      filepath.Base(cfg.ModelPath),
+		Language:   language,
+		Mode:       mode,
+		Suspect:    suspect,
+	}
+	if _, err := a.history.Add(entry, cfg.HistoryMaxEntries); err != nil {
+		a.logger.Warn("文字起こし履歴の保存に失敗: %v", err)
+	}
+}
+
+// lockAction は画面ロック中のホットキーによる音声入力の扱い
+type lockAction int
+
+const (
+	lockNormal        lockAction = iota // 通常どおり録音して貼り付ける
+	lockClipboardOnly                   // 録音するが、貼り付けずにクリップボードにのみコピーする
+	lockIgnore                          // ホットキーの押下を無視する
+)
+
+// screenLockAction は screen_locked_policy と画面のロック状態から音声入力の扱いを決める
+// 不明なポリシーは安全側（無視）に倒す
+func screenLockAction(policy string, locked bool) lockAction {
+	if !locked {
+		return lockNormal
+	}
+	switch policy {
+	case config.ScreenLockedNormal:
+		return lockNormal
+	case config.ScreenLockedClipboardOnly:
+		return lockClipboardOnly
+	default:
+		return lockIgnore
+	}
+}
+
+// isScreenLocked は画面がロックされているかを返す
+func (a *App) isScreenLocked() bool {
+	return a.screenLocked != nil && a.screenLocked()
+}
+
+// copyLockedTranscription は画面ロック中の文字起こし結果を貼り付けずにクリップボードにコピーする
+// ロック画面に表示される可能性があるため、通知には結果の本文を含めない
+// 録音開始後にロックされた場合（ignore ポリシー）も、録音済みの結果は破棄せずにコピーする
+func (a *App) copyLockedTranscription(transcription string) {
+	a.logger.Info("画面ロック中のため貼り付けずにクリップボードにコピーします")
+	if err := a.clipboard.CopyText(transcription); err != nil {
+		a.logger.Error("クリップボードへのコピーに失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("クリップボードへのコピーに失敗: %v", err))
+		return
+	}
+	a.trayMgr.ShowNotification("文字起こし", "画面ロック中の文字起こし結果をクリップボードにコピーしました")
+}
+
+// hotkeySource は録音を開始したホットキーを表す
+type hotkeySource int
+
+const (
+	noHotkey      hotkeySource = iota // ホットキーで録音中ではない
+	primaryHotkey                     // hotkey
+	secondHotkey                      // second_hotkey
+)
+
+// hotkeyOptions は source のホットキーに設定された認識の上書きを返す
+func (a *App) hotkeyOptions(source hotkeySource) config.HotkeyOptions {
+	cfg := a.config.Clone()
+	switch source {
+	case primaryHotkey:
+		return cfg.Hotkey.Options
+	case secondHotkey:
+		return cfg.SecondHotkey.Options
+	}
+	return config.HotkeyOptions{}
+}
+
+// completionLanguage は完了通知に表示する言語を返す
+// ホットキーやアプリ別の設定で language が global の言語と異なる場合と翻訳した場合だけ、実際に使った言語
+// （auto の場合は検出した言語）を返す。それ以外は空文字列
+func completionLanguage(result recognition.Result, task recognition.Task, language, global string) string {
+	if language == global && task != recognition.TaskTranslate {
+		return ""
+	}
+	if result.Language != "" && result.Language != "auto" {
+		return result.Language
+	}
+	if language == "auto" {
+		return ""
+	}
+	return language
+}
+
+// hotkeyModeLabel は翻訳したかと言語を通知用の短い説明にする（どちらもない場合は空文字列）
+func hotkeyModeLabel(task recognition.Task, language string) string {
+	translate := task == recognition.TaskTranslate
+	switch {
+	case translate && language != "":
+		return fmt.Sprintf("%s の音声を英語に翻訳しました", language)
+	case translate:
+		return "英語に翻訳しました"
+	case language != "":
+		return fmt.Sprintf("%s で文字起こししました", language)
+	}
+	return ""
+}
+
+// pasteTranscription は文字起こし結果を最前面のアプリに貼り付ける
+// 上限を超えた結果は切り詰めて貼り付け、全文はクリップボードに残す
+// 出力モードが copy のアプリ（ターミナルなど）では貼り付けずにクリップボードにコピーする
+// paste_timestamp が設定されている場合は現在時刻を先頭に付ける
+func (a *App) pasteTranscription(transcription string) {
+	transcription = prependTimestamp(transcription, a.config.Clone().PasteTimestamp, time.Now())
+
+	if bundleID := a.frontmostBundle(); a.config.OutputModeFor(bundleID) == config.OutputCopy {
+		a.copyTranscription(transcription, bundleID)
+		return
+	}
+
+	// クリップボードに貼り付け（アクセシビリティ権限が必要）
+	if !a.accGranted.Load() {
+		a.logger.Warn("アクセシビリティ権限なしのため貼り付けをスキップ")
+		a.trayMgr.ShowError("アクセシビリティ権限がありません。システム設定で許可してください。")
+		return
+	}
+
+	// 暴走した出力を大量にタイプしないよう、上限を超えた分は切り詰める
+	maxPasteChars := a.config.Clone().MaxPasteChars
+	pasteText, truncated := truncateRunes(transcription, maxPasteChars)
+
+	a.logger.Info("クリップボード貼り付け開始")
+
+	if err := a.pasteText(pasteText); err != nil {
+		if errors.Is(err, context.Canceled) {
+			a.logger.Info("終了処理のため貼り付けを中断しました")
+			return
+		}
+		if errors.Is(err, clipboard.ErrAccessibilityDenied) {
+			a.handleAccessibilityLost(transcription)
+			return
+		}
+		if errors.Is(err, clipboard.ErrSecureInput) {
+			a.handleSecureInputBlocked(transcription)
+			return
+		}
+		a.logger.Error("貼り付けエラー: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("貼り付けに失敗: %v", err))
+		return
+	}
+
+	if truncated {
+		// 全文はクリップボードに残し、必要なら手動で貼り付けられるようにする
+		a.logger.Warn("文字起こし結果が上限を超えたため切り詰めました (%d 文字 > %d 文字)", utf8.RuneCountInString(transcription), maxPasteChars)
+		if err := a.clipboard.CopyText(transcription); err != nil {
+			a.logger.Error("全文のクリップボードへのコピーに失敗: %v", err)
+		}
+		a.trayMgr.ShowNotification("文字起こし", fmt.Sprintf("結果が長すぎるため先頭の%d文字のみ貼り付けました。全文はクリップボードにあります。", maxPasteChars))
+	}
+
+	a.logger.Info("貼り付け完了")
+}
+
+// registerClipboardHotkey は clipboard_transcribe_hotkey を登録し、押されたら
+// クリップボードの音声ファイルを文字起こしする
+// 設定の変更は再起動後に反映される
+func (a *App) registerClipboardHotkey() {
+	cfg := a.config.Clone().ClipboardTranscribeHotkey
+	if cfg.Key == "" {
+		return
+	}
+
+	hotkeyConfig := hotkey.Config{
+		Modifiers: configToModifiers(cfg),
+		Key:       hotkey.KeyFromString(cfg.Key),
+		Mode:      hotkey.PressToHold,
+	}
+	hotkeyFormatted := hotkey.FormatHotkey(hotkeyConfig.Modifiers, hotkeyConfig.Key)
+
+	mgr := hotkey.New()
+	if err := mgr.Register(hotkeyConfig); err != nil {
+		a.logger.Error("クリップボード文字起こしのホットキー登録に失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("クリップボード文字起こしのホットキー (%s) を登録できませんでした: %v", hotkeyFormatted, err))
+		return
+	}
+	a.clipHotkey = mgr
+	a.logger.Info("クリップボード文字起こしのホットキー登録完了: %s", hotkeyFormatted)
+
+	go func() {
+		for event := range mgr.Events() {
+			if event.Type == hotkey.Pressed {
+				go a.transcribeClipboardAudio()
+			}
+		}
+	}()
+}
+
+// registerSecondHotkey は second_hotkey を登録する
+// 録音モードは hotkey と共通で、録音後の文字起こしには second_hotkey の options（翻訳など）を適用する
+// 設定の変更は再起動後に反映される
+func (a *App) registerSecondHotkey() {
+	cfg := a.config.Clone()
+	if cfg.SecondHotkey.Key == "" {
+		return
+	}
+
+	hotkeyConfig := buildRecordingHotkeyConfig(cfg, cfg.SecondHotkey)
+	hotkeyFormatted := hotkey.FormatHotkey(hotkeyConfig.Modifiers, hotkeyConfig.Key)
+
+	mgr := hotkey.New()
+	if err := mgr.Register(hotkeyConfig); err != nil {
+		a.logger.Error("2つ目のホットキー登録に失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("2つ目のホットキー (%s) を登録できませんでした: %v", hotkeyFormatted, err))
+		return
+	}
+	a.secHotkey = mgr
+	a.logger.Info("2つ目のホットキー登録完了: %s (translate=%v, language=%q)", hotkeyFormatted, cfg.SecondHotkey.Options.Translate, cfg.SecondHotkey.Options.Language)
+
+	go a.processHotkeyEvents(mgr.Events(), secondHotkey)
+}
+
+// clipboardAudioPath はクリップボードにある対応形式の音声ファイルのパスを返す
+// Finderでコピーしたファイルを優先し、なければ file:// URL または絶対パスのテキストを使う
+func (a *App) clipboardAudioPath() (string, bool) {
+	var candidates []string
+	if a.clipboardFiles != nil {
+		candidates = a.clipboardFiles()
+	}
+	if a.clipboardText != nil {
+		if text, err := a.clipboardText(); err == nil {
+			if path, ok := clipboard.FilePathFromText(text); ok {
+				candidates = append(candidates, path)
+			}
+		}
+	}
+
+	for _, path := range candidates {
+		if audiofile.IsSupported(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// transcribeClipboardAudio はクリップボードの音声ファイルを文字起こしして貼り付ける
+// アクセシビリティ権限がない場合は結果をクリップボードにコピーする
+// 画面ロック中は録音用のホットキーと同じく screen_locked_policy に従う
+func (a *App) transcribeClipboardAudio() {
+	if !a.clipTranscribing.CompareAndSwap(false, true) {
+		a.logger.Info("クリップボードの音声ファイルを文字起こし中のため無視します")
+		return
+	}
+	defer a.clipTranscribing.Store(false)
+
+	startLocked := a.isScreenLocked()
+	if screenLockAction(a.config.Clone().ScreenLockedPolicy, startLocked) == lockIgnore {
+		a.logger.Debug("クリップボード文字起こしのホットキーを検出しましたが、画面ロック中のため無視します")
+		return
+	}
+
+	path, ok := a.clipboardAudioPath()
+	if !ok {
+		a.trayMgr.ShowError("クリップボードに文字起こしできる音声ファイル（WAV）がありません。Finderでファイルをコピーしてください。")
+		return
+	}
+
+	if !a.modelLoaded.Load() {
+		a.trayMgr.ShowError("モデルが読み込まれていません。設定画面でモデルを選択してください。")
+		return
+	}
+
+	a.logger.Info("クリップボードの音声ファイルを文字起こし: %s", path)
+	a.trayMgr.SetState(tray.StateProcessing)
+	defer a.trayMgr.SetState(tray.StateIdle)
+
+	_, audioConfig := a.audioState()
+	audioData, err := audiofile.Load(path, audioConfig.SampleRate, audioConfig.Channels)
+	if err != nil {
+		a.logger.Error("音声ファイルの読み込みに失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("音声ファイルを読み込めませんでした: %v", err))
+		return
+	}
+
+	result, err := a.transcribe(audioData, audioConfig, a.recognitionLanguage())
+	if err != nil {
+		a.logger.Error("文字起こしエラー: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("文字起こしに失敗: %v", err))
+		return
+	}
+
+	if result.Text == "" {
+		a.trayMgr.ShowNotification("文字起こし", fmt.Sprintf("%s から文字を認識できませんでした", filepath.Base(path)))
+		return
+	}
+
+	// 開始時か現在、画面がロックされている場合は、ロック解除時の最前面のウィンドウに貼り付けない
+	locked := startLocked || a.isScreenLocked()
+	if screenLockAction(a.config.Clone().ScreenLockedPolicy, locked) != lockNormal {
+		a.copyLockedTranscription(result.Text)
+		return
+	}
+
+	if !a.accGranted.Load() {
+		if err := a.clipboard.CopyText(result.Text); err != nil {
+			a.logger.Error("クリップボードへのコピーに失敗: %v", err)
+			a.trayMgr.ShowError(fmt.Sprintf("クリップボードへのコピーに失敗: %v", err))
+			return
+		}
+		a.trayMgr.ShowNotification("文字起こし", fmt.Sprintf("%s の文字起こし結果をクリップボードにコピーしました（言語: %s）", filepath.Base(path), result.Language))
+		return
+	}
+
+	a.pasteTranscription(result.Text)
+}
+
+// copyTranscription は出力モードが copy の場合に、文字起こし結果を貼り付けずにクリップボードにコピーする
+// 貼り付けないため上限による切り詰めはせず、アクセシビリティ権限も不要
+func (a *App) copyTranscription(transcription, bundleID string) {
+	a.logger.Info("出力モードが copy のため貼り付けずにクリップボードにコピーします (アプリ: %s)", bundleID)
+	if err := a.clipboard.CopyText(transcription); err != nil {
+		a.logger.Error("クリップボードへのコピーに失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("クリップボードへのコピーに失敗: %v", err))
+		return
+	}
+	a.trayMgr.ShowNotification("文字起こし", "文字起こし結果をクリップボードにコピーしました")
+}
+
+// handleAccessibilityLost は貼り付け時にアクセシビリティ権限の取り消しを検出した場合の処理
+// キー送信は行われていないため、全文をクリップボードに残して手動で貼り付けられるようにする
+func (a *App) handleAccessibilityLost(text string) {
+	a.logger.Warn("貼り付け時にアクセシビリティ権限が無効になっていることを検出しました")
+
+	if err := a.clipboard.CopyText(text); err != nil {
+		a.logger.Error("クリップボードへのコピーに失敗: %v", err)
+	}
+
+	a.trayMgr.ShowError("アクセシビリティ権限が無効になっているため貼り付けできませんでした。文字起こし結果はクリップボードにあります。システム設定で許可してください。")
+
+	// 初めて検出したときだけシステム設定のアクセシビリティ画面を開く
+	if a.accGranted.Swap(false) && a.openAccessibilitySettings != nil {
+		if err := a.openAccessibilitySettings(); err != nil {
+			a.logger.Error("システム設定を開けませんでした: %v", err)
+		}
+	}
+
+	a.refreshHealth()
+}
+
+// handleSecureInputBlocked はアクセシビリティ権限があるのにセキュアキーボード入力で貼り付けが妨げられた場合の処理
+// パスワード欄などにフォーカスがあるとキー送信が捨てられるため、全文をクリップボードに残して理由を通知する
+func (a *App) handleSecureInputBlocked(text string) {
+	a.logger.Warn("セキュアキーボード入力が有効なため貼り付けをスキップしました")
+
+	if err := a.clipboard.CopyText(text); err != nil {
+		a.logger.Error("クリップボードへのコピーに失敗: %v", err)
+	}
+
+	a.trayMgr.ShowError("他のアプリがセキュアキーボード入力を有効にしているため貼り付けできませんでした（パスワード欄やターミナルを確認してください）。文字起こし結果はクリップボードにあります。")
+}
+
+// clipboardConfig は貼り付けに関する設定を Clipboard Manager の設定に変換する
+func clipboardConfig(cfg *config.Config) clipboard.Config {
+	cfg = cfg.Clone()
+	clipCfg := clipboard.DefaultConfig()
+	clipCfg.SplitSize = cfg.PasteSplitSize
+	clipCfg.SplitInterval = time.Duration(cfg.PasteSplitIntervalMs) * time.Millisecond
+
+	// 文字が欠ける貼り付け先アプリ（バンドルIDで指定）では分割貼り付けの間隔を広げる
+	if len(cfg.PasteAppIntervalsMs) > 0 {
+		clipCfg.AppSplitIntervals = make(map[string]time.Duration, len(cfg.PasteAppIntervalsMs))
+		for bundleID, ms := range cfg.PasteAppIntervalsMs {
+			clipCfg.AppSplitIntervals[bundleID] = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if !cfg.PasteWaitModifiers {
+		// 修飾キーの解放を待たずに即座に貼り付ける
+		clipCfg.ModifierReleaseTimeout = 0
+	}
+	return clipCfg
+}
+
+// healthProblems は録音から貼り付けまでに必要な前提条件のうち、満たされていないものを返す
+func (a *App) healthProblems(perms map[string]bool) []string {
+	var problems []string
+
+	if !a.modelLoaded.Load() {
+		if a.config.Clone().ModelPath == "" {
+			problems = append(problems, "モデル未設定")
+		} else {
+			problems = append(problems, "モデル未読み込み")
+		}
+	}
+
+	// フェイクオーディオはマイクを使わない
+	if !perms["microphone"] && a.fakeAudioSource == "" {
+		problems = append(problems, "マイク権限なし")
+	}
+
+	// 貼り付け時に取り消しを検出した場合は、システムの状態が更新される前でも警告する
+	if !perms["accessibility"] || !a.accGranted.Load() {
+		problems = append(problems, "アクセシビリティ権限なし")
+	}
+
+	return problems
+}
+
+// updateHealth は perms とモデルの状態からトレイアイコンの警告表示を更新する
+func (a *App) updateHealth(perms map[string]bool) {
+	a.trayMgr.SetProblems(a.healthProblems(perms))
+}
+
+// refreshHealth は現在の権限状態を確認してトレイアイコンの警告表示を更新する
+func (a *App) refreshHealth() {
+	if a.checkPermissions == nil {
+		return
+	}
+	a.updateHealth(a.checkPermissions())
+}
+
+// watchPermissions は permissionWatchInterval ごとに権限を確認し、トレイアイコンの警告表示に反映する
+// アクセシビリティ権限が再び許可された場合は、貼り付け時に無効化したキャッシュも戻す
+func (a *App) watchPermissions() {
+	var previous map[string]bool
+	for {
+		time.Sleep(permissionWatchInterval)
+
+		perms := a.checkPermissions()
+		if maps.Equal(perms, previous) {
+			continue
+		}
+		previous = perms
+
+		if perms["accessibility"] && !a.accGranted.Load() {
+			a.logger.Info("アクセシビリティ権限が許可されました")
+			a.accGranted.Store(true)
+		}
+		if perms["microphone"] && !a.micGranted.Load() {
+			// 設定画面の「許可をリクエスト」やシステム設定で許可された場合
+			a.logger.Info("マイク権限が許可されました")
+			a.enableMicrophone()
+		}
+		a.updateHealth(perms)
+	}
+}
+
+// promptMicrophone はマイク権限が未確認（NotDetermined）の場合に macOS の許可ダイアログを表示し、回答を反映する
+// システム設定には一度も許可を求めていないアプリが表示されないため、設定画面へ誘導しても許可できない
+// ユーザーの明示的な操作（ホットキー押下）からのみ呼ぶこと
+func (a *App) promptMicrophone() {
+	if a.requestMicrophone == nil || !a.micPrompting.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.micPrompting.Store(false)
+
+	status := a.requestMicrophone()
+	if status == permissions.PermissionAuthorized {
+		a.logger.Info("マイク権限が許可されました")
+		a.enableMicrophone()
+		a.trayMgr.ShowNotification("マイク権限", "マイクが使用できるようになりました。もう一度ホットキーを押して録音してください。")
+		return
+	}
+
+	// 拒否された場合はシステム設定から許可してもらう
+	a.logger.Warn("マイク権限が許可されませんでした: %s", status)
+	a.trayMgr.ShowError("マイク権限が拒否されました。システム設定の「プライバシーとセキュリティ > マイク」で許可してください。")
+}
+
+// enableMicrophone はマイク権限が許可されたときに録音を有効にし、未初期化のオーディオドライバを初期化する
+func (a *App) enableMicrophone() {
+	a.micSetupMutex.Lock()
+	defer a.micSetupMutex.Unlock()
+
+	if a.micGranted.Load() {
+		return
+	}
+	// ホットキーが初期化途中のドライバを使わないよう、初期化が終わってから録音を有効にする
+	// 初期化に失敗した場合も、メニューからデバイスを選び直せるよう権限は許可済みとして扱う
+	if driver, _ := a.audioState(); driver == nil {
+		a.initAudioDriver()
+	}
+	a.micGranted.Store(true)
+	a.refreshHealth()
+}
+
+// pasteText は貼り付けを直列化して SafePasteWithSplitContext を呼ぶ
+// 分割貼り付けの途中に別の貼り付けが割り込まないよう、すべての貼り付けはここを通す
+// 終了時には lifecycleCtx がキャンセルされ、残りのチャンクは貼り付けない
+func (a *App) pasteText(text string) error {
+	a.pasteMutex.Lock()
+	defer a.pasteMutex.Unlock()
+
+	ctx := a.lifecycleCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.clipboard.SafePasteWithSplitContext(ctx, text)
+	if errors.Is(err, clipboard.ErrClipboardModifiedExternally) {
+		// 貼り付け自体は完了している。貼り付け中にユーザーがコピーした内容を上書きしないよう、元の内容は復元していない
+		a.logger.Warn("貼り付け中にクリップボードが変更されたため、元の内容を復元しませんでした")
+		a.trayMgr.ShowNotification("貼り付け", "貼り付け中にクリップボードが変更されたため、元のクリップボードの内容は復元していません。")
+		return nil
+	}
+	return err
+}
+
+// testPaste は /api/test/paste から呼ばれ、ホットキーの文字起こしと同じ設定・経路で text を貼り付ける
+func (a *App) testPaste(text string) error {
+	if !a.accGranted.Load() {
+		return &clipboard.PasteError{Stage: clipboard.StageKeystroke, Err: clipboard.ErrAccessibilityDenied}
+	}
+
+	pasteText, _ := truncateRunes(text, a.config.Clone().MaxPasteChars)
+
+	a.logger.Info("テスト貼り付け開始")
+	if err := a.pasteText(pasteText); err != nil {
+		a.logger.Warn("テスト貼り付けに失敗: %v", err)
+		return err
+	}
+
+	a.logger.Info("テスト貼り付け完了")
+	return nil
+}
+
+// transcribeTest は /api/test/record の録音をホットキーと同じ設定で文字起こしする（貼り付けはしない）
+func (a *App) transcribeTest(audioData []byte) (string, error) {
+	a.trayMgr.SetState(tray.StateProcessing)
+	defer a.trayMgr.SetState(tray.StateIdle)
+
+	// /api/test/record は現在のドライバで録音している
+	driver, audioConfig := a.audioState()
+	result, err := a.transcribe(audioData, audio.RecordingFormat(driver, audioConfig), a.recognitionLanguage())
+	if err != nil {
+		a.logger.Warn("テスト録音の文字起こしに失敗: %v", err)
+		return "", err
+	}
+
+	a.logTranscription("テスト録音の文字起こし完了", result.Text)
+	return result.Text, nil
+}
+
+// runUpdateChecks は起動時と updateCheckInterval ごとにアップデートを確認する
+// check_updates は毎回読み直すため、設定画面での変更は次回の確認から反映される
+func (a *App) runUpdateChecks() {
+	for {
+		a.checkForUpdate()
+		time.Sleep(updateCheckInterval)
+	}
+}
+
+// checkForUpdate はバージョンマニフェストを取得し、新しいバージョンがあれば通知する
+// ネットワークエラーなどは通知せずログに残すだけにする（ダウンロードは行わない）
+func (a *App) checkForUpdate() {
+	cfg := a.config.Clone()
+	if !cfg.CheckUpdates {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	release, newer, err := update.Check(ctx, http.DefaultClient, cfg.UpdateManifestURL, version.Version)
+	if err != nil {
+		a.logger.Debug("アップデート確認に失敗: %v", err)
+		return
+	}
+
+	if !newer {
+		a.logger.Debug("アップデート確認: 最新版です (v%s)", version.Version)
+		return
+	}
+
+	// 同じバージョンについては一度だけ通知する
+	if release.Version == a.notifiedUpdate {
+		return
+	}
+	a.notifiedUpdate = release.Version
+
+	a.logger.Info("新しいバージョンが利用可能です: %s (現在: v%s)", release.Version, version.Version)
+	message := fmt.Sprintf("新しいバージョン %s が利用可能です（現在: v%s）", release.Version, version.Version)
+	if release.URL != "" {
+		message += "\n" + release.URL
+	}
+	a.trayMgr.ShowNotification("アップデート", message)
+}
+
+// initAudioDriver はオーディオドライバを作成・初期化する（マイク権限がある場合のみ呼ぶ）
+// 失敗した場合は audioDriver を nil のままにする
+func (a *App) initAudioDriver() {
+	driver, err := a.newAudioDriver()
+	if err != nil {
+		a.logger.Error("オーディオドライバの作成に失敗: %v", err)
+		return
+	}
+
+	audioConfig := audio.DefaultConfig()
+	// 設定ファイルのデバイスIDを反映（-1の場合はシステムデフォルト、見つからない場合もシステムデフォルト）
+	audioConfig.DeviceID = a.startupDeviceID(driver)
+	a.logger.Info("設定からオーディオデバイスIDを適用: %d", audioConfig.DeviceID)
+	if err := driver.Initialize(audioConfig); err != nil {
+		a.logger.Error("オーディオドライバの初期化に失敗: %v", err)
+		// Initialize失敗時はドライバをクローズする
+		if closeErr := driver.Close(); closeErr != nil {
+			a.logger.Error("ドライバのクローズに失敗: %v", closeErr)
+		}
+		a.trayMgr.ShowError(fmt.Sprintf("オーディオデバイスの初期化に失敗しました。設定画面でデバイスを変更してください。\nエラー: %v", err))
+		return
+	}
+
+	a.logger.Info("オーディオドライバ初期化完了")
+	// 初期化が終わったドライバだけを公開する（API Handlerにも設定）
+	a.setAudioState(driver, audioConfig)
+	a.checkStreamSampleRate()
+}
+
+// audioState は現在のオーディオドライバと設定を返す（未初期化の場合ドライバは nil）
+func (a *App) audioState() (audio.AudioDriver, audio.Config) {
+	a.audioMutex.RLock()
+	defer a.audioMutex.RUnlock()
+	return a.audioDriver, a.audioConfig
+}
+
+// setAudioState はオーディオドライバと設定を置き換え、API Handler にも反映する
+func (a *App) setAudioState(driver audio.AudioDriver, audioConfig audio.Config) {
+	a.audioMutex.Lock()
+	a.audioDriver = driver
+	a.audioConfig = audioConfig
+	a.audioMutex.Unlock()
+
+	if a.apiHandler != nil {
+		a.apiHandler.SetAudioDriver(driver)
+	}
+}
+
+// newAudioDriver は設定に応じたオーディオドライバ（PortAudio またはフェイク）を作成する
+func (a *App) newAudioDriver() (audio.AudioDriver, error) {
+	if a.fakeAudioSource != "" {
+		driver, err := fakeaudio.NewFromSource(a.fakeAudioSource, 1)
+		if err != nil {
+			return nil, err
+		}
+		return driver, nil
+	}
+
+	driver, err := audio.NewPortAudioDriver()
+	if err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+// handleOpenSettings は設定画面を開く
+func (a *App) handleOpenSettings() {
+	a.logger.Info("設定画面を開く要求")
+	a.openServerPage("/")
+}
+
+// handleAbout はバージョン情報ページを開く
+func (a *App) handleAbout() {
+	a.logger.Info("バージョン情報を開く要求")
+	a.openServerPage("/about")
+}
+
+// openServerPage は内蔵サーバーのページ（path）をブラウザで開く
+func (a *App) openServerPage(path string) {
+	// サーバーが起動していない場合はエラー
+	if !a.httpServer.IsRunning() {
+		a.logger.Error("HTTPサーバーが起動していません")
+		a.trayMgr.ShowError("設定画面が利用できません。アプリケーションを再起動してください。")
+		return
+	}
+
+	url := a.httpServer.URL() + path
+
+	// ブラウザを開かない設定の場合はURLの表示のみ
+	if a.noBrowser {
+		a.logger.Info("ブラウザを開かずにURLを表示します: %s", url)
+		fmt.Printf("[情報] URL: %s\n", url)
+		return
+	}
+
+	// ブラウザでページを開く
+	a.logger.Info("ブラウザを開きます: %s", url)
+
+	// goroutineで非同期実行
+	go func() {
+		cmd := exec.Command("open", url)
+		if err := cmd.Run(); err != nil {
+			a.logger.Error("ブラウザの起動に失敗: %v", err)
+			a.trayMgr.ShowError(fmt.Sprintf("ブラウザの起動に失敗: %v", err))
+
+			// フォールバック: ターミナルにURLを表示
+			fmt.Printf("\n[警告] ブラウザが自動で開きませんでした\n")
+			fmt.Printf("[情報] URL: %s\n", url)
+			fmt.Printf("[ヒント] 上記URLをブラウザで開いてください\n\n")
+		}
+	}()
+}
+
+// isHeadlessSession はGUI（Aqua）のないセッションで起動されたかを判定する
+// SSH接続、または launchd のセッション種別が Aqua 以外の場合はブラウザを開けない
+func isHeadlessSession(getenv func(string) string, managerName func() (string, error)) bool {
+	if getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != "" {
+		return true
+	}
+
+	name, err := managerName()
+	if err != nil {
+		// 判定できない場合は従来どおりブラウザを開く
+		return false
+	}
+	return name != "Aqua"
+}
+
+// launchdManagerName は現在のセッションの launchd セッション種別（GUIでは "Aqua"）を返す
+func launchdManagerName() (string, error) {
+	out, err := exec.Command("launchctl", "managername").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// handleReloadModel は設定されたモデルを再読み込みする
+// 同じパスのモデルファイルを差し替えた場合に、アプリを再起動せずに反映するために使う
+func (a *App) handleReloadModel() {
+	a.logger.Info("モデル再読み込み要求")
+
+	// goroutineで非同期実行（UIブロックを防ぐ）
+	go a.reloadModel()
+}
+
+// handleResetHotkey はメニューからホットキーを初期設定（⌃⌥Space）に戻す
+// 設定画面を開けない状態でもホットキーを復旧できるようにするため
+func (a *App) handleResetHotkey() {
+	a.logger.Info("ホットキー初期化要求")
+
+	// goroutineで非同期実行（UIブロックを防ぐ）
+	go a.resetHotkey()
+}
+
+// resetHotkey は既定のホットキーを保存して再登録する
+// 他のアプリと競合して登録できない場合は以前のホットキーを保存し直す
+func (a *App) resetHotkey() {
+	// 翻訳・言語のオプションは設定画面でのみ変更するため、キーの組み合わせだけを戻す
+	previous := a.config.Hotkey
+	a.config.Hotkey = previous.WithKeys(config.DefaultHotkey())
+
+	configPath := config.GetConfigPath()
+	if err := a.config.Save(configPath); err != nil {
+		a.logger.Error("設定ファイルの保存に失敗: %v", err)
+		a.config.Hotkey = previous
+		a.trayMgr.ShowError(fmt.Sprintf("設定の保存に失敗しました: %v", err))
+		return
+	}
+
+	if err := a.ReloadHotkey(); err != nil {
+		a.logger.Error("ホットキーの初期化に失敗: %v", err)
+
+		var conflictErr *hotkey.ConflictError
+		if errors.As(err, &conflictErr) {
+			a.config.Hotkey = previous
+			if saveErr := a.config.Save(configPath); saveErr != nil {
+				a.logger.Warn("以前のホットキーの保存に失敗: %v", saveErr)
+			}
+		}
+		a.trayMgr.ShowError(fmt.Sprintf("ホットキーを初期設定に戻せませんでした。\nエラー: %v", err))
+	}
+}
+
+// handleExportHistory はメニューから文字起こし履歴を Markdown に書き出す
+// 保存先は保存ダイアログで選ぶ
+func (a *App) handleExportHistory() {
+	a.logger.Info("履歴の書き出し要求")
+
+	// goroutineで非同期実行（保存ダイアログの間UIをブロックしない）
+	go a.exportHistory()
+}
+
+// exportHistory は履歴を書き出し、保存先を通知する
+func (a *App) exportHistory() {
+	path, err := a.apiHandler.SaveHistoryExport(context.Background())
+	if err != nil {
+		a.logger.Error("履歴の書き出しに失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("履歴を書き出せませんでした。\nエラー: %v", err))
+		return
+	}
+	if path == "" {
+		a.logger.Info("履歴の書き出しをキャンセルしました")
+		return
+	}
+
+	a.logger.Info("履歴を書き出しました: %s", path)
+	a.trayMgr.ShowNotification("履歴", fmt.Sprintf("%s に保存しました", filepath.Base(path)))
+}
+
+// handleExportDiagnostics はメニューから診断情報（ログ・設定・状態）を zip に書き出す
+// 保存先は保存ダイアログで選ぶ
+func (a *App) handleExportDiagnostics() {
+	a.logger.Info("診断情報の書き出し要求")
+
+	// goroutineで非同期実行（保存ダイアログの間UIをブロックしない）
+	go a.exportDiagnostics()
+}
+
+// exportDiagnostics は診断情報を書き出し、保存先を通知する
+func (a *App) exportDiagnostics() {
+	path, err := a.apiHandler.SaveDiagnostics(context.Background())
+	if err != nil {
+		a.logger.Error("診断情報の書き出しに失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("診断情報を書き出せませんでした。\nエラー: %v", err))
+		return
+	}
+	if path == "" {
+		a.logger.Info("診断情報の書き出しをキャンセルしました")
+		return
+	}
 
-	for event := range eventChan {
-		switch event.Type {
-		case hotkey.Pressed:
-			if !a.micGranted {
-				a.logger.Warn("ホットキー押下検出しましたが、マイク権限がないため無視します")
-				continue
-			}
-			if a.audioDriver == nil {
-				a.logger.Warn("ホットキー押下検出しましたが、オーディオデバイスが初期化されていないため無視します")
-				a.trayMgr.ShowError("オーディオデバイスが初期化されていません。設定画面でデバイスを確認してください。")
-				continue
-			}
+	a.logger.Info("診断情報を書き出しました: %s", path)
+	a.trayMgr.ShowNotification("診断情報", fmt.Sprintf("%s に保存しました", filepath.Base(path)))
+}
 
-			a.logger.Info("ホットキー押下検出 - 録音開始")
-			a.trayMgr.SetState(tray.StateRecording)
+// reloadModel はモデルを読み込み直し、推論設定を再調整して結果を通知する
+// 読み込みに失敗した場合は以前のモデルがそのまま使われる
+func (a *App) reloadModel() {
+	if !a.reloadModelMutex.TryLock() {
+		a.logger.Warn("モデル再読み込み: 既に実行中です")
+		return
+	}
+	defer a.reloadModelMutex.Unlock()
 
-			if err := a.audioDriver.StartRecording(); err != nil {
-				a.logger.Error("録音開始エラー: %v", err)
-				a.trayMgr.ShowError(fmt.Sprintf("録音開始に失敗: %v", err))
-				a.trayMgr.SetState(tray.StateIdle)
-			}
+	driver, _ := a.audioState()
+	if driver != nil && driver.IsRecording() {
+		a.logger.Warn("モデル再読み込み: 録音中のため中止")
+		a.trayMgr.ShowError("録音中はモデルを再読み込みできません。録音終了後に再度お試しください。")
+		return
+	}
 
-		case hotkey.Released:
-			if !a.micGranted || a.audioDriver == nil {
-				continue
-			}
+	if err := a.config.ValidateModelPath(); err != nil {
+		a.logger.Warn("モデル再読み込み: モデルパスの検証に失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("モデルパスが無効です。設定画面で確認してください。\nエラー: %v", err))
+		return
+	}
 
-			a.logger.Info("ホットキー解放検出 - 録音停止")
-			a.trayMgr.SetState(tray.StateProcessing)
+	modelPath, err := a.config.GetModelPath()
+	if err != nil {
+		a.logger.Error("モデル再読み込み: モデルパスの展開に失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("モデルパスの展開に失敗: %v", err))
+		return
+	}
 
-			audioData, err := a.audioDriver.StopRecording()
-			if err != nil {
-				a.logger.Error("録音停止エラー: %v", err)
-				a.trayMgr.ShowError(fmt.Sprintf("録音停止に失敗: %v", err))
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+	a.trayMgr.SetState(tray.StateProcessing)
+	defer a.trayMgr.SetState(tray.StateIdle)
 
-			dataSize := len(audioData)
-			a.logger.Info("録音データ受信: %d バイト", dataSize)
+	// 文字起こし・アイドル解放・アイドル解放からの復帰が、読み込みから動作確認までの途中のモデルを使わないようにする
+	a.transcribeMutex.Lock()
+	defer a.transcribeMutex.Unlock()
 
-			// データが空の場合はスキップ
-			if dataSize == 0 {
-				a.logger.Warn("録音データが空です")
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+	a.logger.Info("モデルを再読み込み中: %s", modelPath)
+	if err := a.recognizer.LoadModel(modelPath); err != nil {
+		a.logger.Error("モデルの再読み込みに失敗: %v", err)
+		a.trayMgr.ShowError(fmt.Sprintf("モデルの再読み込みに失敗: %v", err))
+		return
+	}
 
-			// モデルがない場合はスキップ
-			if !a.modelLoaded {
-				a.logger.Warn("モデル未読み込みのため文字起こしをスキップ")
-				a.trayMgr.ShowError("モデルが読み込まれていません。設定画面でモデルを選択してください。")
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+	tuning := a.applyModelTuning(modelPath)
+	if !a.verifyModel(modelPath) {
+		return
+	}
+	a.logger.Info("モデル再読み込み完了")
+	a.trayMgr.ShowNotification("モデル再読み込み完了", fmt.Sprintf("%s\nスレッド数: %d / プリセット: %s", filepath.Base(modelPath), tuning.Threads, tuning.Preset))
+}
 
-			// 文字起こし処理
-			a.logger.Info("文字起こし処理開始")
+// verifyModel は読み込んだモデルで無音を一度推論し、実際に文字起こしできるかを modelLoaded に反映する
+// ggml のバージョン不一致など、読み込みには成功しても推論で失敗するモデルを最初の録音より前に検出する
+func (a *App) verifyModel(modelPath string) bool {
+	if err := a.recognizer.SelfTest(); err != nil {
+		a.logger.Error("モデルの動作確認に失敗: %v", err)
+		a.modelLoaded.Store(false)
+		a.refreshHealth()
+		a.trayMgr.ShowError(fmt.Sprintf("モデル %s は読み込めましたが、文字起こしを実行できません。別のモデルを選択してください。\nエラー: %v", filepath.Base(modelPath), err))
+		return false
+	}
 
-			transcription, err := a.recognizer.Transcribe(audioData, a.audioConfig.SampleRate)
-			if err != nil {
-				a.logger.Error("文字起こしエラー: %v", err)
-				a.trayMgr.ShowError(fmt.Sprintf("文字起こしに失敗: %v", err))
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+	a.modelLoaded.Store(true)
+	a.refreshHealth()
+	a.modelReady(modelPath)
+	return true
+}
 
-			a.logger.Info("文字起こし完了: %s", transcription)
+// errModelWake はアイドル解放したモデルを読み込み直せなかったことを表す
+var errModelWake = errors.New("モデルの再読み込みに失敗")
 
-			// 文字起こし結果が空の場合はスキップ
-			if transcription == "" {
-				a.logger.Warn("文字起こし結果が空です")
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+// modelReady は読み込んだモデルをアイドル解放の対象にし、タイマーを開始する
+func (a *App) modelReady(modelPath string) {
+	a.idleMutex.Lock()
+	a.idleModelPath = modelPath
+	a.modelIdle = false
+	a.idleMutex.Unlock()
 
-			// クリップボードに貼り付け（アクセシビリティ権限が必要）
-			if !a.accGranted {
-				a.logger.Warn("アクセシビリティ権限なしのため貼り付けをスキップ")
-				a.trayMgr.ShowError("アクセシビリティ権限がありません。システム設定で許可してください。")
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+	a.scheduleIdleUnload()
+}
 
-			a.logger.Info("クリップボード貼り付け開始")
+// scheduleIdleUnload は idle_unload_minutes 後にモデルを解放するタイマーを設定し直す
+// 設定の変更は次の文字起こしの後に反映される
+func (a *App) scheduleIdleUnload() {
+	minutes := a.config.Clone().IdleUnloadMinutes
 
-			if err := a.clipboard.SafePasteWithSplit(transcription); err != nil {
-				a.logger.Error("貼り付けエラー: %v", err)
-				a.trayMgr.ShowError(fmt.Sprintf("貼り付けに失敗: %v", err))
-				a.trayMgr.SetState(tray.StateIdle)
-				continue
-			}
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
 
-			a.logger.Info("貼り付け完了")
-			a.trayMgr.SetState(tray.StateIdle)
-		}
+	if a.idleTimer != nil {
+		a.idleTimer.Stop()
+		a.idleTimer = nil
+	}
+	if minutes <= 0 || a.afterFunc == nil || a.modelIdle {
+		return
 	}
 
-	a.logger.Info("ホットキーイベントループ終了")
+	var timer timerHandle
+	timer = a.afterFunc(time.Duration(minutes)*time.Minute, func() {
+		a.unloadIdleModel(timer, minutes)
+	})
+	a.idleTimer = timer
 }
 
-// handleOpenSettings は設定画面を開く
-func (a *App) handleOpenSettings() {
-	a.logger.Info("設定画面を開く要求")
+// unloadIdleModel はタイマーの満了時にモデルを解放する
+// 文字起こし中は終わるまで待ち、その間に設定し直された古いタイマーは何もしない
+func (a *App) unloadIdleModel(timer timerHandle, minutes int) {
+	a.transcribeMutex.Lock()
+	defer a.transcribeMutex.Unlock()
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
 
-	// サーバーが起動していない場合はエラー
-	if !a.httpServer.IsRunning() {
-		a.logger.Error("HTTPサーバーが起動していません")
-		a.trayMgr.ShowError("設定画面が利用できません。アプリケーションを再起動してください。")
+	if a.idleTimer != timer || a.modelIdle {
 		return
 	}
+	a.idleTimer = nil
 
-	// ブラウザで設定画面を開く
-	url := a.httpServer.URL()
-	a.logger.Info("ブラウザを開きます: %s", url)
+	a.recognizer.Unload()
+	a.modelIdle = true
+	a.logger.Info("%d 分間文字起こしがなかったためモデルを解放しました", minutes)
+}
 
-	// goroutineで非同期実行
-	go func() {
-		cmd := exec.Command("open", url)
-		if err := cmd.Run(); err != nil {
-			a.logger.Error("ブラウザの起動に失敗: %v", err)
-			a.trayMgr.ShowError(fmt.Sprintf("ブラウザの起動に失敗: %v", err))
+// wakeModel はアイドル解放したモデルを読み込み直す（transcribeMutex を保持して呼ぶ）
+// 読み込みにかかった時間は文字起こしの遅延としてログに記録する
+func (a *App) wakeModel() error {
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
 
-			// フォールバック: ターミナルにURLを表示
-			fmt.Printf("\n[警告] ブラウザが自動で開きませんでした\n")
-			fmt.Printf("[情報] 設定画面URL: %s\n", url)
-			fmt.Printf("[ヒント] 上記URLをブラウザで開いてください\n\n")
-		}
-	}()
+	if !a.modelIdle {
+		return nil
+	}
+
+	a.logger.Info("アイドル解放したモデルを読み込み直します: %s", a.idleModelPath)
+	start := time.Now()
+	if err := a.recognizer.LoadModel(a.idleModelPath); err != nil {
+		return fmt.Errorf("%w: %v", errModelWake, err)
+	}
+	a.modelIdle = false
+	a.logger.Info("モデルの再読み込み完了: 遅延 %dms", time.Since(start).Milliseconds())
+	return nil
 }
 
 // handleRecordTest は録音テストを実行
@@ -412,25 +2214,26 @@ func (a *App) handleRecordTest() {
 	// goroutineで非同期実行（UIブロックを防ぐ）
 	go func() {
 		// 1. 権限チェック
-		if !a.micGranted {
+		if !a.micGranted.Load() {
 			a.logger.Warn("録音テスト: マイク権限がありません")
 			a.trayMgr.ShowError("マイク権限がありません。システム設定で許可してください。")
 			return
 		}
 
-		if !a.accGranted {
+		if !a.accGranted.Load() {
 			a.logger.Warn("録音テスト: アクセシビリティ権限がありません")
 			a.trayMgr.ShowError("アクセシビリティ権限がありません。システム設定で許可してください。")
 			return
 		}
 
-		if a.audioDriver == nil {
+		driver, audioConfig := a.audioState()
+		if driver == nil {
 			a.logger.Error("録音テスト: オーディオドライバが初期化されていません")
 			a.trayMgr.ShowError("オーディオデバイスが初期化されていません。設定画面でデバイスを確認してください。")
 			return
 		}
 
-		if !a.modelLoaded {
+		if !a.modelLoaded.Load() {
 			a.logger.Warn("録音テスト: モデルが読み込まれていません")
 			a.trayMgr.ShowError("モデルが読み込まれていません。設定画面でモデルを選択してください。")
 			return
@@ -441,9 +2244,9 @@ func (a *App) handleRecordTest() {
 		a.trayMgr.ShowNotification("録音テスト", "録音を開始します（5秒間話してください）")
 		a.trayMgr.SetState(tray.StateRecording)
 
-		if err := a.audioDriver.StartRecording(); err != nil {
+		if err := audio.StartWithRetry(driver, a.startBackoff); err != nil {
 			a.logger.Error("録音テスト: 録音開始エラー: %v", err)
-			a.trayMgr.ShowError(fmt.Sprintf("録音開始に失敗: %v", err))
+			a.trayMgr.ShowError(recordingStartErrorMessage(err))
 			a.trayMgr.SetState(tray.StateIdle)
 			return
 		}
@@ -455,7 +2258,7 @@ func (a *App) handleRecordTest() {
 		a.logger.Info("録音テスト: 録音停止")
 		a.trayMgr.SetState(tray.StateProcessing)
 
-		audioData, err := a.audioDriver.StopRecording()
+		audioData, err := driver.StopRecording()
 		if err != nil {
 			a.logger.Error("録音テスト: 録音停止エラー: %v", err)
 			a.trayMgr.ShowError(fmt.Sprintf("録音停止に失敗: %v", err))
@@ -464,7 +2267,8 @@ func (a *App) handleRecordTest() {
 		}
 
 		dataSize := len(audioData)
-		a.logger.Info("録音テスト: 録音データ受信: %d バイト", dataSize)
+		format := audio.RecordingFormat(driver, audioConfig)
+		a.logger.Info("録音テスト: 録音データ受信: %d バイト (%d Hz)", dataSize, format.SampleRate)
 
 		// データが空の場合
 		if dataSize == 0 {
@@ -474,11 +2278,21 @@ func (a *App) handleRecordTest() {
 			return
 		}
 
+		// save_recordings が有効な場合は、保存先を結果の通知にも表示する
+		savedNote := recordingNote(a.saveRecording(audioData, format))
+
+		if audio.IsSilent(audioData) {
+			a.logger.Warn("録音テスト: 録音データが無音です")
+			a.trayMgr.ShowError(silentMicMessage + savedNote)
+			a.trayMgr.SetState(tray.StateIdle)
+			return
+		}
+
 		// 5. 文字起こし処理
 		a.logger.Info("録音テスト: 文字起こし処理開始")
 		a.trayMgr.ShowNotification("録音テスト", "文字起こし処理中...")
 
-		transcription, err := a.recognizer.Transcribe(audioData, a.audioConfig.SampleRate)
+		result, err := a.transcribe(audioData, format, a.recognitionLanguage())
 		if err != nil {
 			a.logger.Error("録音テスト: 文字起こしエラー: %v", err)
 			a.trayMgr.ShowError(fmt.Sprintf("文字起こしに失敗: %v", err))
@@ -486,46 +2300,41 @@ func (a *App) handleRecordTest() {
 			return
 		}
 
-		a.logger.Info("録音テスト: 文字起こし完了: %s", transcription)
+		transcription := result.Text
+
+		a.logTranscription("録音テスト: 文字起こし完了", transcription)
 
 		// 文字起こし結果が空の場合
 		if transcription == "" {
 			a.logger.Warn("録音テスト: 文字起こし結果が空です")
-			a.trayMgr.ShowError("文字起こし結果が空です。音声が短すぎるか、ノイズが多い可能性があります。")
+			a.trayMgr.ShowError("文字起こし結果が空です。音声が短すぎるか、ノイズが多い可能性があります。" + savedNote)
 			a.trayMgr.SetState(tray.StateIdle)
 			return
 		}
 
 		// 6. 結果を通知
 		a.logger.Info("録音テスト: テスト完了")
-		a.trayMgr.ShowNotification("録音テスト完了", fmt.Sprintf("文字起こし結果:\n%s", transcription))
+		a.trayMgr.ShowNotification("録音テスト完了", fmt.Sprintf("文字起こし結果（言語: %s）:\n%s%s", result.Language, transcription, savedNote))
 		a.trayMgr.SetState(tray.StateIdle)
 	}()
 }
 
 // updateDeviceMenu はトレイメニューのデバイスリストを更新
 func (a *App) updateDeviceMenu() {
+	driver, _ := a.audioState()
 	a.logger.Info("デバイスメニューを更新します")
 
 	// 利用可能なデバイスリストを取得
 	var devices []tray.Device
 
-	if a.audioDriver != nil {
-		audioDevices, err := a.audioDriver.ListDevices()
+	if driver != nil {
+		audioDevices, err := driver.ListDevices()
 		if err != nil {
 			a.logger.Error("デバイスリストの取得に失敗: %v", err)
 			return
 		}
 
-		// audio.Device を tray.Device に変換
-		for _, dev := range audioDevices {
-			devices = append(devices, tray.Device{
-				ID:        dev.ID,
-				Name:      dev.Name,
-				IsDefault: dev.IsDefault,
-				IsCurrent: dev.ID == a.config.AudioDeviceID,
-			})
-		}
+		devices = trayDevices(audioDevices, a.config.AudioDeviceID)
 	} else {
 		// audioDriverがnilの場合は、一時的なドライバを作成してデバイスリストを取得
 		tempDriver, err := audio.NewPortAudioDriver()
@@ -544,14 +2353,7 @@ func (a *App) updateDeviceMenu() {
 					{ID: -1, Name: "システムデフォルト", IsDefault: true, IsCurrent: a.config.AudioDeviceID == -1},
 				}
 			} else {
-				for _, dev := range audioDevices {
-					devices = append(devices, tray.Device{
-						ID:        dev.ID,
-						Name:      dev.Name,
-						IsDefault: dev.IsDefault,
-						IsCurrent: dev.ID == a.config.AudioDeviceID,
-					})
-				}
+				devices = trayDevices(audioDevices, a.config.AudioDeviceID)
 			}
 		}
 	}
@@ -561,6 +2363,52 @@ func (a *App) updateDeviceMenu() {
 	a.logger.Info("デバイスメニューを更新しました: %d個のデバイス", len(devices))
 }
 
+// trayDevices は audio.Device をトレイメニューの tray.Device に変換する
+// 設定のデバイスID（-1 の場合はシステムデフォルトのデバイス）にチェックを付ける
+func trayDevices(audioDevices []audio.Device, configuredID int) []tray.Device {
+	devices := make([]tray.Device, 0, len(audioDevices))
+	for _, dev := range audioDevices {
+		isCurrent := dev.ID == configuredID
+		if configuredID == -1 {
+			isCurrent = dev.IsDefault
+		}
+		devices = append(devices, tray.Device{
+			ID:        dev.ID,
+			Name:      dev.Name,
+			IsDefault: dev.IsDefault,
+			IsCurrent: isCurrent,
+		})
+	}
+	return devices
+}
+
+// startupDeviceID は保存済みのデバイスIDが現在も存在するか確認し、使用するデバイスIDを返す
+// 見つからない場合（マイクを取り外した場合など）は通知して -1（システムデフォルト）を返す
+// 設定ファイルは書き換えないため、デバイスを接続し直せば次回起動時に再び使われる
+func (a *App) startupDeviceID(driver audio.AudioDriver) int {
+	deviceID := a.config.AudioDeviceID
+	if deviceID == -1 {
+		return -1
+	}
+
+	devices, err := driver.ListDevices()
+	if err != nil {
+		// 確認できない場合は保存済みのIDをそのまま試す
+		a.logger.Warn("デバイスリストの取得に失敗したため保存済みのデバイスIDを使用します: %v", err)
+		return deviceID
+	}
+
+	for _, dev := range devices {
+		if dev.ID == deviceID {
+			return deviceID
+		}
+	}
+
+	a.logger.Warn("保存済みのオーディオデバイス (ID: %d) が見つかりません。システムデフォルトを使用します", deviceID)
+	a.trayMgr.ShowNotification("入力デバイス", "設定した入力デバイスが見つからないため、システムデフォルトのデバイスを使用します。")
+	return -1
+}
+
 // handleDeviceChange はデバイス変更要求を処理
 func (a *App) handleDeviceChange(deviceID int) {
 	// 並行実行を防止（ReloadHotkeyと同じmutexを使用）
@@ -570,12 +2418,20 @@ func (a *App) handleDeviceChange(deviceID int) {
 	a.logger.Info("デバイス変更要求: デバイスID %d", deviceID)
 
 	// 権限チェック
-	if !a.micGranted {
+	if !a.micGranted.Load() {
 		a.logger.Warn("デバイス変更: マイク権限がありません")
 		a.trayMgr.ShowError("マイク権限が必要です。システム設定で許可してください。")
 		return
 	}
 
+	// 録音中にドライバを閉じると録音が失われるため、録音が終わってから選び直してもらう
+	driver, audioConfig := a.audioState()
+	if driver != nil && driver.IsRecording() {
+		a.logger.Warn("デバイス変更: 録音中のため中止")
+		a.trayMgr.ShowError("録音中は入力デバイスを変更できません。録音終了後に再度お試しください。")
+		return
+	}
+
 	// 設定ファイルを更新
 	a.config.AudioDeviceID = deviceID
 	configPath := config.GetConfigPath()
@@ -587,33 +2443,31 @@ func (a *App) handleDeviceChange(deviceID int) {
 	a.logger.Info("設定ファイルを更新しました: audio_device_id=%d", deviceID)
 
 	// 既存のオーディオドライバをクローズ
-	if a.audioDriver != nil {
+	// 閉じたドライバが使われないよう、先に取り外してから閉じる
+	if driver != nil {
 		a.logger.Info("既存のオーディオドライバをクローズします")
-		if err := a.audioDriver.Close(); err != nil {
+		a.setAudioState(nil, audioConfig)
+		if err := driver.Close(); err != nil {
 			a.logger.Error("オーディオドライバのクローズに失敗: %v", err)
 		}
-		a.audioDriver = nil
 	}
 
 	// 新しいデバイスで初期化
-	var err error
-	a.audioDriver, err = audio.NewPortAudioDriver()
+	driver, err := a.newAudioDriver()
 	if err != nil {
-		a.logger.Error("PortAudioドライバの作成に失敗: %v", err)
-		a.audioDriver = nil
+		a.logger.Error("オーディオドライバの作成に失敗: %v", err)
 		a.trayMgr.ShowError(fmt.Sprintf("オーディオドライバの作成に失敗しました: %v", err))
 		// メニューを更新して状態を反映
 		a.updateDeviceMenu()
 		return
 	}
 
-	a.audioConfig.DeviceID = deviceID
-	if err := a.audioDriver.Initialize(a.audioConfig); err != nil {
+	audioConfig.DeviceID = deviceID
+	if err := driver.Initialize(audioConfig); err != nil {
 		a.logger.Error("オーディオドライバの初期化に失敗: %v", err)
-		if closeErr := a.audioDriver.Close(); closeErr != nil {
+		if closeErr := driver.Close(); closeErr != nil {
 			a.logger.Error("ドライバのクローズに失敗: %v", closeErr)
 		}
-		a.audioDriver = nil
 		a.trayMgr.ShowError(fmt.Sprintf("デバイスの初期化に失敗しました。別のデバイスを選択してください。\nエラー: %v", err))
 		// メニューを更新して状態を反映
 		a.updateDeviceMenu()
@@ -621,8 +2475,9 @@ func (a *App) handleDeviceChange(deviceID int) {
 	}
 
 	a.logger.Info("オーディオドライバの初期化が完了しました")
-	// API HandlerにAudioDriverを設定
-	a.apiHandler.SetAudioDriver(a.audioDriver)
+	// API HandlerにもAudioDriverを設定
+	a.setAudioState(driver, audioConfig)
+	a.checkStreamSampleRate()
 
 	// メニューを更新して変更を反映
 	a.updateDeviceMenu()
@@ -652,6 +2507,12 @@ func (a *App) handleQuit() {
 func (a *App) cleanupResources() {
 	a.logger.Info("終了処理開始")
 
+	// 0. 進行中の分割貼り付けとモデルのダウンロードを中断（イベントループの終了待ちが長引かないように）
+	if a.cancelLifecycle != nil {
+		a.cancelLifecycle()
+	}
+	a.allowSleep()
+
 	// 1. ホットキーマネージャーをクローズ（新しい入力を受け付けない）
 	if a.hotkeyMgr != nil {
 		a.logger.Info("ホットキーマネージャーをクローズ中...")
@@ -666,10 +2527,22 @@ func (a *App) cleanupResources() {
 		}
 	}
 
+	if a.clipHotkey != nil {
+		if err := a.clipHotkey.Close(); err != nil {
+			a.logger.Error("クリップボード文字起こしのホットキーのクローズに失敗: %v", err)
+		}
+	}
+	if a.secHotkey != nil {
+		if err := a.secHotkey.Close(); err != nil {
+			a.logger.Error("2つ目のホットキーのクローズに失敗: %v", err)
+		}
+	}
+
 	// 2. オーディオドライバをクローズ（録音を停止）
-	if a.audioDriver != nil {
+	driver, _ := a.audioState()
+	if driver != nil {
 		a.logger.Info("オーディオドライバをクローズ中...")
-		if err := a.audioDriver.Close(); err != nil {
+		if err := driver.Close(); err != nil {
 			a.logger.Error("オーディオドライバのクローズに失敗: %v", err)
 		}
 	}
@@ -694,7 +2567,7 @@ func (a *App) ReloadHotkey() error {
 	a.logger.Info("ホットキー再登録要求")
 
 	// 権限チェック
-	if !a.accGranted {
+	if !a.accGranted.Load() {
 		a.logger.Warn("ホットキー再登録: アクセシビリティ権限がありません")
 		return fmt.Errorf("アクセシビリティ権限が付与されていません")
 	}
@@ -713,13 +2586,9 @@ func (a *App) ReloadHotkey() error {
 	}
 
 	// 新しいホットキー設定を作成
-	newConfig := hotkey.Config{
-		Modifiers: configToModifiers(freshConfig.Hotkey),
-		Key:       stringToKey(freshConfig.Hotkey.Key),
-		Mode:      hotkey.PressToHold, // TODO: RecordingModeから決定
-	}
+	newConfig := buildHotkeyConfig(freshConfig)
 
-	a.logger.Info("新しいホットキー設定: Modifiers=%v, Key=%v", newConfig.Modifiers, newConfig.Key)
+	a.logger.Info("新しいホットキー設定: Modifiers=%v, Key=%v, Mode=%v", newConfig.Modifiers, newConfig.Key, newConfig.Mode)
 
 	// 現在の設定と比較（同じ場合はスキップ）
 	if a.hotkeyMgr.IsRunning() {
@@ -732,50 +2601,24 @@ func (a *App) ReloadHotkey() error {
 		}
 	}
 
-	// 既存の設定をバックアップ（ロールバック用）
-	var oldConfig hotkey.Config
-	needsRollback := false
-
-	// 既存のホットキーを解除
-	if a.hotkeyMgr.IsRunning() {
-		a.logger.Info("既存のホットキーを解除します")
-		oldConfig = a.hotkeyMgr.GetConfig()
-		needsRollback = true
-
-		if err := a.hotkeyMgr.Close(); err != nil {
-			// Close() のエラーはログに記録するが、処理は継続
-			// hotkey.go の修正により、エラーが発生しても m.running = false になる
-			a.logger.Warn("既存のホットキー解除時に警告: %v (処理は継続します)", err)
-		}
-
-		// イベントループが完全に終了するまで待機
-		a.logger.Info("イベントループの終了を待機中...")
-		a.hotkeyEventLoopWg.Wait()
-		a.logger.Info("イベントループが終了しました")
-	}
-
-	// 新しいホットキーを登録
+	// 動作中のホットキーは1回の操作で差し替える
+	// イベントチャネルは開いたままなので、イベントループは動き続け、イベントの取りこぼしや重複がない
+	// 登録に失敗した場合、マネージャーが旧ホットキーを登録し直す
+	wasRunning := a.hotkeyMgr.IsRunning()
 	a.logger.Info("新しいホットキーを登録します")
-	if err := a.hotkeyMgr.Register(newConfig); err != nil {
+	if err := a.hotkeyMgr.Reconfigure(newConfig); err != nil {
 		a.logger.Error("新しいホットキー登録に失敗: %v", err)
-
-		// ロールバック: 旧ホットキーを再登録
-		if needsRollback {
-			a.logger.Warn("ロールバック: 旧ホットキーを再登録します")
-			if rollbackErr := a.hotkeyMgr.Register(oldConfig); rollbackErr != nil {
-				a.logger.Error("ロールバック失敗: %v", rollbackErr)
-				a.trayMgr.ShowError("ホットキーの登録に失敗しました。アプリケーションを再起動してください。")
-				return fmt.Errorf("新しいホットキー登録に失敗し、ロールバックも失敗しました: %w (ロールバックエラー: %v)", err, rollbackErr)
-			}
-			go a.hotkeyEventLoop()
-			a.logger.Info("ロールバック完了")
+		if wasRunning && !a.hotkeyMgr.IsRunning() {
+			a.logger.Error("旧ホットキーの再登録にも失敗しました")
+			a.trayMgr.ShowError("ホットキーの登録に失敗しました。アプリケーションを再起動してください。")
 		}
-
 		return fmt.Errorf("新しいホットキーの登録に失敗: %w", err)
 	}
 
-	// イベントループを再起動
-	go a.hotkeyEventLoop()
+	// 停止していた場合は新しいイベントチャネルでイベントループを開始
+	if !wasRunning {
+		go a.hotkeyEventLoop()
+	}
 
 	// アプリケーションの設定を更新
 	a.config = freshConfig
@@ -829,7 +2672,7 @@ func (a *App) EnableHotkey() error {
 	a.logger.Info("ホットキー再有効化要求")
 
 	// 権限チェック
-	if !a.accGranted {
+	if !a.accGranted.Load() {
 		a.logger.Warn("ホットキー再有効化: アクセシビリティ権限がありません")
 		return fmt.Errorf("アクセシビリティ権限が付与されていません")
 	}
@@ -846,11 +2689,7 @@ func (a *App) EnableHotkey() error {
 	}
 
 	// 現在の設定でホットキーを登録
-	currentConfig := hotkey.Config{
-		Modifiers: configToModifiers(a.config.Hotkey),
-		Key:       stringToKey(a.config.Hotkey.Key),
-		Mode:      hotkey.PressToHold, // TODO: RecordingModeから決定
-	}
+	currentConfig := buildHotkeyConfig(a.config)
 
 	a.logger.Info("ホットキーを再有効化します: Modifiers=%v, Key=%v", currentConfig.Modifiers, currentConfig.Key)
 
@@ -866,6 +2705,409 @@ func (a *App) EnableHotkey() error {
 	return nil
 }
 
+// transcribe は format の録音データを文字起こしする
+// language が空でない場合はこの呼び出しだけ認識言語を上書きする（音声認識の設定は変更しない）
+// 結果はすべての経路（貼り付け・ログ・通知）で同じ recognition.Result をそのまま使う
+func (a *App) transcribe(audioData []byte, format audio.Config, language string) (recognition.Result, error) {
+	result, _, err := a.transcribeWith(audio.PCMToFloat32(audioData), format, config.HotkeyOptions{Language: language})
+	return result, err
+}
+
+// transcribeWith は transcribe と同じだが float32 のサンプルを受け取り、ホットキーごとの上書き（翻訳など）もこの呼び出しの間だけ適用する
+// サンプルは前処理から Whisper まで float32 のまま受け渡す
+// 結果とともに、translation_mode とホットキーの設定から決めた実際のタスク（文字起こしか翻訳か）を返す
+func (a *App) transcribeWith(samples []float32, format audio.Config, options config.HotkeyOptions) (recognition.Result, recognition.Task, error) {
+	_, audioConfig := a.audioState()
+	a.transcribeMutex.Lock()
+	defer a.transcribeMutex.Unlock()
+
+	// アイドル解放したモデルは文字起こしの前に読み込み直し、終了後にタイマーを設定し直す
+	if err := a.wakeModel(); err != nil {
+		return recognition.Result{}, "", err
+	}
+	defer a.scheduleIdleUnload()
+
+	if previous := a.recognizer.GetLanguage(); options.Language != "" && options.Language != previous {
+		a.logger.Info("認識言語を一時的に上書き: %s -> %s", previous, options.Language)
+	}
+	cfg := a.config.Clone()
+
+	// translation_mode が translate の場合は、ホットキーの設定にかかわらずすべての文字起こしを英語に翻訳する
+	task := recognition.TaskTranscribe
+	if options.Translate || cfg.TranslationMode == config.TranslationTranslate {
+		task = recognition.TaskTranslate
+		a.recognizer.SetTranslate(true)
+		defer a.recognizer.SetTranslate(false)
+		a.logger.Info("英語に翻訳して文字起こしします")
+	}
+
+	// すべての経路（ホットキー・録音テスト・API）で同じ順序の前処理を適用する
+	pipeline := a.audioPipeline(cfg, format)
+	if stages := pipeline.Stages(); len(stages) > 0 {
+		samples = pipeline.ProcessFloat32(samples)
+		a.logger.Debug("音声前処理: %v (%d サンプル)", stages, len(samples))
+	}
+
+	// プロンプトのプレースホルダ（{date}, {app}）は文字起こしのたびに展開する
+	a.recognizer.SetInitialPrompt(a.initialPrompt(cfg.InitialPrompt))
+	// 自動検出の確信度が低い場合は auto_fallback_languages の中から言語を選ぶ
+	a.recognizer.SetFallbackLanguages(cfg.AutoFallbackLanguages)
+	// adaptive_decoding が有効な場合は録音の長さでグリーディとビームサーチを切り替える
+	var adaptiveThreshold time.Duration
+	if cfg.AdaptiveDecoding {
+		adaptiveThreshold = time.Duration(cfg.AdaptiveDecodingSeconds) * time.Second
+	}
+	// silence_threshold 未満の録音は Whisper を実行しない（無音で定型文を作り出すのを防ぐ）
+	a.recognizer.SetSilenceThreshold(cfg.SilenceThreshold)
+
+	result, err := a.recognizer.TranscribeFloat32(samples, audioConfig.SampleRate, recognition.TranscribeOptions{
+		Language:          options.Language,
+		AdaptiveThreshold: adaptiveThreshold,
+		Repetition: recognition.RepetitionConfig{
+			MaxRepeats:          cfg.RepetitionMaxRepeats,
+			MaxCompressionRatio: cfg.RepetitionMaxCompressionRatio,
+			DedupeSegments:      cfg.DedupeSegments,
+		},
+	})
+	if err != nil {
+		return recognition.Result{}, "", err
+	}
+
+	// Whisperが先頭に付ける半角スペースを取り除く（貼り付け位置がずれないように）
+	if cfg.StripLeadingSpace {
+		result.Text = recognition.StripLeadingSpace(result.Text)
+	}
+
+	a.logger.Info("文字起こし結果: 言語=%s 音声=%dms 推論=%dms デコード=%s 信頼度=%.2f セグメント=%d",
+		result.Language, result.DurationMS, result.InferenceMS, result.Decoding, result.AvgConfidence, len(result.Segments))
+	return result, task, nil
+}
+
+// recognitionLanguage は最前面のアプリに応じた認識言語を返す
+// 優先順位は app_languages のアプリ別設定 > language > auto
+func (a *App) recognitionLanguage() string {
+	bundleID := a.frontmostBundle()
+	language := a.config.LanguageFor(bundleID)
+	a.logger.Debug("認識言語: %s (アプリ: %s)", language, bundleID)
+	return language
+}
+
+// initialPrompt は initial_prompt のプレースホルダを現在の日付と最前面のアプリ名で展開する
+// 最前面のアプリ名は {app} を含む場合のみ取得する
+func (a *App) initialPrompt(template string) string {
+	ctx := recognition.PromptContext{Now: time.Now()}
+	if recognition.UsesApp(template) && a.frontmostApp != nil {
+		ctx.App = a.frontmostApp()
+	}
+
+	prompt := recognition.ExpandPrompt(template, ctx)
+	if prompt != "" {
+		a.logger.Debug("初期プロンプト: %s", prompt)
+	}
+	return prompt
+}
+
+// audioPipeline は設定から、format の録音データを文字起こし前に処理するパイプラインを組み立てる
+// ドライバはデバイスの実際のレート（audio.RecordingFormat）で録音データを返すため、要求レートとの変換もここで行う
+func (a *App) audioPipeline(cfg *config.Config, format audio.Config) *audio.Pipeline {
+	_, audioConfig := a.audioState()
+	return audio.NewPipeline(audio.PipelineConfig{
+		Channels:    format.Channels,
+		InputRate:   format.SampleRate,
+		OutputRate:  audioConfig.SampleRate,
+		TrimSilence: cfg.AudioTrimSilence,
+		Normalize:   cfg.AudioNormalize,
+	})
+}
+
+// isAPIRecording はAPI経由の録音セッションが進行中かどうかを返す
+func (a *App) isAPIRecording() bool {
+	a.apiRecordingMutex.Lock()
+	defer a.apiRecordingMutex.Unlock()
+	return a.apiRecording
+}
+
+// StartAPIRecording は /api/recording/start から録音を開始する
+// language はこのセッションの文字起こしにのみ適用される（空の場合は設定値）
+// ホットキーの録音中は開始しない（ホットキーの文字起こし中はその完了を待つ）
+func (a *App) StartAPIRecording(language string) error {
+	// ホットキーのイベント処理と同じ順序（hotkeyEventMutex → apiRecordingMutex）でロックする
+	a.hotkeyEventMutex.Lock()
+	defer a.hotkeyEventMutex.Unlock()
+	a.apiRecordingMutex.Lock()
+	defer a.apiRecordingMutex.Unlock()
+
+	if a.apiRecording {
+		return fmt.Errorf("既に録音中です")
+	}
+	if a.hotkeySession != noHotkey {
+		return fmt.Errorf("ホットキーで録音中です")
+	}
+	if !a.micGranted.Load() {
+		return fmt.Errorf("マイク権限がありません")
+	}
+	driver, _ := a.audioState()
+	if driver == nil {
+		return fmt.Errorf("オーディオデバイスが初期化されていません")
+	}
+	if !a.modelLoaded.Load() {
+		return fmt.Errorf("モデルが読み込まれていません")
+	}
+
+	if err := audio.StartWithRetry(driver, a.startBackoff); err != nil {
+		return fmt.Errorf("録音開始に失敗: %w", err)
+	}
+
+	a.apiRecording = true
+	a.apiLanguage = language
+	a.keepAwake()
+	a.trayMgr.SetState(tray.StateRecording)
+	a.logger.Info("API経由で録音開始 (言語: %q)", language)
+
+	return nil
+}
+
+// StopAPIRecording は /api/recording/start で開始した録音を停止し、文字起こし結果を返す
+func (a *App) StopAPIRecording() (string, error) {
+	audioData, format, language, err := a.stopAPIRecording()
+	if err != nil {
+		return "", err
+	}
+	defer a.trayMgr.SetState(tray.StateIdle)
+
+	if len(audioData) == 0 {
+		return "", fmt.Errorf("録音データが空です")
+	}
+	if audio.IsSilent(audioData) {
+		return "", fmt.Errorf("%s", silentMicMessage)
+	}
+
+	if language == "" {
+		language = a.recognitionLanguage()
+	}
+	result, err := a.transcribe(audioData, format, language)
+	if err != nil {
+		return "", fmt.Errorf("文字起こしに失敗: %w", err)
+	}
+
+	a.logTranscription("API経由の文字起こし完了", result.Text)
+	return result.Text, nil
+}
+
+// stopAPIRecording は API経由の録音を停止し、録音データとその形式、セッションの言語を返す
+// 文字起こしの間もホットキーが isAPIRecording で待たされないよう、apiRecordingMutex は録音の停止までしか保持しない
+func (a *App) stopAPIRecording() ([]byte, audio.Config, string, error) {
+	a.apiRecordingMutex.Lock()
+	defer a.apiRecordingMutex.Unlock()
+
+	if !a.apiRecording {
+		return nil, audio.Config{}, "", fmt.Errorf("録音中ではありません")
+	}
+
+	a.apiRecording = false
+	a.allowSleep()
+	a.trayMgr.SetState(tray.StateProcessing)
+
+	driver, audioConfig := a.audioState()
+	audioData, err := driver.StopRecording()
+	if err != nil {
+		a.trayMgr.SetState(tray.StateIdle)
+		return nil, audio.Config{}, "", fmt.Errorf("録音停止に失敗: %w", err)
+	}
+	return audioData, audio.RecordingFormat(driver, audioConfig), a.apiLanguage, nil
+}
+
+// logTranscription は文字起こし結果をログに記録する
+// 口述内容がログファイルに残らないよう、log_transcription_text が有効な場合を除き文字数のみを記録する
+func (a *App) logTranscription(label, text string) {
+	if a.config.Clone().LogTranscriptionText {
+		a.logger.Info("%s: %s", label, text)
+		return
+	}
+	a.logger.Info("%s: %d 文字", label, utf8.RuneCountInString(text))
+}
+
+// applyModelTuning はモデルとマシンのスペックから推奨スレッド数とプリセットを選び、認識器に適用する
+// 設定ファイルで明示的に指定されている値はそちらを優先する
+func (a *App) applyModelTuning(modelPath string) recognition.Tuning {
+	system := recognition.DetectSystem()
+	tuning := recognition.Tuning{Preset: recognition.PresetBalanced}
+
+	model, err := recognition.InspectModel(modelPath)
+	if err != nil {
+		a.logger.Warn("モデル情報の取得に失敗: %v", err)
+	} else {
+		tuning = recognition.RecommendTuning(model, system)
+		a.logger.Info("モデル情報: クラス=%s, 量子化=%s, サイズ=%d バイト (コア数=%d, メモリ=%d バイト)",
+			model.Class, model.Quantization, model.SizeBytes, system.Cores, system.MemoryBytes)
+	}
+	// 設定画面で threads を 0 に戻したときに使えるよう、上書き前の推奨値を残す
+	a.autoThreads.Store(int32(tuning.Threads))
+
+	cfg := a.config.Clone()
+	if cfg.Threads > 0 {
+		tuning.Threads = cfg.Threads
+	}
+	if cfg.DecodingPreset != "" && cfg.DecodingPreset != "auto" {
+		tuning.Preset = recognition.Preset(cfg.DecodingPreset)
+	}
+
+	if tuning.Warning != "" {
+		a.logger.Warn("メモリ警告: %s", tuning.Warning)
+	}
+	a.logger.Info("推論設定: スレッド数=%d, プリセット=%s", tuning.Threads, tuning.Preset)
+
+	a.recognizer.SetTuning(tuning)
+	return tuning
+}
+
+// handleSettingsSaved は設定画面で保存された設定のうち、再起動やモデルの再読み込みなしで反映できるものを適用する
+func (a *App) handleSettingsSaved() {
+	cfg := a.config.Clone()
+
+	// threads は次の文字起こしから反映する（0 は読み込んだモデルに推奨されるスレッド数）
+	threads := cfg.Threads
+	if threads == 0 {
+		threads = int(a.autoThreads.Load())
+	}
+	a.recognizer.SetThreads(threads)
+	a.logger.Info("設定を反映: スレッド数=%d", threads)
+
+	// paste_* の設定は次の貼り付けから反映する（貼り付け中の場合は終わるまで待つ）
+	a.clipboard.SetConfig(clipboardConfig(cfg))
+}
+
+// status は /api/status で返すアプリケーションの実行状態を組み立てる
+func (a *App) status() map[string]interface{} {
+	driver, _ := a.audioState()
+	status := map[string]interface{}{
+		"version":       version.Version,
+		"model_loaded":  a.modelLoaded.Load(),
+		"model_idle":    a.isModelIdle(),
+		"accessibility": a.accGranted.Load(),
+	}
+
+	if a.modelLoaded.Load() {
+		status["tuning"] = a.recognizer.GetTuning()
+	}
+
+	if provider, ok := driver.(audio.StreamInfoProvider); ok {
+		status["audio_stream"] = provider.StreamInfo()
+	}
+
+	return status
+}
+
+// isModelIdle はアイドル解放でモデルを解放済みかどうかを返す
+func (a *App) isModelIdle() bool {
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
+	return a.modelIdle
+}
+
+// checkStreamSampleRate はデバイスが要求と異なるサンプルレートで開かれた場合に警告する
+// 録音データはドライバから実際のレートのまま返り、transcribeWith のパイプラインが要求レートにリサンプリングする
+func (a *App) checkStreamSampleRate() {
+	driver, _ := a.audioState()
+	provider, ok := driver.(audio.StreamInfoProvider)
+	if !ok {
+		return
+	}
+
+	info := provider.StreamInfo()
+	a.logger.Info("オーディオストリーム: デバイス=%s, 要求=%d Hz, 実際=%d Hz", info.DeviceName, info.RequestedSampleRate, info.EffectiveSampleRate)
+
+	if !info.SampleRateMismatch() {
+		return
+	}
+
+	a.logger.Warn("デバイス %s が要求と異なるサンプルレートで開かれました（要求: %d Hz, 実際: %d Hz）。録音データをリサンプリングします",
+		info.DeviceName, info.RequestedSampleRate, info.EffectiveSampleRate)
+	a.trayMgr.ShowNotification("サンプルレートの不一致",
+		fmt.Sprintf("%s は %d Hz で動作しています（要求: %d Hz）。録音は自動的に変換されます。", info.DeviceName, info.EffectiveSampleRate, info.RequestedSampleRate))
+}
+
+// prependTimestamp は議事録向けに、now を Go の時刻レイアウト layout で整形して text の先頭に付ける
+// layout が空の場合は text をそのまま返す
+func prependTimestamp(text, layout string, now time.Time) string {
+	if layout == "" {
+		return text
+	}
+	return now.Format(layout) + " " + text
+}
+
+// truncateRunes は text を最大 maxChars 文字（rune 単位）に切り詰める
+// maxChars が 0 以下の場合は切り詰めない。切り詰めた場合は true を返す
+func truncateRunes(text string, maxChars int) (string, bool) {
+	if maxChars <= 0 || utf8.RuneCountInString(text) <= maxChars {
+		return text, false
+	}
+
+	runes := []rune(text)
+	return string(runes[:maxChars]), true
+}
+
+// silentMicMessage は録音が無音だった場合（マイクのミュートなど）に表示するメッセージ
+const silentMicMessage = "マイクが無音です。ミュートされていないか確認してください"
+
+// hotkeyConflictMessage はホットキーが競合した場合に、競合相手と代わりの候補を示すメッセージを返す
+func hotkeyConflictMessage(err error, cfg hotkey.Config) string {
+	current := hotkey.FormatHotkey(cfg.Modifiers, cfg.Key)
+
+	message := fmt.Sprintf("ホットキー %s は他のアプリまたはシステムで使用されています。", current)
+	var conflictErr *hotkey.ConflictError
+	if errors.As(err, &conflictErr) && len(conflictErr.Conflicts) > 0 {
+		message = fmt.Sprintf("ホットキー %s は %s と競合しています。", current, strings.Join(conflictErr.Conflicts, ", "))
+	}
+
+	var candidates []string
+	for _, mods := range hotkey.SuggestAlternatives(cfg.Modifiers, cfg.Key, 3) {
+		candidates = append(candidates, hotkey.FormatHotkey(mods, cfg.Key))
+	}
+	if len(candidates) > 0 {
+		message += fmt.Sprintf("\n設定画面で %s などに変更してください。", strings.Join(candidates, " / "))
+	}
+
+	return message
+}
+
+// recordingStartErrorMessage は録音開始エラーをユーザー向けのメッセージに変換する
+func recordingStartErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, audio.ErrDeviceBusy):
+		return "マイクが他のアプリで使用中の可能性があります。他のアプリを終了してから再度お試しください。"
+	case errors.Is(err, audio.ErrInvalidDevice):
+		return "入力デバイスが見つからないか、使用できません。設定画面でデバイスを選択し直してください。"
+	case errors.Is(err, audio.ErrHostError):
+		return "オーディオシステムでエラーが発生しました。デバイスを接続し直すか、アプリを再起動してください。"
+	case errors.Is(err, audio.ErrTransient):
+		return "オーディオシステムの準備ができていません。スリープ解除や Bluetooth の切り替え直後の場合は、少し待ってから再度お試しください。"
+	default:
+		return fmt.Sprintf("録音開始に失敗: %v", err)
+	}
+}
+
+// buildHotkeyConfig は設定ファイルの内容から hotkey.Config を組み立てる
+// RecordingMode が不正な値の場合は押下中録音にフォールバックする
+func buildHotkeyConfig(cfg *config.Config) hotkey.Config {
+	return buildRecordingHotkeyConfig(cfg, cfg.Hotkey)
+}
+
+// buildRecordingHotkeyConfig は録音用ホットキー keys に、設定ファイルの録音モードを組み合わせた hotkey.Config を返す
+func buildRecordingHotkeyConfig(cfg *config.Config, keys config.HotkeyConfig) hotkey.Config {
+	mode, err := hotkey.ParseRecordingMode(cfg.RecordingMode)
+	if err != nil {
+		mode = hotkey.PressToHold
+	}
+
+	return hotkey.Config{
+		Modifiers:         configToModifiers(keys),
+		Key:               hotkey.KeyFromString(keys.Key),
+		Mode:              mode,
+		ToggleGraceWindow: time.Duration(cfg.ToggleGraceMs) * time.Millisecond,
+	}
+}
+
 // configToModifiers は HotkeyConfig を golang.design/x/hotkey の Modifier スライスに変換
 func configToModifiers(hkConfig config.HotkeyConfig) []hk.Modifier {
 	var mods []hk.Modifier
@@ -883,56 +3125,3 @@ func configToModifiers(hkConfig config.HotkeyConfig) []hk.Modifier {
 	}
 	return mods
 }
-
-// stringToKey は文字列をキーコードに変換
-func stringToKey(keyStr string) hk.Key {
-	keyMap := map[string]hk.Key{
-		"Space":  hk.KeySpace,
-		"A":      hk.KeyA,
-		"B":      hk.KeyB,
-		"C":      hk.KeyC,
-		"D":      hk.KeyD,
-		"E":      hk.KeyE,
-		"F":      hk.KeyF,
-		"G":      hk.KeyG,
-		"H":      hk.KeyH,
-		"I":      hk.KeyI,
-		"J":      hk.KeyJ,
-		"K":      hk.KeyK,
-		"L":      hk.KeyL,
-		"M":      hk.KeyM,
-		"N":      hk.KeyN,
-		"O":      hk.KeyO,
-		"P":      hk.KeyP,
-		"Q":      hk.KeyQ,
-		"R":      hk.KeyR,
-		"S":      hk.KeyS,
-		"T":      hk.KeyT,
-		"U":      hk.KeyU,
-		"V":      hk.KeyV,
-		"W":      hk.KeyW,
-		"X":      hk.KeyX,
-		"Y":      hk.KeyY,
-		"Z":      hk.KeyZ,
-		"0":      hk.Key0,
-		"1":      hk.Key1,
-		"2":      hk.Key2,
-		"3":      hk.Key3,
-		"4":      hk.Key4,
-		"5":      hk.Key5,
-		"6":      hk.Key6,
-		"7":      hk.Key7,
-		"8":      hk.Key8,
-		"9":      hk.Key9,
-		"Escape": hk.KeyEscape,
-		"Return": hk.KeyReturn,
-		"Tab":    hk.KeyTab,
-	}
-
-	if key, ok := keyMap[keyStr]; ok {
-		return key
-	}
-
-	// デフォルトはSpace
-	return hk.KeySpace
-}
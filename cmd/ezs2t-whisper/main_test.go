@@ -0,0 +1,2227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio/fakeaudio"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/clipboard"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/history"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/hotkey"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/logger"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/metrics"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/notification"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/permissions"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/recognition"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/tray"
+	hk "golang.design/x/hotkey"
+)
+
+// fakeRecognizer returns fixed segments and records the audio it was given
+type fakeRecognizer struct {
+	mu       sync.Mutex
+	segments []string
+	language string
+	received [][]byte
+	loaded   []string
+	loadErr  error
+	testErr  error    // Returned by SelfTest
+	prompts  []string // Initial prompt in effect for each transcription
+
+	languages    []string // Language in effect for each transcription
+	unloads      int      // Number of Unload calls
+	translate    bool
+	translations []bool // Translate flag in effect for each transcription
+	fallback     []string
+	silent       bool            // TranscribeFloat32 reports the audio as silent without segments
+	adaptive     []time.Duration // Adaptive decoding threshold of each transcription
+	threads      []int           // Arguments of SetThreads
+	block        chan struct{}   // TranscribeFloat32 waits until it is closed, nil = no wait
+}
+
+func (r *fakeRecognizer) LoadModel(modelPath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loaded = append(r.loaded, modelPath)
+	return r.loadErr
+}
+
+func (r *fakeRecognizer) SelfTest() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.testErr
+}
+
+// TranscribeStream sends the whole transcription as a partial result for every chunk
+func (r *fakeRecognizer) TranscribeStream(ctx context.Context, sampleRate int, chunks <-chan []byte) <-chan recognition.Partial {
+	out := make(chan recognition.Partial)
+	go func() {
+		defer close(out)
+		for range chunks {
+			r.mu.Lock()
+			text := strings.Join(r.segments, "")
+			r.mu.Unlock()
+			out <- recognition.Partial{Text: text}
+		}
+	}()
+	return out
+}
+
+// TranscribeFloat32 records the samples as 16-bit PCM in received, so tests can compare them with driver output
+func (r *fakeRecognizer) TranscribeFloat32(samples []float32, sampleRate int, options recognition.TranscribeOptions) (recognition.Result, error) {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	language := options.Language
+	if language == "" {
+		language = r.language
+	}
+	r.received = append(r.received, audio.Float32ToPCM(samples))
+	r.languages = append(r.languages, language)
+	r.adaptive = append(r.adaptive, options.AdaptiveThreshold)
+	r.translations = append(r.translations, r.translate)
+	if r.silent {
+		return recognition.Result{Language: language, Silent: true}, nil
+	}
+
+	segments := make([]recognition.Segment, len(r.segments))
+	for i, text := range r.segments {
+		segments[i] = recognition.Segment{Text: text, Confidence: 0.9}
+	}
+	durationMS := int64(len(samples)) * 1000 / int64(sampleRate)
+	return recognition.NewResult(segments, language, durationMS, 1, options.Repetition), nil
+}
+
+func (r *fakeRecognizer) SetLanguage(language string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.language = language
+}
+
+func (r *fakeRecognizer) GetLanguage() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.language
+}
+
+func (r *fakeRecognizer) SetTuning(tuning recognition.Tuning) {}
+
+func (r *fakeRecognizer) SetThreads(threads int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.threads = append(r.threads, threads)
+}
+
+func (r *fakeRecognizer) GetTuning() recognition.Tuning { return recognition.Tuning{} }
+
+func (r *fakeRecognizer) SetInitialPrompt(prompt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts = append(r.prompts, prompt)
+}
+
+func (r *fakeRecognizer) SetTranslate(translate bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.translate = translate
+}
+
+func (r *fakeRecognizer) SetFallbackLanguages(languages []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = languages
+}
+
+func (r *fakeRecognizer) SetSilenceThreshold(threshold float64) {}
+
+func (r *fakeRecognizer) Unload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unloads++
+}
+
+func (r *fakeRecognizer) Close() error { return nil }
+
+// fakeTimer is a timer created by fakeClock that only fires when told to
+type fakeTimer struct {
+	duration time.Duration
+	f        func()
+	stopped  bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+// fire runs the callback like an expired time.Timer, which can no longer be stopped
+func (t *fakeTimer) fire() {
+	t.stopped = true
+	t.f()
+}
+
+// fakeClock replaces time.AfterFunc and records the timers it creates
+type fakeClock struct {
+	timers []*fakeTimer
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) timerHandle {
+	timer := &fakeTimer{duration: d, f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// active returns the timers that have not been stopped
+func (c *fakeClock) active() []*fakeTimer {
+	var active []*fakeTimer
+	for _, timer := range c.timers {
+		if !timer.stopped {
+			active = append(active, timer)
+		}
+	}
+	return active
+}
+
+// fakePaster records pasted text instead of sending key events
+type fakePaster struct {
+	pasted    []string
+	clipboard string
+	pasteErr  error              // Returned by SafePasteWithSplitContext instead of pasting
+	configs   []clipboard.Config // Arguments of SetConfig
+}
+
+func (p *fakePaster) SafePasteWithSplitContext(ctx context.Context, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.pasteErr != nil {
+		return p.pasteErr
+	}
+	p.pasted = append(p.pasted, text)
+	return nil
+}
+
+func (p *fakePaster) CopyText(text string) error {
+	p.clipboard = text
+	return nil
+}
+
+func (p *fakePaster) SetConfig(config clipboard.Config) {
+	p.configs = append(p.configs, config)
+}
+
+// fakeTray records state changes and notifications instead of touching the menu bar
+type fakeTray struct {
+	states        []tray.State
+	errors        []string
+	notifications []string
+	problems      []string
+	partials      []string // Partial results shown in the tooltip, "" when cleared
+}
+
+func (t *fakeTray) Run()                                   {}
+func (t *fakeTray) Quit()                                  {}
+func (t *fakeTray) SetState(state tray.State)              { t.states = append(t.states, state) }
+func (t *fakeTray) SetProblems(problems []string)          { t.problems = problems }
+func (t *fakeTray) SetPartialText(text string)             { t.partials = append(t.partials, text) }
+func (t *fakeTray) UpdateDeviceMenu(devices []tray.Device) {}
+func (t *fakeTray) ShowNotification(title, message string) {
+	t.notifications = append(t.notifications, message)
+}
+func (t *fakeTray) ShowError(message string)   { t.errors = append(t.errors, message) }
+func (t *fakeTray) ShowSuccess(message string) {}
+
+// newTestApp builds an App wired to fake audio, recognizer, clipboard and tray
+func newTestApp(t *testing.T, segments []string) (*App, *fakeRecognizer, *fakePaster, *fakeTray) {
+	t.Helper()
+
+	log, err := logger.New(logger.Config{LogDir: t.TempDir(), Level: logger.DEBUG, RetentionDays: 1})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	audioConfig := audio.DefaultConfig()
+	driver := fakeaudio.New("test", fakeaudio.Sine(440, 500*time.Millisecond, audioConfig.SampleRate, 0.3), 0, 0)
+	if err := driver.Initialize(audioConfig); err != nil {
+		t.Fatalf("Failed to initialize fake audio: %v", err)
+	}
+
+	recognizer := &fakeRecognizer{segments: segments, language: "auto"}
+	paster := &fakePaster{}
+	trayUI := &fakeTray{}
+
+	app := &App{
+		logger:      log,
+		config:      config.DefaultConfig(),
+		trayMgr:     trayUI,
+		audioDriver: driver,
+		audioConfig: audioConfig,
+		recognizer:  recognizer,
+		clipboard:   paster,
+		stats:       metrics.NewStats(0),
+	}
+	app.micGranted.Store(true)
+	app.accGranted.Store(true)
+	app.modelLoaded.Store(true)
+
+	return app, recognizer, paster, trayUI
+}
+
+// runEvents feeds the events through the hotkey pipeline and waits for it to finish
+func runEvents(app *App, types ...hotkey.EventType) {
+	runHotkeyEvents(app, primaryHotkey, types...)
+}
+
+// runHotkeyEvents is runEvents for the hotkey source
+func runHotkeyEvents(app *App, source hotkeySource, types ...hotkey.EventType) {
+	events := make(chan hotkey.Event, len(types))
+	for _, eventType := range types {
+		events <- hotkey.Event{Type: eventType}
+	}
+	close(events)
+
+	app.processHotkeyEvents(events, source)
+}
+
+func TestHotkeyPipeline_RecordTranscribePaste(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"こんにちは、", "世界。"})
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 1 {
+		t.Fatalf("Expected 1 transcription, got %d", len(recognizer.received))
+	}
+
+	// 500ms at 16kHz, 16-bit mono
+	if len(recognizer.received[0]) != 16000 {
+		t.Errorf("Expected 16000 bytes of audio, got %d", len(recognizer.received[0]))
+	}
+
+	if len(paster.pasted) != 1 || paster.pasted[0] != "こんにちは、世界。" {
+		t.Errorf("Expected 'こんにちは、世界。' to be pasted, got %v", paster.pasted)
+	}
+
+	expectedStates := []tray.State{tray.StateRecording, tray.StateProcessing, tray.StateIdle}
+	if len(trayUI.states) != len(expectedStates) {
+		t.Fatalf("Expected states %v, got %v", expectedStates, trayUI.states)
+	}
+	for i := range expectedStates {
+		if trayUI.states[i] != expectedStates[i] {
+			t.Errorf("State %d: expected %v, got %v", i, expectedStates[i], trayUI.states[i])
+		}
+	}
+
+	if len(trayUI.errors) != 0 {
+		t.Errorf("Expected no errors, got %v", trayUI.errors)
+	}
+}
+
+// pcmOnlyDriver hides StopRecordingFloat32 of the wrapped driver, like a driver that only records 16-bit PCM
+type pcmOnlyDriver struct {
+	audio.AudioDriver
+}
+
+func TestHotkeyPipeline_PCMOnlyDriver(t *testing.T) {
+	floatApp, floatRecognizer, _, _ := newTestApp(t, []string{"テスト"})
+	runEvents(floatApp, hotkey.Pressed, hotkey.Released)
+
+	app, recognizer, paster, _ := newTestApp(t, []string{"テスト"})
+	app.audioDriver = pcmOnlyDriver{app.audioDriver}
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	// Converting the PCM recording must give the recognizer the same samples as the float32 path
+	if len(recognizer.received) != 1 || len(floatRecognizer.received) != 1 {
+		t.Fatalf("Expected 1 transcription on each path, got %d and %d", len(recognizer.received), len(floatRecognizer.received))
+	}
+	if !bytes.Equal(recognizer.received[0], floatRecognizer.received[0]) {
+		t.Error("Expected the PCM and float32 paths to transcribe the same audio")
+	}
+
+	if len(paster.pasted) != 1 || paster.pasted[0] != "テスト" {
+		t.Errorf("Expected 'テスト' to be pasted, got %v", paster.pasted)
+	}
+}
+
+func TestHotkeyPipeline_TranscriptionTextLogging(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		app, _, _, _ := newTestApp(t, []string{"秘密のメモ"})
+
+		logDir := t.TempDir()
+		log, err := logger.New(logger.Config{LogDir: logDir, Level: logger.DEBUG, RetentionDays: 1})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		app.logger = log
+		app.config.LogTranscriptionText = enabled
+
+		runEvents(app, hotkey.Pressed, hotkey.Released)
+		log.Close()
+
+		files, _ := filepath.Glob(filepath.Join(logDir, "*.log"))
+		var logged strings.Builder
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read log: %v", err)
+			}
+			logged.Write(data)
+		}
+
+		if got := strings.Contains(logged.String(), "秘密のメモ"); got != enabled {
+			t.Errorf("log_transcription_text=%v: expected text logged=%v, got %v", enabled, enabled, got)
+		}
+		if !enabled && !strings.Contains(logged.String(), "文字起こし完了: 5 文字") {
+			t.Errorf("Expected only the length to be logged, got:\n%s", logged.String())
+		}
+	}
+}
+
+func TestHotkeyPipeline_LanguageUpdate(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"hello"})
+	app.config.Language = "ja"
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	// Saving the settings changes the language of the very next transcription
+	if err := app.config.Update(map[string]interface{}{"language": "en"}); err != nil {
+		t.Fatalf("Failed to update language: %v", err)
+	}
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.languages) != 2 || recognizer.languages[0] != "ja" || recognizer.languages[1] != "en" {
+		t.Errorf("Expected ja and then en, got %v", recognizer.languages)
+	}
+}
+
+func TestHotkeyPipeline_AppLanguage(t *testing.T) {
+	app, recognizer, _, trayUI := newTestApp(t, []string{"TODO: fix"})
+	app.config.Language = "ja"
+	app.config.AppLanguages = config.AppLanguages{"com.apple.dt.Xcode": "en"}
+
+	frontmost := "com.apple.dt.Xcode"
+	app.frontmostBundleID = func() string { return frontmost }
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	frontmost = "com.tinyspeck.slackmacgap"
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.languages) != 2 || recognizer.languages[0] != "en" || recognizer.languages[1] != "ja" {
+		t.Errorf("Expected the Xcode override and then the global language, got %v", recognizer.languages)
+	}
+
+	// The override applies to a single utterance only
+	if recognizer.GetLanguage() != "auto" {
+		t.Errorf("Expected the recognizer language to be restored, got %q", recognizer.GetLanguage())
+	}
+
+	// Only the overridden language is shown in the completion notification
+	if len(trayUI.notifications) != 1 || trayUI.notifications[0] != "en で文字起こししました" {
+		t.Errorf("Expected a notification with the Xcode language, got %v", trayUI.notifications)
+	}
+}
+
+func TestCompletionLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		detected string
+		task     recognition.Task
+		language string
+		global   string
+		expected string
+	}{
+		{"global language", "ja", recognition.TaskTranscribe, "ja", "ja", ""},
+		{"per-app language", "en", recognition.TaskTranscribe, "en", "ja", "en"},
+		{"per-app auto uses the detected language", "fr", recognition.TaskTranscribe, "auto", "en", "fr"},
+		{"translation shows the source language", "ja", recognition.TaskTranslate, "ja", "ja", "ja"},
+		{"translation without a detected language", "", recognition.TaskTranslate, "auto", "auto", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := recognition.Result{Language: tt.detected}
+			if got := completionLanguage(result, tt.task, tt.language, tt.global); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHotkeyPipeline_AppOutputMode(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"ls -la"})
+	app.config.AppOutputModes = config.AppOutputs{"com.apple.Terminal": config.OutputCopy}
+
+	frontmost := "com.apple.Terminal"
+	app.frontmostBundleID = func() string { return frontmost }
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 0 || paster.clipboard != "ls -la" {
+		t.Errorf("Expected the Terminal result to be copied only, got pasted=%v clipboard=%q", paster.pasted, paster.clipboard)
+	}
+	if len(trayUI.notifications) != 1 || !strings.Contains(trayUI.notifications[0], "コピー") {
+		t.Errorf("Expected a copy notification, got %v", trayUI.notifications)
+	}
+
+	// Other apps fall back to the global output_mode
+	frontmost = "com.apple.TextEdit"
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 1 {
+		t.Errorf("Expected the result to be pasted into other apps, got %v", paster.pasted)
+	}
+}
+
+func TestHotkeyPipeline_SecondHotkeyTranslates(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"Hello."})
+	app.config.Language = "ja"
+	app.config.SecondHotkey = config.HotkeyConfig{Key: "t", Ctrl: true, Options: config.HotkeyOptions{Translate: true}}
+
+	runHotkeyEvents(app, secondHotkey, hotkey.Pressed, hotkey.Released)
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.translations) != 2 || !recognizer.translations[0] || recognizer.translations[1] {
+		t.Errorf("Expected only the second hotkey to translate, got %v", recognizer.translations)
+	}
+	if recognizer.languages[0] != "ja" {
+		t.Errorf("Expected the source language to stay ja, got %q", recognizer.languages[0])
+	}
+	if len(paster.pasted) != 2 {
+		t.Errorf("Expected both results to be pasted, got %v", paster.pasted)
+	}
+
+	found := 0
+	for _, n := range trayUI.notifications {
+		if strings.Contains(n, "英語に翻訳") {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Errorf("Expected one translation notification, got %v", trayUI.notifications)
+	}
+}
+
+func TestHotkeyPipeline_TranslationMode(t *testing.T) {
+	app, recognizer, _, trayUI := newTestApp(t, []string{"Hello."})
+	app.history = history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	app.config.HistoryEnabled = true
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	app.config.TranslationMode = config.TranslationTranslate
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.translations) != 2 || recognizer.translations[0] || !recognizer.translations[1] {
+		t.Errorf("Expected only the second recording to be translated, got %v", recognizer.translations)
+	}
+	if recognizer.translate {
+		t.Error("Expected the recognizer to be reset to transcription afterwards")
+	}
+
+	// History and notification follow the global mode, not just the hotkey options
+	entries, total, err := app.history.List(0, 10)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", total)
+	}
+	modes := map[string]int{}
+	for _, entry := range entries {
+		modes[entry.Mode]++
+	}
+	if modes[history.ModeTranscribe] != 1 || modes[history.ModeTranslate] != 1 {
+		t.Errorf("Expected one transcribed and one translated entry, got %+v", entries)
+	}
+
+	found := 0
+	for _, n := range trayUI.notifications {
+		if strings.Contains(n, "英語に翻訳") {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Errorf("Expected one translation notification, got %v", trayUI.notifications)
+	}
+}
+
+func TestHotkeyPipeline_OtherHotkeyIgnoredWhileRecording(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+
+	// The release of the other hotkey must not end the session the primary hotkey started
+	runEvents(app, hotkey.Pressed)
+	runHotkeyEvents(app, secondHotkey, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 0 || !app.audioDriver.IsRecording() {
+		t.Fatalf("Expected the primary session to keep recording, got %d transcriptions", len(recognizer.received))
+	}
+
+	runEvents(app, hotkey.Released)
+
+	if len(recognizer.received) != 1 {
+		t.Errorf("Expected the primary hotkey to finish its session, got %d transcriptions", len(recognizer.received))
+	}
+}
+
+func TestHotkeyPipeline_ShortRecordingDiscarded(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"ありがとうございました"})
+
+	// A brush of the hotkey captures only 100 ms
+	driver := fakeaudio.New("short", fakeaudio.Sine(440, 100*time.Millisecond, app.audioConfig.SampleRate, 0.3), 0, 0)
+	if err := driver.Initialize(app.audioConfig); err != nil {
+		t.Fatalf("Failed to initialize fake audio: %v", err)
+	}
+	app.audioDriver = driver
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 0 || len(paster.pasted) != 0 {
+		t.Errorf("Expected the short recording to be discarded, got %d transcriptions", len(recognizer.received))
+	}
+	if len(trayUI.notifications) != 0 || len(trayUI.errors) != 0 {
+		t.Errorf("Expected no notification, got %v %v", trayUI.notifications, trayUI.errors)
+	}
+	if last := trayUI.states[len(trayUI.states)-1]; last != tray.StateIdle {
+		t.Errorf("Expected the tray to return to idle, got %v", last)
+	}
+
+	// 0 disables the check
+	app.config.MinRecordMs = 0
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 1 {
+		t.Errorf("Expected the recording to be transcribed with the check disabled, got %d", len(recognizer.received))
+	}
+}
+
+func TestHotkeyPipeline_RestoreFocus(t *testing.T) {
+	app, _, paster, _ := newTestApp(t, []string{"こんにちは"})
+	app.config.RestoreFocusBeforePaste = true
+
+	frontmost := "com.apple.TextEdit"
+	app.frontmostBundleID = func() string { return frontmost }
+	var activated []string
+	app.activateApp = func(bundleID string) error {
+		activated = append(activated, bundleID)
+		frontmost = bundleID
+		return nil
+	}
+
+	// Stopping from the menu bar moved the focus away from the editor
+	runEvents(app, hotkey.Pressed)
+	frontmost = "com.apple.systemuiserver"
+	runEvents(app, hotkey.Released)
+
+	if len(activated) != 1 || activated[0] != "com.apple.TextEdit" {
+		t.Errorf("Expected TextEdit to be activated before pasting, got %v", activated)
+	}
+	if len(paster.pasted) != 1 {
+		t.Errorf("Expected the result to be pasted, got %v", paster.pasted)
+	}
+
+	// Nothing is activated when the app is still in front or the option is off
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	app.config.RestoreFocusBeforePaste = false
+	runEvents(app, hotkey.Pressed)
+	frontmost = "com.apple.systemuiserver"
+	runEvents(app, hotkey.Released)
+
+	if len(activated) != 1 {
+		t.Errorf("Expected no further activation, got %v", activated)
+	}
+}
+
+func TestHotkeyPipeline_Timings(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if snapshot := app.stats.Snapshot(); snapshot.Runs != 1 || snapshot.AudioTotalMs == 0 {
+		t.Errorf("Expected one run with the recording length, got %+v", snapshot)
+	}
+	if len(trayUI.notifications) != 0 {
+		t.Errorf("Expected timings to stay out of notifications by default, got %v", trayUI.notifications)
+	}
+
+	app.config.ShowTimings = true
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(trayUI.notifications) != 1 || !strings.Contains(trayUI.notifications[0], "RTF") {
+		t.Errorf("Expected a notification with the timings, got %v", trayUI.notifications)
+	}
+	if len(paster.pasted) != 2 || app.stats.Snapshot().Runs != 2 {
+		t.Errorf("Expected both results to be pasted and measured, got %v", paster.pasted)
+	}
+}
+
+func TestScreenLockAction(t *testing.T) {
+	tests := []struct {
+		policy   string
+		locked   bool
+		expected lockAction
+	}{
+		{config.ScreenLockedIgnore, false, lockNormal},
+		{config.ScreenLockedClipboardOnly, false, lockNormal},
+		{config.ScreenLockedIgnore, true, lockIgnore},
+		{config.ScreenLockedClipboardOnly, true, lockClipboardOnly},
+		{config.ScreenLockedNormal, true, lockNormal},
+		{"", true, lockIgnore},
+	}
+
+	for _, tt := range tests {
+		if got := screenLockAction(tt.policy, tt.locked); got != tt.expected {
+			t.Errorf("screenLockAction(%q, %v) = %v, expected %v", tt.policy, tt.locked, got, tt.expected)
+		}
+	}
+}
+
+func TestHotkeyPipeline_ScreenLocked(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"聞かれたくない会話"})
+	locked := true
+	app.screenLocked = func() bool { return locked }
+
+	// The default policy ignores the press altogether
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 0 || app.audioDriver.IsRecording() {
+		t.Fatalf("Expected no recording while locked, got %d transcriptions", len(recognizer.received))
+	}
+
+	// clipboard-only records, but unlocking before the release must not paste
+	app.config.ScreenLockedPolicy = config.ScreenLockedClipboardOnly
+	runEvents(app, hotkey.Pressed)
+	locked = false
+	runEvents(app, hotkey.Released)
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted, got %v", paster.pasted)
+	}
+	if paster.clipboard != "聞かれたくない会話" {
+		t.Errorf("Expected the result on the clipboard, got %q", paster.clipboard)
+	}
+	for _, n := range trayUI.notifications {
+		if strings.Contains(n, "聞かれたくない") {
+			t.Errorf("Expected the notification to leave out the text, got %q", n)
+		}
+	}
+
+	// Unlocked sessions paste as usual
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 1 {
+		t.Errorf("Expected the unlocked session to paste, got %v", paster.pasted)
+	}
+}
+
+func TestHotkeyPipeline_CancelledToggleDiscardsRecording(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+
+	runEvents(app, hotkey.Pressed, hotkey.Cancelled)
+
+	if len(recognizer.received) != 0 {
+		t.Errorf("Expected no transcription for a cancelled session, got %d", len(recognizer.received))
+	}
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted, got %v", paster.pasted)
+	}
+
+	if app.audioDriver.IsRecording() {
+		t.Error("Expected recording to be stopped")
+	}
+
+	if last := trayUI.states[len(trayUI.states)-1]; last != tray.StateIdle {
+		t.Errorf("Expected tray to return to idle, got %v", last)
+	}
+}
+
+func TestHotkeyPipeline_MaxRecordTime(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	clock := &fakeClock{}
+	app.afterFunc = clock.AfterFunc
+	app.config.IdleUnloadMinutes = 0
+	app.config.MaxRecordTime = 30
+
+	// Stopping the recording with the hotkey cancels the limit
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	if len(clock.timers) != 1 || clock.timers[0].duration != 30*time.Second {
+		t.Fatalf("Expected a 30 second limit for the recording, got %d timers", len(clock.timers))
+	}
+	if len(clock.active()) != 0 {
+		t.Error("Expected the limit to be stopped with the recording")
+	}
+
+	// A forgotten toggle session is stopped and transcribed at the limit
+	runEvents(app, hotkey.Pressed)
+	clock.active()[0].fire()
+
+	if app.audioDriver.IsRecording() {
+		t.Error("Expected recording to be stopped at the limit")
+	}
+	if len(recognizer.received) != 2 || len(paster.pasted) != 2 {
+		t.Fatalf("Expected the stopped recording to be transcribed and pasted, got %v", paster.pasted)
+	}
+	if !slices.ContainsFunc(trayUI.notifications, func(n string) bool { return strings.Contains(n, "最大録音時間") }) {
+		t.Errorf("Expected a notification about the limit, got %v", trayUI.notifications)
+	}
+
+	// The stop the user sends afterwards is ignored
+	runEvents(app, hotkey.Released)
+	if len(recognizer.received) != 2 {
+		t.Errorf("Expected no second transcription, got %d", len(recognizer.received))
+	}
+}
+
+func TestHotkeyPipeline_ToggleMaxRecordTime(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	clock := &fakeClock{}
+	app.afterFunc = clock.AfterFunc
+	app.config.IdleUnloadMinutes = 0
+	app.config.RecordingMode = "toggle"
+	app.config.MaxRecordTime = 30
+	app.config.ToggleMaxRecordTime = 120
+
+	runEvents(app, hotkey.Pressed)
+	if len(clock.timers) != 1 || clock.timers[0].duration != 120*time.Second {
+		t.Fatalf("Expected the toggle mode limit of 120 seconds, got %d timers", len(clock.timers))
+	}
+	clock.active()[0].fire()
+
+	if len(paster.pasted) != 1 {
+		t.Fatalf("Expected the recording to be pasted at the limit, got %v", paster.pasted)
+	}
+	if !slices.Contains(trayUI.notifications, notification.RecordingTimeExceededMessage(120)) {
+		t.Errorf("Expected a notification about the limit, got %v", trayUI.notifications)
+	}
+	if last := trayUI.states[len(trayUI.states)-1]; last != tray.StateIdle {
+		t.Errorf("Expected to return to idle, got %v", last)
+	}
+}
+
+func TestHotkeyPipeline_PreventSleep(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{"こんにちは"})
+
+	held := 0
+	app.preventSleep = func(reason string) (func(), error) {
+		held++
+		return func() { held-- }, nil
+	}
+
+	runEvents(app, hotkey.Pressed)
+	if held != 1 {
+		t.Fatalf("Expected sleep to be prevented while recording, got %d assertions", held)
+	}
+	runEvents(app, hotkey.Released)
+	if held != 0 {
+		t.Errorf("Expected sleep to be allowed after recording, got %d assertions", held)
+	}
+
+	// Disabled by prevent_sleep_while_recording
+	app.config.PreventSleepWhileRecording = false
+	runEvents(app, hotkey.Pressed)
+	if held != 0 {
+		t.Errorf("Expected no assertion when disabled, got %d", held)
+	}
+	runEvents(app, hotkey.Released)
+}
+
+func TestHotkeyPipeline_Streaming(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	app.config.Streaming = true
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	// The preview is shown while recording and cleared when it stops
+	if len(trayUI.partials) != 2 || trayUI.partials[0] != "こんにちは" || trayUI.partials[1] != "" {
+		t.Errorf("Expected a partial result followed by clearing it, got %q", trayUI.partials)
+	}
+	if len(paster.pasted) != 1 || paster.pasted[0] != "こんにちは" {
+		t.Errorf("Expected the full transcription to be pasted, got %v", paster.pasted)
+	}
+	if app.streamChunks != nil {
+		t.Error("Expected streaming to be stopped after recording")
+	}
+}
+
+func TestHotkeyPipeline_AutoFallbackLanguages(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+	app.config.AutoFallbackLanguages = []string{"ja", "en"}
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.fallback) != 2 || recognizer.fallback[0] != "ja" || recognizer.fallback[1] != "en" {
+		t.Errorf("Expected the fallback languages to be passed to the recognizer, got %v", recognizer.fallback)
+	}
+}
+
+func TestHotkeyPipeline_AdaptiveDecoding(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	app.config.AdaptiveDecoding = true
+	app.config.AdaptiveDecodingSeconds = 20
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.adaptive) != 2 || recognizer.adaptive[0] != 0 || recognizer.adaptive[1] != 20*time.Second {
+		t.Errorf("Expected no threshold and then 20s, got %v", recognizer.adaptive)
+	}
+}
+
+func TestHotkeyPipeline_RepetitionLoopIsTruncated(t *testing.T) {
+	segments := []string{"会議を始めます。"}
+	for i := 0; i < 10; i++ {
+		segments = append(segments, "ご視聴ありがとうございました。")
+	}
+	app, _, paster, trayUI := newTestApp(t, segments)
+	app.history = history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	app.config.HistoryEnabled = true
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 1 || paster.pasted[0] != "会議を始めます。ご視聴ありがとうございました。" {
+		t.Errorf("Expected truncated text to be pasted, got %v", paster.pasted)
+	}
+
+	// The history keeps the truncated text marked as suspect
+	if entries, _, err := app.history.List(0, 10); err != nil || len(entries) != 1 || !entries[0].Suspect {
+		t.Errorf("Expected a suspect history entry, got %+v (err=%v)", entries, err)
+	}
+
+	found := false
+	for _, n := range trayUI.notifications {
+		if strings.Contains(n, "繰り返し") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected repetition notification, got %v", trayUI.notifications)
+	}
+}
+
+func TestHotkeyPipeline_SilentRecordingNotifies(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, nil)
+	recognizer.silent = true
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted, got %v", paster.pasted)
+	}
+	if !slices.ContainsFunc(trayUI.notifications, func(n string) bool { return strings.Contains(n, "無音") }) {
+		t.Errorf("Expected a notification about the silent recording, got %v", trayUI.notifications)
+	}
+}
+
+func TestHotkeyPipeline_LongOutputIsTruncated(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"あいうえお", "かきくけこ"})
+	app.config.MaxPasteChars = 7
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 1 || paster.pasted[0] != "あいうえおかき" {
+		t.Errorf("Expected first 7 characters to be pasted, got %v", paster.pasted)
+	}
+
+	if paster.clipboard != "あいうえおかきくけこ" {
+		t.Errorf("Expected full text to be kept on the clipboard, got %q", paster.clipboard)
+	}
+
+	if len(trayUI.notifications) != 1 {
+		t.Errorf("Expected a truncation notification, got %v", trayUI.notifications)
+	}
+}
+
+func TestHotkeyPipeline_NoAccessibilitySkipsPaste(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	app.accGranted.Store(false)
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 1 {
+		t.Errorf("Expected transcription to run, got %d", len(recognizer.received))
+	}
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted without accessibility permission, got %v", paster.pasted)
+	}
+
+	if len(trayUI.errors) == 0 {
+		t.Error("Expected an error notification")
+	}
+}
+
+func TestHotkeyPipeline_ClipboardModifiedDuringPaste(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	paster.pasteErr = clipboard.ErrClipboardModifiedExternally
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	// The paste itself succeeded; only the restore was skipped
+	if len(trayUI.errors) != 0 {
+		t.Errorf("Expected no error, got %v", trayUI.errors)
+	}
+	found := false
+	for _, n := range trayUI.notifications {
+		if strings.Contains(n, "復元していません") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a notification about the skipped restore, got %v", trayUI.notifications)
+	}
+}
+
+func TestHotkeyPipeline_History(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{"こんにちは"})
+	app.history = history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	app.config.HistoryEnabled = true
+	app.config.Hotkey.Options = config.HotkeyOptions{Translate: true, Language: "ja"}
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	entries, total, err := app.history.List(0, 10)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 1 || entries[0].Text != "こんにちは" {
+		t.Fatalf("Expected the transcription in the history, got %d entries %+v", total, entries)
+	}
+	if entries[0].Language != "ja" || entries[0].Mode != history.ModeTranslate {
+		t.Errorf("Expected the language and mode of the hotkey, got %q/%q", entries[0].Language, entries[0].Mode)
+	}
+	if entries[0].Suspect {
+		t.Error("Expected an ordinary transcription not to be marked suspect")
+	}
+
+	// Disabling the history stops recording but keeps what was saved
+	app.config.HistoryEnabled = false
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if _, total, _ := app.history.List(0, 10); total != 1 {
+		t.Errorf("Expected no new entry while the history is disabled, got %d", total)
+	}
+}
+
+func TestHotkeyPipeline_SaveRecordings(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{"こんにちは"})
+	app.recordingsDir = t.TempDir()
+
+	// Nothing is saved unless enabled
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if entries, _ := os.ReadDir(app.recordingsDir); len(entries) != 0 {
+		t.Fatalf("Expected no recording while disabled, got %d files", len(entries))
+	}
+
+	app.config.SaveRecordings = true
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	entries, err := os.ReadDir(app.recordingsDir)
+	if err != nil || len(entries) != 1 || filepath.Ext(entries[0].Name()) != ".wav" {
+		t.Errorf("Expected one WAV recording, got %v (err=%v)", entries, err)
+	}
+}
+
+func TestRecordingNote(t *testing.T) {
+	if note := recordingNote(""); note != "" {
+		t.Errorf("Expected no note without a saved recording, got %q", note)
+	}
+	if note := recordingNote("/tmp/a.wav"); note != "\n録音: /tmp/a.wav" {
+		t.Errorf("Unexpected note %q", note)
+	}
+}
+
+func TestHotkeyPipeline_AccessibilityRevokedAtPaste(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	paster.pasteErr = fmt.Errorf("failed to paste chunk 0: %w", clipboard.ErrAccessibilityDenied)
+
+	opened := 0
+	app.openAccessibilitySettings = func() error {
+		opened++
+		return nil
+	}
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if app.accGranted.Load() {
+		t.Error("Expected cached accessibility state to be cleared")
+	}
+
+	if paster.clipboard != "こんにちは" {
+		t.Errorf("Expected transcription to be left on the clipboard, got %q", paster.clipboard)
+	}
+
+	if len(trayUI.errors) != 1 {
+		t.Errorf("Expected an accessibility error notification, got %v", trayUI.errors)
+	}
+
+	if opened != 1 {
+		t.Errorf("Expected accessibility settings to be opened once, got %d", opened)
+	}
+
+	if status := app.status(); status["accessibility"] != false {
+		t.Errorf("Expected status to report accessibility as false, got %v", status["accessibility"])
+	}
+
+	// Later attempts are skipped by the cached state without reopening settings
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if opened != 1 {
+		t.Errorf("Expected accessibility settings not to be reopened, got %d", opened)
+	}
+}
+
+func TestHotkeyPipeline_PasteCancelledOnQuit(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	app.lifecycleCtx, app.cancelLifecycle = context.WithCancel(context.Background())
+	app.cancelLifecycle()
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted after quitting, got %v", paster.pasted)
+	}
+	if len(trayUI.errors) != 0 {
+		t.Errorf("Expected no error to be shown for a cancelled paste, got %v", trayUI.errors)
+	}
+	if last := trayUI.states[len(trayUI.states)-1]; last != tray.StateIdle {
+		t.Errorf("Expected to return to idle, got %v", last)
+	}
+}
+
+func TestHotkeyPipeline_SilentMicSkipsTranscription(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+
+	driver := fakeaudio.New("muted", fakeaudio.Silence(500*time.Millisecond, app.audioConfig.SampleRate), 0, 0)
+	if err := driver.Initialize(app.audioConfig); err != nil {
+		t.Fatalf("Failed to initialize fake audio: %v", err)
+	}
+	app.audioDriver = driver
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 0 {
+		t.Errorf("Expected no transcription for a silent recording, got %d", len(recognizer.received))
+	}
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted, got %v", paster.pasted)
+	}
+
+	if len(trayUI.errors) != 1 || trayUI.errors[0] != silentMicMessage {
+		t.Errorf("Expected muted microphone warning, got %v", trayUI.errors)
+	}
+}
+
+func TestHotkeyPipeline_StartBeep(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+
+	beeps := 0
+	app.playStartBeep = func() error {
+		beeps++
+		return nil
+	}
+
+	// Disabled by default
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	if beeps != 0 {
+		t.Fatalf("Expected no beep when start_beep is off, got %d", beeps)
+	}
+
+	app.config.StartBeep = true
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if beeps != 1 {
+		t.Fatalf("Expected 1 beep, got %d", beeps)
+	}
+
+	if len(recognizer.received) != 2 {
+		t.Fatalf("Expected 2 transcriptions, got %d", len(recognizer.received))
+	}
+
+	// The guard at the start is muted so a captured beep is not transcribed
+	received := recognizer.received[1]
+	guard := int(audio.StartBeepGuard.Seconds()*float64(app.audioConfig.SampleRate)) * 2
+	if len(received) != 16000 {
+		t.Errorf("Expected recording length to be kept, got %d bytes", len(received))
+	}
+	for i := 0; i < guard; i++ {
+		if received[i] != 0 {
+			t.Fatalf("Expected first %d bytes to be muted, byte %d is %d", guard, i, received[i])
+		}
+	}
+	if audio.IsSilent(received[guard:]) {
+		t.Error("Expected audio after the guard to be kept")
+	}
+}
+
+func TestHotkeyPipeline_StartRetriesTransientError(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"こんにちは"})
+	driver := app.audioDriver.(*fakeaudio.Driver)
+	driver.FailStarts(2, &audio.DeviceError{Class: audio.ErrTransient, Err: errors.New("host error")})
+
+	app.config.StartBeep = true
+	beepWhileRecording := false
+	app.playStartBeep = func() error {
+		beepWhileRecording = driver.IsRecording()
+		return nil
+	}
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if driver.Starts() != 3 {
+		t.Errorf("Expected 3 start attempts, got %d", driver.Starts())
+	}
+
+	// The retries are invisible: no error, and the tray stays in recording until release
+	if len(trayUI.errors) != 0 {
+		t.Errorf("Expected no error after a successful retry, got %v", trayUI.errors)
+	}
+	expectedStates := []tray.State{tray.StateRecording, tray.StateProcessing, tray.StateIdle}
+	if !slices.Equal(trayUI.states, expectedStates) {
+		t.Errorf("Expected states %v, got %v", expectedStates, trayUI.states)
+	}
+
+	// Nothing is captured while the stream fails to start, so the start cue
+	// only plays once recording runs and the user speaks after the retry window
+	if !beepWhileRecording {
+		t.Error("Expected the start beep to play after recording started")
+	}
+
+	// The recording starts with the successful attempt and is kept whole
+	if len(recognizer.received) != 1 || len(recognizer.received[0]) != 16000 {
+		t.Fatalf("Expected one 16000 byte recording, got %d transcriptions", len(recognizer.received))
+	}
+	if len(paster.pasted) != 1 || paster.pasted[0] != "こんにちは" {
+		t.Errorf("Expected 'こんにちは' to be pasted, got %v", paster.pasted)
+	}
+}
+
+func TestHotkeyPipeline_StartGivesUpAfterRetries(t *testing.T) {
+	app, recognizer, _, trayUI := newTestApp(t, []string{"こんにちは"})
+	driver := app.audioDriver.(*fakeaudio.Driver)
+	driver.FailStarts(10, &audio.DeviceError{Class: audio.ErrDeviceBusy, Err: errors.New("device unavailable")})
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if driver.Starts() != 3 {
+		t.Errorf("Expected 3 start attempts, got %d", driver.Starts())
+	}
+
+	// Only the final failure is reported
+	if len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "他のアプリで使用中") {
+		t.Errorf("Expected one busy device error, got %v", trayUI.errors)
+	}
+	expectedStates := []tray.State{tray.StateRecording, tray.StateIdle}
+	if !slices.Equal(trayUI.states, expectedStates) {
+		t.Errorf("Expected states %v, got %v", expectedStates, trayUI.states)
+	}
+	if len(recognizer.received) != 0 {
+		t.Errorf("Expected no transcription, got %d", len(recognizer.received))
+	}
+}
+
+func TestTestPaste(t *testing.T) {
+	app, _, paster, _ := newTestApp(t, nil)
+	app.config.MaxPasteChars = 3
+
+	// Uses the same truncation as a hotkey transcription
+	if err := app.testPaste("テスト貼り付け"); err != nil {
+		t.Fatalf("testPaste failed: %v", err)
+	}
+	if len(paster.pasted) != 1 || paster.pasted[0] != "テスト" {
+		t.Errorf("Expected truncated text to be pasted, got %v", paster.pasted)
+	}
+
+	// Reports the failed stage from the clipboard manager unchanged
+	paster.pasteErr = &clipboard.PasteError{Stage: clipboard.StageSecureInput, Err: clipboard.ErrSecureInput}
+	if err := app.testPaste("テスト"); clipboard.FailedStage(err) != clipboard.StageSecureInput {
+		t.Errorf("Expected secure input stage, got %v", err)
+	}
+}
+
+func TestHotkeyPipeline_SecureInputKeepsText(t *testing.T) {
+	app, _, paster, trayUI := newTestApp(t, []string{"こんにちは、", "世界。"})
+	paster.pasteErr = &clipboard.PasteError{Stage: clipboard.StageSecureInput, Err: clipboard.ErrSecureInput}
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	// The whole text stays on the clipboard, not just a chunk of a split paste
+	if paster.clipboard != "こんにちは、世界。" {
+		t.Errorf("Expected the full text on the clipboard, got %q", paster.clipboard)
+	}
+	if len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "セキュアキーボード入力") {
+		t.Errorf("Expected a secure input notification, got %v", trayUI.errors)
+	}
+	if !app.accGranted.Load() {
+		t.Error("Expected accessibility to stay granted")
+	}
+}
+
+func TestTestPaste_NoAccessibility(t *testing.T) {
+	app, _, paster, _ := newTestApp(t, nil)
+	app.accGranted.Store(false)
+
+	err := app.testPaste("テスト")
+	if !errors.Is(err, clipboard.ErrAccessibilityDenied) || clipboard.FailedStage(err) != clipboard.StageKeystroke {
+		t.Errorf("Expected accessibility error at the keystroke stage, got %v", err)
+	}
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted, got %v", paster.pasted)
+	}
+}
+
+// writeTestWAV writes one second of a tone at 8kHz and returns its path
+func writeTestWAV(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "memo.wav")
+	samples := fakeaudio.Sine(440, time.Second, 8000, 0.3)
+	if err := os.WriteFile(path, audio.EncodeWAV(samples, 8000), 0644); err != nil {
+		t.Fatalf("Failed to write WAV: %v", err)
+	}
+	return path
+}
+
+func TestTranscribeClipboardAudio(t *testing.T) {
+	app, recognizer, paster, _ := newTestApp(t, []string{"音声メモ"})
+	path := writeTestWAV(t)
+	app.clipboardFiles = func() []string { return []string{"/tmp/notes.txt", path} }
+
+	app.transcribeClipboardAudio()
+
+	if len(paster.pasted) != 1 || paster.pasted[0] != "音声メモ" {
+		t.Errorf("Expected the transcription to be pasted, got %v", paster.pasted)
+	}
+
+	// The file is resampled to the recording format before transcription
+	if len(recognizer.received) != 1 {
+		t.Fatalf("Expected one transcription, got %d", len(recognizer.received))
+	}
+	if frames := len(recognizer.received[0]) / 2; frames < 15900 || frames > 16100 {
+		t.Errorf("Expected about one second of 16kHz audio, got %d frames", frames)
+	}
+}
+
+func TestTranscribeClipboardAudio_FileURLText(t *testing.T) {
+	app, _, paster, _ := newTestApp(t, []string{"音声メモ"})
+	path := writeTestWAV(t)
+	app.clipboardText = func() (string, error) { return "file://" + path, nil }
+
+	// Without accessibility the result is copied instead of pasted
+	app.accGranted.Store(false)
+	app.transcribeClipboardAudio()
+
+	if len(paster.pasted) != 0 || paster.clipboard != "音声メモ" {
+		t.Errorf("Expected the transcription on the clipboard, got pasted=%v clipboard=%q", paster.pasted, paster.clipboard)
+	}
+}
+
+func TestTranscribeClipboardAudio_ScreenLocked(t *testing.T) {
+	app, recognizer, paster, trayUI := newTestApp(t, []string{"聞かれたくない会話"})
+	path := writeTestWAV(t)
+	app.clipboardFiles = func() []string { return []string{path} }
+	app.screenLocked = func() bool { return true }
+
+	// The default policy ignores the hotkey
+	app.transcribeClipboardAudio()
+
+	if len(recognizer.received) != 0 {
+		t.Fatalf("Expected no transcription while locked, got %d", len(recognizer.received))
+	}
+
+	// clipboard-only transcribes but copies instead of pasting
+	app.config.ScreenLockedPolicy = config.ScreenLockedClipboardOnly
+	app.transcribeClipboardAudio()
+
+	if len(paster.pasted) != 0 {
+		t.Errorf("Expected nothing to be pasted, got %v", paster.pasted)
+	}
+	if paster.clipboard != "聞かれたくない会話" {
+		t.Errorf("Expected the result on the clipboard, got %q", paster.clipboard)
+	}
+	for _, n := range trayUI.notifications {
+		if strings.Contains(n, "聞かれたくない") {
+			t.Errorf("Expected the notification to leave out the text, got %q", n)
+		}
+	}
+}
+
+func TestTranscribeClipboardAudio_NoAudioFile(t *testing.T) {
+	app, recognizer, _, trayUI := newTestApp(t, []string{"音声メモ"})
+	app.clipboardText = func() (string, error) { return "こんにちは", nil }
+
+	app.transcribeClipboardAudio()
+
+	if len(trayUI.errors) != 1 || len(recognizer.received) != 0 {
+		t.Errorf("Expected an error without transcription, got %v", trayUI.errors)
+	}
+}
+
+func TestStartupDeviceID(t *testing.T) {
+	tests := []struct {
+		name     string
+		saved    int
+		expected int
+		notified bool
+	}{
+		{"system default", -1, -1, false},
+		{"device present", 0, 0, false},
+		{"device missing", 5, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, _, trayUI := newTestApp(t, nil)
+			app.config.AudioDeviceID = tt.saved
+
+			if got := app.startupDeviceID(app.audioDriver); got != tt.expected {
+				t.Errorf("Expected device ID %d, got %d", tt.expected, got)
+			}
+			if notified := len(trayUI.notifications) > 0; notified != tt.notified {
+				t.Errorf("Expected notified=%v, got %v", tt.notified, trayUI.notifications)
+			}
+
+			// The preferred device is kept so it is used again once reconnected
+			if app.config.AudioDeviceID != tt.saved {
+				t.Errorf("Expected saved device ID %d to be kept, got %d", tt.saved, app.config.AudioDeviceID)
+			}
+		})
+	}
+}
+
+func TestTrayDevices(t *testing.T) {
+	audioDevices := []audio.Device{
+		{ID: 0, Name: "Built-in Microphone", IsDefault: true},
+		{ID: 2, Name: "USB Microphone"},
+	}
+
+	// The system default device is checked while no device is configured
+	devices := trayDevices(audioDevices, -1)
+	if len(devices) != 2 || !devices[0].IsCurrent || devices[1].IsCurrent {
+		t.Errorf("Expected the default device to be checked, got %+v", devices)
+	}
+
+	devices = trayDevices(audioDevices, 2)
+	if devices[0].IsCurrent || !devices[1].IsCurrent || !devices[0].IsDefault {
+		t.Errorf("Expected the configured device to be checked, got %+v", devices)
+	}
+}
+
+func TestHandleDeviceChange_WhileRecording(t *testing.T) {
+	app, _, _, trayUI := newTestApp(t, nil)
+	app.config.AudioDeviceID = -1
+	driver := app.audioDriver
+
+	runEvents(app, hotkey.Pressed)
+	app.handleDeviceChange(2)
+
+	if app.audioDriver != driver || !driver.IsRecording() {
+		t.Error("Expected the recording driver to be kept")
+	}
+	if app.config.AudioDeviceID != -1 {
+		t.Errorf("Expected audio_device_id to stay -1, got %d", app.config.AudioDeviceID)
+	}
+	if len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "録音中") {
+		t.Errorf("Expected an error about the recording, got %v", trayUI.errors)
+	}
+}
+
+func TestClipboardConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.PasteSplitSize = 200
+	cfg.PasteSplitIntervalMs = 80
+	cfg.PasteAppIntervalsMs = config.AppIntervals{"com.tinyspeck.slackmacgap": 300}
+	cfg.PasteWaitModifiers = false
+
+	got := clipboardConfig(cfg)
+
+	if got.SplitSize != 200 || got.SplitInterval != 80*time.Millisecond {
+		t.Errorf("Expected split settings from config, got size=%d interval=%v", got.SplitSize, got.SplitInterval)
+	}
+	if got.AppSplitIntervals["com.tinyspeck.slackmacgap"] != 300*time.Millisecond {
+		t.Errorf("Expected Slack override of 300ms, got %v", got.AppSplitIntervals)
+	}
+	if got.ModifierReleaseTimeout != 0 {
+		t.Errorf("Expected no modifier wait, got %v", got.ModifierReleaseTimeout)
+	}
+}
+
+func TestHealthProblems(t *testing.T) {
+	allGranted := map[string]bool{"microphone": true, "accessibility": true}
+
+	tests := []struct {
+		name        string
+		modelPath   string
+		modelLoaded bool
+		perms       map[string]bool
+		accGranted  bool
+		expected    []string
+	}{
+		{"all ready", "/models/ggml-base.bin", true, allGranted, true, nil},
+		{"no model configured", "", false, allGranted, true, []string{"モデル未設定"}},
+		{"model not loaded", "/models/ggml-base.bin", false, allGranted, true, []string{"モデル未読み込み"}},
+		{"permissions denied", "/models/ggml-base.bin", true, map[string]bool{}, false, []string{"マイク権限なし", "アクセシビリティ権限なし"}},
+		{"revoked at paste", "/models/ggml-base.bin", true, allGranted, false, []string{"アクセシビリティ権限なし"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, _, _ := newTestApp(t, nil)
+			app.config.ModelPath = tt.modelPath
+			app.modelLoaded.Store(tt.modelLoaded)
+			app.accGranted.Store(tt.accGranted)
+
+			if got := app.healthProblems(tt.perms); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHealthProblems_FakeAudioIgnoresMicrophone(t *testing.T) {
+	app, _, _, _ := newTestApp(t, nil)
+	app.fakeAudioSource = "sine"
+
+	if got := app.healthProblems(map[string]bool{"accessibility": true}); len(got) != 0 {
+		t.Errorf("Expected no problems with fake audio, got %v", got)
+	}
+}
+
+func TestEnableMicrophone(t *testing.T) {
+	app, _, _, _ := newTestApp(t, nil)
+	app.audioDriver = nil
+	app.micGranted.Store(false)
+	app.fakeAudioSource = "sine"
+
+	app.enableMicrophone()
+
+	// Recording is enabled together with an initialized driver
+	driver, audioConfig := app.audioState()
+	if !app.micGranted.Load() || driver == nil {
+		t.Fatalf("Expected the microphone to be enabled with a driver, got granted=%v driver=%v", app.micGranted.Load(), driver)
+	}
+	if audioConfig.SampleRate != audio.DefaultConfig().SampleRate {
+		t.Errorf("Expected the default audio config, got %+v", audioConfig)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	if err := driver.StartRecording(); err != nil {
+		t.Errorf("Expected the driver to be initialized, got %v", err)
+	}
+}
+
+func TestRefreshHealth(t *testing.T) {
+	app, _, _, trayUI := newTestApp(t, nil)
+	perms := map[string]bool{"microphone": true, "accessibility": false}
+	app.checkPermissions = func() map[string]bool { return perms }
+
+	app.refreshHealth()
+
+	if len(trayUI.problems) != 1 || trayUI.problems[0] != "アクセシビリティ権限なし" {
+		t.Errorf("Expected the tray to show the missing permission, got %v", trayUI.problems)
+	}
+
+	// Granting the permission clears the warning
+	perms["accessibility"] = true
+	app.refreshHealth()
+
+	if len(trayUI.problems) != 0 {
+		t.Errorf("Expected no problems after the permission was granted, got %v", trayUI.problems)
+	}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "99.0.0", "url": "https://example.com/releases/99.0.0"}`))
+	}))
+	defer server.Close()
+
+	app, _, _, trayUI := newTestApp(t, nil)
+	app.config.UpdateManifestURL = server.URL
+
+	// Disabled by default
+	app.checkForUpdate()
+	if len(trayUI.notifications) != 0 {
+		t.Fatalf("Expected no notification while update checks are disabled, got %v", trayUI.notifications)
+	}
+
+	app.config.CheckUpdates = true
+	app.checkForUpdate()
+	if len(trayUI.notifications) != 1 || !strings.Contains(trayUI.notifications[0], "https://example.com/releases/99.0.0") {
+		t.Fatalf("Expected an update notification with the release link, got %v", trayUI.notifications)
+	}
+
+	// The same version is not announced again on the next daily check
+	app.checkForUpdate()
+	if len(trayUI.notifications) != 1 {
+		t.Errorf("Expected a single notification per version, got %v", trayUI.notifications)
+	}
+}
+
+func TestCheckForUpdate_FailsSilently(t *testing.T) {
+	app, _, _, trayUI := newTestApp(t, nil)
+	app.config.CheckUpdates = true
+	app.config.UpdateManifestURL = "http://127.0.0.1:1/version.json"
+
+	app.checkForUpdate()
+
+	if len(trayUI.notifications) != 0 || len(trayUI.errors) != 0 {
+		t.Errorf("Expected no notifications on network error, got %v / %v", trayUI.notifications, trayUI.errors)
+	}
+}
+
+func TestPrependTimestamp(t *testing.T) {
+	now := time.Date(2025, 4, 1, 9, 5, 0, 0, time.Local)
+
+	if text := prependTimestamp("こんにちは", "", now); text != "こんにちは" {
+		t.Errorf("Expected no timestamp when disabled, got %q", text)
+	}
+
+	if text := prependTimestamp("こんにちは", "[15:04]", now); text != "[09:05] こんにちは" {
+		t.Errorf("Expected the time before the text, got %q", text)
+	}
+}
+
+func TestHotkeyPipeline_PasteTimestamp(t *testing.T) {
+	app, _, paster, _ := newTestApp(t, []string{"こんにちは"})
+	app.config.PasteTimestamp = "2006-01-02 -"
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	expected := time.Now().Format("2006-01-02 -") + " こんにちは"
+	if len(paster.pasted) != 1 || paster.pasted[0] != expected {
+		t.Errorf("Expected %q to be pasted, got %q", expected, paster.pasted)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if text, truncated := truncateRunes("こんにちは", 0); text != "こんにちは" || truncated {
+		t.Errorf("Expected no truncation when unlimited, got %q/%v", text, truncated)
+	}
+
+	if text, truncated := truncateRunes("こんにちは", 5); text != "こんにちは" || truncated {
+		t.Errorf("Expected no truncation at the limit, got %q/%v", text, truncated)
+	}
+
+	if text, truncated := truncateRunes("こんにちは", 3); text != "こんに" || !truncated {
+		t.Errorf("Expected truncation to 3 characters, got %q/%v", text, truncated)
+	}
+}
+
+func TestTranscribe_AppliesAudioPipeline(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+	app.config.AudioNormalize = true
+
+	source := fakeaudio.Sine(440, 500*time.Millisecond, app.audioConfig.SampleRate, 0.3)
+	pcm := make([]byte, len(source)*2)
+	for i, s := range source {
+		pcm[i*2] = byte(s)
+		pcm[i*2+1] = byte(uint16(s) >> 8)
+	}
+
+	if _, err := app.transcribe(pcm, app.audioConfig, ""); err != nil {
+		t.Fatalf("transcribe failed: %v", err)
+	}
+
+	if peak := audio.ComputeEnvelope(recognizer.received[0], audio.DefaultEnvelopeBuckets).Peak; peak < 0.85 || peak > 0.95 {
+		t.Errorf("Expected normalized peak ~%.1f, got %f", audio.NormalizeTargetPeak, peak)
+	}
+}
+
+func TestTranscribe_ResamplesDeviceRate(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+
+	// A device that opened at 48kHz returns its recordings at that rate
+	format := app.audioConfig
+	format.SampleRate = 48000
+	result, err := app.transcribe(make([]byte, 48000*2), format, "")
+	if err != nil {
+		t.Fatalf("transcribe failed: %v", err)
+	}
+
+	if len(recognizer.received[0]) != app.audioConfig.SampleRate*2 {
+		t.Errorf("Expected one second at %d Hz, got %d bytes", app.audioConfig.SampleRate, len(recognizer.received[0]))
+	}
+	if result.DurationMS != 1000 {
+		t.Errorf("Expected 1000ms, got %d", result.DurationMS)
+	}
+}
+
+func TestTranscribe_ReturnsFullResult(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{"こんにちは、", "世界。"})
+
+	pcm := make([]byte, app.audioConfig.SampleRate*2) // 1 second of silence
+	result, err := app.transcribe(pcm, app.audioConfig, "")
+	if err != nil {
+		t.Fatalf("transcribe failed: %v", err)
+	}
+
+	if result.Text != "こんにちは、世界。" || len(result.Segments) != 2 {
+		t.Errorf("Expected joined text and both segments, got %+v", result)
+	}
+	if result.Language != "auto" || result.DurationMS != 1000 {
+		t.Errorf("Expected language and duration to be carried through, got %+v", result)
+	}
+	if result.AvgConfidence != 0.9 {
+		t.Errorf("Expected confidence 0.9, got %f", result.AvgConfidence)
+	}
+}
+
+func TestTranscribe_StripsLeadingSpace(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{" Hello", " world."})
+	pcm := make([]byte, 3200)
+
+	result, err := app.transcribe(pcm, app.audioConfig, "")
+	if err != nil {
+		t.Fatalf("transcribe failed: %v", err)
+	}
+	if result.Text != "Hello world." {
+		t.Errorf("Expected leading space to be stripped, got %q", result.Text)
+	}
+
+	app.config.StripLeadingSpace = false
+	if result, _ := app.transcribe(pcm, app.audioConfig, ""); result.Text != " Hello world." {
+		t.Errorf("Expected text unchanged when disabled, got %q", result.Text)
+	}
+}
+
+func TestTranscribe_ExpandsInitialPrompt(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"func main"})
+	lookups := 0
+	app.frontmostApp = func() string {
+		lookups++
+		return "Code"
+	}
+	pcm := make([]byte, 3200)
+
+	app.config.InitialPrompt = "{app} でのプログラミング"
+	if _, err := app.transcribe(pcm, app.audioConfig, ""); err != nil {
+		t.Fatalf("transcribe failed: %v", err)
+	}
+
+	// The frontmost app is looked up only when the template needs it
+	app.config.InitialPrompt = "Go, goroutine"
+	if _, err := app.transcribe(pcm, app.audioConfig, ""); err != nil {
+		t.Fatalf("transcribe failed: %v", err)
+	}
+
+	expected := []string{"Code でのプログラミング", "Go, goroutine"}
+	if len(recognizer.prompts) != 2 || recognizer.prompts[0] != expected[0] || recognizer.prompts[1] != expected[1] {
+		t.Errorf("Expected prompts %q, got %q", expected, recognizer.prompts)
+	}
+	if lookups != 1 {
+		t.Errorf("Expected 1 frontmost app lookup, got %d", lookups)
+	}
+}
+
+func TestHotkeyConflictMessage(t *testing.T) {
+	cfg := hotkey.Config{Modifiers: []hk.Modifier{hk.ModCmd}, Key: hk.KeySpace}
+
+	message := hotkeyConflictMessage(&hotkey.ConflictError{Conflicts: []string{"Spotlight"}}, cfg)
+	if !strings.Contains(message, "Spotlight") || !strings.Contains(message, "⌃⌥Space") {
+		t.Errorf("Expected conflict name and a suggestion, got %q", message)
+	}
+
+	// Refused by the OS without a known conflict name
+	message = hotkeyConflictMessage(&hotkey.ConflictError{Err: errors.New("already registered")}, cfg)
+	if !strings.Contains(message, "⌘Space") || !strings.Contains(message, "設定画面") {
+		t.Errorf("Expected the hotkey and suggestions, got %q", message)
+	}
+}
+
+func TestRecordingStartErrorMessage(t *testing.T) {
+	busy := fmt.Errorf("failed to start stream: %w", &audio.DeviceError{Class: audio.ErrDeviceBusy, Err: errors.New("device unavailable")})
+	if msg := recordingStartErrorMessage(busy); !strings.Contains(msg, "他のアプリで使用中") {
+		t.Errorf("Expected busy device message, got %q", msg)
+	}
+
+	transient := fmt.Errorf("failed to start stream: %w", &audio.DeviceError{Class: audio.ErrTransient, Err: errors.New("host error")})
+	if msg := recordingStartErrorMessage(transient); !strings.Contains(msg, "少し待って") {
+		t.Errorf("Expected transient error message, got %q", msg)
+	}
+
+	other := errors.New("boom")
+	if msg := recordingStartErrorMessage(other); !strings.Contains(msg, "boom") {
+		t.Errorf("Expected raw error for unclassified errors, got %q", msg)
+	}
+}
+
+func TestResetHotkey_KeepsOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	app, _, _, _ := newTestApp(t, nil)
+	options := config.HotkeyOptions{Translate: true, Language: "ja"}
+	app.config.Hotkey = config.HotkeyConfig{Cmd: true, Key: "R", Options: options}
+
+	app.resetHotkey()
+
+	expected := config.DefaultHotkey()
+	expected.Options = options
+	if app.config.Hotkey != expected {
+		t.Errorf("Expected the default keys with the previous options, got %+v", app.config.Hotkey)
+	}
+
+	saved, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("Failed to load saved config: %v", err)
+	}
+	if saved.Hotkey != expected {
+		t.Errorf("Expected the options to be saved, got %+v", saved.Hotkey)
+	}
+}
+
+func TestReloadModel(t *testing.T) {
+	app, recognizer, _, trayUI := newTestApp(t, nil)
+
+	modelPath := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(modelPath, []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write model file: %v", err)
+	}
+	app.config.ModelPath = modelPath
+
+	app.reloadModel()
+
+	if len(recognizer.loaded) != 1 || recognizer.loaded[0] != modelPath {
+		t.Errorf("Expected model to be reloaded from %s, got %v", modelPath, recognizer.loaded)
+	}
+
+	if len(trayUI.errors) != 0 {
+		t.Errorf("Expected no errors, got %v", trayUI.errors)
+	}
+
+	if last := trayUI.states[len(trayUI.states)-1]; last != tray.StateIdle {
+		t.Errorf("Expected tray to return to idle, got %v", last)
+	}
+
+	// A failed reload is reported and keeps the previous model
+	recognizer.loadErr = errors.New("corrupt model")
+	app.reloadModel()
+
+	if len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "corrupt model") {
+		t.Errorf("Expected reload failure to be reported, got %v", trayUI.errors)
+	}
+
+	if !app.modelLoaded.Load() {
+		t.Error("Expected previously loaded model to remain in use")
+	}
+}
+
+func TestReloadModel_SelfTestFailure(t *testing.T) {
+	app, recognizer, _, trayUI := newTestApp(t, nil)
+
+	modelPath := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(modelPath, []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write model file: %v", err)
+	}
+	app.config.ModelPath = modelPath
+
+	// The model loads but inference fails, e.g. a ggml version mismatch
+	recognizer.testErr = errors.New("whisper_full returned code -6")
+	app.reloadModel()
+
+	if app.modelLoaded.Load() {
+		t.Error("Expected a model that fails the self-test to be reported as not loaded")
+	}
+	if len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "ggml-base.bin") || !strings.Contains(trayUI.errors[0], "code -6") {
+		t.Errorf("Expected the self-test failure to be reported, got %v", trayUI.errors)
+	}
+	if len(trayUI.notifications) != 0 {
+		t.Errorf("Expected no success notification, got %v", trayUI.notifications)
+	}
+
+	// A working model clears the failure
+	recognizer.testErr = nil
+	app.reloadModel()
+
+	if !app.modelLoaded.Load() {
+		t.Error("Expected the model to be loaded after a passing self-test")
+	}
+}
+
+func TestReloadModel_WaitsForTranscription(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+	recognizer.block = make(chan struct{})
+
+	modelPath := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(modelPath, []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write model file: %v", err)
+	}
+	app.config.ModelPath = modelPath
+
+	transcribed := make(chan error, 1)
+	go func() {
+		_, err := app.transcribe(make([]byte, 3200), app.audioConfig, "")
+		transcribed <- err
+	}()
+	// Wait until the transcription holds transcribeMutex
+	for app.transcribeMutex.TryLock() {
+		app.transcribeMutex.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	reloaded := make(chan struct{})
+	go func() {
+		app.reloadModel()
+		close(reloaded)
+	}()
+
+	select {
+	case <-reloaded:
+		t.Fatal("Expected the reload to wait for the running transcription")
+	case <-time.After(50 * time.Millisecond):
+	}
+	recognizer.mu.Lock()
+	loaded := len(recognizer.loaded)
+	recognizer.mu.Unlock()
+	if loaded != 0 {
+		t.Errorf("Expected no model load during the transcription, got %d", loaded)
+	}
+
+	close(recognizer.block)
+	if err := <-transcribed; err != nil {
+		t.Errorf("Failed to transcribe: %v", err)
+	}
+	<-reloaded
+	if len(recognizer.loaded) != 1 || recognizer.loaded[0] != modelPath {
+		t.Errorf("Expected the model to be reloaded after the transcription, got %v", recognizer.loaded)
+	}
+}
+
+// newIdleTestApp returns a test app whose model is freed after 10 idle minutes on a fake clock
+func newIdleTestApp(t *testing.T, segments []string) (*App, *fakeRecognizer, *fakePaster, *fakeTray, *fakeClock) {
+	t.Helper()
+
+	app, recognizer, paster, trayUI := newTestApp(t, segments)
+	clock := &fakeClock{}
+	app.afterFunc = clock.AfterFunc
+	app.config.IdleUnloadMinutes = 10
+	app.modelReady("/models/ggml-base.bin")
+
+	return app, recognizer, paster, trayUI, clock
+}
+
+func TestIdleUnload(t *testing.T) {
+	app, recognizer, paster, trayUI, clock := newIdleTestApp(t, []string{"こんにちは"})
+
+	if active := clock.active(); len(active) != 1 || active[0].duration != 10*time.Minute {
+		t.Fatalf("Expected one 10 minute timer after loading, got %d", len(active))
+	}
+
+	// Each transcription restarts the timer
+	first := clock.active()[0]
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	if !first.stopped || len(clock.active()) != 1 {
+		t.Fatalf("Expected the timer to be restarted after transcribing, got %d active", len(clock.active()))
+	}
+
+	// A timer that was restarted does nothing if it had already expired
+	first.f()
+	if recognizer.unloads != 0 || app.isModelIdle() {
+		t.Error("Expected a stale timer to keep the model loaded")
+	}
+
+	clock.active()[0].fire()
+	if recognizer.unloads != 1 || !app.isModelIdle() {
+		t.Fatalf("Expected the model to be freed after the idle time (unloads=%d)", recognizer.unloads)
+	}
+	if len(clock.active()) != 0 {
+		t.Error("Expected no timer while the model is freed")
+	}
+
+	// The next hotkey press loads the model again before transcribing
+	trayUI.states = nil
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if app.isModelIdle() || len(recognizer.loaded) != 1 || recognizer.loaded[0] != "/models/ggml-base.bin" {
+		t.Errorf("Expected the model to be loaded again, got %v", recognizer.loaded)
+	}
+	if len(paster.pasted) != 2 {
+		t.Errorf("Expected both recordings to be pasted, got %v", paster.pasted)
+	}
+	if len(trayUI.states) < 2 || trayUI.states[1] != tray.StateProcessing {
+		t.Errorf("Expected the processing icon while the model loads, got %v", trayUI.states)
+	}
+	if len(clock.active()) != 1 {
+		t.Error("Expected the timer to start again after transcribing")
+	}
+}
+
+func TestIdleUnload_DisabledByDefault(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{"こんにちは"})
+	clock := &fakeClock{}
+	app.afterFunc = clock.AfterFunc
+	app.modelReady("/models/ggml-base.bin")
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(clock.timers) != 0 {
+		t.Errorf("Expected no timer with idle_unload_minutes 0, got %d", len(clock.timers))
+	}
+}
+
+func TestIdleUnload_ReloadFailureKeepsRecording(t *testing.T) {
+	app, recognizer, paster, trayUI, clock := newIdleTestApp(t, []string{"こんにちは"})
+	clock.active()[0].fire()
+
+	recognizer.loadErr = errors.New("model file not found")
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "model file not found") {
+		t.Errorf("Expected the reload failure to be reported, got %v", trayUI.errors)
+	}
+	if len(recognizer.received) != 0 || len(paster.pasted) != 0 {
+		t.Error("Expected nothing to be transcribed without a model")
+	}
+	if len(app.heldAudio) != 16000 || !app.isModelIdle() {
+		t.Fatalf("Expected the recording to be kept, got %d bytes (idle=%v)", len(app.heldAudio), app.isModelIdle())
+	}
+
+	// Once the model loads, the kept recording is transcribed with the new one
+	recognizer.loadErr = nil
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+
+	if len(recognizer.received) != 1 || len(recognizer.received[0]) != 32000 {
+		t.Errorf("Expected both recordings in one transcription, got %d", len(recognizer.received))
+	}
+	if len(paster.pasted) != 1 || app.heldAudio != nil {
+		t.Errorf("Expected the result to be pasted and nothing kept, got %v", paster.pasted)
+	}
+}
+
+func TestIsHeadlessSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		manager  string
+		err      error
+		expected bool
+	}{
+		{"GUI session", nil, "Aqua", nil, false},
+		{"SSH connection", map[string]string{"SSH_CONNECTION": "10.0.0.2 52100 10.0.0.1 22"}, "Aqua", nil, true},
+		{"SSH terminal", map[string]string{"SSH_TTY": "/dev/ttys003"}, "Aqua", nil, true},
+		{"background session", nil, "Background", nil, true},
+		{"unknown session type", nil, "", errors.New("launchctl not found"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			managerName := func() (string, error) { return tt.manager, tt.err }
+
+			if got := isHeadlessSession(getenv, managerName); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHotkeyPipeline_PromptsForUndeterminedMicrophone(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  permissions.PermissionStatus
+		granted bool
+	}{
+		{"granted", permissions.PermissionAuthorized, true},
+		{"denied", permissions.PermissionDenied, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, recognizer, _, trayUI := newTestApp(t, []string{"テスト"})
+			app.micGranted.Store(false)
+			app.microphoneStatus = func() permissions.PermissionStatus { return permissions.PermissionNotDetermined }
+
+			requested := make(chan struct{}, 1)
+			app.requestMicrophone = func() permissions.PermissionStatus {
+				requested <- struct{}{}
+				return tt.answer
+			}
+
+			runEvents(app, hotkey.Pressed, hotkey.Released)
+
+			select {
+			case <-requested:
+			case <-time.After(time.Second):
+				t.Fatal("Expected the hotkey press to show the permission dialog")
+			}
+
+			// The press that showed the dialog does not record
+			if len(recognizer.received) != 0 {
+				t.Errorf("Expected nothing transcribed, got %d", len(recognizer.received))
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for app.micPrompting.Load() && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			if app.micGranted.Load() != tt.granted {
+				t.Errorf("Expected micGranted %v, got %v", tt.granted, app.micGranted.Load())
+			}
+			if !tt.granted && (len(trayUI.errors) != 1 || !strings.Contains(trayUI.errors[0], "システム設定")) {
+				t.Errorf("Expected the denial to point to System Settings, got %v", trayUI.errors)
+			}
+		})
+	}
+}
+
+func TestHotkeyPipeline_DeniedMicrophoneDoesNotPrompt(t *testing.T) {
+	app, _, _, _ := newTestApp(t, nil)
+	app.micGranted.Store(false)
+	app.microphoneStatus = func() permissions.PermissionStatus { return permissions.PermissionDenied }
+	app.requestMicrophone = func() permissions.PermissionStatus {
+		t.Error("Expected no permission dialog once the permission was denied")
+		return permissions.PermissionDenied
+	}
+
+	runEvents(app, hotkey.Pressed, hotkey.Released)
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestRunSelfCheck(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"テスト"})
+	app.fakeAudioSource = "silence"
+	app.checkPermissions = func() map[string]bool {
+		return map[string]bool{"microphone": false, "accessibility": true}
+	}
+
+	modelPath := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(modelPath, []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+	app.config.ModelPath = modelPath
+
+	var out strings.Builder
+	if code := app.runSelfCheck(&out); code != 0 {
+		t.Errorf("Expected every check to pass, got exit code %d:\n%s", code, out.String())
+	}
+	for _, line := range []string{"[OK]   マイク権限", "[OK]   入力デバイス: Fake Audio (silence)", "[OK]   モデル: ggml-base.bin", "[OK]   文字起こし: \"テスト\""} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected %q in the report:\n%s", line, out.String())
+		}
+	}
+	if len(recognizer.loaded) != 1 || len(recognizer.received) != 1 {
+		t.Errorf("Expected the model to be loaded and used once, got %d loads and %d transcriptions", len(recognizer.loaded), len(recognizer.received))
+	}
+
+	// A model that fails to load also fails the transcription check
+	app.whisperInfo = recognition.BuildInfo{Version: "1.7.5", ExpectedVersion: "v1.7.6", Mismatch: true}
+	recognizer.loadErr = errors.New("invalid model file")
+	out.Reset()
+	if code := app.runSelfCheck(&out); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	for _, line := range []string{"[FAIL] whisper.cpp", "[FAIL] モデル", "[FAIL] 文字起こし"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected %q in the report:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestHandleSettingsSaved_AppliesThreads(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, nil)
+	app.autoThreads.Store(6)
+
+	app.config.Threads = 4
+	app.handleSettingsSaved()
+
+	// 0 goes back to the thread count recommended for the loaded model
+	app.config.Threads = 0
+	app.handleSettingsSaved()
+
+	if len(recognizer.threads) != 2 || recognizer.threads[0] != 4 || recognizer.threads[1] != 6 {
+		t.Errorf("Expected 4 and then the recommended 6 threads, got %v", recognizer.threads)
+	}
+}
+
+func TestHandleSettingsSaved_AppliesPasteSettings(t *testing.T) {
+	app, _, paster, _ := newTestApp(t, nil)
+
+	app.config.PasteSplitSize = 200
+	app.config.PasteAppIntervalsMs = config.AppIntervals{"com.tinyspeck.slackmacgap": 300}
+	app.config.PasteWaitModifiers = false
+	app.handleSettingsSaved()
+
+	if len(paster.configs) != 1 {
+		t.Fatalf("Expected the paste settings to be applied once, got %d", len(paster.configs))
+	}
+	applied := paster.configs[0]
+	if applied.SplitSize != 200 || applied.AppSplitIntervals["com.tinyspeck.slackmacgap"] != 300*time.Millisecond || applied.ModifierReleaseTimeout != 0 {
+		t.Errorf("Expected the saved paste settings, got %+v", applied)
+	}
+}
+
+func TestStartAPIRecording_RefusedDuringHotkeySession(t *testing.T) {
+	app, _, _, _ := newTestApp(t, []string{"こんにちは"})
+
+	runEvents(app, hotkey.Pressed)
+	if err := app.StartAPIRecording(""); err == nil {
+		t.Error("Expected the API recording to be refused while the hotkey is recording")
+	}
+	if app.isAPIRecording() {
+		t.Error("Expected no API session")
+	}
+
+	runEvents(app, hotkey.Released)
+	if err := app.StartAPIRecording(""); err != nil {
+		t.Fatalf("Expected the API recording to start after the hotkey session, got %v", err)
+	}
+	if _, err := app.StopAPIRecording(); err != nil {
+		t.Errorf("Failed to stop the API recording: %v", err)
+	}
+}
+
+func TestStopAPIRecording_DoesNotBlockHotkeys(t *testing.T) {
+	app, recognizer, _, _ := newTestApp(t, []string{"こんにちは"})
+	recognizer.block = make(chan struct{})
+
+	if err := app.StartAPIRecording(""); err != nil {
+		t.Fatalf("Failed to start the API recording: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := app.StopAPIRecording()
+		done <- err
+	}()
+
+	// The hotkey path checks isAPIRecording, which must not wait for the transcription
+	deadline := time.Now().Add(time.Second)
+	for {
+		checked := make(chan bool, 1)
+		go func() { checked <- app.isAPIRecording() }()
+		select {
+		case recording := <-checked:
+			if !recording {
+				close(recognizer.block)
+				if err := <-done; err != nil {
+					t.Errorf("Failed to stop the API recording: %v", err)
+				}
+				return
+			}
+		case <-time.After(time.Second):
+			close(recognizer.block)
+			t.Fatal("isAPIRecording blocked while the API recording was transcribed")
+		}
+		if time.Now().After(deadline) {
+			close(recognizer.block)
+			t.Fatal("The API session did not end")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -7,6 +7,7 @@ require (
 	github.com/go-vgo/robotgo v0.110.8
 	github.com/gordonklaus/portaudio v0.0.0-20250206071425-98a94950218b
 	golang.design/x/hotkey v0.4.1
+	golang.org/x/sys v0.37.0
 )
 
 require (
@@ -43,5 +44,4 @@ require (
 	golang.org/x/exp v0.0.0-20250506013437-ce4c2cf36ca6 // indirect
 	golang.org/x/image v0.27.0 // indirect
 	golang.org/x/net v0.46.0 // indirect
-	golang.org/x/sys v0.37.0 // indirect
 )
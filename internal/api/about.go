@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/hotkey"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/version"
+)
+
+// RepositoryURL is the project page linked from the About page
+const RepositoryURL = "https://github.com/yok-tottii/EzS2T-Whisper"
+
+// openLogsTimeout bounds how long /api/about/open-logs waits for Finder
+const openLogsTimeout = 5 * time.Second
+
+// License describes a bundled third-party component
+type License struct {
+	Name    string `json:"name"`
+	License string `json:"license"`
+	URL     string `json:"url"`
+}
+
+// ThirdPartyLicenses lists the components whose license texts are in LICENSES/
+var ThirdPartyLicenses = []License{
+	{Name: "whisper.cpp", License: "MIT", URL: "https://github.com/ggml-org/whisper.cpp"},
+	{Name: "PortAudio Go bindings (gordonklaus/portaudio)", License: "MIT", URL: "https://github.com/gordonklaus/portaudio"},
+	{Name: "robotgo", License: "Apache-2.0", URL: "https://github.com/go-vgo/robotgo"},
+	{Name: "systray (getlantern/systray)", License: "Apache-2.0", URL: "https://github.com/getlantern/systray"},
+	{Name: "golang.design/x/hotkey", License: "MIT", URL: "https://github.com/golang-design/hotkey"},
+	{Name: "Material Symbols and Icons", License: "Apache-2.0", URL: "https://github.com/google/material-design-icons"},
+}
+
+// About is the response of GET /api/about
+type About struct {
+	Build         version.Info `json:"build"`
+	ModelPath     string       `json:"model_path"`
+	ModelLoaded   bool         `json:"model_loaded"`
+	Hotkey        string       `json:"hotkey"` // e.g. "⌃⌥Space"
+	RecordingMode string       `json:"recording_mode"`
+	ServerURL     string       `json:"server_url"`
+	RepositoryURL string       `json:"repository_url"`
+	LogDir        string       `json:"log_dir"`
+	Licenses      []License    `json:"licenses"`
+}
+
+// SetLogDir sets the log folder shown and opened by the About page
+func (h *Handler) SetLogDir(dir string) {
+	h.logDir = dir
+}
+
+// handleAbout handles GET /api/about
+func (h *Handler) handleAbout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg := h.config.Clone()
+
+	about := About{
+		Build:         version.Get(),
+		ModelPath:     cfg.ModelPath,
+		Hotkey:        hotkey.FormatHotkey(hotkeyConfigToModifiers(cfg.Hotkey), hotkey.KeyFromString(cfg.Hotkey.Key)),
+		RecordingMode: cfg.RecordingMode,
+		ServerURL:     "http://" + r.Host,
+		RepositoryURL: RepositoryURL,
+		LogDir:        h.logDir,
+		Licenses:      ThirdPartyLicenses,
+	}
+
+	if h.statusProvider != nil {
+		about.ModelLoaded, _ = h.statusProvider()["model_loaded"].(bool)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(about)
+}
+
+// handleAboutOpenLogs handles POST /api/about/open-logs
+// Opens the log folder in Finder, which a page in the browser cannot do itself.
+func (h *Handler) handleAboutOpenLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.logDir == "" {
+		http.Error(w, "Log folder not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), openLogsTimeout)
+	defer cancel()
+
+	if _, err := h.runCommand(ctx, "open", h.logDir); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to open log folder: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/version"
+)
+
+func TestHandleAbout(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ModelPath = "/models/ggml-base.bin"
+
+	handler := New(cfg, nil, nil, nil, nil)
+	handler.SetLogDir("/Users/test/Library/Logs/EzS2T-Whisper")
+	handler.SetStatusProvider(func() map[string]interface{} {
+		return map[string]interface{}{"model_loaded": true}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/about", nil)
+	req.Host = "127.0.0.1:18765"
+	w := httptest.NewRecorder()
+
+	handler.handleAbout(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var about About
+	if err := json.NewDecoder(w.Body).Decode(&about); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if about.Build.Version != version.Version {
+		t.Errorf("Expected version %q, got %q", version.Version, about.Build.Version)
+	}
+	if about.ModelPath != "/models/ggml-base.bin" || !about.ModelLoaded {
+		t.Errorf("Expected loaded model, got %q (loaded=%v)", about.ModelPath, about.ModelLoaded)
+	}
+	if about.Hotkey != "⌃⌥Space" {
+		t.Errorf("Expected default hotkey ⌃⌥Space, got %q", about.Hotkey)
+	}
+	if about.ServerURL != "http://127.0.0.1:18765" {
+		t.Errorf("Expected server URL from the request host, got %q", about.ServerURL)
+	}
+	if about.LogDir != "/Users/test/Library/Logs/EzS2T-Whisper" || about.RepositoryURL != RepositoryURL {
+		t.Errorf("Unexpected links: log=%q repo=%q", about.LogDir, about.RepositoryURL)
+	}
+	if len(about.Licenses) != len(ThirdPartyLicenses) {
+		t.Errorf("Expected %d licenses, got %d", len(ThirdPartyLicenses), len(about.Licenses))
+	}
+}
+
+func TestHandleAboutOpenLogs(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	runner := &fakeRunner{}
+	handler.runCommand = runner.run
+
+	// Without a log folder there is nothing to open
+	req := httptest.NewRequest(http.MethodPost, "/api/about/open-logs", nil)
+	w := httptest.NewRecorder()
+	handler.handleAboutOpenLogs(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+
+	handler.SetLogDir("/tmp/logs")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/about/open-logs", nil)
+	w = httptest.NewRecorder()
+	handler.handleAboutOpenLogs(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if runner.name != "open" || len(runner.args) != 1 || runner.args[0] != "/tmp/logs" {
+		t.Errorf("Expected open /tmp/logs, got %s %v", runner.name, runner.args)
+	}
+}
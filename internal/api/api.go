@@ -1,17 +1,28 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/clipboard"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/download"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/history"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/hotkey"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/metrics"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/modelinfo"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/permissions"
 	"github.com/yok-tottii/EzS2T-Whisper/internal/wizard"
 	hk "golang.design/x/hotkey"
@@ -22,43 +33,219 @@ type Handler struct {
 	config           *config.Config
 	wizard           *wizard.SetupWizard
 	audioDriver      audio.AudioDriver
-	onHotkeyChanged  func() error // Callback to reload hotkey in main app
-	onHotkeyDisable  func() error // Callback to disable hotkey (for settings modal)
-	onHotkeyEnable   func() error // Callback to enable hotkey (for settings modal)
+	audioMu          sync.RWMutex                  // Guards audioDriver, which main.go replaces from other goroutines
+	deviceLister     DeviceLister                  // Lists devices while the audio driver is not initialized
+	onHotkeyChanged  func() error                  // Callback to reload hotkey in main app
+	onHotkeyDisable  func() error                  // Callback to disable hotkey (for settings modal)
+	onHotkeyEnable   func() error                  // Callback to enable hotkey (for settings modal)
+	statusProvider   func() map[string]interface{} // Returns the runtime status of the main app
+	recordingStart   func(language string) error   // Starts a recording session in the main app
+	recordingStop    func() (string, error)        // Stops the session and returns the transcription
+	testRecordLength time.Duration                 // Length of the recording made by /api/test/record
+	testTranscribe   func([]byte) (string, error)  // Transcribes a test recording, nil when not available
+	modelLoaded      func() bool                   // Reports whether a model is loaded for testTranscribe
+	recordingsDir    string                        // Where /api/test/record-save writes WAV files
+	testPaste        func(text string) error       // Pastes text through the main app's output path
+	onModelSelected  func()                        // Loads the configured model after a rescan selected one
+	onSettingsSaved  func()                        // Applies saved settings that take effect without a restart
+	stats            *metrics.Stats                // Timings of recent transcriptions for /api/stats, nil when not available
+	history          *history.Store                // Past transcriptions for /api/history, nil when not available
+	whisperInfo      *WhisperInfo                  // whisper.cpp build information for /api/system, nil when not available
+	testPasteDelay   time.Duration                 // Countdown before /api/test/paste pastes
+	modelInfo        *modelinfo.Cache              // Parsed model headers, reused across scans
+	permissions      PermissionChecker             // Source of the permission status
+	permissionPoll   time.Duration                 // How often /api/permissions/events checks for changes
+	streams          StreamRegistry                // Tracks open event streams so the server can close them, may be nil
+	pickerTimeout    time.Duration                 // How long /api/models/browse waits for the user
+	runCommand       commandRunner                 // Runs external commands (file picker, opening the log folder)
+	logDir           string                        // Log folder shown on the About page
+	logSource        LogSource                     // Entries for /api/logs/stream, nil when not available
+	downloadBaseURL  string                        // Where /api/models/download fetches models from
+	downloadClient   *http.Client                  // HTTP client for model downloads (no overall timeout, models are large; download.Fetch gives up on stalls)
+	downloadMu       sync.Mutex                    // Guards downloadProgress
+	lifecycle        context.Context               // Ends when the app quits, cancelling background work such as downloads
+	downloadProgress download.Progress             // State of the running or latest model download
+}
+
+// DeviceLister lists the audio input devices
+type DeviceLister interface {
+	ListDevices() ([]audio.Device, error)
+}
+
+// portAudioDeviceLister lists the devices with a temporary PortAudio driver
+type portAudioDeviceLister struct{}
+
+func (portAudioDeviceLister) ListDevices() ([]audio.Device, error) {
+	driver, err := audio.NewPortAudioDriver()
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close()
+
+	return driver.ListDevices()
+}
+
+// PermissionChecker reports whether each system permission is granted, keyed by
+// "microphone" and "accessibility"
+type PermissionChecker interface {
+	CheckAllPermissions() map[string]bool
+}
+
+// StreamRegistry tracks long-lived streaming responses. Register returns a
+// context that ends with parent or when the server closes the stream; done
+// must be called when the handler returns.
+type StreamRegistry interface {
+	Register(parent context.Context) (ctx context.Context, done func())
 }
 
 // New creates a new API handler
 func New(cfg *config.Config, wiz *wizard.SetupWizard, onHotkeyChanged, onHotkeyDisable, onHotkeyEnable func() error) *Handler {
 	return &Handler{
-		config:          cfg,
-		wizard:          wiz,
-		audioDriver:     nil, // Will be set later via SetAudioDriver
-		onHotkeyChanged: onHotkeyChanged,
-		onHotkeyDisable: onHotkeyDisable,
-		onHotkeyEnable:  onHotkeyEnable,
+		config:           cfg,
+		wizard:           wiz,
+		audioDriver:      nil, // Will be set later via SetAudioDriver
+		deviceLister:     portAudioDeviceLister{},
+		onHotkeyChanged:  onHotkeyChanged,
+		onHotkeyDisable:  onHotkeyDisable,
+		onHotkeyEnable:   onHotkeyEnable,
+		testRecordLength: 3 * time.Second,
+		recordingsDir:    audio.DefaultRecordingsDir(),
+		testPasteDelay:   3 * time.Second,
+		modelInfo:        modelinfo.NewCache(),
+		permissions:      permissions.NewPermissionChecker(),
+		permissionPoll:   time.Second,
+		pickerTimeout:    DefaultFilePickerTimeout,
+		runCommand:       execCommand,
+		downloadBaseURL:  download.DefaultBaseURL,
+		downloadClient:   &http.Client{},
+		lifecycle:        context.Background(),
 	}
 }
 
 // SetAudioDriver sets the audio driver instance
-// This is called after the audio driver is initialized in main.go
+// This is called after the audio driver is initialized in main.go, and again
+// with nil or a new driver when the input device changes
 func (h *Handler) SetAudioDriver(driver audio.AudioDriver) {
+	h.audioMu.Lock()
+	defer h.audioMu.Unlock()
 	h.audioDriver = driver
 }
 
+// currentAudioDriver returns the audio driver, nil while it is not initialized
+func (h *Handler) currentAudioDriver() audio.AudioDriver {
+	h.audioMu.RLock()
+	defer h.audioMu.RUnlock()
+	return h.audioDriver
+}
+
+// SetStatusProvider sets the callback that reports the runtime status of the main app
+func (h *Handler) SetStatusProvider(provider func() map[string]interface{}) {
+	h.statusProvider = provider
+}
+
+// SetDeviceLister replaces how /api/devices lists devices before the audio
+// driver is initialized (PortAudio by default)
+func (h *Handler) SetDeviceLister(lister DeviceLister) {
+	h.deviceLister = lister
+}
+
+// SetPermissionChecker replaces the permission source and the interval at which
+// /api/permissions/events polls it for changes
+func (h *Handler) SetPermissionChecker(checker PermissionChecker, pollInterval time.Duration) {
+	h.permissions = checker
+	h.permissionPoll = pollInterval
+}
+
+// SetLifecycleContext sets the context that ends when the app quits. Work
+// that outlives a request, such as model downloads, is cancelled with it.
+func (h *Handler) SetLifecycleContext(ctx context.Context) {
+	h.lifecycle = ctx
+}
+
+// SetStreamRegistry sets where /api/permissions/events registers its streams
+func (h *Handler) SetStreamRegistry(registry StreamRegistry) {
+	h.streams = registry
+}
+
+// SetFilePickerTimeout sets how long /api/models/browse waits for the user to pick a file
+func (h *Handler) SetFilePickerTimeout(timeout time.Duration) {
+	h.pickerTimeout = timeout
+}
+
+// SetRecordingControls sets the callbacks used by the recording API
+// language passed to start overrides the configured language for that session only ("" = configured)
+func (h *Handler) SetRecordingControls(start func(language string) error, stop func() (string, error)) {
+	h.recordingStart = start
+	h.recordingStop = stop
+}
+
+// SetPasteTest sets the callback used by /api/test/paste
+// paste must use the same output path and settings as a hotkey transcription
+func (h *Handler) SetPasteTest(paste func(text string) error) {
+	h.testPaste = paste
+}
+
+// SetTestTranscriber sets the callbacks /api/test/record uses to transcribe
+// the recording. A test recording is refused while loaded reports false.
+func (h *Handler) SetTestTranscriber(loaded func() bool, transcribe func(audioData []byte) (string, error)) {
+	h.modelLoaded = loaded
+	h.testTranscribe = transcribe
+}
+
+// SetModelReload sets the callback that loads the configured model after
+// POST /api/models/rescan auto-selected the recommended one
+func (h *Handler) SetModelReload(reload func()) {
+	h.onModelSelected = reload
+}
+
+// SetSettingsSaved sets the callback run after PUT /api/settings saved the
+// configuration, so the main app can apply settings that need no restart
+func (h *Handler) SetSettingsSaved(saved func()) {
+	h.onSettingsSaved = saved
+}
+
+// SetStats sets the transcription timings reported by /api/stats
+func (h *Handler) SetStats(stats *metrics.Stats) {
+	h.stats = stats
+}
+
 // RegisterRoutes registers all API routes on the given mux
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/settings", h.handleSettings)
+	mux.HandleFunc("/api/settings/validate", h.handleSettingsValidate)
 	mux.HandleFunc("/api/hotkey/validate", h.handleHotkeyValidate)
 	mux.HandleFunc("/api/hotkey/register", h.handleHotkeyRegister)
+	mux.HandleFunc("/api/hotkey/reset", h.handleHotkeyReset)
+	mux.HandleFunc("/api/hotkey/keymap", h.handleHotkeyKeymap)
 	mux.HandleFunc("/api/hotkey/disable", h.handleHotkeyDisable)
 	mux.HandleFunc("/api/hotkey/enable", h.handleHotkeyEnable)
+	mux.HandleFunc("/api/recording-mode", h.handleRecordingMode)
+	mux.HandleFunc("/api/recording/start", h.handleRecordingStart)
+	mux.HandleFunc("/api/recording/stop", h.handleRecordingStop)
 	mux.HandleFunc("/api/devices", h.handleDevices)
 	mux.HandleFunc("/api/models", h.handleModels)
 	mux.HandleFunc("/api/models/rescan", h.handleModelsRescan)
 	mux.HandleFunc("/api/models/browse", h.handleModelsBrowse)
 	mux.HandleFunc("/api/models/validate", h.handleModelsValidate)
+	mux.HandleFunc("/api/models/download", h.handleModelsDownload)
+	mux.HandleFunc("/api/models/download/progress", h.handleModelsDownloadProgress)
 	mux.HandleFunc("/api/test/record", h.handleTestRecord)
+	mux.HandleFunc("/api/test/record-save", h.handleTestRecordSave)
+	mux.HandleFunc("/api/test/paste", h.handleTestPaste)
 	mux.HandleFunc("/api/permissions", h.handlePermissions)
+	mux.HandleFunc("/api/permissions/events", h.handlePermissionEvents)
+	mux.HandleFunc("/api/permissions/microphone/request", h.handleMicrophoneRequest)
+	mux.HandleFunc("/api/permissions/request/", h.handlePermissionRequest)
+	mux.HandleFunc("/api/status", h.handleStatus)
+	mux.HandleFunc("/api/stats", h.handleStats)
+	mux.HandleFunc("/api/history", h.handleHistory)
+	mux.HandleFunc("/api/history/export", h.handleHistoryExport)
+	mux.HandleFunc("/api/history/", h.handleHistoryEntry)
+	mux.HandleFunc("/api/about", h.handleAbout)
+	mux.HandleFunc("/api/about/open-logs", h.handleAboutOpenLogs)
+	mux.HandleFunc("/api/logs/stream", h.handleLogStream)
+	mux.HandleFunc("/api/diagnostics", h.handleDiagnostics)
+	mux.HandleFunc("/api/system", h.handleSystem)
 }
 
 // handleSettings handles GET and PUT /api/settings
@@ -87,6 +274,12 @@ func (h *Handler) putSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 保存前にドライランと同じ検証を通し、不正な値は一切反映しない
+	if errs := h.config.ValidateUpdates(updates); len(errs) > 0 {
+		writeSettingsValidation(w, http.StatusBadRequest, errs)
+		return
+	}
+
 	if err := h.config.Update(updates); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to update config: %v", err), http.StatusBadRequest)
 		return
@@ -107,12 +300,51 @@ func (h *Handler) putSettings(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if h.onSettingsSaved != nil {
+		h.onSettingsSaved()
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"status": "success",
 	})
 }
 
+// SettingsValidation is the response of POST /api/settings/validate, and of
+// PUT /api/settings when the update is rejected
+type SettingsValidation struct {
+	Valid  bool                    `json:"valid"`
+	Errors config.ValidationErrors `json:"errors,omitempty"`
+}
+
+// handleSettingsValidate handles POST /api/settings/validate.
+// It checks proposed updates exactly like PUT /api/settings but never saves
+// them or notifies any callbacks.
+func (h *Handler) handleSettingsValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	writeSettingsValidation(w, http.StatusOK, h.config.ValidateUpdates(updates))
+}
+
+// writeSettingsValidation writes the validation result as JSON
+func writeSettingsValidation(w http.ResponseWriter, status int, errs config.ValidationErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(SettingsValidation{
+		Valid:  len(errs) == 0,
+		Errors: errs,
+	})
+}
+
 // handleHotkeyValidate handles POST /api/hotkey/validate
 func (h *Handler) handleHotkeyValidate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -127,8 +359,13 @@ func (h *Handler) handleHotkeyValidate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// HotkeyConfigからModifiersとKeyに変換
+	// 未対応のキー名をSpaceとして検査しないよう、/api/hotkey/register と同じく拒否する
 	mods := hotkeyConfigToModifiers(request)
-	key := stringToKeyCode(request.Key)
+	key, err := hotkey.ParseKey(request.Key)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid key: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	// 競合チェック
 	conflicts := hotkey.CheckConflicts(mods, key)
@@ -139,9 +376,51 @@ func (h *Handler) handleHotkeyValidate(w http.ResponseWriter, r *http.Request) {
 		conflictNames = append(conflictNames, c.Name)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response := map[string]interface{}{
 		"conflicts": conflictNames,
+	}
+	if len(conflictNames) > 0 {
+		response["suggestions"] = hotkeySuggestions(mods, key)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// KeymapResult is the response of POST /api/hotkey/keymap
+type KeymapResult struct {
+	Valid   bool   `json:"valid"`   // The key maps to a known key
+	Key     string `json:"key"`     // Normalized key name as it would be saved (e.g. NBSP becomes "Space")
+	Display string `json:"display"` // Display form of the key that would be registered (Space when invalid)
+}
+
+// handleHotkeyKeymap handles POST /api/hotkey/keymap
+// It previews how a key string maps to a key so the settings form can warn
+// before saving a key that would silently fall back to Space
+func (h *Handler) handleHotkeyKeymap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	key, valid := hotkey.LookupKey(request.Key)
+	if !valid {
+		key = hotkey.KeyFromString(request.Key)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(KeymapResult{
+		Valid:   valid,
+		Key:     config.NormalizeKeyName(request.Key),
+		Display: hotkey.StringFromKey(key),
 	})
 }
 
@@ -152,30 +431,70 @@ func (h *Handler) handleHotkeyRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var hotkey config.HotkeyConfig
-	if err := json.NewDecoder(r.Body).Decode(&hotkey); err != nil {
+	var request config.HotkeyConfig
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// デバッグログ: 受信したホットキー情報を出力
 	fmt.Printf("DEBUG: Received hotkey config: Ctrl=%v, Shift=%v, Alt=%v, Cmd=%v, Key=%q\n",
-		hotkey.Ctrl, hotkey.Shift, hotkey.Alt, hotkey.Cmd, hotkey.Key)
+		request.Ctrl, request.Shift, request.Alt, request.Cmd, request.Key)
 
 	// Validate hotkey configuration
-	if hotkey.Key == "" {
+	request.Key = config.NormalizeKeyName(request.Key)
+	if request.Key == "" {
 		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
 		return
 	}
 
+	key, err := hotkey.ParseKey(request.Key)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid key: %v", err), http.StatusBadRequest)
+		return
+	}
+	// Save the canonical name ("Esc" and "escape" become "Escape") so that
+	// SameCombination, which compares the names, sees the same key
+	request.Key = hotkey.KeyName(key)
+
 	// Check if at least one modifier is set (recommended for safety)
-	if !hotkey.Ctrl && !hotkey.Shift && !hotkey.Alt && !hotkey.Cmd {
+	if !request.Ctrl && !request.Shift && !request.Alt && !request.Cmd {
 		http.Error(w, "At least one modifier key (Ctrl/Shift/Alt/Cmd) is recommended", http.StatusBadRequest)
 		return
 	}
 
-	// Update config
-	h.config.Hotkey = hotkey
+	// Reject known conflicts before touching the saved configuration
+	mods := hotkeyConfigToModifiers(request)
+	if conflicts := hotkey.CheckConflicts(mods, key); len(conflicts) > 0 {
+		names := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			names[i] = c.Name
+		}
+		writeHotkeyConflict(w, request, names)
+		return
+	}
+
+	h.applyHotkey(w, request, "Hotkey registered and applied successfully")
+}
+
+// handleHotkeyReset handles POST /api/hotkey/reset
+// It restores the default hotkey, e.g. when the configured one no longer works
+func (h *Handler) handleHotkeyReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.applyHotkey(w, config.DefaultHotkey(), "Hotkey reset to default")
+}
+
+// applyHotkey saves request as the hotkey and reloads it in the running
+// application, writing message on success
+func (h *Handler) applyHotkey(w http.ResponseWriter, request config.HotkeyConfig, message string) {
+	// Update config; the recognition options are only changed through the settings
+	previous := h.config.Hotkey
+	request = previous.WithKeys(request)
+	h.config.Hotkey = request
 
 	// Save to file
 	configPath := config.GetConfigPath()
@@ -187,6 +506,18 @@ func (h *Handler) handleHotkeyRegister(w http.ResponseWriter, r *http.Request) {
 	// Reload hotkey in the running application
 	if h.onHotkeyChanged != nil {
 		if err := h.onHotkeyChanged(); err != nil {
+			// Another application owns the combination: the running app has rolled back
+			// to the previous hotkey, so restore it in the saved configuration as well
+			var conflictErr *hotkey.ConflictError
+			if errors.As(err, &conflictErr) {
+				h.config.Hotkey = previous
+				if saveErr := h.config.Save(configPath); saveErr != nil {
+					fmt.Printf("Warning: Failed to restore previous hotkey: %v\n", saveErr)
+				}
+				writeHotkeyConflict(w, request, conflictErr.Conflicts)
+				return
+			}
+
 			// Log warning but don't fail the request (config is already saved)
 			fmt.Printf("Warning: Failed to reload hotkey: %v\n", err)
 			// Return partial success response
@@ -202,7 +533,50 @@ func (h *Handler) handleHotkeyRegister(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
-		"message": "Hotkey registered and applied successfully",
+		"message": message,
+	})
+}
+
+// maxHotkeySuggestions is the number of alternatives offered for a conflicting hotkey
+const maxHotkeySuggestions = 3
+
+// HotkeySuggestion is an alternative hotkey offered when the requested one conflicts
+type HotkeySuggestion struct {
+	config.HotkeyConfig
+	Display string `json:"display"` // e.g. "⌃⇧Space"
+}
+
+// hotkeySuggestions returns conflict-free modifier combinations for the same key
+func hotkeySuggestions(mods []hk.Modifier, key hk.Key) []HotkeySuggestion {
+	suggestions := []HotkeySuggestion{}
+	for _, alt := range hotkey.SuggestAlternatives(mods, key, maxHotkeySuggestions) {
+		suggestions = append(suggestions, HotkeySuggestion{
+			HotkeyConfig: modifiersToHotkeyConfig(alt, hotkey.KeyName(key)),
+			Display:      hotkey.FormatHotkey(alt, key),
+		})
+	}
+	return suggestions
+}
+
+// writeHotkeyConflict responds with 409 Conflict, the conflicting shortcut names
+// (empty if another application refused without saying which) and alternatives
+// (empty for a key name that does not map to a key)
+func writeHotkeyConflict(w http.ResponseWriter, request config.HotkeyConfig, conflicts []string) {
+	if conflicts == nil {
+		conflicts = []string{}
+	}
+	suggestions := []HotkeySuggestion{}
+	if key, err := hotkey.ParseKey(request.Key); err == nil {
+		suggestions = hotkeySuggestions(hotkeyConfigToModifiers(request), key)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":      "conflict",
+		"message":     "The hotkey is already used by another shortcut",
+		"conflicts":   conflicts,
+		"suggestions": suggestions,
 	})
 }
 
@@ -250,26 +624,183 @@ func (h *Handler) handleHotkeyEnable(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleRecordingMode handles GET and PUT /api/recording-mode
+func (h *Handler) handleRecordingMode(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"mode": h.config.Clone().RecordingMode,
+		})
+	case http.MethodPut:
+		h.putRecordingMode(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// putRecordingMode updates only the recording mode and re-applies it to the running hotkey
+func (h *Handler) putRecordingMode(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !config.IsValidRecordingMode(request.Mode) {
+		http.Error(w, fmt.Sprintf("Invalid mode: %q (must be 'press-to-hold' or 'toggle')", request.Mode), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.config.Update(map[string]interface{}{"recording_mode": request.Mode}); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update config: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Save to file
+	configPath := config.GetConfigPath()
+	if err := h.config.Save(configPath); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Re-register the hotkey so the new mode takes effect immediately
+	if h.onHotkeyChanged != nil {
+		if err := h.onHotkeyChanged(); err != nil {
+			fmt.Printf("Warning: Failed to apply recording mode: %v\n", err)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{
+				"status":  "partial",
+				"mode":    request.Mode,
+				"message": fmt.Sprintf("Recording mode saved but could not be applied: %v. Please restart the application.", err),
+			})
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "success",
+		"mode":   request.Mode,
+	})
+}
+
+// isValidLanguageOverride checks if the normalized value can be used as a per-request language
+func isValidLanguageOverride(language string) bool {
+	return language == "" || config.IsValidLanguage(language)
+}
+
+// handleRecordingStart handles POST /api/recording/start
+// Body (optional): {"language": "en"} to override the configured language for this session
+func (h *Handler) handleRecordingStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	language := config.NormalizeLanguage(request.Language)
+	if !isValidLanguageOverride(language) {
+		http.Error(w, fmt.Sprintf("Invalid language: %q", language), http.StatusBadRequest)
+		return
+	}
+
+	if h.recordingStart == nil {
+		http.Error(w, "Recording not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.recordingStart(language); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to start recording: %v", err), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":   "recording",
+		"language": language,
+	})
+}
+
+// handleRecordingStop handles POST /api/recording/stop
+// Stops the session started by /api/recording/start and returns the transcription
+func (h *Handler) handleRecordingStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.recordingStop == nil {
+		http.Error(w, "Recording not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	text, err := h.recordingStop()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to stop recording: %v", err), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "success",
+		"text":   text,
+	})
+}
+
 // Device represents an audio device
 type Device struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	IsDefault bool   `json:"is_default"`
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	IsDefault         bool   `json:"is_default"`
+	Selected          bool   `json:"selected"`            // Matches audio_device_id in the configuration
+	Active            bool   `json:"active"`              // Opened by the audio driver (may differ from selected after a fallback)
+	DefaultSampleRate int    `json:"default_sample_rate"` // 0 if unknown
+	Channels          int    `json:"channels"`            // Input channels, 0 if unknown
+}
+
+// convertAudioDevice converts an audio.Device to an api.Device
+func convertAudioDevice(dev audio.Device) Device {
+	return Device{
+		ID:                dev.ID,
+		Name:              dev.Name,
+		IsDefault:         dev.IsDefault,
+		DefaultSampleRate: dev.DefaultSampleRate,
+		Channels:          dev.MaxInputChannels,
+	}
 }
 
 // convertAudioDevices converts audio.Device slice to api.Device slice
 func convertAudioDevices(audioDevices []audio.Device) []Device {
 	devices := make([]Device, 0, len(audioDevices))
 	for _, dev := range audioDevices {
-		devices = append(devices, Device{
-			ID:        dev.ID,
-			Name:      dev.Name,
-			IsDefault: dev.IsDefault,
-		})
+		devices = append(devices, convertAudioDevice(dev))
 	}
 	return devices
 }
 
+// markDevices sets Selected from the configured device ID (-1 selects the
+// system default) and Active from the device the driver actually opened
+func markDevices(devices []Device, configuredID int, active *Device) {
+	for i := range devices {
+		if configuredID == -1 {
+			devices[i].Selected = devices[i].IsDefault
+		} else {
+			devices[i].Selected = devices[i].ID == configuredID
+		}
+		devices[i].Active = active != nil && devices[i].ID == active.ID
+	}
+}
+
 // handleDevices handles GET /api/devices
 func (h *Handler) handleDevices(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -280,48 +811,68 @@ func (h *Handler) handleDevices(w http.ResponseWriter, r *http.Request) {
 	var devices []Device
 
 	// Get actual devices from audio driver
-	if h.audioDriver != nil {
-		audioDevices, err := h.audioDriver.ListDevices()
+	driver := h.currentAudioDriver()
+	if driver != nil {
+		audioDevices, err := driver.ListDevices()
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Failed to list audio devices: %v", err), http.StatusInternalServerError)
 			return
 		}
 		devices = convertAudioDevices(audioDevices)
 	} else {
-		// AudioDriver not initialized - create a temporary driver to list devices
+		// AudioDriver not initialized - list the devices without it
 		// This allows users to see and select devices even before granting microphone permission
-		tempDriver, err := audio.NewPortAudioDriver()
+		audioDevices, err := h.deviceLister.ListDevices()
 		if err != nil {
-			// If we can't create a driver, return system default only
+			// If we can't list devices, return system default only
 			devices = []Device{
 				{ID: -1, Name: "システムデフォルト", IsDefault: true},
 			}
 		} else {
-			defer tempDriver.Close()
-			audioDevices, err := tempDriver.ListDevices()
-			if err != nil {
-				// If we can't list devices, return system default only
-				devices = []Device{
-					{ID: -1, Name: "システムデフォルト", IsDefault: true},
-				}
-			} else {
-				devices = convertAudioDevices(audioDevices)
-			}
+			devices = convertAudioDevices(audioDevices)
 		}
 	}
 
+	// The device the driver actually opened, null if it is not initialized
+	var activeDevice *Device
+	if driver != nil {
+		if current, err := driver.CurrentDevice(); err == nil {
+			converted := convertAudioDevice(current)
+			activeDevice = &converted
+		}
+	}
+
+	markDevices(devices, h.config.Clone().AudioDeviceID, activeDevice)
+	if activeDevice != nil {
+		activeDevice.Selected = slices.ContainsFunc(devices, func(d Device) bool { return d.Active && d.Selected })
+		activeDevice.Active = true
+	}
+
+	response := map[string]interface{}{
+		"devices":       devices,
+		"active_device": activeDevice,
+	}
+
+	// Include the effective stream parameters of the active device for diagnostics
+	if provider, ok := driver.(audio.StreamInfoProvider); ok {
+		response["stream"] = provider.StreamInfo()
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"devices": devices,
-	})
+	json.NewEncoder(w).Encode(response)
 }
 
 // Model represents a Whisper model
 type Model struct {
-	Name        string `json:"name"`
-	Path        string `json:"path"`
-	Size        string `json:"size"`
-	Recommended bool   `json:"recommended"`
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Size         string `json:"size"`
+	Recommended  bool   `json:"recommended"`
+	Compatible   bool   `json:"compatible"`      // Header is a readable whisper.cpp ggml model
+	Multilingual bool   `json:"multilingual"`    // False for English-only (.en) models
+	Class        string `json:"class,omitempty"` // "tiny", "base", "small", "medium", "large"
+	Turbo        bool   `json:"turbo"`           // large-v3-turbo
+	Speed        string `json:"speed,omitempty"` // "fast", "medium", "slow"
 }
 
 // handleModels handles GET /api/models
@@ -347,23 +898,66 @@ func (h *Handler) handleModelsRescan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	models := h.scanModels()
+	response := map[string]interface{}{
+		"models": models,
+	}
+	if path := h.autoSelectRecommended(models); path != "" {
+		response["auto_selected"] = path
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"models": models,
-	})
+	json.NewEncoder(w).Encode(response)
+}
+
+// autoSelectRecommended selects and loads the recommended model from models when
+// auto_select_recommended is on and the configured model is missing or invalid.
+// It returns the selected path, or "" when the configuration was left unchanged.
+func (h *Handler) autoSelectRecommended(models []Model) string {
+	if !h.config.Clone().AutoSelectRecommended || h.config.ValidateModelPath() == nil {
+		return ""
+	}
+
+	for _, model := range models {
+		// A recommended name alone is not enough: the file must also be a readable model
+		if !model.Recommended || !model.Compatible {
+			continue
+		}
+
+		if err := h.config.Update(map[string]interface{}{"model_path": model.Path}); err != nil {
+			fmt.Printf("Warning: Failed to select recommended model: %v\n", err)
+			return ""
+		}
+		if err := h.config.Save(config.GetConfigPath()); err != nil {
+			// The model is still used until the app restarts
+			fmt.Printf("Warning: Failed to save selected model: %v\n", err)
+		}
+		if h.onModelSelected != nil {
+			h.onModelSelected()
+		}
+		return model.Path
+	}
+
+	return ""
+}
+
+// modelsDirectory returns the directory where downloaded models are stored
+func modelsDirectory() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, "Library", "Application Support", "EzS2T-Whisper", "models"), nil
 }
 
 // scanModels scans the models directory and returns available models
 func (h *Handler) scanModels() []Model {
-	homeDir, err := os.UserHomeDir()
+	modelsDir, err := modelsDirectory()
 	if err != nil {
 		// Cannot get home directory, return empty list
 		return []Model{}
 	}
 
-	modelsDir := filepath.Join(homeDir, "Library", "Application Support", "EzS2T-Whisper", "models")
-
 	var models []Model
 
 	// Check if directory exists
@@ -398,12 +992,23 @@ func (h *Handler) scanModels() []Model {
 		baseName := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
 		recommended := baseName == "ggml-large-v3-turbo-q5_0"
 
-		models = append(models, Model{
+		model := Model{
 			Name:        entry.Name(),
 			Path:        path,
 			Size:        size,
 			Recommended: recommended,
-		})
+		}
+
+		// Read only the header; the result is cached by path and mod time
+		if meta, err := h.modelInfo.Get(path); err == nil {
+			model.Compatible = true
+			model.Multilingual = meta.Multilingual
+			model.Class = meta.Class
+			model.Turbo = meta.Turbo
+			model.Speed = meta.Speed
+		}
+
+		models = append(models, model)
 	}
 
 	return models
@@ -423,25 +1028,282 @@ func formatSize(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// ErrorCodeMicSilent is returned by /api/test/record when the recording contains no signal
+const ErrorCodeMicSilent = "mic_silent"
+
+// Bounds of the length requested with {"seconds": N} by /api/test/record
+const (
+	MinTestRecordSeconds = 1
+	MaxTestRecordSeconds = 30
+)
+
+// testRecordDuration returns the recording length for the requested seconds,
+// clamped to MinTestRecordSeconds..MaxTestRecordSeconds, or the default
+// length when none was requested
+func (h *Handler) testRecordDuration(seconds int) time.Duration {
+	if seconds == 0 {
+		return h.testRecordLength
+	}
+	return time.Duration(min(max(seconds, MinTestRecordSeconds), MaxTestRecordSeconds)) * time.Second
+}
+
 // handleTestRecord handles POST /api/test/record
+// It records for the requested length ({"seconds": N}, optional) and, when a
+// model is loaded, transcribes the recording like a hotkey transcription.
 func (h *Handler) handleTestRecord(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// TODO: Implement actual test recording
-	// For now, return success
+	var request struct {
+		Seconds int `json:"seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Refuse before recording so the user does not speak for nothing
+	if h.testTranscribe != nil && h.modelLoaded != nil && !h.modelLoaded() {
+		http.Error(w, "Model not loaded", http.StatusConflict)
+		return
+	}
+
+	// Recording up to MaxTestRecordSeconds and transcribing may take longer than the write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, "Failed to wait for the recording", http.StatusInternalServerError)
+		return
+	}
+
+	audioData, ok := h.recordTestAudio(w, r, h.testRecordDuration(request.Seconds))
+	if !ok {
+		return
+	}
+
+	format := h.testRecordFormat()
+	response := map[string]interface{}{
+		"status":      "success",
+		"bytes":       len(audioData),
+		"duration_ms": format.Duration(audioData).Milliseconds(),
+		"waveform":    audio.ComputeEnvelope(audioData, audio.DefaultEnvelopeBuckets),
+	}
+
+	// A recording with no signal at all usually means a muted or dead microphone.
+	// Report a distinct error code so the UI can show targeted guidance.
+	if audio.IsSilent(audioData) {
+		response["status"] = "error"
+		response["error_code"] = ErrorCodeMicSilent
+		response["message"] = "マイクが無音です。ミュートされていないか確認してください"
+	} else if h.testTranscribe != nil {
+		text, err := h.testTranscribe(audioData)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to transcribe: %v", err), http.StatusInternalServerError)
+			return
+		}
+		response["transcription"] = text
+	}
+
+	if h.wizard != nil {
+		h.wizard.SetRecordTestResult(response["status"] == "success")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// recordTestAudio records for length, stopping early if the client goes
+// away. On failure it writes the error response and returns false.
+func (h *Handler) recordTestAudio(w http.ResponseWriter, r *http.Request, length time.Duration) ([]byte, bool) {
+	driver := h.currentAudioDriver()
+	if driver == nil {
+		http.Error(w, "Audio device not available", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	// A denied permission records silence; NotDetermined is asked for by the recording
+	if requester, ok := h.permissions.(MicrophoneRequester); ok {
+		switch requester.CheckMicrophonePermission() {
+		case permissions.PermissionDenied, permissions.PermissionRestricted:
+			http.Error(w, "Microphone permission denied", http.StatusForbidden)
+			return nil, false
+		}
+	}
+
+	if err := driver.StartRecording(); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to start recording: %v", err), http.StatusConflict)
+		return nil, false
+	}
+
+	select {
+	case <-time.After(length):
+	case <-r.Context().Done():
+	}
+
+	audioData, err := driver.StopRecording()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to stop recording: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	return audioData, true
+}
+
+// testRecordFormat returns the format of the audio returned by the driver,
+// which is the effective rate of the opened stream
+func (h *Handler) testRecordFormat() audio.Config {
+	return audio.RecordingFormat(h.currentAudioDriver(), audio.DefaultConfig())
+}
+
+// handleTestRecordSave handles POST /api/test/record-save
+// It records for the test length like /api/test/record and saves the raw
+// captured audio as a WAV file, so a bad transcription can be reproduced.
+func (h *Handler) handleTestRecordSave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.recordingsDir == "" {
+		http.Error(w, "Failed to get recordings directory", http.StatusInternalServerError)
+		return
+	}
+
+	audioData, ok := h.recordTestAudio(w, r, h.testRecordLength)
+	if !ok {
+		return
+	}
+
+	format := h.testRecordFormat()
+
+	path, err := audio.SaveRecording(h.recordingsDir, audioData, format.SampleRate, format.Channels, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save recording: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":  "success",
-		"message": "Test recording not yet implemented",
+		"status":      "success",
+		"path":        path,
+		"bytes":       len(audioData),
+		"sample_rate": format.SampleRate,
 	})
 }
 
+// DefaultTestPasteText is pasted by /api/test/paste when no text is given
+const DefaultTestPasteText = "EzS2T-Whisper 貼り付けテスト"
+
+// maxTestPasteRunes limits the sample text so a test cannot type a long document
+const maxTestPasteRunes = 200
+
+// testPasteMessages describes each failed paste stage for the user
+var testPasteMessages = map[string]string{
+	clipboard.StageClipboard:   "クリップボードへの書き込みに失敗しました",
+	clipboard.StageKeystroke:   "キー入力を送信できませんでした。アクセシビリティ権限を確認してください",
+	clipboard.StageSecureInput: "他のアプリがセキュアキーボード入力を有効にしているため貼り付けできません（パスワード欄やターミナルを確認してください）",
+}
+
+// handleTestPaste handles POST /api/test/paste.
+// After a countdown that lets the user focus a text field, it pastes a short
+// sample text through the same path as a hotkey transcription and reports the
+// stage that failed, if any.
+func (h *Handler) handleTestPaste(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	text := request.Text
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTestPasteText
+	}
+	if len([]rune(text)) > maxTestPasteRunes {
+		http.Error(w, fmt.Sprintf("Text too long (max %d characters)", maxTestPasteRunes), http.StatusBadRequest)
+		return
+	}
+
+	if h.testPaste == nil {
+		http.Error(w, "Paste not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Count down, giving up without pasting if the client goes away
+	select {
+	case <-time.After(h.testPasteDelay):
+	case <-r.Context().Done():
+		return
+	}
+
+	response := map[string]interface{}{
+		"status": "success",
+		"text":   text,
+	}
+
+	if err := h.testPaste(text); err != nil {
+		stage := clipboard.FailedStage(err)
+		message, ok := testPasteMessages[stage]
+		if !ok {
+			message = fmt.Sprintf("貼り付けに失敗しました: %v", err)
+		}
+
+		response["status"] = "error"
+		response["stage"] = stage
+		response["message"] = message
+		response["error"] = err.Error()
+	}
+
+	if h.wizard != nil {
+		h.wizard.SetPasteTestResult(response["status"] == "success")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // Permission represents a system permission status
 type Permission struct {
-	Granted bool `json:"granted"`
+	Granted bool   `json:"granted"`
+	Status  string `json:"status,omitempty"` // Microphone only: "not_determined", "restricted", "denied" or "authorized"
+}
+
+// microphoneStatus returns the API name of a microphone permission status
+func microphoneStatus(status permissions.PermissionStatus) string {
+	switch status {
+	case permissions.PermissionNotDetermined:
+		return "not_determined"
+	case permissions.PermissionRestricted:
+		return "restricted"
+	case permissions.PermissionDenied:
+		return "denied"
+	case permissions.PermissionAuthorized:
+		return "authorized"
+	default:
+		return "unknown"
+	}
+}
+
+// MicrophoneRequester is implemented by permission checkers that can tell a
+// microphone permission that was never asked for from a denied one, and show
+// the system dialog for it
+type MicrophoneRequester interface {
+	CheckMicrophonePermission() permissions.PermissionStatus
+	RequestMicrophoneAccess() permissions.PermissionStatus
+}
+
+// SettingsOpener is implemented by permission checkers that can open the
+// privacy pane of System Settings for each permission
+type SettingsOpener interface {
+	RequestMicrophonePermission() error
+	RequestAccessibilityPermission() error
 }
 
 // handlePermissions handles GET /api/permissions
@@ -451,54 +1313,227 @@ func (h *Handler) handlePermissions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get actual permission status
-	permChecker := permissions.NewPermissionChecker()
-	permsStatus := permChecker.CheckAllPermissions()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.permissionStatus())
+}
 
-	perms := map[string]Permission{
-		"microphone":    {Granted: permsStatus["microphone"]},
+// permissionStatus returns the current status of every permission. Without
+// a checker nothing is reported as granted, so the UI never shows a false green.
+func (h *Handler) permissionStatus() map[string]Permission {
+	if h.permissions == nil {
+		return map[string]Permission{"microphone": {}, "accessibility": {}}
+	}
+	permsStatus := h.permissions.CheckAllPermissions()
+
+	microphone := Permission{Granted: permsStatus["microphone"]}
+	if requester, ok := h.permissions.(MicrophoneRequester); ok {
+		microphone.Status = microphoneStatus(requester.CheckMicrophonePermission())
+	}
+
+	return map[string]Permission{
+		"microphone":    microphone,
 		"accessibility": {Granted: permsStatus["accessibility"]},
 	}
+}
+
+// handleMicrophoneRequest handles POST /api/permissions/microphone/request.
+// While the microphone permission is NotDetermined the app is not listed in
+// System Settings yet, so this shows the macOS permission dialog instead and
+// responds with the user's answer. For any other status nothing is shown.
+// It is only called from a button in the settings page, never automatically.
+func (h *Handler) handleMicrophoneRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requester, ok := h.permissions.(MicrophoneRequester)
+	if !ok {
+		http.Error(w, "Microphone request not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The dialog waits for the user, which may take longer than the write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, "Failed to wait for the answer", http.StatusInternalServerError)
+		return
+	}
+
+	status := requester.RequestMicrophoneAccess()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(perms)
+	json.NewEncoder(w).Encode(Permission{
+		Granted: status == permissions.PermissionAuthorized,
+		Status:  microphoneStatus(status),
+	})
 }
 
-// handleModelsBrowse handles POST /api/models/browse
-// Opens a native file picker dialog using osascript (AppleScript)
-func (h *Handler) handleModelsBrowse(w http.ResponseWriter, r *http.Request) {
+// handlePermissionRequest handles POST /api/permissions/request/{name}.
+// It opens the System Settings pane where the user grants the permission name
+// ("microphone" or "accessibility"), so the wizard can send the user there.
+func (h *Handler) handlePermissionRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Use osascript to open macOS file picker
-	// AppleScript command to choose file with .bin or .gguf extension
-	script := `
-		set theFile to choose file with prompt "Whisperモデルファイル (.bin / .gguf) を選択してください" of type {"bin", "gguf"}
-		return POSIX path of theFile
-	`
+	opener, ok := h.permissions.(SettingsOpener)
+	if !ok {
+		http.Error(w, "System Settings not available", http.StatusServiceUnavailable)
+		return
+	}
 
-	cmd := exec.Command("osascript", "-e", script)
-	output, err := cmd.Output()
+	var err error
+	switch name := strings.TrimPrefix(r.URL.Path, "/api/permissions/request/"); name {
+	case "microphone":
+		err = opener.RequestMicrophonePermission()
+	case "accessibility":
+		err = opener.RequestAccessibilityPermission()
+	default:
+		http.Error(w, fmt.Sprintf("Unknown permission: %q", name), http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		// User cancelled or error occurred
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Exit code 128 means user cancelled
-			if exitErr.ExitCode() == 128 {
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(map[string]interface{}{
-					"cancelled": true,
-				})
+		http.Error(w, fmt.Sprintf("Failed to open System Settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// handlePermissionEvents handles GET /api/permissions/events.
+// It streams Server-Sent Events: a "permissions" event with the full status
+// map on connect, then another one whenever any permission changes, so the
+// settings page updates as soon as the user grants access in System Settings.
+func (h *Handler) handlePermissionEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The stream stays open far longer than the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	send := func(status map[string]Permission) error {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: permissions\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	ctx := r.Context()
+	if h.streams != nil {
+		var done func()
+		ctx, done = h.streams.Register(ctx)
+		defer done()
+	}
+
+	last := h.permissionStatus()
+	if err := send(last); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.permissionPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			status := h.permissionStatus()
+			if maps.Equal(status, last) {
+				continue
+			}
+			last = status
+			if err := send(status); err != nil {
 				return
 			}
 		}
+	}
+}
+
+// handleStatus handles GET /api/status
+func (h *Handler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.statusProvider == nil {
+		http.Error(w, "Status not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.statusProvider())
+}
+
+// handleStats handles GET /api/stats
+// Returns the transcription and paste time percentiles of recent transcriptions.
+func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.stats == nil {
+		http.Error(w, "Stats not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.stats.Snapshot())
+}
+
+// handleModelsBrowse handles POST /api/models/browse
+// Opens a native file picker dialog using osascript (AppleScript), starting in
+// the models directory. The dialog is closed after pickerTimeout and the
+// response is {"cancelled": true, "reason": "timeout"}.
+func (h *Handler) handleModelsBrowse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The user may take longer than the server's write timeout to pick a file
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(h.pickerTimeout + 10*time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
 		http.Error(w, fmt.Sprintf("Failed to open file picker: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Get the file path from output
-	filePath := strings.TrimSpace(string(output))
+	startDir, _ := modelsDirectory()
+	picked, err := chooseModelFile(r.Context(), h.runCommand, startDir, h.pickerTimeout)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to open file picker: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if picked.Cancelled {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"cancelled": true,
+			"reason":    picked.Reason,
+		})
+		return
+	}
+
+	filePath := picked.Path
 
 	// Validate the selected file
 	expandedPath, err := config.ExpandPath(filePath)
@@ -628,60 +1663,20 @@ func hotkeyConfigToModifiers(hkConfig config.HotkeyConfig) []hk.Modifier {
 	return mods
 }
 
-// stringToKeyCode は文字列をキーコードに変換
-func stringToKeyCode(keyStr string) hk.Key {
-	// NBSP正規化: macOS IMEでスペースキーを押すとNBSP（U+00A0）が送信されることがあるため
-	if keyStr == "\u00a0" {
-		keyStr = "Space"
-	}
-
-	keyMap := map[string]hk.Key{
-		"Space":  hk.KeySpace,
-		"A":      hk.KeyA,
-		"B":      hk.KeyB,
-		"C":      hk.KeyC,
-		"D":      hk.KeyD,
-		"E":      hk.KeyE,
-		"F":      hk.KeyF,
-		"G":      hk.KeyG,
-		"H":      hk.KeyH,
-		"I":      hk.KeyI,
-		"J":      hk.KeyJ,
-		"K":      hk.KeyK,
-		"L":      hk.KeyL,
-		"M":      hk.KeyM,
-		"N":      hk.KeyN,
-		"O":      hk.KeyO,
-		"P":      hk.KeyP,
-		"Q":      hk.KeyQ,
-		"R":      hk.KeyR,
-		"S":      hk.KeyS,
-		"T":      hk.KeyT,
-		"U":      hk.KeyU,
-		"V":      hk.KeyV,
-		"W":      hk.KeyW,
-		"X":      hk.KeyX,
-		"Y":      hk.KeyY,
-		"Z":      hk.KeyZ,
-		"0":      hk.Key0,
-		"1":      hk.Key1,
-		"2":      hk.Key2,
-		"3":      hk.Key3,
-		"4":      hk.Key4,
-		"5":      hk.Key5,
-		"6":      hk.Key6,
-		"7":      hk.Key7,
-		"8":      hk.Key8,
-		"9":      hk.Key9,
-		"Escape": hk.KeyEscape,
-		"Return": hk.KeyReturn,
-		"Tab":    hk.KeyTab,
-	}
-
-	if key, ok := keyMap[keyStr]; ok {
-		return key
-	}
-
-	// デフォルトはSpace
-	return hk.KeySpace
+// modifiersToHotkeyConfig is the inverse of hotkeyConfigToModifiers
+func modifiersToHotkeyConfig(mods []hk.Modifier, key string) config.HotkeyConfig {
+	hkConfig := config.HotkeyConfig{Key: key}
+	for _, mod := range mods {
+		switch mod {
+		case hk.ModCtrl:
+			hkConfig.Ctrl = true
+		case hk.ModShift:
+			hkConfig.Shift = true
+		case hk.ModOption:
+			hkConfig.Alt = true
+		case hk.ModCmd:
+			hkConfig.Cmd = true
+		}
+	}
+	return hkConfig
 }
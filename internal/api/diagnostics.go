@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/diagnostics"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/version"
+)
+
+// systemInfoTimeout bounds how long the diagnostics bundle waits for sw_vers
+const systemInfoTimeout = 5 * time.Second
+
+// SystemInfo is the response of GET /api/system and the system.json snapshot
+// of the diagnostics bundle
+type SystemInfo struct {
+	OS           string       `json:"os"`
+	Arch         string       `json:"arch"`
+	MacOSVersion string       `json:"macos_version"`     // e.g. "15.1", empty if sw_vers failed
+	Whisper      *WhisperInfo `json:"whisper,omitempty"` // Version and features of the linked whisper.cpp
+}
+
+// WhisperInfo describes the linked whisper.cpp library in /api/system
+type WhisperInfo struct {
+	Version         string `json:"version"`          // Reported by the library, "" if it is too old to tell
+	ExpectedVersion string `json:"expected_version"` // Version of the header the binary was built with
+	SystemInfo      string `json:"system_info"`      // CPU and GPU features in use
+	Mismatch        bool   `json:"mismatch"`         // The library differs from the header the binary was built with
+}
+
+// SetWhisperInfo sets the whisper.cpp build information reported by /api/system
+func (h *Handler) SetWhisperInfo(info WhisperInfo) {
+	h.whisperInfo = &info
+}
+
+// diagnosticsBundle collects the state written to a diagnostics bundle
+func (h *Handler) diagnosticsBundle(ctx context.Context) diagnostics.Bundle {
+	bundle := diagnostics.Bundle{
+		Config:      h.config,
+		Version:     version.Get(),
+		System:      h.systemInfo(ctx),
+		Permissions: h.permissionStatus(),
+		LogDir:      h.logDir,
+	}
+
+	if h.statusProvider != nil {
+		bundle.Status = h.statusProvider()
+	}
+
+	// Only the initialized driver is asked; a temporary PortAudio instance could disturb recording
+	driver := h.currentAudioDriver()
+	if driver != nil {
+		if devices, err := driver.ListDevices(); err != nil {
+			bundle.Devices = map[string]string{"error": err.Error()}
+		} else {
+			bundle.Devices = convertAudioDevices(devices)
+		}
+	}
+
+	return bundle
+}
+
+// systemInfo reports the OS and, on macOS, its version
+func (h *Handler) systemInfo(ctx context.Context) SystemInfo {
+	info := SystemInfo{OS: runtime.GOOS, Arch: runtime.GOARCH, Whisper: h.whisperInfo}
+
+	ctx, cancel := context.WithTimeout(ctx, systemInfoTimeout)
+	defer cancel()
+
+	if output, err := h.runCommand(ctx, "sw_vers", "-productVersion"); err == nil {
+		info.MacOSVersion = strings.TrimSpace(string(output))
+	}
+	return info
+}
+
+// handleSystem handles GET /api/system
+func (h *Handler) handleSystem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.systemInfo(r.Context()))
+}
+
+// handleDiagnostics handles GET /api/diagnostics
+// Returns a zip with recent logs, the redacted configuration and snapshots of
+// the status, version, devices and permissions for attaching to bug reports.
+func (h *Handler) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Build the whole archive first so a failure can still be reported as an error
+	var buf bytes.Buffer
+	if err := h.diagnosticsBundle(r.Context()).Write(&buf); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create diagnostics bundle: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", diagnostics.FileName(time.Now())))
+	w.Write(buf.Bytes())
+}
+
+// SaveDiagnostics asks for a destination with the native save dialog and
+// writes the diagnostics bundle there. It returns the saved path, or "" when
+// the user cancelled or the dialog timed out.
+func (h *Handler) SaveDiagnostics(ctx context.Context) (string, error) {
+	result, err := chooseSaveFile(ctx, h.runCommand, "診断情報の保存先を選択してください", diagnostics.FileName(time.Now()), h.pickerTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if result.Cancelled {
+		return "", nil
+	}
+
+	path := result.Path
+	if !strings.HasSuffix(strings.ToLower(path), ".zip") {
+		path += ".zip"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if err := h.diagnosticsBundle(ctx).Write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/permissions"
+)
+
+// diagnosticsRunner answers sw_vers with a macOS version and the save dialog with path
+func diagnosticsRunner(path string) commandRunner {
+	return func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if name == "sw_vers" {
+			return []byte("15.1\n"), nil
+		}
+		return []byte(path + "\n"), nil
+	}
+}
+
+// zipNames returns the file names in a zip archive
+func zipNames(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestHandleDiagnostics(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.runCommand = diagnosticsRunner("")
+	handler.SetPermissionChecker(&fakeMicrophoneChecker{status: permissions.PermissionAuthorized}, 0)
+	handler.SetStatusProvider(func() map[string]interface{} {
+		return map[string]interface{}{"model_loaded": true}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagnostics", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleDiagnostics(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Expected a zip, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "ezs2t-whisper-diagnostics-") {
+		t.Errorf("Expected a download file name, got %q", cd)
+	}
+
+	names := strings.Join(zipNames(t, w.Body.Bytes()), ",")
+	for _, name := range []string{"config.json", "version.json", "system.json", "status.json", "permissions.json"} {
+		if !strings.Contains(names, name) {
+			t.Errorf("Expected %s in the bundle, got %s", name, names)
+		}
+	}
+}
+
+func TestHandleSystem(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.runCommand = diagnosticsRunner("")
+	handler.SetWhisperInfo(WhisperInfo{Version: "1.7.6", ExpectedVersion: "v1.7.6"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleSystem(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var info struct {
+		MacOSVersion string       `json:"macos_version"`
+		Whisper      *WhisperInfo `json:"whisper"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if info.MacOSVersion != "15.1" || info.Whisper == nil || info.Whisper.Version != "1.7.6" {
+		t.Errorf("Unexpected system info %+v", info)
+	}
+}
+
+func TestSaveDiagnostics(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.SetPermissionChecker(&fakeMicrophoneChecker{status: permissions.PermissionAuthorized}, 0)
+
+	// The extension is added when the user leaves it out
+	path := filepath.Join(t.TempDir(), "report")
+	handler.runCommand = diagnosticsRunner(path)
+
+	saved, err := handler.SaveDiagnostics(context.Background())
+	if err != nil {
+		t.Fatalf("SaveDiagnostics failed: %v", err)
+	}
+	if saved != path+".zip" {
+		t.Errorf("Expected %s.zip, got %s", path, saved)
+	}
+
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	if names := zipNames(t, data); len(names) == 0 {
+		t.Error("Expected a non-empty bundle")
+	}
+}
+
+func TestSaveDiagnostics_Cancelled(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.SetFilePickerTimeout(0)
+	handler.runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	saved, err := handler.SaveDiagnostics(context.Background())
+	if err != nil || saved != "" {
+		t.Errorf("Expected a timed out dialog to save nothing, got %q (err=%v)", saved, err)
+	}
+}
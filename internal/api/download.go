@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/download"
+)
+
+// ModelDownloadRequest is the body of POST /api/models/download
+type ModelDownloadRequest struct {
+	Name  string `json:"name"`  // e.g. "ggml-large-v3-turbo-q5_0.bin"
+	Force bool   `json:"force"` // Replace an existing model file once the download completes
+}
+
+// handleModelsDownload handles POST /api/models/download
+// It starts downloading an official ggml model into the models folder and
+// returns 202 with the initial progress. Only one download runs at a time; a
+// previously interrupted download of the same model is resumed.
+func (h *Handler) handleModelsDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ModelDownloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !download.IsValidModelName(req.Name) {
+		http.Error(w, fmt.Sprintf("Invalid model name: %q", req.Name), http.StatusBadRequest)
+		return
+	}
+
+	modelsDir, err := modelsDirectory()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get models directory: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create models directory: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	dest := filepath.Join(modelsDir, req.Name)
+	if _, err := os.Stat(dest); err == nil && !req.Force {
+		http.Error(w, fmt.Sprintf("Model already exists: %s (set force to replace it)", req.Name), http.StatusConflict)
+		return
+	}
+
+	h.downloadMu.Lock()
+	if h.downloadProgress.Active {
+		h.downloadMu.Unlock()
+		http.Error(w, fmt.Sprintf("Another download is in progress: %s", h.downloadProgress.Model), http.StatusConflict)
+		return
+	}
+	h.downloadProgress = download.Progress{Model: req.Name, Active: true}
+	progress := h.downloadProgress
+	h.downloadMu.Unlock()
+
+	// The download outlives the request; it is cancelled when the app quits
+	go h.runModelDownload(req.Name, dest)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(progress)
+}
+
+// runModelDownload downloads name to dest and records the progress and the result
+func (h *Handler) runModelDownload(name, dest string) {
+	err := download.Fetch(h.lifecycle, h.downloadClient, download.URL(h.downloadBaseURL, name), dest, func(downloaded, total int64) {
+		h.downloadMu.Lock()
+		defer h.downloadMu.Unlock()
+
+		h.downloadProgress.Downloaded = downloaded
+		h.downloadProgress.Total = total
+	})
+
+	h.downloadMu.Lock()
+	defer h.downloadMu.Unlock()
+
+	h.downloadProgress.Active = false
+	if err != nil {
+		h.downloadProgress.Error = err.Error()
+		return
+	}
+	h.downloadProgress.Done = true
+}
+
+// handleModelsDownloadProgress handles GET /api/models/download/progress
+// Returns the progress of the running or latest download for a progress bar
+func (h *Handler) handleModelsDownloadProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.downloadMu.Lock()
+	progress := h.downloadProgress
+	h.downloadMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
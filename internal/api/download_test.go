@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/download"
+)
+
+// postModelDownload sends POST /api/models/download with body
+func postModelDownload(handler *Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/models/download", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.handleModelsDownload(w, req)
+	return w
+}
+
+// waitForDownload polls the progress until the download is no longer active
+func waitForDownload(t *testing.T, handler *Handler) download.Progress {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		handler.downloadMu.Lock()
+		progress := handler.downloadProgress
+		handler.downloadMu.Unlock()
+		if !progress.Active {
+			return progress
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Download did not finish")
+	return download.Progress{}
+}
+
+func TestHandleModelsDownload(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ggml-tiny.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("model"))
+	}))
+	defer server.Close()
+
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.downloadBaseURL = server.URL
+	handler.downloadClient = server.Client()
+
+	if w := postModelDownload(handler, `{"name": "ggml-tiny.bin"}`); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if progress := waitForDownload(t, handler); !progress.Done || progress.Downloaded != 5 || progress.Total != 5 {
+		t.Errorf("Expected a completed 5 byte download, got %+v", progress)
+	}
+
+	path := filepath.Join(home, "Library", "Application Support", "EzS2T-Whisper", "models", "ggml-tiny.bin")
+	if data, err := os.ReadFile(path); err != nil || string(data) != "model" {
+		t.Errorf("Expected the model in the models folder, got %q (err=%v)", data, err)
+	}
+
+	// An existing model is only replaced with force
+	if w := postModelDownload(handler, `{"name": "ggml-tiny.bin"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for an existing model, got %d", w.Code)
+	}
+	if w := postModelDownload(handler, `{"name": "ggml-tiny.bin", "force": true}`); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202 with force, got %d", w.Code)
+	}
+	waitForDownload(t, handler)
+
+	// Failures are reported through the progress
+	if w := postModelDownload(handler, `{"name": "ggml-missing.bin"}`); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", w.Code)
+	}
+	if progress := waitForDownload(t, handler); progress.Done || progress.Error == "" {
+		t.Errorf("Expected an error for a missing model, got %+v", progress)
+	}
+}
+
+func TestHandleModelsDownload_CancelledOnQuit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// The server sends part of the model and then waits
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("model"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, quit := context.WithCancel(context.Background())
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.downloadBaseURL = server.URL
+	handler.downloadClient = server.Client()
+	handler.SetLifecycleContext(ctx)
+
+	if w := postModelDownload(handler, `{"name": "ggml-tiny.bin"}`); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", w.Code)
+	}
+	// Quit once the received data has been written to the partial file
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		handler.downloadMu.Lock()
+		downloaded := handler.downloadProgress.Downloaded
+		handler.downloadMu.Unlock()
+		if downloaded == 5 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	quit()
+
+	progress := waitForDownload(t, handler)
+	if progress.Done || !strings.Contains(progress.Error, context.Canceled.Error()) {
+		t.Errorf("Expected the download to be cancelled, got %+v", progress)
+	}
+
+	path := filepath.Join(home, "Library", "Application Support", "EzS2T-Whisper", "models", "ggml-tiny.bin")
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no model after cancelling, got err=%v", err)
+	}
+	if _, err := os.Stat(path + download.PartialSuffix); err != nil {
+		t.Errorf("Expected the partial file to be kept for resuming: %v", err)
+	}
+}
+
+func TestHandleModelsDownload_InvalidName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+
+	for _, body := range []string{`{"name": "../config.json"}`, `{"name": "ggml-../../x.bin"}`, `{}`, `not json`} {
+		if w := postModelDownload(handler, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultFilePickerTimeout is how long /api/models/browse waits for the user
+// before closing the dialog and reporting a timeout
+const DefaultFilePickerTimeout = 2 * time.Minute
+
+// Reasons reported with {"cancelled": true}
+const (
+	PickerCancelledByUser = "user"
+	PickerTimedOut        = "timeout"
+)
+
+// commandRunner runs a command and returns its standard output.
+// The command must be killed when ctx is done. Tests replace it with a fake.
+type commandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// execCommand runs a real process
+func execCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// chooseModelScript shows the file picker in front of other windows. The
+// starting directory is passed as an argument so it never needs quoting.
+const chooseModelScript = `
+on run argv
+	set startDir to missing value
+	if (count of argv) > 0 then set startDir to POSIX file (item 1 of argv) as alias
+	tell application "System Events"
+		activate
+		if startDir is missing value then
+			set theFile to choose file with prompt "Whisperモデルファイル (.bin / .gguf) を選択してください" of type {"bin", "gguf"}
+		else
+			set theFile to choose file with prompt "Whisperモデルファイル (.bin / .gguf) を選択してください" of type {"bin", "gguf"} default location startDir
+		end if
+	end tell
+	return POSIX path of theFile
+end run
+`
+
+// filePickResult is the outcome of the native file picker
+type filePickResult struct {
+	Path      string // Selected POSIX path, empty when cancelled
+	Cancelled bool
+	Reason    string // PickerCancelledByUser or PickerTimedOut when cancelled
+}
+
+// chooseModelFile opens the native file picker starting in startDir (ignored
+// if it does not exist) and kills it after timeout
+func chooseModelFile(ctx context.Context, run commandRunner, startDir string, timeout time.Duration) (filePickResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := []string{"-e", chooseModelScript}
+	if info, err := os.Stat(startDir); err == nil && info.IsDir() {
+		args = append(args, startDir)
+	}
+
+	return runPicker(ctx, run, args)
+}
+
+// chooseSaveScript shows the save dialog in front of other windows. The
+// prompt and the suggested file name are passed as arguments so they never
+// need quoting.
+const chooseSaveScript = `
+on run argv
+	tell application "System Events"
+		activate
+		set theFile to choose file name with prompt (item 1 of argv) default name (item 2 of argv)
+	end tell
+	return POSIX path of theFile
+end run
+`
+
+// chooseSaveFile opens the native save dialog with prompt, suggesting
+// defaultName, and kills it after timeout
+func chooseSaveFile(ctx context.Context, run commandRunner, prompt, defaultName string, timeout time.Duration) (filePickResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return runPicker(ctx, run, []string{"-e", chooseSaveScript, prompt, defaultName})
+}
+
+// runPicker runs an osascript dialog until ctx is done and returns the chosen path
+func runPicker(ctx context.Context, run commandRunner, args []string) (filePickResult, error) {
+	output, err := run(ctx, "osascript", args...)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return filePickResult{Cancelled: true, Reason: PickerTimedOut}, nil
+		}
+		if isUserCancelled(err) {
+			return filePickResult{Cancelled: true, Reason: PickerCancelledByUser}, nil
+		}
+		return filePickResult{}, err
+	}
+
+	return filePickResult{Path: strings.TrimSpace(string(output))}, nil
+}
+
+// isUserCancelled reports whether osascript failed because the user pressed
+// Cancel (AppleScript error -128)
+func isUserCancelled(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	return exitErr.ExitCode() == 128 || strings.Contains(string(exitErr.Stderr), "(-128)")
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+)
+
+// fakeRunner records the command and returns a fixed result
+type fakeRunner struct {
+	name   string
+	args   []string
+	output string
+	err    error
+}
+
+func (f *fakeRunner) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	f.name = name
+	f.args = args
+	return []byte(f.output), f.err
+}
+
+func TestChooseModelFile(t *testing.T) {
+	dir := t.TempDir()
+	runner := &fakeRunner{output: "/Users/test/models/ggml-base.bin\n"}
+
+	result, err := chooseModelFile(context.Background(), runner.run, dir, time.Second)
+	if err != nil {
+		t.Fatalf("chooseModelFile failed: %v", err)
+	}
+
+	if result.Cancelled || result.Path != "/Users/test/models/ggml-base.bin" {
+		t.Errorf("Expected selected path, got %+v", result)
+	}
+
+	if runner.name != "osascript" || len(runner.args) != 3 || runner.args[2] != dir {
+		t.Errorf("Expected osascript with the start directory as argument, got %s %v", runner.name, runner.args)
+	}
+}
+
+func TestChooseModelFile_MissingStartDir(t *testing.T) {
+	runner := &fakeRunner{output: "/tmp/model.gguf"}
+
+	if _, err := chooseModelFile(context.Background(), runner.run, filepath.Join(t.TempDir(), "missing"), time.Second); err != nil {
+		t.Fatalf("chooseModelFile failed: %v", err)
+	}
+
+	if len(runner.args) != 2 {
+		t.Errorf("Expected no start directory argument, got %v", runner.args)
+	}
+}
+
+func TestChooseModelFile_UserCancelled(t *testing.T) {
+	runner := &fakeRunner{err: &exec.ExitError{Stderr: []byte("execution error: User canceled. (-128)")}}
+
+	result, err := chooseModelFile(context.Background(), runner.run, "", time.Second)
+	if err != nil {
+		t.Fatalf("Expected cancel not to be an error, got %v", err)
+	}
+
+	if !result.Cancelled || result.Reason != PickerCancelledByUser {
+		t.Errorf("Expected user cancel, got %+v", result)
+	}
+}
+
+func TestChooseModelFile_Timeout(t *testing.T) {
+	// Blocks like a dialog nobody answers until the context kills it
+	blocking := func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, errors.New("signal: killed")
+	}
+
+	start := time.Now()
+	result, err := chooseModelFile(context.Background(), blocking, "", 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected timeout not to be an error, got %v", err)
+	}
+
+	if !result.Cancelled || result.Reason != PickerTimedOut {
+		t.Errorf("Expected timeout, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the picker to be abandoned after the timeout, took %v", elapsed)
+	}
+}
+
+func TestChooseModelFile_Error(t *testing.T) {
+	runner := &fakeRunner{err: errors.New("osascript not found")}
+
+	if _, err := chooseModelFile(context.Background(), runner.run, "", time.Second); err == nil {
+		t.Error("Expected error when osascript fails")
+	}
+}
+
+func TestHandleModelsBrowse_Timeout(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.SetFilePickerTimeout(20 * time.Millisecond)
+	handler.runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/models/browse", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleModelsBrowse(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response["cancelled"] != true || response["reason"] != PickerTimedOut {
+		t.Errorf("Expected {cancelled: true, reason: timeout}, got %v", response)
+	}
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/history"
+)
+
+const (
+	// defaultHistoryLimit is the page size of GET /api/history without a limit
+	defaultHistoryLimit = 50
+	// maxHistoryLimit bounds the page size of GET /api/history
+	maxHistoryLimit = 500
+)
+
+// HistoryPage is the response of GET /api/history
+type HistoryPage struct {
+	Entries []history.Entry `json:"entries"` // Newest first
+	Total   int             `json:"total"`
+	Offset  int             `json:"offset"`
+	Limit   int             `json:"limit"`
+}
+
+// SetHistory sets the transcription history served by /api/history
+func (h *Handler) SetHistory(store *history.Store) {
+	h.history = store
+}
+
+// handleHistory handles GET and DELETE /api/history
+// GET returns a page of entries (?offset=0&limit=50), newest first. DELETE clears the history.
+func (h *Handler) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.history == nil {
+		http.Error(w, "History not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.history.Clear(); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to clear history: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+		return
+	}
+
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", defaultHistoryLimit)
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		http.Error(w, fmt.Sprintf("Invalid limit (must be between 1 and %d)", maxHistoryLimit), http.StatusBadRequest)
+		return
+	}
+
+	entries, total, err := h.history.List(offset, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []history.Entry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HistoryPage{Entries: entries, Total: total, Offset: offset, Limit: limit})
+}
+
+// handleHistoryEntry handles DELETE /api/history/{id}
+func (h *Handler) handleHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.history == nil {
+		http.Error(w, "History not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/history/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Invalid history entry id", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.history.Delete(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete history entry: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, fmt.Sprintf("History entry not found: %s", id), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "id": id})
+}
+
+// historyDateLayout is the format of the from and to parameters of /api/history/export
+const historyDateLayout = "2006-01-02"
+
+// historyFileName returns the suggested file name of a history export
+func historyFileName(format history.Format, now time.Time) string {
+	return fmt.Sprintf("ezs2t-whisper-history-%s.%s", now.Format("20060102"), format)
+}
+
+// parseHistoryRange parses the from and to dates (local time, inclusive) of
+// an export. An empty date is unbounded.
+func parseHistoryRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if from != "" {
+		t, err := time.ParseInLocation(historyDateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date: %q (expected YYYY-MM-DD)", from)
+		}
+		start = t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation(historyDateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date: %q (expected YYYY-MM-DD)", to)
+		}
+		// Include the whole last day
+		end = t.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// exportHistory renders the entries between from and to (zero = unbounded) in format
+func (h *Handler) exportHistory(format history.Format, from, to time.Time) ([]byte, error) {
+	entries, err := h.history.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := history.Export(&buf, history.Filter(entries, from, to), format, time.Local); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// handleHistoryExport handles GET /api/history/export?format=md&from=2026-01-01&to=2026-01-31
+// It returns the history as a Markdown (default), plain-text or JSON document
+// grouped by day. from and to are inclusive local dates and may be omitted.
+func (h *Handler) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.history == nil {
+		http.Error(w, "History not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	format, err := history.ParseFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseHistoryRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.exportHistory(format, from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to export history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", historyFileName(format, time.Now())))
+	w.Write(data)
+}
+
+// SaveHistoryExport asks for a destination with the native save dialog and
+// writes the whole history there as a Markdown digest. It returns the saved
+// path, or "" when the user cancelled or the dialog timed out.
+func (h *Handler) SaveHistoryExport(ctx context.Context) (string, error) {
+	if h.history == nil {
+		return "", fmt.Errorf("history not available")
+	}
+
+	// Render first so a read error does not leave an empty file behind
+	data, err := h.exportHistory(history.FormatMarkdown, time.Time{}, time.Time{})
+	if err != nil {
+		return "", err
+	}
+
+	result, err := chooseSaveFile(ctx, h.runCommand, "履歴の保存先を選択してください", historyFileName(history.FormatMarkdown, time.Now()), h.pickerTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if result.Cancelled {
+		return "", nil
+	}
+
+	path := result.Path
+	if !strings.HasSuffix(strings.ToLower(path), ".md") {
+		path += ".md"
+	}
+
+	// Only the user can read the file, like the history itself
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// queryInt returns the integer query parameter name, or fallback when it is absent
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
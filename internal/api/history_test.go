@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/history"
+)
+
+// newHistoryHandler returns a handler serving a history with the given texts, oldest first
+func newHistoryHandler(t *testing.T, texts ...string) (*Handler, []history.Entry) {
+	t.Helper()
+
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	var entries []history.Entry
+	for _, text := range texts {
+		entry, err := store.Add(history.Entry{Text: text, Model: "ggml-base.bin"}, 0)
+		if err != nil {
+			t.Fatalf("Failed to add history entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.SetHistory(store)
+	return handler, entries
+}
+
+func TestHandleHistory(t *testing.T) {
+	handler, _ := newHistoryHandler(t, "一", "二", "三")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/history?offset=1&limit=1", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleHistory(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var page HistoryPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if page.Total != 3 || page.Offset != 1 || page.Limit != 1 {
+		t.Errorf("Unexpected page %+v", page)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].Text != "二" {
+		t.Errorf("Expected the second newest entry, got %+v", page.Entries)
+	}
+}
+
+func TestHandleHistory_InvalidPage(t *testing.T) {
+	handler, _ := newHistoryHandler(t)
+
+	for _, query := range []string{"?limit=0", "?limit=1000", "?offset=-1", "?offset=x"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/history"+query, nil)
+		w := httptest.NewRecorder()
+
+		handler.handleHistory(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestHandleHistory_Clear(t *testing.T) {
+	handler, _ := newHistoryHandler(t, "一", "二")
+
+	w := httptest.NewRecorder()
+	handler.handleHistory(w, httptest.NewRequest(http.MethodDelete, "/api/history", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	if _, total, _ := handler.history.List(0, 10); total != 0 {
+		t.Errorf("Expected the history to be cleared, got %d entries", total)
+	}
+}
+
+func TestHandleHistoryEntry_Delete(t *testing.T) {
+	handler, entries := newHistoryHandler(t, "一", "二")
+
+	w := httptest.NewRecorder()
+	handler.handleHistoryEntry(w, httptest.NewRequest(http.MethodDelete, "/api/history/"+entries[0].ID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if remaining, total, _ := handler.history.List(0, 10); total != 1 || remaining[0].ID != entries[1].ID {
+		t.Errorf("Expected only the other entry to remain, got %+v", remaining)
+	}
+
+	// Deleting it again finds nothing
+	w = httptest.NewRecorder()
+	handler.handleHistoryEntry(w, httptest.NewRequest(http.MethodDelete, "/api/history/"+entries[0].ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestHandleHistoryExport(t *testing.T) {
+	handler, _ := newHistoryHandler(t, "一", "二")
+	today := time.Now().Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/history/export?format=txt&from="+today+"&to="+today, nil)
+	w := httptest.NewRecorder()
+	handler.handleHistoryExport(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected plain text, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, ".txt") {
+		t.Errorf("Expected a .txt download, got %q", cd)
+	}
+	if body := w.Body.String(); !strings.Contains(body, today) || !strings.Contains(body, "一") || !strings.Contains(body, "二") {
+		t.Errorf("Expected today's entries, got %q", body)
+	}
+
+	// A range before today is empty
+	req = httptest.NewRequest(http.MethodGet, "/api/history/export?format=json&to="+yesterday, nil)
+	w = httptest.NewRecorder()
+	handler.handleHistoryExport(w, req)
+
+	var entries []history.Entry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no entries before today, got %v (err=%v)", entries, err)
+	}
+}
+
+func TestHandleHistoryExport_InvalidQuery(t *testing.T) {
+	handler, _ := newHistoryHandler(t)
+
+	for _, query := range []string{"?format=pdf", "?from=yesterday", "?to=2026-13-01"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/history/export"+query, nil)
+		w := httptest.NewRecorder()
+		handler.handleHistoryExport(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestSaveHistoryExport(t *testing.T) {
+	handler, _ := newHistoryHandler(t, "保存する履歴")
+
+	// The extension is added when the user leaves it out
+	path := filepath.Join(t.TempDir(), "history")
+	runner := &fakeRunner{output: path + "\n"}
+	handler.runCommand = runner.run
+
+	saved, err := handler.SaveHistoryExport(context.Background())
+	if err != nil {
+		t.Fatalf("SaveHistoryExport failed: %v", err)
+	}
+	if saved != path+".md" {
+		t.Errorf("Expected %s.md, got %s", path, saved)
+	}
+	if len(runner.args) != 4 || runner.args[2] != "履歴の保存先を選択してください" {
+		t.Errorf("Expected the history prompt to be passed to the dialog, got %v", runner.args)
+	}
+
+	data, err := os.ReadFile(saved)
+	if err != nil || !strings.Contains(string(data), "保存する履歴") {
+		t.Errorf("Expected the Markdown digest, got %q (err=%v)", data, err)
+	}
+}
+
+func TestHandleHistory_NotAvailable(t *testing.T) {
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	handler.handleHistory(w, httptest.NewRequest(http.MethodGet, "/api/history", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
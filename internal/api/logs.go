@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/logger"
+)
+
+// MaxLogEventsPerSecond caps /api/logs/stream so a burst of log output cannot flood the browser
+const MaxLogEventsPerSecond = 50
+
+// logStreamBuffer is how many entries may queue up for a slow client before the logger drops them
+const logStreamBuffer = 256
+
+// LogSource delivers log entries as they are written (implemented by *logger.Logger)
+type LogSource interface {
+	Subscribe(buffer int) (<-chan logger.Entry, func())
+}
+
+// RecentLogSource is a LogSource that also keeps the latest entries, which
+// /api/logs/stream replays on connect with ?tail=N (implemented by *logger.Logger)
+type RecentLogSource interface {
+	Recent(n int) []logger.Entry
+}
+
+// SetLogSource sets where /api/logs/stream takes its entries from
+func (h *Handler) SetLogSource(source LogSource) {
+	h.logSource = source
+}
+
+// handleLogStream handles GET /api/logs/stream?level=warn&tail=100
+// It streams Server-Sent Events: a "log" event ({"time","level","message"}) for
+// each entry at or above level (default INFO), starting with up to tail of the
+// latest entries (default 0, at most logger.RecentEntries) so the viewer does
+// not open empty. Entries come from the logger itself, so the stream survives
+// log file rotation. Beyond MaxLogEventsPerSecond entries are skipped and
+// reported once per second with a "dropped" event ({"count": n}).
+func (h *Handler) handleLogStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.logSource == nil {
+		http.Error(w, "Log stream not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	minLevel := logger.INFO
+	if name := r.URL.Query().Get("level"); name != "" {
+		level, err := logger.ParseLevel(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		minLevel = level
+	}
+
+	tail := 0
+	if value := r.URL.Query().Get("tail"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("Invalid tail: %q", value), http.StatusBadRequest)
+			return
+		}
+		tail = min(n, logger.RecentEntries)
+	}
+
+	// The stream stays open far longer than the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	send := func(event string, payload interface{}) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	ctx := r.Context()
+	if h.streams != nil {
+		var done func()
+		ctx, done = h.streams.Register(ctx)
+		defer done()
+	}
+
+	entries, unsubscribe := h.logSource.Subscribe(logStreamBuffer)
+	defer unsubscribe()
+
+	// Send the headers right away so the client knows the stream is open
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	// Replay the latest entries; the subscription above already holds anything
+	// written since, so entries up to the last replayed one are skipped below.
+	// They are compared by Seq: several entries can share a timestamp.
+	var replayedUntil uint64
+	if recent, ok := h.logSource.(RecentLogSource); ok && tail > 0 {
+		for _, entry := range recent.Recent(tail) {
+			replayedUntil = entry.Seq
+			if entry.Level < minLevel {
+				continue
+			}
+			if err := send("log", entry); err != nil {
+				return
+			}
+		}
+	}
+
+	window := time.NewTicker(time.Second)
+	defer window.Stop()
+	sent, dropped := 0, 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-window.C:
+			if dropped > 0 {
+				if err := send("dropped", map[string]int{"count": dropped}); err != nil {
+					return
+				}
+			}
+			sent, dropped = 0, 0
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			if entry.Level < minLevel || entry.Seq <= replayedUntil {
+				continue
+			}
+			if sent >= MaxLogEventsPerSecond {
+				dropped++
+				continue
+			}
+			sent++
+			if err := send("log", entry); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/logger"
+)
+
+// trackedLogSource counts the open subscriptions of a real logger
+type trackedLogSource struct {
+	*logger.Logger
+	active atomic.Int32
+}
+
+func (s *trackedLogSource) Subscribe(buffer int) (<-chan logger.Entry, func()) {
+	entries, cancel := s.Logger.Subscribe(buffer)
+	s.active.Add(1)
+	return entries, func() {
+		s.active.Add(-1)
+		cancel()
+	}
+}
+
+// sseEvent is one event read from a Server-Sent Events stream
+type sseEvent struct {
+	name string
+	data string
+}
+
+// openLogStream connects to /api/logs/stream and returns its events
+func openLogStream(t *testing.T, source LogSource, query string) (<-chan sseEvent, func()) {
+	t.Helper()
+
+	handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+	handler.SetLogSource(source)
+	server := httptest.NewServer(http.HandlerFunc(handler.handleLogStream))
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/logs/stream" + query)
+	if err != nil {
+		t.Fatalf("Failed to connect to log stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	events := make(chan sseEvent, 100)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.data = strings.TrimPrefix(line, "data: ")
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+
+	return events, func() { resp.Body.Close() }
+}
+
+// nextEvent waits for the next event on the stream
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Log stream closed unexpectedly")
+		}
+		return event
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for a log event")
+	}
+	return sseEvent{}
+}
+
+func TestHandleLogStream(t *testing.T) {
+	log, err := logger.New(logger.Config{LogDir: t.TempDir(), Level: logger.DEBUG, RetentionDays: 1})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+	source := &trackedLogSource{Logger: log}
+
+	events, disconnect := openLogStream(t, source, "?level=warn")
+
+	// The subscription is made before the headers are sent
+	log.Info("filtered out")
+	log.Warn("device %d busy", 3)
+
+	event := nextEvent(t, events)
+	if event.name != "log" {
+		t.Fatalf("Expected a log event, got %q", event.name)
+	}
+
+	var entry struct {
+		Time    time.Time `json:"time"`
+		Level   string    `json:"level"`
+		Message string    `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(event.data), &entry); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	if entry.Level != "WARN" || entry.Message != "device 3 busy" || entry.Time.IsZero() {
+		t.Errorf("Expected the WARN entry, got %+v", entry)
+	}
+
+	// Disconnecting ends the subscription
+	disconnect()
+	deadline := time.Now().Add(2 * time.Second)
+	for source.active.Load() != 0 && time.Now().Before(deadline) {
+		log.Warn("keep the stream writing")
+		time.Sleep(10 * time.Millisecond)
+	}
+	if active := source.active.Load(); active != 0 {
+		t.Errorf("Expected the subscription to end on disconnect, %d still open", active)
+	}
+}
+
+func TestHandleLogStream_Tail(t *testing.T) {
+	log, err := logger.New(logger.Config{LogDir: t.TempDir(), Level: logger.DEBUG, RetentionDays: 1})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	log.Info("before connecting 1")
+	log.Debug("filtered out")
+	log.Info("before connecting 2")
+
+	events, disconnect := openLogStream(t, log, "?tail=2")
+	defer disconnect()
+
+	// The latest two entries are replayed, then the level filter applies as usual
+	if event := nextEvent(t, events); !strings.Contains(event.data, "before connecting 2") {
+		t.Errorf("Expected the last INFO entry to be replayed, got %s", event.data)
+	}
+
+	log.Info("after connecting")
+	if event := nextEvent(t, events); !strings.Contains(event.data, "after connecting") {
+		t.Errorf("Expected the new entry after the replay, got %s", event.data)
+	}
+}
+
+// replayLogSource replays recent and delivers live to every subscriber
+type replayLogSource struct {
+	recent []logger.Entry
+	live   []logger.Entry
+}
+
+func (s *replayLogSource) Subscribe(buffer int) (<-chan logger.Entry, func()) {
+	entries := make(chan logger.Entry, len(s.live))
+	for _, entry := range s.live {
+		entries <- entry
+	}
+	return entries, func() {}
+}
+
+func (s *replayLogSource) Recent(n int) []logger.Entry {
+	return s.recent[max(len(s.recent)-n, 0):]
+}
+
+func TestHandleLogStream_TailSameTimestamp(t *testing.T) {
+	// Entries written within the clock resolution share a timestamp
+	now := time.Now()
+	source := &replayLogSource{
+		recent: []logger.Entry{
+			{Seq: 1, Time: now, Level: logger.INFO, Message: "replayed 1"},
+			{Seq: 2, Time: now, Level: logger.INFO, Message: "replayed 2"},
+		},
+		live: []logger.Entry{
+			{Seq: 2, Time: now, Level: logger.INFO, Message: "replayed 2"},
+			{Seq: 3, Time: now, Level: logger.INFO, Message: "live"},
+		},
+	}
+
+	events, disconnect := openLogStream(t, source, "?tail=2")
+	defer disconnect()
+
+	// The live copy of the last replayed entry is skipped, the new entry is not
+	for _, expected := range []string{"replayed 1", "replayed 2", "live"} {
+		if event := nextEvent(t, events); !strings.Contains(event.data, `"`+expected+`"`) {
+			t.Errorf("Expected %q, got %s", expected, event.data)
+		}
+	}
+}
+
+func TestHandleLogStream_RateLimit(t *testing.T) {
+	log, err := logger.New(logger.Config{LogDir: t.TempDir(), Level: logger.DEBUG, RetentionDays: 1})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	events, disconnect := openLogStream(t, log, "")
+	defer disconnect()
+
+	for i := 0; i < MaxLogEventsPerSecond+10; i++ {
+		log.Info("burst %d", i)
+	}
+
+	for i := 0; i < MaxLogEventsPerSecond; i++ {
+		if event := nextEvent(t, events); event.name != "log" {
+			t.Fatalf("Expected log event %d, got %q", i, event.name)
+		}
+	}
+
+	event := nextEvent(t, events)
+	if event.name != "dropped" || event.data != `{"count":10}` {
+		t.Errorf("Expected 10 dropped entries to be reported, got %s %s", event.name, event.data)
+	}
+}
+
+func TestHandleLogStream_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   LogSource
+		method   string
+		query    string
+		expected int
+	}{
+		{"no source", nil, http.MethodGet, "", http.StatusServiceUnavailable},
+		{"unknown level", &trackedLogSource{}, http.MethodGet, "?level=verbose", http.StatusBadRequest},
+		{"invalid tail", &trackedLogSource{}, http.MethodGet, "?tail=-1", http.StatusBadRequest},
+		{"wrong method", &trackedLogSource{}, http.MethodPost, "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := New(config.DefaultConfig(), nil, nil, nil, nil)
+			if tt.source != nil {
+				handler.SetLogSource(tt.source)
+			}
+
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("/api/logs/stream%s", tt.query), nil)
+			w := httptest.NewRecorder()
+			handler.handleLogStream(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
@@ -1,10 +1,17 @@
 package audio
 
+import (
+	"math"
+	"time"
+)
+
 // Device represents an audio input device
 type Device struct {
-	ID        int
-	Name      string
-	IsDefault bool
+	ID                int
+	Name              string
+	IsDefault         bool
+	DefaultSampleRate int // Native sample rate reported by the device, 0 if unknown
+	MaxInputChannels  int // Number of input channels, 0 if unknown
 }
 
 // LatencyMode defines the latency priority
@@ -38,6 +45,24 @@ func DefaultConfig() Config {
 	}
 }
 
+// Duration returns the length of 16-bit PCM recorded with c
+func (c Config) Duration(pcm []byte) time.Duration {
+	bytesPerSecond := c.SampleRate * max(c.Channels, 1) * 2
+	if bytesPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(len(pcm)) * time.Second / time.Duration(bytesPerSecond)
+}
+
+// SamplesDuration returns the length of n interleaved samples recorded with c
+func (c Config) SamplesDuration(n int) time.Duration {
+	samplesPerSecond := c.SampleRate * max(c.Channels, 1)
+	if samplesPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second / time.Duration(samplesPerSecond)
+}
+
 // AudioDriver is the interface for audio input
 // This abstraction allows for future replacement of PortAudio with other libraries (e.g., miniaudio)
 type AudioDriver interface {
@@ -50,12 +75,106 @@ type AudioDriver interface {
 	// StartRecording starts recording audio
 	StartRecording() error
 
-	// StopRecording stops recording and returns the recorded audio data (PCM format)
+	// StopRecording stops recording and returns the recorded audio data (PCM format, see RecordingFormat)
 	StopRecording() ([]byte, error)
 
 	// IsRecording returns whether recording is currently active
 	IsRecording() bool
 
+	// CurrentDevice returns the device the driver was last initialized with.
+	// When the configuration asks for the system default, this is the actual default device.
+	CurrentDevice() (Device, error)
+
 	// Close releases all resources
 	Close() error
 }
+
+// StreamInfo describes the input stream actually opened by the driver
+type StreamInfo struct {
+	DeviceName          string `json:"device_name"`
+	RequestedSampleRate int    `json:"requested_sample_rate"`
+	EffectiveSampleRate int    `json:"effective_sample_rate"`
+	Resampling          bool   `json:"resampling"` // Recorded audio is resampled to the requested rate before transcription
+}
+
+// SampleRateMismatch reports whether the device opened at a different rate than requested
+func (s StreamInfo) SampleRateMismatch() bool {
+	return s.EffectiveSampleRate != 0 && s.EffectiveSampleRate != s.RequestedSampleRate
+}
+
+// StreamInfoProvider is implemented by drivers that can report details of the opened stream
+type StreamInfoProvider interface {
+	// StreamInfo returns information about the current stream (zero value if not initialized)
+	StreamInfo() StreamInfo
+}
+
+// RecordingFormat returns the format of the audio StopRecording of driver,
+// initialized with config, returns: config at the effective sample rate when
+// the driver reports one. Recordings are not resampled by the driver; the
+// pipeline converts them to the requested format before transcription.
+func RecordingFormat(driver AudioDriver, config Config) Config {
+	if provider, ok := driver.(StreamInfoProvider); ok {
+		if rate := provider.StreamInfo().EffectiveSampleRate; rate > 0 {
+			config.SampleRate = rate
+		}
+	}
+	return config
+}
+
+// ChunkNotifier is implemented by drivers that can pass on the audio of the
+// running recording as it arrives, e.g. for transcribing while recording
+type ChunkNotifier interface {
+	// SetChunkHandler sets a function called with each new chunk of 16-bit
+	// little-endian PCM at the configured sample rate while recording. It is
+	// called from the audio callback and must not block. nil removes it.
+	SetChunkHandler(handler func(pcm []byte))
+}
+
+// Float32Recorder is implemented by drivers that can return the recording as
+// float32 samples directly, sparing callers that feed the recognizer the
+// conversion to 16-bit PCM and back
+type Float32Recorder interface {
+	// StopRecordingFloat32 stops recording like StopRecording and returns the
+	// interleaved samples normalized to [-1, 1]
+	StopRecordingFloat32() ([]float32, error)
+}
+
+// EncodePCM converts samples to 16-bit little-endian PCM
+func EncodePCM(samples []int16) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		data[i*2] = byte(sample)
+		data[i*2+1] = byte(sample >> 8)
+	}
+	return data
+}
+
+// PCMToFloat32 converts 16-bit little-endian PCM to samples normalized to [-1, 1]
+func PCMToFloat32(pcm []byte) []float32 {
+	samples := make([]float32, len(pcm)/2)
+	for i := range samples {
+		samples[i] = float32(int16(uint16(pcm[i*2])|uint16(pcm[i*2+1])<<8)) / 32768.0
+	}
+	return samples
+}
+
+// Int16ToFloat32 converts 16-bit samples to samples normalized to [-1, 1]
+func Int16ToFloat32(samples []int16) []float32 {
+	out := make([]float32, len(samples))
+	for i, sample := range samples {
+		out[i] = float32(sample) / 32768.0
+	}
+	return out
+}
+
+// Float32ToPCM converts normalized samples to 16-bit little-endian PCM,
+// clamping values outside [-1, 1]
+func Float32ToPCM(samples []float32) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		v := int16(max(min(float64(sample)*32768, math.MaxInt16), math.MinInt16))
+		data[i*2] = byte(v)
+		data[i*2+1] = byte(v >> 8)
+	}
+	return data
+}
@@ -1,7 +1,9 @@
 package audio
 
 import (
+	"bytes"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -24,6 +26,69 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestConfigDuration(t *testing.T) {
+	config := DefaultConfig()
+
+	// 16kHz mono 16-bit: 32000 bytes per second
+	if got := config.Duration(make([]byte, 48000)); got != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s, got %v", got)
+	}
+
+	config.Channels = 2
+	if got := config.Duration(make([]byte, 64000)); got != time.Second {
+		t.Errorf("Expected 1s of stereo, got %v", got)
+	}
+
+	if got := (Config{}).Duration(make([]byte, 100)); got != 0 {
+		t.Errorf("Expected 0 without a sample rate, got %v", got)
+	}
+}
+
+// streamInfoDriver is a driver that only reports stream details
+type streamInfoDriver struct {
+	AudioDriver
+	info StreamInfo
+}
+
+func (d streamInfoDriver) StreamInfo() StreamInfo { return d.info }
+
+func TestFloat32RoundTrip(t *testing.T) {
+	pcm := EncodePCM([]int16{0, 16384, -16384, 32767, -32768})
+
+	samples := PCMToFloat32(pcm)
+	if samples[1] != 0.5 || samples[2] != -0.5 || samples[4] != -1 {
+		t.Errorf("Unexpected normalized samples: %v", samples)
+	}
+
+	if got := Float32ToPCM(samples); !bytes.Equal(got, pcm) {
+		t.Errorf("Expected round trip to keep PCM, got %v, want %v", got, pcm)
+	}
+
+	// Out-of-range samples are clamped instead of wrapping around
+	clamped := PCMToFloat32(Float32ToPCM([]float32{1.5, -1.5}))
+	if clamped[0] < 0.99 || clamped[1] != -1 {
+		t.Errorf("Expected clamped samples, got %v", clamped)
+	}
+}
+
+func TestRecordingFormat(t *testing.T) {
+	config := DefaultConfig()
+
+	// A device that opened at its native rate returns recordings at that rate
+	driver := streamInfoDriver{info: StreamInfo{RequestedSampleRate: 16000, EffectiveSampleRate: 48000, Resampling: true}}
+	if got := RecordingFormat(driver, config); got.SampleRate != 48000 || got.Channels != 1 {
+		t.Errorf("Expected 48kHz mono, got %d Hz/%d channels", got.SampleRate, got.Channels)
+	}
+
+	// Without stream details the requested format is assumed
+	if got := RecordingFormat(streamInfoDriver{}, config); got.SampleRate != 16000 {
+		t.Errorf("Expected the requested 16kHz, got %d Hz", got.SampleRate)
+	}
+	if got := RecordingFormat(nil, config); got.SampleRate != 16000 {
+		t.Errorf("Expected the requested 16kHz for no driver, got %d Hz", got.SampleRate)
+	}
+}
+
 func TestNewPortAudioDriver(t *testing.T) {
 	driver, err := NewPortAudioDriver()
 	if err != nil {
@@ -0,0 +1,111 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Start cue played through the default output device when recording begins
+const (
+	// StartBeepDuration is the length of the rising start tone
+	StartBeepDuration = 120 * time.Millisecond
+	// StartBeepGuard is the beginning of the recording muted after the cue, so a
+	// built-in microphone picking up the tone does not feed it to the recognizer.
+	// It is longer than the tone to cover output latency and room echo.
+	StartBeepGuard = 200 * time.Millisecond
+
+	startBeepFrom      = 660.0 // Hz
+	startBeepTo        = 990.0 // Hz
+	startBeepAmplitude = 0.25
+	startBeepRate      = 44100
+)
+
+// RisingTone generates a mono sine sweep from one frequency to another.
+// The first and last few milliseconds are faded to avoid clicks.
+func RisingTone(from, to float64, duration time.Duration, sampleRate int, amplitude float64) []int16 {
+	n := int(duration.Seconds() * float64(sampleRate))
+	fade := min(sampleRate*5/1000, n/2)
+
+	samples := make([]int16, n)
+	phase := 0.0
+	for i := range samples {
+		frequency := from + (to-from)*float64(i)/float64(n)
+		phase += 2 * math.Pi * frequency / float64(sampleRate)
+
+		gain := amplitude
+		if i < fade {
+			gain *= float64(i) / float64(fade)
+		} else if i >= n-fade {
+			gain *= float64(n-1-i) / float64(fade)
+		}
+
+		samples[i] = int16(gain * math.Sin(phase) * math.MaxInt16)
+	}
+	return samples
+}
+
+// EncodeWAV encodes mono 16-bit samples as a PCM WAV file
+func EncodeWAV(samples []int16, sampleRate int) []byte {
+	var buf bytes.Buffer
+	writeWAVHeader(&buf, len(samples)*2, sampleRate, 1)
+	binary.Write(&buf, binary.LittleEndian, samples)
+
+	return buf.Bytes()
+}
+
+// MuteLeading zeroes the first duration of interleaved 16-bit little-endian PCM
+// in place and returns it. The length is kept so timing is unchanged.
+func MuteLeading(pcm []byte, sampleRate, channels int, duration time.Duration) []byte {
+	n := int(duration.Seconds()*float64(sampleRate)) * max(channels, 1) * 2
+	clear(pcm[:min(n, len(pcm))])
+	return pcm
+}
+
+// MuteLeadingFloat32 is MuteLeading for interleaved float32 samples
+func MuteLeadingFloat32(samples []float32, sampleRate, channels int, duration time.Duration) []float32 {
+	n := int(duration.Seconds()*float64(sampleRate)) * max(channels, 1)
+	clear(samples[:min(n, len(samples))])
+	return samples
+}
+
+// BeepPlayer plays the start cue with afplay. The WAV file is written to the
+// temporary directory on first use and reused afterwards.
+type BeepPlayer struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+// NewBeepPlayer creates a player for the start cue
+func NewBeepPlayer() *BeepPlayer {
+	return &BeepPlayer{}
+}
+
+// Play starts the cue and returns immediately without waiting for it to finish
+func (p *BeepPlayer) Play() error {
+	p.once.Do(func() {
+		p.path = filepath.Join(os.TempDir(), "ezs2t-whisper-start-beep.wav")
+		tone := RisingTone(startBeepFrom, startBeepTo, StartBeepDuration, startBeepRate, startBeepAmplitude)
+		if err := os.WriteFile(p.path, EncodeWAV(tone, startBeepRate), 0644); err != nil {
+			p.err = fmt.Errorf("failed to write start beep: %w", err)
+		}
+	})
+	if p.err != nil {
+		return p.err
+	}
+
+	cmd := exec.Command("afplay", p.path)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to play start beep: %w", err)
+	}
+	go cmd.Wait()
+
+	return nil
+}
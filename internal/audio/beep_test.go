@@ -0,0 +1,68 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestRisingTone(t *testing.T) {
+	samples := RisingTone(660, 990, StartBeepDuration, 16000, 0.25)
+
+	if len(samples) != 1920 {
+		t.Fatalf("Expected 1920 samples for 120ms at 16kHz, got %d", len(samples))
+	}
+
+	// Faded in and out to avoid clicks
+	if samples[0] != 0 || samples[len(samples)-1] != 0 {
+		t.Errorf("Expected silent edges, got %d and %d", samples[0], samples[len(samples)-1])
+	}
+
+	var peak int16
+	for _, s := range samples {
+		peak = max(peak, s)
+	}
+	if peak < 7000 || peak > 8300 {
+		t.Errorf("Expected peak near 0.25 full scale, got %d", peak)
+	}
+}
+
+func TestEncodeWAV(t *testing.T) {
+	data := EncodeWAV([]int16{1, -1, 1000}, 44100)
+
+	if len(data) != 44+6 {
+		t.Fatalf("Expected 50 bytes, got %d", len(data))
+	}
+	if !bytes.Equal(data[0:4], []byte("RIFF")) || !bytes.Equal(data[8:12], []byte("WAVE")) {
+		t.Error("Expected RIFF/WAVE header")
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != 44100 {
+		t.Errorf("Expected sample rate 44100, got %d", rate)
+	}
+	if last := int16(binary.LittleEndian.Uint16(data[48:50])); last != 1000 {
+		t.Errorf("Expected last sample 1000, got %d", last)
+	}
+}
+
+func TestMuteLeading(t *testing.T) {
+	pcm := bytes.Repeat([]byte{0x10, 0x20}, 16000) // 1 second at 16kHz mono
+
+	muted := MuteLeading(pcm, 16000, 1, 200*time.Millisecond)
+
+	if len(muted) != len(pcm) {
+		t.Fatalf("Expected length to be kept, got %d", len(muted))
+	}
+	if !bytes.Equal(muted[:6400], make([]byte, 6400)) {
+		t.Error("Expected first 200ms to be muted")
+	}
+	if muted[6400] != 0x10 {
+		t.Error("Expected audio after the guard to be kept")
+	}
+
+	// Shorter than the guard: everything is muted without panicking
+	short := MuteLeading([]byte{1, 2, 3, 4}, 16000, 1, 200*time.Millisecond)
+	if !bytes.Equal(short, make([]byte, 4)) {
+		t.Errorf("Expected short recording to be fully muted, got %v", short)
+	}
+}
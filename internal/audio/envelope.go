@@ -0,0 +1,96 @@
+package audio
+
+import (
+	"math"
+)
+
+// DefaultEnvelopeBuckets is the number of min/max pairs used for waveform previews
+const DefaultEnvelopeBuckets = 200
+
+// SilencePeakThreshold is the normalized peak amplitude (about -60 dBFS) below which
+// a whole recording is treated as coming from a muted or dead microphone
+const SilencePeakThreshold = 0.001
+
+// Envelope is a downsampled amplitude summary of a recording for visualization.
+// Values are normalized to [-1, 1] and the size depends only on the bucket count,
+// not on the recording length.
+type Envelope struct {
+	Min  []float32 `json:"min"`  // Minimum sample per bucket
+	Max  []float32 `json:"max"`  // Maximum sample per bucket
+	Peak float64   `json:"peak"` // Maximum absolute amplitude over the whole recording
+	RMS  float64   `json:"rms"`  // Root mean square amplitude over the whole recording
+}
+
+// ComputeEnvelope summarizes 16-bit little-endian PCM into at most buckets min/max pairs
+func ComputeEnvelope(pcm []byte, buckets int) Envelope {
+	numSamples := len(pcm) / 2
+	if numSamples == 0 || buckets <= 0 {
+		return Envelope{Min: []float32{}, Max: []float32{}}
+	}
+
+	if buckets > numSamples {
+		buckets = numSamples
+	}
+
+	envelope := Envelope{
+		Min: make([]float32, buckets),
+		Max: make([]float32, buckets),
+	}
+
+	var sumSquares float64
+	for b := 0; b < buckets; b++ {
+		start := b * numSamples / buckets
+		end := (b + 1) * numSamples / buckets
+
+		lo, hi := float32(1), float32(-1)
+		for i := start; i < end; i++ {
+			v := float32(int16(uint16(pcm[i*2])|uint16(pcm[i*2+1])<<8)) / 32768.0
+			lo = min(lo, v)
+			hi = max(hi, v)
+
+			abs := math.Abs(float64(v))
+			envelope.Peak = max(envelope.Peak, abs)
+			sumSquares += float64(v) * float64(v)
+		}
+
+		envelope.Min[b] = lo
+		envelope.Max[b] = hi
+	}
+
+	envelope.RMS = math.Sqrt(sumSquares / float64(numSamples))
+	return envelope
+}
+
+// IsSilent reports whether a non-empty 16-bit little-endian PCM recording never
+// exceeds SilencePeakThreshold, which indicates a muted or dead microphone.
+// Empty recordings are not reported as silent.
+func IsSilent(pcm []byte) bool {
+	numSamples := len(pcm) / 2
+	if numSamples == 0 {
+		return false
+	}
+
+	const limit = SilencePeakThreshold * 32768
+	for i := 0; i < numSamples; i++ {
+		v := float64(int16(uint16(pcm[i*2]) | uint16(pcm[i*2+1])<<8))
+		if math.Abs(v) > limit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSilentFloat32 is IsSilent for samples normalized to [-1, 1]
+func IsSilentFloat32(samples []float32) bool {
+	if len(samples) == 0 {
+		return false
+	}
+
+	for _, sample := range samples {
+		if math.Abs(float64(sample)) > SilencePeakThreshold {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// pcmFromSamples encodes samples as 16-bit little-endian PCM
+func pcmFromSamples(samples []int16) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(s))
+	}
+	return data
+}
+
+func TestComputeEnvelope_Silence(t *testing.T) {
+	envelope := ComputeEnvelope(pcmFromSamples(make([]int16, 16000)), DefaultEnvelopeBuckets)
+
+	if len(envelope.Min) != DefaultEnvelopeBuckets || len(envelope.Max) != DefaultEnvelopeBuckets {
+		t.Fatalf("Expected %d buckets, got %d/%d", DefaultEnvelopeBuckets, len(envelope.Min), len(envelope.Max))
+	}
+
+	if envelope.Peak != 0 || envelope.RMS != 0 {
+		t.Errorf("Expected zero peak and RMS for silence, got %f/%f", envelope.Peak, envelope.RMS)
+	}
+}
+
+func TestComputeEnvelope_Sine(t *testing.T) {
+	const amplitude = 0.5
+	samples := make([]int16, 16000)
+	for i := range samples {
+		samples[i] = int16(amplitude * 32767 * math.Sin(2*math.Pi*440*float64(i)/16000))
+	}
+
+	envelope := ComputeEnvelope(pcmFromSamples(samples), DefaultEnvelopeBuckets)
+
+	if math.Abs(envelope.Peak-amplitude) > 0.01 {
+		t.Errorf("Expected peak ~%f, got %f", amplitude, envelope.Peak)
+	}
+
+	// RMS of a sine wave is amplitude / sqrt(2)
+	if expected := amplitude / math.Sqrt2; math.Abs(envelope.RMS-expected) > 0.01 {
+		t.Errorf("Expected RMS ~%f, got %f", expected, envelope.RMS)
+	}
+
+	// Each bucket spans several periods, so it should reach both extremes
+	for b := range envelope.Min {
+		if envelope.Min[b] > -0.45 || envelope.Max[b] < 0.45 {
+			t.Errorf("Bucket %d: expected full swing, got [%f, %f]", b, envelope.Min[b], envelope.Max[b])
+			break
+		}
+	}
+}
+
+func TestComputeEnvelope_SizeIndependentOfLength(t *testing.T) {
+	long := pcmFromSamples(make([]int16, 16000*60)) // 60 seconds
+	envelope := ComputeEnvelope(long, DefaultEnvelopeBuckets)
+
+	if len(envelope.Min) != DefaultEnvelopeBuckets {
+		t.Errorf("Expected %d buckets for a long recording, got %d", DefaultEnvelopeBuckets, len(envelope.Min))
+	}
+}
+
+func TestComputeEnvelope_ShortRecording(t *testing.T) {
+	envelope := ComputeEnvelope(pcmFromSamples([]int16{16384, -16384, 0}), DefaultEnvelopeBuckets)
+
+	if len(envelope.Min) != 3 {
+		t.Fatalf("Expected one bucket per sample when shorter than bucket count, got %d", len(envelope.Min))
+	}
+
+	if envelope.Max[0] != 0.5 || envelope.Min[1] != -0.5 {
+		t.Errorf("Unexpected bucket values: min=%v max=%v", envelope.Min, envelope.Max)
+	}
+}
+
+func TestComputeEnvelope_Empty(t *testing.T) {
+	envelope := ComputeEnvelope(nil, DefaultEnvelopeBuckets)
+
+	if envelope.Min == nil || len(envelope.Min) != 0 {
+		t.Error("Expected empty (non-nil) envelope for empty input")
+	}
+}
+
+func TestIsSilent(t *testing.T) {
+	if !IsSilent(pcmFromSamples(make([]int16, 16000))) {
+		t.Error("Expected all-zero recording to be silent")
+	}
+
+	// Tiny noise below the threshold is still treated as a muted microphone
+	if !IsSilent(pcmFromSamples([]int16{3, -5, 8, -2})) {
+		t.Error("Expected near-zero recording to be silent")
+	}
+
+	samples := make([]int16, 16000)
+	samples[8000] = 1000
+	if IsSilent(pcmFromSamples(samples)) {
+		t.Error("Expected recording with a single loud sample not to be silent")
+	}
+
+	if IsSilent(nil) {
+		t.Error("Expected empty recording not to be reported as silent")
+	}
+}
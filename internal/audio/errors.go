@@ -0,0 +1,80 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Error classes for audio driver failures. Drivers wrap their native errors in a
+// DeviceError so callers can use errors.Is to choose a message or a retry policy.
+var (
+	// ErrDeviceBusy means the device exists but could not be opened or started,
+	// typically because another application holds it exclusively
+	ErrDeviceBusy = errors.New("audio device is busy or unavailable")
+
+	// ErrInvalidDevice means the device does not exist or cannot record with the requested parameters
+	ErrInvalidDevice = errors.New("invalid audio device")
+
+	// ErrHostError means the OS audio system reported an unexpected error
+	ErrHostError = errors.New("audio host error")
+
+	// ErrTransient means the OS audio system failed in a way that usually clears
+	// up within moments, e.g. while CoreAudio settles after wake or a Bluetooth
+	// headset switches profiles
+	ErrTransient = errors.New("transient audio error")
+)
+
+// DeviceError associates a native driver error with one of the error classes above
+type DeviceError struct {
+	Class error // ErrDeviceBusy, ErrInvalidDevice, ErrHostError or ErrTransient
+	Err   error // Underlying driver error
+}
+
+// Error returns the class followed by the underlying error
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Class, e.Err)
+}
+
+// Unwrap allows errors.Is to match both the class and the underlying error
+func (e *DeviceError) Unwrap() []error {
+	return []error{e.Class, e.Err}
+}
+
+// IsRetryable reports whether the operation may succeed if retried shortly
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrDeviceBusy) || errors.Is(err, ErrTransient)
+}
+
+// Retry policy for starting a recording. The attempts take about 600 ms in
+// total, short enough not to eat into the user's speech.
+const (
+	startAttempts = 3
+
+	// StartBackoff is the wait before the first retry; it doubles for each further retry
+	StartBackoff = 200 * time.Millisecond
+)
+
+// StartWithRetry starts recording on driver, retrying errors IsRetryable
+// reports as transient up to startAttempts times, waiting backoff before the
+// first retry. The last error is returned if every attempt fails.
+func StartWithRetry(driver AudioDriver, backoff time.Duration) error {
+	return retry(startAttempts, backoff, driver.StartRecording)
+}
+
+// retry calls op up to attempts times, doubling the backoff between attempts.
+// Only retryable errors are retried; any other error is returned immediately.
+func retry(attempts int, backoff time.Duration, op func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = op(); err == nil || !IsRetryable(err) {
+			return err
+		}
+
+		if i < attempts-1 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// fakeStream fails Start with err for the first failures calls, then succeeds
+type fakeStream struct {
+	failures int
+	err      error
+	starts   int
+}
+
+func (s *fakeStream) Start() error {
+	s.starts++
+	if s.starts <= s.failures {
+		return s.err
+	}
+	return nil
+}
+
+func (s *fakeStream) Stop() error  { return nil }
+func (s *fakeStream) Close() error { return nil }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		class error
+	}{
+		{"device unavailable", portaudio.DeviceUnavailable, ErrDeviceBusy},
+		{"timed out", portaudio.TimedOut, ErrDeviceBusy},
+		{"invalid device", portaudio.InvalidDevice, ErrInvalidDevice},
+		{"no default input", portaudio.NoDefaultInputDevice, ErrInvalidDevice},
+		{"invalid sample rate", portaudio.InvalidSampleRate, ErrInvalidDevice},
+		{"internal error", portaudio.InternalError, ErrHostError},
+		{"unanticipated host error", portaudio.UnanticipatedHostError{Code: -50, Text: "host"}, ErrTransient},
+		{"stream not stopped", portaudio.StreamIsNotStopped, ErrTransient},
+		{"input overflowed", portaudio.InputOverflowed, ErrTransient},
+		{"wrapped", fmt.Errorf("open: %w", portaudio.DeviceUnavailable), ErrDeviceBusy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+
+			if !errors.Is(err, tt.class) {
+				t.Errorf("Expected class %v, got %v", tt.class, err)
+			}
+
+			// The original error must remain inspectable
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestClassifyError_Unknown(t *testing.T) {
+	if classifyError(nil) != nil {
+		t.Error("Expected nil for nil error")
+	}
+
+	err := classifyError(portaudio.InsufficientMemory)
+	if errors.Is(err, ErrDeviceBusy) || errors.Is(err, ErrInvalidDevice) || errors.Is(err, ErrHostError) || errors.Is(err, ErrTransient) {
+		t.Errorf("Expected unclassified error, got %v", err)
+	}
+}
+
+func TestStartWithRetry_RetriesBusyDevice(t *testing.T) {
+	stream := &fakeStream{failures: 2, err: portaudio.DeviceUnavailable}
+	driver := &PortAudioDriver{stream: stream, initialized: true}
+
+	if err := StartWithRetry(driver, 0); err != nil {
+		t.Fatalf("Expected StartWithRetry to succeed after retries, got %v", err)
+	}
+
+	if stream.starts != 3 {
+		t.Errorf("Expected 3 start attempts, got %d", stream.starts)
+	}
+
+	if !driver.IsRecording() {
+		t.Error("Expected driver to be recording")
+	}
+}
+
+func TestStartWithRetry_RetriesTransientHostError(t *testing.T) {
+	stream := &fakeStream{failures: 1, err: portaudio.UnanticipatedHostError{Code: -50, Text: "host"}}
+	driver := &PortAudioDriver{stream: stream, initialized: true}
+
+	if err := StartWithRetry(driver, 0); err != nil {
+		t.Fatalf("Expected StartWithRetry to succeed after a transient error, got %v", err)
+	}
+
+	if stream.starts != 2 {
+		t.Errorf("Expected 2 start attempts, got %d", stream.starts)
+	}
+}
+
+func TestStartWithRetry_GivesUpWhenBusy(t *testing.T) {
+	stream := &fakeStream{failures: 10, err: portaudio.DeviceUnavailable}
+	driver := &PortAudioDriver{stream: stream, initialized: true}
+
+	err := StartWithRetry(driver, 0)
+	if !errors.Is(err, ErrDeviceBusy) {
+		t.Fatalf("Expected ErrDeviceBusy, got %v", err)
+	}
+
+	if stream.starts != startAttempts {
+		t.Errorf("Expected %d start attempts, got %d", startAttempts, stream.starts)
+	}
+
+	if driver.IsRecording() {
+		t.Error("Expected driver not to be recording")
+	}
+}
+
+func TestStartWithRetry_NoRetryForInvalidDevice(t *testing.T) {
+	stream := &fakeStream{failures: 10, err: portaudio.InvalidDevice}
+	driver := &PortAudioDriver{stream: stream, initialized: true}
+
+	if err := StartWithRetry(driver, 0); !errors.Is(err, ErrInvalidDevice) {
+		t.Fatalf("Expected ErrInvalidDevice, got %v", err)
+	}
+
+	if stream.starts != 1 {
+		t.Errorf("Expected a single start attempt, got %d", stream.starts)
+	}
+}
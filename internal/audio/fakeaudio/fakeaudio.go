@@ -0,0 +1,320 @@
+// Package fakeaudio provides an AudioDriver that "records" from a WAV file or a
+// generated signal instead of a microphone. It is used by tests and by the
+// --fake-audio demo mode on machines without an input device.
+package fakeaudio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+)
+
+// framesPerChunk matches the PortAudio driver's buffer size
+const framesPerChunk = 1024
+
+// Driver implements audio.AudioDriver by streaming samples from a fixed source
+type Driver struct {
+	name        string
+	source      []int16 // Mono 16-bit samples played back on each recording
+	sourceRate  int     // Sample rate of source, 0 = matches any configuration
+	speed       float64 // 1 = real-time, 10 = 10x, <= 0 = whole source available immediately
+	config      audio.Config
+	buffer      []int16
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	recording   bool
+	initialized bool
+	onChunk     func(pcm []byte) // Receives each streamed chunk, nil for none
+	startErrs   []error          // Returned by the next StartRecording calls, in order
+	starts      int              // Number of StartRecording calls
+}
+
+// New creates a fake driver that plays back the given mono samples.
+// sourceRate is the sample rate of samples (0 if they match any configuration).
+// speed 1 streams in real time, larger values stream faster, and <= 0 makes the
+// whole source available as soon as recording starts.
+func New(name string, samples []int16, sourceRate int, speed float64) *Driver {
+	return &Driver{
+		name:       name,
+		source:     samples,
+		sourceRate: sourceRate,
+		speed:      speed,
+	}
+}
+
+// NewFromSource creates a fake driver from a source description:
+// "sine" (440 Hz tone), "silence", or a path to a 16-bit PCM WAV file
+func NewFromSource(source string, speed float64) (*Driver, error) {
+	const sampleRate = 16000
+
+	switch source {
+	case "sine":
+		return New("Fake Audio (sine)", Sine(440, 5*time.Second, sampleRate, 0.3), 0, speed), nil
+	case "silence", "":
+		return New("Fake Audio (silence)", Silence(5*time.Second, sampleRate), 0, speed), nil
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAV file: %w", err)
+	}
+	defer file.Close()
+
+	samples, rate, err := audio.ReadWAV(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAV file %s: %w", source, err)
+	}
+
+	return New(fmt.Sprintf("Fake Audio (%s)", source), samples, rate, speed), nil
+}
+
+// Sine generates a mono sine tone with amplitude in [0, 1]
+func Sine(frequency float64, duration time.Duration, sampleRate int, amplitude float64) []int16 {
+	n := int(duration.Seconds() * float64(sampleRate))
+	samples := make([]int16, n)
+	for i := range samples {
+		v := amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate))
+		samples[i] = int16(v * math.MaxInt16)
+	}
+	return samples
+}
+
+// Silence generates mono silence
+func Silence(duration time.Duration, sampleRate int) []int16 {
+	return make([]int16, int(duration.Seconds()*float64(sampleRate)))
+}
+
+// ListDevices returns a single fake input device
+func (d *Driver) ListDevices() ([]audio.Device, error) {
+	return []audio.Device{d.device()}, nil
+}
+
+// device describes the single fake input device
+func (d *Driver) device() audio.Device {
+	return audio.Device{
+		ID:                0,
+		Name:              d.name,
+		IsDefault:         true,
+		DefaultSampleRate: d.sourceRate,
+		MaxInputChannels:  1,
+	}
+}
+
+// CurrentDevice returns the fake device once the driver is initialized
+func (d *Driver) CurrentDevice() (audio.Device, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.initialized {
+		return audio.Device{}, fmt.Errorf("driver not initialized")
+	}
+	return d.device(), nil
+}
+
+// Initialize initializes the driver with the given configuration
+func (d *Driver) Initialize(config audio.Config) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.recording {
+		return fmt.Errorf("cannot initialize while recording")
+	}
+
+	if d.sourceRate != 0 && d.sourceRate != config.SampleRate {
+		return fmt.Errorf("source sample rate %d Hz does not match configured %d Hz", d.sourceRate, config.SampleRate)
+	}
+
+	d.config = config
+	d.initialized = true
+	return nil
+}
+
+// StreamInfo reports the configured sample rate as the effective rate
+func (d *Driver) StreamInfo() audio.StreamInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.initialized {
+		return audio.StreamInfo{}
+	}
+
+	return audio.StreamInfo{
+		DeviceName:          d.name,
+		RequestedSampleRate: d.config.SampleRate,
+		EffectiveSampleRate: d.config.SampleRate,
+	}
+}
+
+// FailStarts makes the next n StartRecording calls fail with err, e.g. to
+// simulate a device that is still busy right after wake
+func (d *Driver) FailStarts(n int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		d.startErrs = append(d.startErrs, err)
+	}
+}
+
+// Starts returns the number of StartRecording calls, including failed ones
+func (d *Driver) Starts() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.starts
+}
+
+// StartRecording starts streaming the source into the recording buffer
+func (d *Driver) StartRecording() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.starts++
+
+	if !d.initialized {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if len(d.startErrs) > 0 {
+		err := d.startErrs[0]
+		d.startErrs = d.startErrs[1:]
+		return err
+	}
+
+	if d.recording {
+		return fmt.Errorf("already recording")
+	}
+
+	d.buffer = d.buffer[:0]
+	d.recording = true
+
+	if d.speed <= 0 {
+		d.buffer = append(d.buffer, d.source...)
+		if d.onChunk != nil {
+			d.onChunk(audio.EncodePCM(d.source))
+		}
+		return nil
+	}
+
+	d.stopChan = make(chan struct{})
+	interval := time.Duration(float64(framesPerChunk) / float64(d.config.SampleRate) / d.speed * float64(time.Second))
+
+	d.wg.Add(1)
+	go d.stream(d.stopChan, interval)
+
+	return nil
+}
+
+// stream appends one chunk per interval, continuing with silence once the source is exhausted
+func (d *Driver) stream(stopChan chan struct{}, interval time.Duration) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	position := 0
+	silence := make([]int16, framesPerChunk)
+
+	for {
+		select {
+		case <-ticker.C:
+			d.mu.Lock()
+			chunk := silence
+			if position < len(d.source) {
+				end := min(position+framesPerChunk, len(d.source))
+				chunk = d.source[position:end]
+				position = end
+			}
+			d.buffer = append(d.buffer, chunk...)
+			if d.onChunk != nil {
+				d.onChunk(audio.EncodePCM(chunk))
+			}
+			d.mu.Unlock()
+
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// SetChunkHandler sets the function that receives each chunk as it is streamed into the recording
+func (d *Driver) SetChunkHandler(handler func(pcm []byte)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onChunk = handler
+}
+
+// StopRecording stops streaming and returns the recorded audio as 16-bit little-endian PCM
+func (d *Driver) StopRecording() ([]byte, error) {
+	if err := d.stop(); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data := make([]byte, len(d.buffer)*2)
+	for i, sample := range d.buffer {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+
+	return data, nil
+}
+
+// StopRecordingFloat32 stops streaming and returns the recorded samples normalized to [-1, 1]
+func (d *Driver) StopRecordingFloat32() ([]float32, error) {
+	if err := d.stop(); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return audio.Int16ToFloat32(d.buffer), nil
+}
+
+// stop ends the running recording and waits for the streaming goroutine to exit
+func (d *Driver) stop() error {
+	d.mu.Lock()
+	if !d.recording {
+		d.mu.Unlock()
+		return fmt.Errorf("not recording")
+	}
+	d.recording = false
+	stopChan := d.stopChan
+	d.stopChan = nil
+	d.mu.Unlock()
+
+	if stopChan != nil {
+		close(stopChan)
+		d.wg.Wait()
+	}
+	return nil
+}
+
+// IsRecording returns whether recording is currently active
+func (d *Driver) IsRecording() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.recording
+}
+
+// Close stops any active recording and releases resources
+func (d *Driver) Close() error {
+	if d.IsRecording() {
+		if _, err := d.StopRecording(); err != nil {
+			return err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.initialized = false
+	return nil
+}
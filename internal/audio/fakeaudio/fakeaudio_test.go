@@ -0,0 +1,196 @@
+package fakeaudio
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+)
+
+func TestInitialize_SampleRateMismatch(t *testing.T) {
+	driver := New("test", make([]int16, 100), 44100, 0)
+
+	if err := driver.Initialize(audio.DefaultConfig()); err == nil {
+		t.Error("Expected error when source sample rate does not match configuration")
+	}
+}
+
+func TestRecording_Instant(t *testing.T) {
+	source := Sine(440, 500*time.Millisecond, 16000, 0.5)
+	driver := New("test", source, 0, 0)
+	defer driver.Close()
+
+	if err := driver.Initialize(audio.DefaultConfig()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := driver.StartRecording(); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+
+	if err := driver.StartRecording(); err == nil {
+		t.Error("StartRecording should fail when already recording")
+	}
+
+	data, err := driver.StopRecording()
+	if err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+
+	if len(data) != len(source)*2 {
+		t.Errorf("Expected %d bytes, got %d", len(source)*2, len(data))
+	}
+
+	if _, err := driver.StopRecording(); err == nil {
+		t.Error("StopRecording should fail when not recording")
+	}
+}
+
+func TestRecording_FailStarts(t *testing.T) {
+	driver := New("test", Sine(440, 100*time.Millisecond, 16000, 0.5), 0, 0)
+	defer driver.Close()
+
+	if err := driver.Initialize(audio.DefaultConfig()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	busy := &audio.DeviceError{Class: audio.ErrDeviceBusy, Err: errors.New("device unavailable")}
+	driver.FailStarts(2, busy)
+
+	if err := audio.StartWithRetry(driver, 0); err != nil {
+		t.Fatalf("Expected StartWithRetry to succeed on the third attempt, got %v", err)
+	}
+	if driver.Starts() != 3 {
+		t.Errorf("Expected 3 start attempts, got %d", driver.Starts())
+	}
+	if !driver.IsRecording() {
+		t.Error("Expected driver to be recording")
+	}
+}
+
+func TestRecording_Float32(t *testing.T) {
+	source := Sine(440, 500*time.Millisecond, 16000, 0.5)
+	driver := New("test", source, 0, 0)
+	defer driver.Close()
+
+	if err := driver.Initialize(audio.DefaultConfig()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := driver.StartRecording(); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+
+	samples, err := driver.StopRecordingFloat32()
+	if err != nil {
+		t.Fatalf("StopRecordingFloat32 failed: %v", err)
+	}
+
+	if len(samples) != len(source) {
+		t.Fatalf("Expected %d samples, got %d", len(source), len(samples))
+	}
+	for i, sample := range source {
+		if samples[i] != float32(sample)/32768 {
+			t.Fatalf("Sample %d: expected %v, got %v", i, float32(sample)/32768, samples[i])
+		}
+	}
+
+	if _, err := driver.StopRecordingFloat32(); err == nil {
+		t.Error("StopRecordingFloat32 should fail when not recording")
+	}
+}
+
+func TestRecording_Streaming(t *testing.T) {
+	source := Sine(440, time.Second, 16000, 0.5)
+	driver := New("test", source, 0, 10) // 10x real-time
+	defer driver.Close()
+
+	if err := driver.Initialize(audio.DefaultConfig()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := driver.StartRecording(); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+
+	// 50ms at 10x covers roughly 0.5s of audio
+	time.Sleep(50 * time.Millisecond)
+
+	data, err := driver.StopRecording()
+	if err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+
+	samples := len(data) / 2
+	if samples == 0 {
+		t.Fatal("Expected streamed samples, got none")
+	}
+	if samples > len(source) {
+		t.Errorf("Expected at most %d samples after 50ms at 10x, got %d", len(source), samples)
+	}
+	if samples%framesPerChunk != 0 {
+		t.Errorf("Expected whole chunks of %d frames, got %d samples", framesPerChunk, samples)
+	}
+}
+
+func TestRecording_ChunkHandler(t *testing.T) {
+	source := Sine(440, 500*time.Millisecond, 16000, 0.5)
+	driver := New("test", source, 0, 0)
+	defer driver.Close()
+
+	if err := driver.Initialize(audio.DefaultConfig()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	var chunks [][]byte
+	driver.SetChunkHandler(func(pcm []byte) {
+		chunks = append(chunks, pcm)
+	})
+
+	if err := driver.StartRecording(); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+	data, err := driver.StopRecording()
+	if err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+
+	if len(chunks) != 1 || !bytes.Equal(chunks[0], data) {
+		t.Errorf("Expected the recording to be passed to the handler, got %d chunks", len(chunks))
+	}
+}
+
+func TestCurrentDevice(t *testing.T) {
+	driver := New("Fake Mic", nil, 16000, 0)
+
+	if _, err := driver.CurrentDevice(); err == nil {
+		t.Error("Expected error before Initialize")
+	}
+
+	if err := driver.Initialize(audio.DefaultConfig()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	device, err := driver.CurrentDevice()
+	if err != nil {
+		t.Fatalf("CurrentDevice failed: %v", err)
+	}
+	if device.Name != "Fake Mic" || device.DefaultSampleRate != 16000 || device.MaxInputChannels != 1 {
+		t.Errorf("Unexpected device: %+v", device)
+	}
+}
+
+func TestListDevices(t *testing.T) {
+	driver := New("Fake Mic", nil, 0, 0)
+
+	devices, err := driver.ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+
+	if len(devices) != 1 || devices[0].Name != "Fake Mic" || !devices[0].IsDefault {
+		t.Errorf("Expected a single default 'Fake Mic' device, got %+v", devices)
+	}
+}
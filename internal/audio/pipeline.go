@@ -0,0 +1,246 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Defaults for the optional processing stages
+const (
+	// NormalizeTargetPeak is the normalized peak amplitude the normalize stage aims for (about -1 dBFS)
+	NormalizeTargetPeak = 0.9
+	// NormalizeMaxGain caps the gain so that faint noise is not amplified into speech-like levels
+	NormalizeMaxGain = 10.0
+	// TrimThreshold is the normalized amplitude below which leading/trailing audio counts as silence
+	TrimThreshold = 0.01
+	// TrimPaddingMs is the audio kept before the first and after the last loud sample
+	TrimPaddingMs = 200
+)
+
+// PipelineConfig selects the processing stages applied to a recording before transcription
+type PipelineConfig struct {
+	Channels    int  // Interleaved channels in the input; more than 1 enables the downmix stage
+	InputRate   int  // Sample rate of the input
+	OutputRate  int  // Sample rate expected by the recognizer; differs from InputRate to enable resampling
+	TrimSilence bool // Remove leading and trailing silence
+	Normalize   bool // Scale to NormalizeTargetPeak (gain at most NormalizeMaxGain)
+}
+
+// Stage is one named processing step. Apply operates on 16-bit samples and
+// ApplyFloat32 on normalized float32 samples; both run the same transform.
+type Stage struct {
+	Name         string
+	Apply        func(samples []int16) []int16
+	ApplyFloat32 func(samples []float32) []float32
+}
+
+// Pipeline applies the enabled stages in a fixed order:
+// downmix → resample → trim → normalize.
+// Every transcription path uses the same pipeline so transforms are never applied
+// in a different order or skipped depending on the caller.
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline builds a pipeline containing only the stages enabled by config
+func NewPipeline(config PipelineConfig) *Pipeline {
+	names, pcm := buildStages[int16](config)
+	_, float := buildStages[float32](config)
+
+	p := &Pipeline{stages: make([]Stage, len(names))}
+	for i, name := range names {
+		p.stages[i] = Stage{Name: name, Apply: pcm[i], ApplyFloat32: float[i]}
+	}
+	return p
+}
+
+// buildStages returns the names and functions of the stages enabled by config for samples of type S
+func buildStages[S sample](config PipelineConfig) ([]string, []func([]S) []S) {
+	var names []string
+	var fns []func([]S) []S
+	add := func(name string, fn func([]S) []S) {
+		names = append(names, name)
+		fns = append(fns, fn)
+	}
+
+	if config.Channels > 1 {
+		channels := config.Channels
+		add("downmix", func(s []S) []S {
+			return downmix(s, channels)
+		})
+	}
+
+	if config.InputRate > 0 && config.OutputRate > 0 && config.InputRate != config.OutputRate {
+		from, to := config.InputRate, config.OutputRate
+		add("resample", func(s []S) []S {
+			return resample(s, from, to)
+		})
+	}
+
+	// Trim and normalize run at the output rate
+	rate := config.OutputRate
+	if rate <= 0 {
+		rate = config.InputRate
+	}
+
+	if config.TrimSilence {
+		padding := rate * TrimPaddingMs / 1000
+		add("trim", func(s []S) []S {
+			return trimSilence(s, TrimThreshold, padding)
+		})
+	}
+
+	if config.Normalize {
+		add("normalize", func(s []S) []S {
+			return normalize(s, NormalizeTargetPeak, NormalizeMaxGain)
+		})
+	}
+
+	return names, fns
+}
+
+// Stages returns the names of the enabled stages in the order they run
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.Name
+	}
+	return names
+}
+
+// Process runs 16-bit little-endian PCM through the enabled stages.
+// The input is returned unchanged if no stage is enabled.
+func (p *Pipeline) Process(pcm []byte) []byte {
+	if len(p.stages) == 0 {
+		return pcm
+	}
+
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+	}
+
+	for _, stage := range p.stages {
+		samples = stage.Apply(samples)
+	}
+
+	out := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(s))
+	}
+	return out
+}
+
+// ProcessFloat32 runs normalized float32 samples through the enabled stages.
+// The input is returned unchanged if no stage is enabled.
+func (p *Pipeline) ProcessFloat32(samples []float32) []float32 {
+	for _, stage := range p.stages {
+		samples = stage.ApplyFloat32(samples)
+	}
+	return samples
+}
+
+// sample is a sample type the processing stages operate on: 16-bit PCM or
+// float32 normalized to [-1, 1]
+type sample interface {
+	int16 | float32
+}
+
+// sampleScale returns the magnitude of a full-scale negative sample and the
+// largest positive sample of S
+func sampleScale[S sample]() (fullScale, maxValue float64) {
+	var zero S
+	if _, ok := any(zero).(int16); ok {
+		return 32768, math.MaxInt16
+	}
+	return 1, 1
+}
+
+// Downmix averages interleaved multi-channel samples into mono
+func Downmix(samples []int16, channels int) []int16 {
+	return downmix(samples, channels)
+}
+
+func downmix[S sample](samples []S, channels int) []S {
+	if channels <= 1 {
+		return samples
+	}
+
+	frames := len(samples) / channels
+	mono := make([]S, frames)
+	for i := 0; i < frames; i++ {
+		sum := 0.0
+		for c := 0; c < channels; c++ {
+			sum += float64(samples[i*channels+c])
+		}
+		mono[i] = S(sum / float64(channels))
+	}
+	return mono
+}
+
+// TrimSilence removes leading and trailing samples quieter than threshold (normalized),
+// keeping padding samples on each side. All-quiet input is returned unchanged so that
+// silence detection still sees the whole recording.
+func TrimSilence(samples []int16, threshold float64, padding int) []int16 {
+	return trimSilence(samples, threshold, padding)
+}
+
+func trimSilence[S sample](samples []S, threshold float64, padding int) []S {
+	fullScale, _ := sampleScale[S]()
+	limit := threshold * fullScale
+
+	first, last := -1, -1
+	for i, s := range samples {
+		if math.Abs(float64(s)) > limit {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	if first < 0 {
+		return samples
+	}
+
+	start := max(first-padding, 0)
+	end := min(last+padding+1, len(samples))
+	return samples[start:end]
+}
+
+// Normalize scales samples so the peak reaches targetPeak (normalized), never
+// applying more than maxGain. Quiet-but-nonzero input is therefore only partially
+// boosted, and silence is left as is.
+func Normalize(samples []int16, targetPeak, maxGain float64) []int16 {
+	return normalize(samples, targetPeak, maxGain)
+}
+
+func normalize[S sample](samples []S, targetPeak, maxGain float64) []S {
+	fullScale, maxValue := sampleScale[S]()
+
+	peak := 0.0
+	for _, s := range samples {
+		peak = max(peak, math.Abs(float64(s)))
+	}
+	if peak == 0 {
+		return samples
+	}
+
+	gain := min(targetPeak*maxValue/peak, maxGain)
+	if gain == 1 {
+		return samples
+	}
+
+	// Integer samples are rounded; float samples keep their precision
+	round := fullScale > 1
+
+	out := make([]S, len(samples))
+	for i, s := range samples {
+		v := float64(s) * gain
+		if round {
+			v = math.Round(v)
+		}
+		out[i] = S(max(min(v, maxValue), -fullScale))
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+package audio
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNewPipeline_StageOrder(t *testing.T) {
+	p := NewPipeline(PipelineConfig{
+		Channels:    2,
+		InputRate:   48000,
+		OutputRate:  16000,
+		TrimSilence: true,
+		Normalize:   true,
+	})
+
+	expected := []string{"downmix", "resample", "trim", "normalize"}
+	if !reflect.DeepEqual(p.Stages(), expected) {
+		t.Errorf("Expected stages %v, got %v", expected, p.Stages())
+	}
+}
+
+func TestNewPipeline_OnlyEnabledStages(t *testing.T) {
+	p := NewPipeline(PipelineConfig{Channels: 1, InputRate: 16000, OutputRate: 16000, Normalize: true})
+
+	if !reflect.DeepEqual(p.Stages(), []string{"normalize"}) {
+		t.Errorf("Expected only normalize, got %v", p.Stages())
+	}
+}
+
+func TestPipeline_ProcessNoStages(t *testing.T) {
+	pcm := pcmFromSamples([]int16{1, 2, 3})
+	p := NewPipeline(PipelineConfig{Channels: 1, InputRate: 16000, OutputRate: 16000})
+
+	if out := p.Process(pcm); &out[0] != &pcm[0] {
+		t.Error("Expected input to be returned unchanged when no stage is enabled")
+	}
+}
+
+func TestPipeline_Process(t *testing.T) {
+	// Stereo frames: silence, then a quiet signal, then silence
+	var stereo []int16
+	for i := 0; i < 100; i++ {
+		stereo = append(stereo, 0, 0)
+	}
+	stereo = append(stereo, 1000, 3000, -1000, -3000)
+	for i := 0; i < 100; i++ {
+		stereo = append(stereo, 0, 0)
+	}
+
+	p := NewPipeline(PipelineConfig{Channels: 2, InputRate: 16000, OutputRate: 16000, TrimSilence: true, Normalize: true})
+	out := p.Process(pcmFromSamples(stereo))
+
+	samples := make([]int16, len(out)/2)
+	for i := range samples {
+		samples[i] = int16(uint16(out[i*2]) | uint16(out[i*2+1])<<8)
+	}
+
+	// Downmixed to 2000/-2000, padding (200ms = 3200 samples) keeps all surrounding silence
+	if len(samples) != 202 {
+		t.Fatalf("Expected 202 mono samples, got %d", len(samples))
+	}
+
+	// Normalized 2000 -> 0.9 * 32767, capped at 10x gain = 20000
+	if samples[100] != 20000 || samples[101] != -20000 {
+		t.Errorf("Expected normalized peak ±20000, got %d/%d", samples[100], samples[101])
+	}
+}
+
+func TestPipeline_ProcessFloat32(t *testing.T) {
+	// Same recording as TestPipeline_Process, normalized to [-1, 1]
+	var stereo []float32
+	for i := 0; i < 100; i++ {
+		stereo = append(stereo, 0, 0)
+	}
+	stereo = append(stereo, 0.025, 0.075, -0.025, -0.075)
+	for i := 0; i < 100; i++ {
+		stereo = append(stereo, 0, 0)
+	}
+
+	p := NewPipeline(PipelineConfig{Channels: 2, InputRate: 16000, OutputRate: 16000, TrimSilence: true, Normalize: true})
+	samples := p.ProcessFloat32(stereo)
+
+	if len(samples) != 202 {
+		t.Fatalf("Expected 202 mono samples, got %d", len(samples))
+	}
+
+	// Downmixed to ±0.05, normalized to 0.9 but capped at 10x gain = ±0.5
+	if math.Abs(float64(samples[100])-0.5) > 1e-6 || math.Abs(float64(samples[101])+0.5) > 1e-6 {
+		t.Errorf("Expected normalized peak ±0.5, got %v/%v", samples[100], samples[101])
+	}
+}
+
+func TestPipeline_ProcessFloat32Resample(t *testing.T) {
+	samples := make([]float32, 48000)
+	for i := range samples {
+		samples[i] = 0.5
+	}
+
+	p := NewPipeline(PipelineConfig{Channels: 1, InputRate: 48000, OutputRate: 16000})
+	out := p.ProcessFloat32(samples)
+
+	if len(out) != 16000 {
+		t.Fatalf("Expected 16000 samples, got %d", len(out))
+	}
+	if out[0] != 0.5 || out[len(out)-1] != 0.5 {
+		t.Errorf("Expected constant signal to be kept, got %v/%v", out[0], out[len(out)-1])
+	}
+}
+
+func TestDownmix(t *testing.T) {
+	result := Downmix([]int16{100, 300, -200, -400}, 2)
+
+	if !reflect.DeepEqual(result, []int16{200, -300}) {
+		t.Errorf("Expected [200 -300], got %v", result)
+	}
+}
+
+func TestTrimSilence(t *testing.T) {
+	samples := []int16{0, 1, 0, 5000, 0, 6000, 0, 2, 0}
+
+	if result := TrimSilence(samples, 0.01, 0); !reflect.DeepEqual(result, []int16{5000, 0, 6000}) {
+		t.Errorf("Expected loud span only, got %v", result)
+	}
+
+	if result := TrimSilence(samples, 0.01, 1); !reflect.DeepEqual(result, []int16{0, 5000, 0, 6000, 0}) {
+		t.Errorf("Expected one sample of padding, got %v", result)
+	}
+
+	quiet := []int16{0, 1, -1, 0}
+	if result := TrimSilence(quiet, 0.01, 0); len(result) != len(quiet) {
+		t.Errorf("Expected all-quiet input to be left unchanged, got %v", result)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if result := Normalize([]int16{16384, -8192}, 0.9, 10); result[0] != 29490 || result[1] != -14745 {
+		t.Errorf("Expected peak scaled to 0.9, got %v", result)
+	}
+
+	// Loud input is attenuated to the target
+	if result := Normalize([]int16{32767}, 0.5, 10); result[0] != 16384 {
+		t.Errorf("Expected attenuation to 0.5, got %v", result)
+	}
+
+	// Gain is capped for faint input
+	if result := Normalize([]int16{100}, 0.9, 10); result[0] != 1000 {
+		t.Errorf("Expected gain capped at 10x, got %v", result)
+	}
+
+	silence := []int16{0, 0}
+	if result := Normalize(silence, 0.9, 10); !reflect.DeepEqual(result, silence) {
+		t.Errorf("Expected silence unchanged, got %v", result)
+	}
+}
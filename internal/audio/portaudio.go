@@ -1,6 +1,7 @@
 package audio
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -8,14 +9,24 @@ import (
 	"github.com/gordonklaus/portaudio"
 )
 
+// audioStream is the subset of *portaudio.Stream used by the driver (replaced in tests)
+type audioStream interface {
+	Start() error
+	Stop() error
+	Close() error
+}
+
 // PortAudioDriver implements AudioDriver using PortAudio
 type PortAudioDriver struct {
 	config    Config
-	stream    *portaudio.Stream
+	stream    audioStream
 	buffer    []int16
 	mu        sync.Mutex
 	recording bool
 	initialized bool
+	streamInfo  StreamInfo
+	device      Device // Device opened by Initialize
+	onChunk      func(pcm []byte) // Receives the audio of the running recording, nil for none
 }
 
 // NewPortAudioDriver creates a new PortAudio driver
@@ -26,7 +37,7 @@ func NewPortAudioDriver() (*PortAudioDriver, error) {
 	}
 
 	return &PortAudioDriver{
-		buffer: make([]int16, 0, 1024*1024), // Pre-allocate 1MB buffer
+		buffer:       make([]int16, 0, 1024*1024), // Pre-allocate 1MB buffer
 	}, nil
 }
 
@@ -53,9 +64,11 @@ func (d *PortAudioDriver) ListDevices() ([]Device, error) {
 			}
 
 			result = append(result, Device{
-				ID:        i,
-				Name:      dev.Name,
-				IsDefault: isDefault,
+				ID:                i,
+				Name:              dev.Name,
+				IsDefault:         isDefault,
+				DefaultSampleRate: int(dev.DefaultSampleRate + 0.5),
+				MaxInputChannels:  dev.MaxInputChannels,
 			})
 		}
 	}
@@ -84,19 +97,28 @@ func (d *PortAudioDriver) Initialize(config Config) error {
 	var device *portaudio.DeviceInfo
 	var err error
 
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	deviceID := config.DeviceID
 	if config.DeviceID == -1 {
 		// Use default input device
 		device, err = portaudio.DefaultInputDevice()
 		if err != nil {
-			return fmt.Errorf("failed to get default input device: %w", err)
+			return fmt.Errorf("failed to get default input device: %w", classifyError(err))
+		}
+
+		// Resolve the index of the default device so CurrentDevice reports a real ID
+		for i, dev := range devices {
+			if dev.Name == device.Name {
+				deviceID = i
+				break
+			}
 		}
 	} else {
 		// Use specified device
-		devices, err := portaudio.Devices()
-		if err != nil {
-			return fmt.Errorf("failed to list devices: %w", err)
-		}
-
 		if config.DeviceID < 0 || config.DeviceID >= len(devices) {
 			return fmt.Errorf("invalid device ID: %d", config.DeviceID)
 		}
@@ -132,19 +154,63 @@ func (d *PortAudioDriver) Initialize(config Config) error {
 		FramesPerBuffer: 1024,
 	}
 
-	// Open stream
+	// Open stream. Some USB interfaces only support their native rate (e.g. 44.1/48kHz):
+	// if the requested rate is rejected, open at the device's default rate instead.
+	// The effective rate read back below makes recordings resampled to the requested rate.
 	stream, err := portaudio.OpenStream(streamParams, d.callback)
+	if errors.Is(err, portaudio.InvalidSampleRate) && device.DefaultSampleRate > 0 && device.DefaultSampleRate != streamParams.SampleRate {
+		streamParams.SampleRate = device.DefaultSampleRate
+		stream, err = portaudio.OpenStream(streamParams, d.callback)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to open stream: %w", err)
+		return fmt.Errorf("failed to open stream: %w", classifyError(err))
+	}
+
+	// Some aggregate/virtual devices silently open at a different rate than requested.
+	// Read back the effective rate so recordings can be resampled to the requested rate.
+	effectiveRate := int(streamParams.SampleRate + 0.5)
+	if info := stream.Info(); info != nil && info.SampleRate > 0 {
+		effectiveRate = int(info.SampleRate + 0.5)
 	}
 
 	d.stream = stream
 	d.config = config
+	d.streamInfo = StreamInfo{
+		DeviceName:          device.Name,
+		RequestedSampleRate: config.SampleRate,
+		EffectiveSampleRate: effectiveRate,
+		Resampling:          effectiveRate != config.SampleRate,
+	}
+	d.device = Device{
+		ID:                deviceID,
+		Name:              device.Name,
+		IsDefault:         config.DeviceID == -1,
+		DefaultSampleRate: int(device.DefaultSampleRate + 0.5),
+		MaxInputChannels:  device.MaxInputChannels,
+	}
 	d.initialized = true
 
 	return nil
 }
 
+// StreamInfo returns information about the opened stream, including its effective sample rate
+func (d *PortAudioDriver) StreamInfo() StreamInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.streamInfo
+}
+
+// CurrentDevice returns the device opened by the last successful Initialize
+func (d *PortAudioDriver) CurrentDevice() (Device, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.initialized {
+		return Device{}, fmt.Errorf("driver not initialized")
+	}
+	return d.device, nil
+}
+
 // callback is called by PortAudio when audio data is available
 func (d *PortAudioDriver) callback(in []int16) {
 	d.mu.Lock()
@@ -152,9 +218,26 @@ func (d *PortAudioDriver) callback(in []int16) {
 
 	if d.recording {
 		d.buffer = append(d.buffer, in...)
+
+		if d.onChunk != nil {
+			// Partial results skip the pipeline, so chunks are resampled here
+			chunk := in
+			if d.streamInfo.Resampling {
+				chunk = Resample(in, d.streamInfo.EffectiveSampleRate, d.streamInfo.RequestedSampleRate)
+			}
+			d.onChunk(EncodePCM(chunk))
+		}
 	}
 }
 
+// SetChunkHandler sets the function that receives the audio of the running recording as it arrives
+func (d *PortAudioDriver) SetChunkHandler(handler func(pcm []byte)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onChunk = handler
+}
+
 // StartRecording starts recording audio
 func (d *PortAudioDriver) StartRecording() error {
 	d.mu.Lock()
@@ -171,8 +254,8 @@ func (d *PortAudioDriver) StartRecording() error {
 	// Clear buffer
 	d.buffer = d.buffer[:0]
 
-	// Start stream
-	if err := d.stream.Start(); err != nil {
+	// Start stream; callers retry busy and transient errors with StartWithRetry
+	if err := classifyError(d.stream.Start()); err != nil {
 		return fmt.Errorf("failed to start stream: %w", err)
 	}
 
@@ -185,25 +268,41 @@ func (d *PortAudioDriver) StopRecording() ([]byte, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	if err := d.stopLocked(); err != nil {
+		return nil, err
+	}
+
+	// Return the audio at the effective rate (see RecordingFormat); the
+	// pipeline resamples it together with the other processing stages
+	return EncodePCM(d.buffer), nil
+}
+
+// StopRecordingFloat32 stops recording and returns the recorded samples
+// normalized to [-1, 1], at the same rate as StopRecording
+func (d *PortAudioDriver) StopRecordingFloat32() ([]float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.stopLocked(); err != nil {
+		return nil, err
+	}
+
+	return Int16ToFloat32(d.buffer), nil
+}
+
+// stopLocked stops the stream of the running recording. d.mu must be held.
+func (d *PortAudioDriver) stopLocked() error {
 	if !d.recording {
-		return nil, fmt.Errorf("not recording")
+		return fmt.Errorf("not recording")
 	}
 
 	// Stop stream
 	if err := d.stream.Stop(); err != nil {
-		return nil, fmt.Errorf("failed to stop stream: %w", err)
+		return fmt.Errorf("failed to stop stream: %w", err)
 	}
 
 	d.recording = false
-
-	// Convert int16 buffer to bytes
-	data := make([]byte, len(d.buffer)*2)
-	for i, sample := range d.buffer {
-		data[i*2] = byte(sample)
-		data[i*2+1] = byte(sample >> 8)
-	}
-
-	return data, nil
+	return nil
 }
 
 // IsRecording returns whether recording is currently active
@@ -240,5 +339,40 @@ func (d *PortAudioDriver) Close() error {
 	}
 
 	d.initialized = false
+	d.streamInfo = StreamInfo{}
 	return nil
 }
+
+// classifyError wraps PortAudio errors in a DeviceError with the matching error class.
+// Errors that do not belong to a known class are returned unchanged.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// CoreAudio reports host errors while the audio route is changing, e.g.
+	// right after wake or a Bluetooth profile switch
+	var hostErr portaudio.UnanticipatedHostError
+	if errors.As(err, &hostErr) {
+		return &DeviceError{Class: ErrTransient, Err: err}
+	}
+
+	var paErr portaudio.Error
+	if !errors.As(err, &paErr) {
+		return err
+	}
+
+	switch paErr {
+	case portaudio.DeviceUnavailable, portaudio.TimedOut:
+		return &DeviceError{Class: ErrDeviceBusy, Err: err}
+	case portaudio.InvalidDevice, portaudio.NoDefaultInputDevice, portaudio.InvalidChannelCount,
+		portaudio.InvalidSampleRate, portaudio.SampleFormatNotSupported, portaudio.BadIODeviceCombination:
+		return &DeviceError{Class: ErrInvalidDevice, Err: err}
+	case portaudio.InternalError, portaudio.HostApiNotFound, portaudio.InvalidHostApi:
+		return &DeviceError{Class: ErrHostError, Err: err}
+	case portaudio.StreamIsNotStopped, portaudio.InputOverflowed:
+		return &DeviceError{Class: ErrTransient, Err: err}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRecordingsDir returns the folder where recordings are saved for
+// debugging, or "" if the home directory is unknown
+func DefaultRecordingsDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(homeDir, "Library", "Application Support", "EzS2T-Whisper", "recordings")
+}
+
+// SaveRecording writes pcm as a WAV file in dir, named after now (e.g.
+// "20250401-090500-123.wav"), and returns its path
+func SaveRecording(dir string, pcm []byte, sampleRate, channels int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+
+	name := strings.ReplaceAll(now.Format("20060102-150405.000"), ".", "-") + ".wav"
+	path := filepath.Join(dir, name)
+	if err := WriteWAV(path, pcm, sampleRate, channels); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// CleanOldRecordings deletes the WAV files in dir last modified more than
+// retentionDays before now
+func CleanOldRecordings(dir string, retentionDays int, now time.Time) error {
+	cutoff := now.AddDate(0, 0, -retentionDays)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read recordings directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".wav" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if info.ModTime().Before(cutoff) {
+			// Continue even if a file cannot be deleted
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveRecording(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+	now := time.Date(2025, 4, 1, 9, 5, 0, 123e6, time.Local)
+
+	path, err := SaveRecording(dir, EncodePCM([]int16{1, 2, 3}), 16000, 1, now)
+	if err != nil {
+		t.Fatalf("SaveRecording failed: %v", err)
+	}
+
+	if path != filepath.Join(dir, "20250401-090500-123.wav") {
+		t.Errorf("Unexpected path %q", path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != wavHeaderSize+6 {
+		t.Errorf("Expected a WAV file with 3 samples, got %v (err=%v)", info, err)
+	}
+}
+
+func TestCleanOldRecordings(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	files := map[string]time.Time{
+		"old.wav":    now.AddDate(0, 0, -8),
+		"recent.wav": now.AddDate(0, 0, -1),
+		"old.txt":    now.AddDate(0, 0, -8),
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set the time of %s: %v", name, err)
+		}
+	}
+
+	if err := CleanOldRecordings(dir, 7, now); err != nil {
+		t.Fatalf("CleanOldRecordings failed: %v", err)
+	}
+
+	for name, kept := range map[string]bool{"old.wav": false, "recent.wav": true, "old.txt": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != kept {
+			t.Errorf("%s: expected kept=%v, got err=%v", name, kept, err)
+		}
+	}
+
+	// A folder that was never created has nothing to clean
+	if err := CleanOldRecordings(filepath.Join(dir, "missing"), 7, now); err != nil {
+		t.Errorf("Expected no error for a missing folder, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package audio
+
+// Resample converts mono 16-bit samples from one sample rate to another using
+// linear interpolation. It is used when a device opens its stream at a rate
+// other than the one requested.
+func Resample(samples []int16, fromRate, toRate int) []int16 {
+	return resample(samples, fromRate, toRate)
+}
+
+func resample[S sample](samples []S, fromRate, toRate int) []S {
+	if fromRate <= 0 || toRate <= 0 || fromRate == toRate || len(samples) == 0 {
+		return samples
+	}
+
+	n := int(int64(len(samples)) * int64(toRate) / int64(fromRate))
+	result := make([]S, n)
+	step := float64(fromRate) / float64(toRate)
+
+	for i := range result {
+		pos := float64(i) * step
+		idx := int(pos)
+		if idx >= len(samples)-1 {
+			result[i] = samples[len(samples)-1]
+			continue
+		}
+		frac := pos - float64(idx)
+		result[i] = S(float64(samples[idx])*(1-frac) + float64(samples[idx+1])*frac)
+	}
+
+	return result
+}
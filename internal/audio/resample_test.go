@@ -0,0 +1,80 @@
+package audio
+
+import (
+	"testing"
+)
+
+func TestResample_SameRate(t *testing.T) {
+	samples := []int16{1, 2, 3}
+	result := Resample(samples, 16000, 16000)
+
+	if len(result) != len(samples) {
+		t.Errorf("Expected samples unchanged, got %v", result)
+	}
+}
+
+func TestResample_Downsample(t *testing.T) {
+	// 48kHz -> 16kHz keeps every third sample
+	samples := make([]int16, 48000)
+	for i := range samples {
+		samples[i] = int16(i % 3000)
+	}
+
+	result := Resample(samples, 48000, 16000)
+
+	if len(result) != 16000 {
+		t.Fatalf("Expected 16000 samples, got %d", len(result))
+	}
+
+	for i := 0; i < 100; i++ {
+		if result[i] != samples[i*3] {
+			t.Errorf("Sample %d: expected %d, got %d", i, samples[i*3], result[i])
+			break
+		}
+	}
+}
+
+func TestResample_Length(t *testing.T) {
+	tests := []struct {
+		fromRate int
+		samples  int
+		expected int
+	}{
+		{48000, 48000, 16000},
+		{48000, 1024, 341},
+		{44100, 44100, 16000},
+		{44100, 1, 0},
+	}
+
+	for _, tt := range tests {
+		if got := len(Resample(make([]int16, tt.samples), tt.fromRate, 16000)); got != tt.expected {
+			t.Errorf("Resample(%d samples, %d, 16000) returned %d samples, expected %d", tt.samples, tt.fromRate, got, tt.expected)
+		}
+	}
+}
+
+func TestResample_Upsample(t *testing.T) {
+	// 8kHz -> 16kHz interpolates midpoints
+	result := Resample([]int16{0, 100, 200}, 8000, 16000)
+
+	expected := []int16{0, 50, 100, 150, 200, 200}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d samples, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Sample %d: expected %d, got %d", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestResample_InvalidRate(t *testing.T) {
+	samples := []int16{1, 2, 3}
+
+	if result := Resample(samples, 0, 16000); len(result) != len(samples) {
+		t.Errorf("Expected samples unchanged for invalid rate, got %v", result)
+	}
+	if result := Resample(nil, 48000, 16000); len(result) != 0 {
+		t.Errorf("Expected no samples for empty input, got %v", result)
+	}
+}
@@ -0,0 +1,159 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// wavHeaderSize is the size of the canonical 44-byte PCM WAV header
+const wavHeaderSize = 44
+
+// writeWAVHeader writes the RIFF/WAVE, "fmt " and "data" chunk headers of a
+// 16-bit PCM WAV file holding dataSize bytes of interleaved samples
+func writeWAVHeader(w io.Writer, dataSize, sampleRate, channels int) {
+	blockAlign := channels * 2
+
+	io.WriteString(w, "RIFF")
+	binary.Write(w, binary.LittleEndian, uint32(wavHeaderSize-8+dataSize))
+	io.WriteString(w, "WAVE")
+
+	io.WriteString(w, "fmt ")
+	binary.Write(w, binary.LittleEndian, uint32(16))
+	binary.Write(w, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(w, binary.LittleEndian, uint16(channels))
+	binary.Write(w, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(w, binary.LittleEndian, uint32(sampleRate*blockAlign)) // Byte rate
+	binary.Write(w, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(w, binary.LittleEndian, uint16(16)) // Bits per sample
+
+	io.WriteString(w, "data")
+	binary.Write(w, binary.LittleEndian, uint32(dataSize))
+}
+
+// WriteWAV writes interleaved 16-bit little-endian PCM, as returned by
+// StopRecording, to path as a WAV file
+func WriteWAV(path string, pcm []byte, sampleRate, channels int) error {
+	if sampleRate <= 0 || channels <= 0 {
+		return fmt.Errorf("invalid WAV format: %d Hz, %d channels", sampleRate, channels)
+	}
+	if len(pcm)%(channels*2) != 0 {
+		return fmt.Errorf("invalid PCM data: %d bytes is not a whole number of %d-channel frames", len(pcm), channels)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(wavHeaderSize + len(pcm))
+	writeWAVHeader(&buf, len(pcm), sampleRate, channels)
+	buf.Write(pcm)
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// maxFmtChunkSize bounds the "fmt " chunk, which is 16 to 40 bytes in valid files
+const maxFmtChunkSize = 1024
+
+// wavReadSize is the size of the pieces ReadWAV reads the data chunk in, so
+// memory grows with the audio actually present instead of the size in the header
+const wavReadSize = 64 * 1024
+
+// ReadWAV reads a 16-bit PCM WAV stream and returns mono samples and the sample rate.
+// Multi-channel input is mixed down by averaging the channels.
+//
+// Chunk sizes in the header are not trusted for allocation: a data chunk that
+// claims more bytes than the stream holds yields the samples that are present,
+// and an oversized "fmt " chunk is rejected.
+func ReadWAV(r io.Reader) ([]int16, int, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, fmt.Errorf("failed to read RIFF header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, 0, fmt.Errorf("not a RIFF/WAVE file")
+	}
+
+	var channels, bitsPerSample, format uint16
+	var sampleRate uint32
+	haveFormat := false
+
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, 0, fmt.Errorf("data chunk not found: %w", err)
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, fmt.Errorf("fmt chunk too short")
+			}
+			if size > maxFmtChunkSize {
+				return nil, 0, fmt.Errorf("fmt chunk too large: %d bytes", size)
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, 0, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+			format = binary.LittleEndian.Uint16(data[0:2])
+			channels = binary.LittleEndian.Uint16(data[2:4])
+			sampleRate = binary.LittleEndian.Uint32(data[4:8])
+			bitsPerSample = binary.LittleEndian.Uint16(data[14:16])
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return nil, 0, fmt.Errorf("data chunk before fmt chunk")
+			}
+			if format != 1 || bitsPerSample != 16 {
+				return nil, 0, fmt.Errorf("unsupported WAV format: format=%d, bits=%d (need 16-bit PCM)", format, bitsPerSample)
+			}
+			if channels == 0 {
+				return nil, 0, fmt.Errorf("invalid channel count: 0")
+			}
+
+			samples, err := readWAVData(io.LimitReader(r, int64(size)), int(channels))
+			if err != nil {
+				return nil, 0, err
+			}
+			return samples, int(sampleRate), nil
+
+		default:
+			// Skip unknown chunks (LIST, fact, ...), including the pad byte of odd-sized chunks
+			if _, err := io.CopyN(io.Discard, r, int64(size)+int64(size%2)); err != nil {
+				return nil, 0, fmt.Errorf("failed to skip %q chunk: %w", id, err)
+			}
+		}
+	}
+}
+
+// readWAVData reads interleaved 16-bit samples until r is exhausted and mixes
+// them down to mono. A trailing partial frame is dropped.
+func readWAVData(r io.Reader, channels int) ([]int16, error) {
+	frameSize := 2 * channels
+	buf := make([]byte, max(wavReadSize/frameSize, 1)*frameSize)
+
+	var samples []int16
+	for {
+		n, err := io.ReadFull(r, buf)
+		frames := buf[:n-n%frameSize]
+
+		interleaved := make([]int16, len(frames)/2)
+		for i := range interleaved {
+			interleaved[i] = int16(binary.LittleEndian.Uint16(frames[i*2:]))
+		}
+		samples = append(samples, Downmix(interleaved, channels)...)
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data chunk: %w", err)
+		}
+	}
+}
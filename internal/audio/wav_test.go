@@ -0,0 +1,185 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.wav")
+	pcm := EncodePCM([]int16{1, -1, 1000, -1000})
+
+	if err := WriteWAV(path, pcm, 48000, 2); err != nil {
+		t.Fatalf("WriteWAV failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read WAV: %v", err)
+	}
+	if len(data) != wavHeaderSize+len(pcm) {
+		t.Fatalf("Expected %d bytes, got %d", wavHeaderSize+len(pcm), len(data))
+	}
+
+	le := binary.LittleEndian
+	checks := []struct {
+		name     string
+		got      uint32
+		expected uint32
+	}{
+		{"RIFF size", le.Uint32(data[4:8]), uint32(36 + len(pcm))},
+		{"fmt size", le.Uint32(data[16:20]), 16},
+		{"format", uint32(le.Uint16(data[20:22])), 1},
+		{"channels", uint32(le.Uint16(data[22:24])), 2},
+		{"sample rate", le.Uint32(data[24:28]), 48000},
+		{"byte rate", le.Uint32(data[28:32]), 48000 * 2 * 2},
+		{"block align", uint32(le.Uint16(data[32:34])), 4},
+		{"bits per sample", uint32(le.Uint16(data[34:36])), 16},
+		{"data size", le.Uint32(data[40:44]), uint32(len(pcm))},
+	}
+	for _, c := range checks {
+		if c.got != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.name, c.expected, c.got)
+		}
+	}
+
+	for _, chunk := range []struct {
+		offset int
+		id     string
+	}{{0, "RIFF"}, {8, "WAVE"}, {12, "fmt "}, {36, "data"}} {
+		if got := string(data[chunk.offset : chunk.offset+4]); got != chunk.id {
+			t.Errorf("Expected %q at %d, got %q", chunk.id, chunk.offset, got)
+		}
+	}
+	if !bytes.Equal(data[wavHeaderSize:], pcm) {
+		t.Error("Expected the PCM data after the header")
+	}
+}
+
+func TestWriteWAV_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.wav")
+
+	if err := WriteWAV(path, []byte{1, 2, 3}, 16000, 1); err == nil {
+		t.Error("Expected an error for a partial sample")
+	}
+	if err := WriteWAV(path, []byte{1, 2}, 0, 1); err == nil {
+		t.Error("Expected an error for an invalid sample rate")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected no file for invalid input")
+	}
+}
+
+// buildWAV encodes interleaved 16-bit samples as a PCM WAV file
+func buildWAV(samples []int16, sampleRate, channels int) []byte {
+	var buf bytes.Buffer
+	writeWAVHeader(&buf, len(samples)*2, sampleRate, channels)
+	binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+func TestReadWAV(t *testing.T) {
+	input := []int16{100, -100, 2000, -2000, 32767}
+	samples, rate, err := ReadWAV(bytes.NewReader(buildWAV(input, 16000, 1)))
+	if err != nil {
+		t.Fatalf("ReadWAV failed: %v", err)
+	}
+
+	if rate != 16000 {
+		t.Errorf("Expected sample rate 16000, got %d", rate)
+	}
+
+	if len(samples) != len(input) {
+		t.Fatalf("Expected %d samples, got %d", len(input), len(samples))
+	}
+	for i := range input {
+		if samples[i] != input[i] {
+			t.Errorf("Sample %d: expected %d, got %d", i, input[i], samples[i])
+		}
+	}
+}
+
+func TestReadWAV_StereoDownmix(t *testing.T) {
+	// Interleaved L/R frames
+	input := []int16{100, 300, -200, -400}
+	samples, _, err := ReadWAV(bytes.NewReader(buildWAV(input, 16000, 2)))
+	if err != nil {
+		t.Fatalf("ReadWAV failed: %v", err)
+	}
+
+	expected := []int16{200, -300}
+	if len(samples) != len(expected) {
+		t.Fatalf("Expected %d frames, got %d", len(expected), len(samples))
+	}
+	for i := range expected {
+		if samples[i] != expected[i] {
+			t.Errorf("Frame %d: expected %d, got %d", i, expected[i], samples[i])
+		}
+	}
+}
+
+func TestReadWAV_Invalid(t *testing.T) {
+	if _, _, err := ReadWAV(bytes.NewReader([]byte("not a wav file"))); err == nil {
+		t.Error("Expected error for invalid WAV data")
+	}
+}
+
+func TestReadWAV_TruncatedData(t *testing.T) {
+	// The header claims 10 samples but the stream ends after 3 and a half
+	data := buildWAV(make([]int16, 10), 16000, 1)
+	data = data[:wavHeaderSize+7]
+
+	samples, rate, err := ReadWAV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadWAV failed: %v", err)
+	}
+	if rate != 16000 || len(samples) != 3 {
+		t.Errorf("Expected the 3 complete samples at 16000 Hz, got %d at %d Hz", len(samples), rate)
+	}
+}
+
+func TestReadWAV_TruncatedHeader(t *testing.T) {
+	data := buildWAV([]int16{1, 2, 3}, 16000, 1)
+
+	// Cut inside the fmt chunk and before the data chunk header
+	for _, size := range []int{8, 20, 30, 38} {
+		if _, _, err := ReadWAV(bytes.NewReader(data[:size])); err == nil {
+			t.Errorf("Expected error for a header truncated to %d bytes", size)
+		}
+	}
+}
+
+func TestReadWAV_OversizedHeader(t *testing.T) {
+	le := binary.LittleEndian
+
+	// A data chunk claiming 4 GiB only yields the samples present
+	data := buildWAV([]int16{100, 200}, 16000, 1)
+	le.PutUint32(data[40:44], 0xFFFFFFFF)
+	samples, _, err := ReadWAV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadWAV failed: %v", err)
+	}
+	if len(samples) != 2 || samples[0] != 100 || samples[1] != 200 {
+		t.Errorf("Expected the 2 samples present, got %v", samples)
+	}
+
+	// An oversized fmt chunk is rejected before anything is allocated for it
+	data = buildWAV([]int16{100, 200}, 16000, 1)
+	le.PutUint32(data[16:20], 0xFFFFFFFF)
+	if _, _, err := ReadWAV(bytes.NewReader(data)); err == nil {
+		t.Error("Expected error for an oversized fmt chunk")
+	}
+
+	// So is an unknown chunk that claims more than the stream holds
+	var buf bytes.Buffer
+	buf.Write(data[:12])
+	buf.WriteString("LIST")
+	binary.Write(&buf, le, uint32(0xFFFFFFFF))
+	buf.WriteString("short")
+	if _, _, err := ReadWAV(&buf); err == nil {
+		t.Error("Expected error for an unknown chunk past the end of the stream")
+	}
+}
@@ -0,0 +1,57 @@
+// Package audiofile loads audio files for transcription in the same PCM format
+// the recorder produces.
+package audiofile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+)
+
+// IsSupported reports whether path has a supported audio file extension.
+// Only 16-bit PCM WAV files can be decoded without external tools.
+func IsSupported(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".wav")
+}
+
+// Load reads the audio file at path and returns 16-bit little-endian PCM with
+// channels interleaved copies of the mono signal at sampleRate
+func Load(path string, sampleRate, channels int) ([]byte, error) {
+	if !IsSupported(path) {
+		return nil, fmt.Errorf("unsupported audio file: %s (only WAV is supported)", filepath.Base(path))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer file.Close()
+
+	samples, rate, err := audio.ReadWAV(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	samples = audio.Resample(samples, rate, sampleRate)
+	return encodePCM(samples, channels), nil
+}
+
+// encodePCM encodes mono samples as 16-bit little-endian PCM, repeating each
+// sample for every channel
+func encodePCM(samples []int16, channels int) []byte {
+	if channels < 1 {
+		channels = 1
+	}
+
+	out := make([]byte, 0, len(samples)*2*channels)
+	for _, s := range samples {
+		for c := 0; c < channels; c++ {
+			out = binary.LittleEndian.AppendUint16(out, uint16(s))
+		}
+	}
+	return out
+}
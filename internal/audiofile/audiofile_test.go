@@ -0,0 +1,98 @@
+package audiofile
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/audio"
+)
+
+func TestIsSupported(t *testing.T) {
+	tests := map[string]bool{
+		"/tmp/memo.wav": true,
+		"/tmp/MEMO.WAV": true,
+		"/tmp/memo.m4a": false,
+		"/tmp/memo":     false,
+	}
+
+	for path, expected := range tests {
+		if got := IsSupported(path); got != expected {
+			t.Errorf("IsSupported(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// One second at 8kHz becomes one second at 16kHz
+	samples := make([]int16, 8000)
+	for i := range samples {
+		samples[i] = 1000
+	}
+
+	path := filepath.Join(t.TempDir(), "memo.wav")
+	if err := os.WriteFile(path, audio.EncodeWAV(samples, 8000), 0644); err != nil {
+		t.Fatalf("Failed to write WAV: %v", err)
+	}
+
+	pcm, err := Load(path, 16000, 1)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if frames := len(pcm) / 2; frames < 15900 || frames > 16100 {
+		t.Errorf("Expected about 16000 frames, got %d", frames)
+	}
+	if s := int16(binary.LittleEndian.Uint16(pcm[2000:])); s != 1000 {
+		t.Errorf("Expected the signal to be preserved, got sample %d", s)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "memo.m4a"), 16000, 1); err == nil {
+		t.Error("Expected an error for an unsupported extension")
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.wav"), 16000, 1); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	broken := filepath.Join(dir, "broken.wav")
+	os.WriteFile(broken, []byte("not a wav file"), 0644)
+	if _, err := Load(broken, 16000, 1); err == nil {
+		t.Error("Expected an error for an invalid WAV file")
+	}
+}
+
+func TestLoad_OversizedDataChunk(t *testing.T) {
+	// A dropped file whose header claims 4 GiB of audio loads the samples present
+	data := audio.EncodeWAV([]int16{1000, 1000, 1000, 1000}, 16000)
+	binary.LittleEndian.PutUint32(data[40:44], 0xFFFFFFFF)
+
+	path := filepath.Join(t.TempDir(), "memo.wav")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write WAV: %v", err)
+	}
+
+	pcm, err := Load(path, 16000, 1)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(pcm) != 8 {
+		t.Errorf("Expected the 4 samples present, got %d bytes", len(pcm))
+	}
+}
+
+func TestEncodePCM_Channels(t *testing.T) {
+	pcm := encodePCM([]int16{1, -1}, 2)
+
+	if len(pcm) != 8 {
+		t.Fatalf("Expected 2 frames of 2 channels, got %d bytes", len(pcm))
+	}
+	if int16(binary.LittleEndian.Uint16(pcm[2:])) != 1 || int16(binary.LittleEndian.Uint16(pcm[6:])) != -1 {
+		t.Errorf("Expected each sample repeated per channel, got %v", pcm)
+	}
+}
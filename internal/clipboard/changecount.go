@@ -0,0 +1,63 @@
+package clipboard
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// clipboardPollInterval is how often the change count is checked after writing the clipboard
+	clipboardPollInterval = 2 * time.Millisecond
+	// clipboardWriteTimeout is how long to wait for the pasteboard to take the new content
+	clipboardWriteTimeout = 500 * time.Millisecond
+)
+
+// ErrClipboardNotUpdated is returned when the pasteboard change count did not move
+// after writing the text, so Cmd+V would paste the previous content
+var ErrClipboardNotUpdated = errors.New("clipboard was not updated")
+
+// waitForClipboardWrite polls changeCount until it differs from before, i.e. the
+// pasteboard has taken the written content. It returns false if timeout elapses first.
+func waitForClipboardWrite(changeCount func() int, before int, timeout, interval time.Duration) bool {
+	if changeCount == nil {
+		return true
+	}
+
+	deadline := time.Now().Add(timeout)
+	for changeCount() == before {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(interval)
+	}
+	return true
+}
+
+// ErrClipboardModifiedExternally is returned after a successful paste when another
+// application wrote to the clipboard during it. The saved content is not restored
+// so the newer content is kept.
+var ErrClipboardModifiedExternally = errors.New("clipboard was modified during the paste, previous content not restored")
+
+// changeTracker follows the pasteboard change count across the writes of one
+// paste, so the saved content is only restored when nothing else wrote to the clipboard
+type changeTracker struct {
+	last     int  // Change count after our latest write, or when the clipboard was saved
+	external bool // Another application changed the clipboard between our writes
+}
+
+// beforeWrite notes a change made by someone else since our last write
+func (t *changeTracker) beforeWrite(current int) {
+	if current != t.last {
+		t.external = true
+	}
+}
+
+// wrote records the change count produced by our write
+func (t *changeTracker) wrote(current int) {
+	t.last = current
+}
+
+// unchanged reports whether only our writes changed the clipboard
+func (t *changeTracker) unchanged(current int) bool {
+	return !t.external && current == t.last
+}
@@ -0,0 +1,89 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+)
+
+// fakePasteboard increments its change count once the write lands after writePolls polls
+type fakePasteboard struct {
+	count      int
+	writePolls int
+	polls      int
+}
+
+func (f *fakePasteboard) changeCount() int {
+	f.polls++
+	if f.polls > f.writePolls {
+		return f.count + 1
+	}
+	return f.count
+}
+
+func TestWaitForClipboardWrite_AlreadyWritten(t *testing.T) {
+	pasteboard := &fakePasteboard{count: 7}
+
+	if !waitForClipboardWrite(pasteboard.changeCount, 7, time.Second, time.Millisecond) {
+		t.Error("Expected wait to succeed once the change count moved")
+	}
+
+	if pasteboard.polls != 1 {
+		t.Errorf("Expected a single poll without sleeping, got %d", pasteboard.polls)
+	}
+}
+
+func TestWaitForClipboardWrite_WrittenLater(t *testing.T) {
+	pasteboard := &fakePasteboard{count: 7, writePolls: 3}
+
+	if !waitForClipboardWrite(pasteboard.changeCount, 7, time.Second, time.Millisecond) {
+		t.Error("Expected wait to succeed once the write lands")
+	}
+
+	if pasteboard.polls != 4 {
+		t.Errorf("Expected 4 polls, got %d", pasteboard.polls)
+	}
+}
+
+func TestWaitForClipboardWrite_Timeout(t *testing.T) {
+	pasteboard := &fakePasteboard{count: 7, writePolls: 1 << 30}
+
+	start := time.Now()
+	if waitForClipboardWrite(pasteboard.changeCount, 7, 30*time.Millisecond, time.Millisecond) {
+		t.Error("Expected wait to fail while the change count stays the same")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected wait to be bounded by the timeout, took %v", elapsed)
+	}
+}
+
+func TestChangeTracker(t *testing.T) {
+	// Three chunks, each write moving the count by one
+	tracker := changeTracker{last: 10}
+	for count := 10; count < 13; count++ {
+		tracker.beforeWrite(count)
+		tracker.wrote(count + 1)
+	}
+	if !tracker.unchanged(13) {
+		t.Error("Expected only our writes to be seen")
+	}
+
+	// The user copies something after the last chunk
+	if tracker.unchanged(14) {
+		t.Error("Expected a copy after the paste to be detected")
+	}
+}
+
+func TestChangeTracker_CopyBetweenChunks(t *testing.T) {
+	tracker := changeTracker{last: 10}
+	tracker.beforeWrite(10)
+	tracker.wrote(11)
+
+	// The user copies something during the wait between chunks
+	tracker.beforeWrite(12)
+	tracker.wrote(13)
+
+	if tracker.unchanged(13) {
+		t.Error("Expected a copy between chunks to be detected")
+	}
+}
@@ -0,0 +1,50 @@
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pasteChunks pastes each chunk with paste, waiting interval between chunks.
+// It checks ctx before every chunk and during the wait, returning ctx.Err()
+// without pasting the rest once ctx is done.
+func pasteChunks(ctx context.Context, chunks []string, interval time.Duration, paste func(string) error) error {
+	for i, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := paste(chunk); err != nil {
+			return fmt.Errorf("failed to paste chunk %d: %w", i, err)
+		}
+
+		// Wait between chunks (except for the last one)
+		if i < len(chunks)-1 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitIntervalFor returns the override for the app with bundleID (matched
+// case-insensitively), or fallback when it has none
+func splitIntervalFor(bundleID string, fallback time.Duration, overrides map[string]time.Duration) time.Duration {
+	if bundleID == "" {
+		return fallback
+	}
+	for id, interval := range overrides {
+		if strings.EqualFold(id, bundleID) {
+			return interval
+		}
+	}
+	return fallback
+}
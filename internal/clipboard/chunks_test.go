@@ -0,0 +1,98 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPasteChunks(t *testing.T) {
+	var pasted []string
+	paste := func(chunk string) error {
+		pasted = append(pasted, chunk)
+		return nil
+	}
+
+	if err := pasteChunks(context.Background(), []string{"a", "b", "c"}, time.Millisecond, paste); err != nil {
+		t.Fatalf("pasteChunks failed: %v", err)
+	}
+
+	if len(pasted) != 3 || pasted[0] != "a" || pasted[2] != "c" {
+		t.Errorf("Expected all chunks in order, got %v", pasted)
+	}
+}
+
+func TestPasteChunks_CancelledBetweenChunks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var pasted []string
+	paste := func(chunk string) error {
+		pasted = append(pasted, chunk)
+		if chunk == "a" {
+			cancel() // e.g. the user quits while the first chunk is pasted
+		}
+		return nil
+	}
+
+	start := time.Now()
+	err := pasteChunks(ctx, []string{"a", "b", "c", "d"}, time.Minute, paste)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(pasted) != 1 {
+		t.Errorf("Expected the chunk in progress to finish and the rest to be skipped, got %v", pasted)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait between chunks to be interrupted, took %v", elapsed)
+	}
+}
+
+func TestPasteChunks_AlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := pasteChunks(ctx, []string{"a"}, time.Millisecond, func(string) error {
+		called = true
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("Expected nothing pasted after cancel, got err=%v called=%v", err, called)
+	}
+}
+
+func TestPasteChunks_Error(t *testing.T) {
+	err := pasteChunks(context.Background(), []string{"a", "b"}, time.Millisecond, func(chunk string) error {
+		if chunk == "b" {
+			return ErrAccessibilityDenied
+		}
+		return nil
+	})
+
+	if !errors.Is(err, ErrAccessibilityDenied) || err.Error() != "failed to paste chunk 1: accessibility permission denied" {
+		t.Errorf("Expected wrapped chunk error, got %v", err)
+	}
+}
+
+func TestSplitIntervalFor(t *testing.T) {
+	overrides := map[string]time.Duration{"com.tinyspeck.slackmacgap": 200 * time.Millisecond}
+
+	tests := []struct {
+		bundleID string
+		expected time.Duration
+	}{
+		{"com.tinyspeck.slackmacgap", 200 * time.Millisecond},
+		{"com.tinyspeck.SlackMacGap", 200 * time.Millisecond},
+		{"Slack", 50 * time.Millisecond},
+		{"com.apple.Notes", 50 * time.Millisecond},
+		{"", 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := splitIntervalFor(tt.bundleID, 50*time.Millisecond, overrides); got != tt.expected {
+			t.Errorf("splitIntervalFor(%q) = %v, expected %v", tt.bundleID, got, tt.expected)
+		}
+	}
+}
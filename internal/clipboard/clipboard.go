@@ -2,36 +2,89 @@ package clipboard
 
 /*
 #cgo CFLAGS: -x objective-c
-#cgo LDFLAGS: -framework Cocoa
+#cgo LDFLAGS: -framework Cocoa -framework Carbon
 #import <Cocoa/Cocoa.h>
+#import <Carbon/Carbon.h>
+#include <stdlib.h>
 
 int get_pasteboard_change_count() {
     return (int)[[NSPasteboard generalPasteboard] changeCount];
 }
+
+int modifier_keys_held() {
+    CGEventFlags flags = CGEventSourceFlagsState(kCGEventSourceStateCombinedSessionState);
+    CGEventFlags mask = kCGEventFlagMaskShift | kCGEventFlagMaskControl |
+                        kCGEventFlagMaskAlternate | kCGEventFlagMaskCommand;
+    return (flags & mask) != 0;
+}
+
+int secure_input_enabled() {
+    return IsSecureEventInputEnabled() ? 1 : 0;
+}
+
+// clipboard_file_paths returns a malloc'd, newline-separated list of the file
+// paths on the pasteboard (e.g. files copied in Finder), or NULL if there are none.
+// The caller must free it.
+char* clipboard_file_paths() {
+    @autoreleasepool {
+        NSArray *urls = [[NSPasteboard generalPasteboard] readObjectsForClasses:@[[NSURL class]]
+                                                                        options:@{NSPasteboardURLReadingFileURLsOnlyKey: @YES}];
+        if (urls == nil || urls.count == 0) {
+            return NULL;
+        }
+        NSMutableArray *paths = [NSMutableArray arrayWithCapacity:urls.count];
+        for (NSURL *url in urls) {
+            [paths addObject:url.path];
+        }
+        return strdup([[paths componentsJoinedByString:@"\n"] UTF8String]);
+    }
+}
 */
 import "C"
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/go-vgo/robotgo"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/frontapp"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/permissions"
 )
 
 // Manager manages clipboard operations with safe restoration
 type Manager struct {
 	savedChangeCount int
 	savedContent     string
+	changes          changeTracker // Change counts of the paste in progress, reset by SaveClipboard
+	mu               sync.Mutex    // Held for a whole paste so that SetConfig never changes the settings below mid-paste
 	restoreTimeout   time.Duration
 	splitSize        int
 	splitInterval    time.Duration
+	modifierTimeout  time.Duration // Maximum wait for hotkey modifiers to be released before pasting
+	modifiersHeld    func() bool   // Reports whether any modifier key is physically held (replaced in tests)
+	accessibility    *trustCache   // Re-checks accessibility trust before sending keystrokes
+	secureInput      func() bool   // Reports whether secure keyboard entry is enabled (replaced in tests)
+	changeCount      func() int    // Returns the pasteboard change count (replaced in tests)
+
+	appIntervals    map[string]time.Duration // Per-app overrides of splitInterval, keyed by bundle identifier
+	frontmostBundle func() string            // Returns the frontmost app bundle identifier (replaced in tests)
 }
 
 // Config holds clipboard manager configuration
 type Config struct {
 	RestoreTimeout time.Duration // Timeout for clipboard restoration (default: 500ms)
 	SplitSize      int           // Maximum characters per paste operation (default: 500)
-	SplitInterval  time.Duration // Interval between split pastes (default: 50ms)
+	SplitInterval  time.Duration // Interval between split pastes, at least RestoreTimeout (default: 50ms)
+	// AppSplitIntervals overrides SplitInterval for apps that drop characters when
+	// chunks arrive too fast, keyed by the frontmost app bundle identifier
+	// (e.g. "com.tinyspeck.slackmacgap", case-insensitive)
+	AppSplitIntervals map[string]time.Duration
+	// ModifierReleaseTimeout is how long to wait for held modifier keys (e.g. the
+	// hotkey's Ctrl+Alt) to be released before sending Cmd+V (default: 2s, 0 = don't wait)
+	ModifierReleaseTimeout time.Duration
 }
 
 // DefaultConfig returns the default clipboard configuration
@@ -40,16 +93,35 @@ func DefaultConfig() Config {
 		RestoreTimeout: 500 * time.Millisecond,
 		SplitSize:      500,
 		SplitInterval:  50 * time.Millisecond,
+
+		ModifierReleaseTimeout: 2 * time.Second,
 	}
 }
 
 // NewManager creates a new clipboard manager
 func NewManager(config Config) *Manager {
-	return &Manager{
-		restoreTimeout: config.RestoreTimeout,
-		splitSize:      config.SplitSize,
-		splitInterval:  config.SplitInterval,
+	m := &Manager{
+		frontmostBundle: frontapp.BundleID,
+		modifiersHeld:   ModifierKeysHeld,
+		accessibility:   newTrustCache(permissions.NewPermissionChecker().IsAccessibilityAuthorized, trustCacheTTL),
+		secureInput:     SecureInputEnabled,
+		changeCount:     GetChangeCount,
 	}
+	m.SetConfig(config)
+	return m
+}
+
+// SetConfig replaces the paste settings, e.g. after they were changed on the
+// settings page. A paste in progress finishes with the previous settings.
+func (m *Manager) SetConfig(config Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.restoreTimeout = config.RestoreTimeout
+	m.splitSize = config.SplitSize
+	m.splitInterval = config.SplitInterval
+	m.appIntervals = config.AppSplitIntervals
+	m.modifierTimeout = config.ModifierReleaseTimeout
 }
 
 // GetChangeCount returns the current pasteboard change count
@@ -57,9 +129,31 @@ func GetChangeCount() int {
 	return int(C.get_pasteboard_change_count())
 }
 
+// ModifierKeysHeld reports whether Shift, Control, Option or Command is currently held
+func ModifierKeysHeld() bool {
+	return C.modifier_keys_held() != 0
+}
+
+// SecureInputEnabled reports whether any application has secure keyboard entry enabled
+func SecureInputEnabled() bool {
+	return C.secure_input_enabled() != 0
+}
+
+// FilePaths returns the paths of the files on the clipboard, e.g. files copied in Finder
+func FilePaths() []string {
+	cPaths := C.clipboard_file_paths()
+	if cPaths == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(cPaths))
+
+	return strings.Split(C.GoString(cPaths), "\n")
+}
+
 // SaveClipboard saves the current clipboard state
 func (m *Manager) SaveClipboard() error {
-	m.savedChangeCount = GetChangeCount()
+	m.savedChangeCount = m.changeCount()
+	m.changes = changeTracker{last: m.savedChangeCount}
 	content, err := robotgo.ReadAll()
 	if err != nil {
 		return fmt.Errorf("failed to read clipboard: %w", err)
@@ -68,74 +162,142 @@ func (m *Manager) SaveClipboard() error {
 	return nil
 }
 
-// RestoreClipboard restores the clipboard if it hasn't been modified externally
+// RestoreClipboard restores the content saved by SaveClipboard once the paste has
+// completed. If anything other than the paste wrote to the clipboard since then
+// (e.g. the user copied something), it returns ErrClipboardModifiedExternally and
+// keeps the newer content.
 func (m *Manager) RestoreClipboard() error {
 	// Wait a bit for the paste operation to complete
 	time.Sleep(m.restoreTimeout)
 
-	// Check if the change count matches (only one change = our paste operation)
-	currentChangeCount := GetChangeCount()
-
-	// If the change count increased by exactly 1, we're the only one who modified it
-	// In this case, restore the original content
-	if currentChangeCount == m.savedChangeCount+1 {
-		robotgo.WriteAll(m.savedContent)
-		return nil
+	if !m.changes.unchanged(m.changeCount()) {
+		return ErrClipboardModifiedExternally
 	}
 
-	// If the change count is different, the user modified the clipboard during our operation
-	// Don't restore in this case
+	if err := robotgo.WriteAll(m.savedContent); err != nil {
+		return fmt.Errorf("failed to restore clipboard: %w", err)
+	}
 	return nil
 }
 
+// CopyText places text on the clipboard without pasting it
+func (m *Manager) CopyText(text string) error {
+	return SetClipboardContent(text)
+}
+
 // SafePaste pastes text to the active application with safe clipboard restoration
 func (m *Manager) SafePaste(text string) error {
-	// Save current clipboard state
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pasteRestoring(context.Background(), []string{text}, 0)
+}
+
+// SafePasteWithSplit pastes text with automatic splitting for long texts
+func (m *Manager) SafePasteWithSplit(text string) error {
+	return m.SafePasteWithSplitContext(context.Background(), text)
+}
+
+// SafePasteWithSplitContext is SafePasteWithSplit that stops between chunks once
+// ctx is done and returns ctx.Err(). A chunk that is already being pasted is
+// always finished so the clipboard is restored.
+func (m *Manager) SafePasteWithSplitContext(ctx context.Context, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// If text is short enough, paste directly
+	if len(text) <= m.splitSize {
+		return m.pasteRestoring(context.Background(), []string{text}, 0)
+	}
+
+	return m.pasteRestoring(ctx, m.SplitText(text), m.chunkInterval())
+}
+
+// pasteRestoring saves the clipboard once, pastes the chunks through it and
+// restores it once at the end. When a chunk fails the pasted text is left on
+// the clipboard so the user can paste it manually.
+func (m *Manager) pasteRestoring(ctx context.Context, chunks []string, interval time.Duration) error {
 	if err := m.SaveClipboard(); err != nil {
 		return fmt.Errorf("failed to save clipboard: %w", err)
 	}
 
-	// Copy the text to clipboard
-	robotgo.WriteAll(text)
+	err := pasteChunks(ctx, chunks, interval, m.pasteChunk)
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
 
-	// Wait a bit for clipboard to update
-	time.Sleep(10 * time.Millisecond)
+	// Stopped between chunks: put the user's content back before returning ctx.Err()
+	if restoreErr := m.RestoreClipboard(); err == nil {
+		err = restoreErr
+	}
+	return err
+}
 
-	// Send Cmd+V to paste
-	robotgo.KeyTap("v", "cmd")
+// pasteChunk writes text to the clipboard and sends Cmd+V
+func (m *Manager) pasteChunk(text string) error {
+	before := m.changeCount()
+	m.changes.beforeWrite(before)
 
-	// Restore clipboard after a timeout
-	return m.RestoreClipboard()
-}
+	// Copy the text to clipboard
+	if err := robotgo.WriteAll(text); err != nil {
+		return &PasteError{Stage: StageClipboard, Err: err}
+	}
 
-// SafePasteWithSplit pastes text with automatic splitting for long texts
-func (m *Manager) SafePasteWithSplit(text string) error {
-	// If text is short enough, paste directly
-	if len(text) <= m.splitSize {
-		return m.SafePaste(text)
+	// Cmd+V must not reach the app before the pasteboard has the new text, or the
+	// previous content is pasted. Wait until the change count moves instead of a fixed sleep.
+	if !waitForClipboardWrite(m.changeCount, before, clipboardWriteTimeout, clipboardPollInterval) {
+		return &PasteError{Stage: StageClipboard, Err: ErrClipboardNotUpdated}
 	}
+	m.changes.wrote(m.changeCount())
 
-	// Split text into chunks
-	chunks := m.splitText(text)
+	// Another application may have written right after us; check the text is what Cmd+V will paste
+	if content, err := robotgo.ReadAll(); err == nil && content != text {
+		return &PasteError{Stage: StageClipboard, Err: ErrClipboardNotUpdated}
+	}
 
-	// Paste each chunk
-	for i, chunk := range chunks {
-		if err := m.SafePaste(chunk); err != nil {
-			return fmt.Errorf("failed to paste chunk %d: %w", i, err)
-		}
+	// Without accessibility trust KeyTap is silently dropped. Leave the text on the
+	// clipboard (no restore) so the user can paste it manually.
+	if !m.accessibility.Trusted() {
+		return &PasteError{Stage: StageKeystroke, Err: ErrAccessibilityDenied}
+	}
 
-		// Wait between chunks (except for the last one)
-		if i < len(chunks)-1 {
-			time.Sleep(m.splitInterval)
-		}
+	// Secure keyboard entry (password fields, some terminals) swallows synthetic
+	// keystrokes the same way. Leave the text on the clipboard here too.
+	if m.secureInput != nil && m.secureInput() {
+		return &PasteError{Stage: StageSecureInput, Err: ErrSecureInput}
 	}
 
+	// With press-to-hold the hotkey modifiers may still be physically held, which would
+	// turn Cmd+V into e.g. Ctrl+Alt+Cmd+V. Wait (bounded) until they are released.
+	// On timeout paste anyway; the target app may still accept it.
+	waitForModifierRelease(m.modifiersHeld, m.modifierTimeout, modifierPollInterval)
+
+	// Send Cmd+V to paste
+	if err := robotgo.KeyTap("v", "cmd"); err != nil {
+		return &PasteError{Stage: StageKeystroke, Err: err}
+	}
 	return nil
 }
 
-// splitText splits text into chunks of maximum splitSize characters
-// Tries to split at sentence boundaries (。、. ,) when possible
-func (m *Manager) splitText(text string) []string {
+// chunkInterval returns the wait between chunks for the frontmost app. It is
+// never shorter than restoreTimeout: the app reads the pasteboard some time
+// after Cmd+V, and writing the next chunk sooner can paste it in place of the
+// previous one.
+func (m *Manager) chunkInterval() time.Duration {
+	interval := m.splitInterval
+	if len(m.appIntervals) > 0 && m.frontmostBundle != nil {
+		interval = splitIntervalFor(m.frontmostBundle(), m.splitInterval, m.appIntervals)
+	}
+	return max(interval, m.restoreTimeout)
+}
+
+// SplitText splits text into chunks of maximum splitSize characters, as
+// SafePasteWithSplit pastes them. Tries to split at sentence boundaries
+// (。、. ,) when possible.
+func (m *Manager) SplitText(text string) []string {
 	if len(text) <= m.splitSize {
 		return []string{text}
 	}
@@ -35,6 +35,44 @@ func TestNewManager(t *testing.T) {
 	}
 }
 
+func TestSetConfig(t *testing.T) {
+	manager := NewManager(DefaultConfig())
+
+	config := DefaultConfig()
+	config.SplitSize = 100
+	config.SplitInterval = 800 * time.Millisecond
+	config.AppSplitIntervals = map[string]time.Duration{"com.tinyspeck.slackmacgap": time.Second}
+	config.ModifierReleaseTimeout = 0
+	manager.SetConfig(config)
+
+	if manager.splitSize != 100 || manager.splitInterval != 800*time.Millisecond || manager.modifierTimeout != 0 {
+		t.Errorf("Expected the new settings, got size %d interval %v modifier timeout %v", manager.splitSize, manager.splitInterval, manager.modifierTimeout)
+	}
+	if manager.appIntervals["com.tinyspeck.slackmacgap"] != time.Second {
+		t.Errorf("Expected the new per-app intervals, got %v", manager.appIntervals)
+	}
+}
+
+func TestChunkInterval(t *testing.T) {
+	manager := &Manager{
+		restoreTimeout: 500 * time.Millisecond,
+		splitInterval:  50 * time.Millisecond,
+		appIntervals:   map[string]time.Duration{"com.tinyspeck.slackmacgap": 800 * time.Millisecond, "com.apple.Notes": 100 * time.Millisecond},
+	}
+
+	// Chunks never overwrite the pasteboard before the app has read the previous one
+	for bundleID, expected := range map[string]time.Duration{
+		"":                          500 * time.Millisecond,
+		"com.apple.Notes":           500 * time.Millisecond,
+		"com.tinyspeck.slackmacgap": 800 * time.Millisecond,
+	} {
+		manager.frontmostBundle = func() string { return bundleID }
+		if got := manager.chunkInterval(); got != expected {
+			t.Errorf("%q: expected %v, got %v", bundleID, expected, got)
+		}
+	}
+}
+
 func TestGetChangeCount(t *testing.T) {
 	// Test that GetChangeCount returns a valid integer
 	changeCount := GetChangeCount()
@@ -69,7 +107,7 @@ func TestSplitText_ShortText(t *testing.T) {
 	manager := NewManager(config)
 
 	text := "Short text"
-	chunks := manager.splitText(text)
+	chunks := manager.SplitText(text)
 
 	if len(chunks) != 1 {
 		t.Errorf("Expected 1 chunk for short text, got %d", len(chunks))
@@ -89,7 +127,7 @@ func TestSplitText_LongText(t *testing.T) {
 	manager := NewManager(config)
 
 	text := "This is a long text that should be split into multiple chunks."
-	chunks := manager.splitText(text)
+	chunks := manager.SplitText(text)
 
 	if len(chunks) <= 1 {
 		t.Errorf("Expected multiple chunks for long text, got %d", len(chunks))
@@ -111,7 +149,7 @@ func TestSplitText_WithSentences(t *testing.T) {
 	manager := NewManager(config)
 
 	text := "これは文です。これも文です。これも文です。"
-	chunks := manager.splitText(text)
+	chunks := manager.SplitText(text)
 
 	if len(chunks) <= 1 {
 		t.Errorf("Expected multiple chunks, got %d", len(chunks))
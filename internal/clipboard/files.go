@@ -0,0 +1,30 @@
+package clipboard
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// FilePathFromText returns the local file path in clipboard text, accepting a
+// file:// URL or an absolute path on a single line
+func FilePathFromText(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, "\r\n") {
+		return "", false
+	}
+
+	if strings.HasPrefix(text, "file://") {
+		u, err := url.Parse(text)
+		if err != nil || (u.Host != "" && u.Host != "localhost") || u.Path == "" {
+			return "", false
+		}
+		return u.Path, true
+	}
+
+	if filepath.IsAbs(text) {
+		return text, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,27 @@
+package clipboard
+
+import "testing"
+
+func TestFilePathFromText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+		ok       bool
+	}{
+		{"file:///Users/test/Desktop/memo.wav", "/Users/test/Desktop/memo.wav", true},
+		{"file://localhost/Users/test/memo.wav", "/Users/test/memo.wav", true},
+		{"file:///Users/test/%E3%83%A1%E3%83%A2.wav", "/Users/test/メモ.wav", true},
+		{"  /Users/test/memo.wav\n", "/Users/test/memo.wav", true},
+		{"file://server/share/memo.wav", "", false},
+		{"memo.wav", "", false},
+		{"/Users/test/a.wav\n/Users/test/b.wav", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := FilePathFromText(tt.text)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("FilePathFromText(%q) = %q, %v; expected %q, %v", tt.text, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package clipboard
+
+import (
+	"time"
+)
+
+// modifierPollInterval is how often the modifier state is checked while waiting
+const modifierPollInterval = 20 * time.Millisecond
+
+// waitForModifierRelease polls modifiersHeld until it reports false or timeout elapses.
+// It returns true if the modifiers were released (or were never held) and false on timeout.
+// A non-positive timeout disables the wait.
+func waitForModifierRelease(modifiersHeld func() bool, timeout, interval time.Duration) bool {
+	if timeout <= 0 || modifiersHeld == nil {
+		return true
+	}
+
+	deadline := time.Now().Add(timeout)
+	for modifiersHeld() {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(interval)
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeModifiers reports modifiers as held for the first heldPolls calls
+type fakeModifiers struct {
+	heldPolls int
+	polls     int
+}
+
+func (f *fakeModifiers) held() bool {
+	f.polls++
+	return f.polls <= f.heldPolls
+}
+
+func TestWaitForModifierRelease_NotHeld(t *testing.T) {
+	modifiers := &fakeModifiers{}
+
+	if !waitForModifierRelease(modifiers.held, time.Second, time.Millisecond) {
+		t.Error("Expected wait to succeed when no modifiers are held")
+	}
+
+	if modifiers.polls != 1 {
+		t.Errorf("Expected a single poll, got %d", modifiers.polls)
+	}
+}
+
+func TestWaitForModifierRelease_ReleasedInTime(t *testing.T) {
+	modifiers := &fakeModifiers{heldPolls: 3}
+
+	if !waitForModifierRelease(modifiers.held, time.Second, time.Millisecond) {
+		t.Error("Expected wait to succeed once modifiers are released")
+	}
+
+	if modifiers.polls != 4 {
+		t.Errorf("Expected 4 polls, got %d", modifiers.polls)
+	}
+}
+
+func TestWaitForModifierRelease_Timeout(t *testing.T) {
+	modifiers := &fakeModifiers{heldPolls: 1 << 30}
+
+	start := time.Now()
+	if waitForModifierRelease(modifiers.held, 30*time.Millisecond, time.Millisecond) {
+		t.Error("Expected wait to time out while modifiers are held")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected wait to be bounded by the timeout, took %v", elapsed)
+	}
+}
+
+func TestWaitForModifierRelease_Disabled(t *testing.T) {
+	modifiers := &fakeModifiers{heldPolls: 1 << 30}
+
+	if !waitForModifierRelease(modifiers.held, 0, time.Millisecond) {
+		t.Error("Expected zero timeout to disable the wait")
+	}
+
+	if modifiers.polls != 0 {
+		t.Errorf("Expected no polls when disabled, got %d", modifiers.polls)
+	}
+}
@@ -0,0 +1,42 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Stages of a paste, reported by PasteError so callers can tell the user
+// which part of the output path failed
+const (
+	StageClipboard   = "clipboard"    // Writing the text to the clipboard
+	StageKeystroke   = "keystroke"    // Sending Cmd+V to the active application
+	StageSecureInput = "secure_input" // Another application has secure keyboard entry enabled
+)
+
+// ErrSecureInput is returned when another application (typically a password field or
+// a terminal) has secure keyboard entry enabled, which blocks synthetic keystrokes.
+// The text is left on the clipboard.
+var ErrSecureInput = errors.New("secure keyboard entry is enabled by another application")
+
+// PasteError records the stage at which a paste failed
+type PasteError struct {
+	Stage string
+	Err   error
+}
+
+func (e *PasteError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *PasteError) Unwrap() error {
+	return e.Err
+}
+
+// FailedStage returns the stage recorded in err, or "" if err does not carry one
+func FailedStage(err error) string {
+	var pasteErr *PasteError
+	if errors.As(err, &pasteErr) {
+		return pasteErr.Stage
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFailedStage(t *testing.T) {
+	denied := &PasteError{Stage: StageKeystroke, Err: ErrAccessibilityDenied}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"keystroke", denied, StageKeystroke},
+		{"wrapped", fmt.Errorf("failed to paste chunk 1: %w", denied), StageKeystroke},
+		{"secure input", &PasteError{Stage: StageSecureInput, Err: ErrSecureInput}, StageSecureInput},
+		{"plain error", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailedStage(tt.err); got != tt.expected {
+				t.Errorf("FailedStage() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPasteError_Unwrap(t *testing.T) {
+	err := fmt.Errorf("failed to paste chunk 0: %w", &PasteError{Stage: StageKeystroke, Err: ErrAccessibilityDenied})
+
+	// Existing callers match on the sentinel errors
+	if !errors.Is(err, ErrAccessibilityDenied) {
+		t.Error("Expected errors.Is to find ErrAccessibilityDenied through PasteError")
+	}
+
+	if got := err.Error(); got != "failed to paste chunk 0: keystroke: accessibility permission denied" {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
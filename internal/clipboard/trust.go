@@ -0,0 +1,54 @@
+package clipboard
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAccessibilityDenied is returned when synthetic keystrokes would be dropped because
+// the process is no longer trusted for accessibility. The text is left on the clipboard.
+var ErrAccessibilityDenied = errors.New("accessibility permission denied")
+
+// trustCacheTTL is how long an accessibility check result is reused, so that a split
+// paste does not query the system for every chunk
+const trustCacheTTL = 2 * time.Second
+
+// trustCache memoizes an accessibility check for a short time
+type trustCache struct {
+	check     func() bool
+	ttl       time.Duration
+	now       func() time.Time // Replaced in tests
+	mu        sync.Mutex
+	checkedAt time.Time
+	trusted   bool
+	valid     bool
+}
+
+// newTrustCache creates a cache around check that keeps results for ttl
+func newTrustCache(check func() bool, ttl time.Duration) *trustCache {
+	return &trustCache{
+		check: check,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Trusted returns the cached result, calling check again once it is older than ttl.
+// A nil cache or check reports trusted so that the paste is not blocked.
+func (c *trustCache) Trusted() bool {
+	if c == nil || c.check == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if !c.valid || now.Sub(c.checkedAt) >= c.ttl {
+		c.trusted = c.check()
+		c.checkedAt = now
+		c.valid = true
+	}
+	return c.trusted
+}
@@ -0,0 +1,62 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrustCache_ReusesResultWithinTTL(t *testing.T) {
+	calls := 0
+	cache := newTrustCache(func() bool {
+		calls++
+		return true
+	}, time.Second)
+
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		if !cache.Trusted() {
+			t.Fatal("Expected trusted")
+		}
+		now = now.Add(100 * time.Millisecond)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected a single check within the TTL, got %d", calls)
+	}
+}
+
+func TestTrustCache_RechecksAfterTTL(t *testing.T) {
+	trusted := true
+	cache := newTrustCache(func() bool { return trusted }, time.Second)
+
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	if !cache.Trusted() {
+		t.Fatal("Expected trusted before revocation")
+	}
+
+	// Revoked, but the cached result is still fresh
+	trusted = false
+	if !cache.Trusted() {
+		t.Error("Expected cached result within the TTL")
+	}
+
+	now = now.Add(time.Second)
+	if cache.Trusted() {
+		t.Error("Expected revocation to be seen after the TTL")
+	}
+}
+
+func TestTrustCache_NilCheck(t *testing.T) {
+	var cache *trustCache
+	if !cache.Trusted() {
+		t.Error("Expected nil cache to report trusted")
+	}
+
+	if !newTrustCache(nil, time.Second).Trusted() {
+		t.Error("Expected nil check to report trusted")
+	}
+}
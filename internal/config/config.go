@@ -3,34 +3,119 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // Config holds application configuration
 type Config struct {
-	Hotkey        HotkeyConfig `json:"hotkey"`
-	RecordingMode string       `json:"recording_mode"` // "press-to-hold" or "toggle"
-	ModelPath     string       `json:"model_path"`
-	Language      string       `json:"language"` // "auto" for automatic detection, or specific language code
-	AudioDeviceID int          `json:"audio_device_id"`
-	UILanguage    string       `json:"ui_language"` // "ja" or "en"
-	MaxRecordTime int          `json:"max_record_time"` // seconds
-	PasteSplitSize int         `json:"paste_split_size"` // characters
-	mu            sync.RWMutex
+	Hotkey                        HotkeyConfig `json:"hotkey"`
+	RecordingMode                 string       `json:"recording_mode"` // "press-to-hold" or "toggle"
+	ModelPath                     string       `json:"model_path"`
+	Language                      string       `json:"language"` // "auto" for automatic detection, or specific language code
+	AudioDeviceID                 int          `json:"audio_device_id"`
+	AudioBackend                  string       `json:"audio_backend"`                    // "portaudio" or "fake" (demo / development without a microphone)
+	FakeAudioSource               string       `json:"fake_audio_source"`                // fake backend: WAV file path, "sine" or "silence"
+	UILanguage                    string       `json:"ui_language"`                      // "ja" or "en"
+	TranslationMode               string       `json:"translation_mode"`                 // "transcribe" or "translate": keep the spoken language or translate the speech to English
+	MaxRecordTime                 int          `json:"max_record_time"`                  // seconds
+	ToggleMaxRecordTime           int          `json:"toggle_max_record_time"`           // seconds in toggle mode, 0 = max_record_time
+	AudioTrimSilence              bool         `json:"audio_trim_silence"`               // trim leading/trailing silence before transcription
+	AudioNormalize                bool         `json:"audio_normalize"`                  // normalize the recording level before transcription
+	StartBeep                     bool         `json:"start_beep"`                       // play a short rising tone the moment recording starts
+	PreventSleepWhileRecording    bool         `json:"prevent_sleep_while_recording"`    // keep the Mac from idle sleeping while recording
+	Streaming                     bool         `json:"streaming"`                        // transcribe while recording and preview the text in the tray tooltip
+	PasteSplitSize                int          `json:"paste_split_size"`                 // characters
+	PasteSplitIntervalMs          int          `json:"paste_split_interval_ms"`          // wait between split pastes
+	PasteAppIntervalsMs           AppIntervals `json:"paste_app_intervals_ms"`           // per-app split interval overrides, keyed by frontmost app bundle identifier
+	MaxPasteChars                 int          `json:"max_paste_chars"`                  // longer transcriptions are truncated before pasting, 0 = unlimited
+	PasteTimestamp                string       `json:"paste_timestamp"`                  // Go time layout of the current time prepended to the text (e.g. "[15:04]"), "" = disabled
+	PasteWaitModifiers            bool         `json:"paste_wait_modifiers"`             // wait for held modifier keys to be released before pasting
+	RestoreFocusBeforePaste       bool         `json:"restore_focus_before_paste"`       // bring the app that was frontmost when recording started back to the front before pasting
+	OutputMode                    string       `json:"output_mode"`                      // "paste" or "copy": paste the transcription or only copy it to the clipboard
+	AppOutputModes                AppOutputs   `json:"app_output_modes"`                 // per-app output_mode, keyed by frontmost app bundle identifier
+	Threads                       int          `json:"threads"`                          // inference threads, 0 = auto-tune for the model
+	DecodingPreset                string       `json:"decoding_preset"`                  // "auto", "fast", "balanced" or "accurate"
+	AdaptiveDecoding              bool         `json:"adaptive_decoding"`                // choose greedy or beam search by recording length instead of decoding_preset
+	AdaptiveDecodingSeconds       int          `json:"adaptive_decoding_seconds"`        // adaptive_decoding: recordings at least this long use beam search
+	UseGPU                        bool         `json:"use_gpu"`                          // load the model for the GPU (Metal), false = CPU only, applied on restart
+	ToggleGraceMs                 int          `json:"toggle_grace_ms"`                  // toggle mode: stop within this window after start cancels the session, 0 = disabled
+	MinRecordMs                   int          `json:"min_record_ms"`                    // recordings shorter than this are discarded without transcription, 0 = disabled
+	SilenceThreshold              float64      `json:"silence_threshold"`                // recordings quieter than this (fraction of full scale) in every 100 ms are not transcribed, 0 = disabled
+	RepetitionMaxRepeats          int          `json:"repetition_max_repeats"`           // consecutive repeats treated as a hallucination loop, 0 = disabled
+	RepetitionMaxCompressionRatio float64      `json:"repetition_max_compression_ratio"` // gzip ratio above which output is suspect, 0 = disabled
+	DedupeSegments                bool         `json:"dedupe_segments"`                  // drop adjacent segments that repeat the previous one
+	StripLeadingSpace             bool         `json:"strip_leading_space"`              // remove the single leading space Whisper puts before the output
+	InitialPrompt                 string       `json:"initial_prompt"`                   // prompt given to Whisper before each transcription, supports {date} and {app}
+	ClipboardTranscribeHotkey     HotkeyConfig `json:"clipboard_transcribe_hotkey"`      // transcribes a WAV file copied in Finder, empty key = disabled
+	SecondHotkey                  HotkeyConfig `json:"second_hotkey"`                    // second recording hotkey, usually with options (e.g. translation), empty key = disabled
+	TrayShowText                  bool         `json:"tray_show_text"`                   // show short state text (e.g. "●REC") next to the menu bar icon
+	CheckUpdates                  bool         `json:"check_updates"`                    // check the version manifest on startup and daily, notify only
+	UpdateManifestURL             string       `json:"update_manifest_url"`              // version manifest ({"version","url"} or a GitHub releases API URL)
+	LogTranscriptionText          bool         `json:"log_transcription_text"`           // write transcribed text to the log, otherwise only its length
+	HistoryEnabled                bool         `json:"history_enabled"`                  // keep past transcriptions in history.jsonl for /api/history
+	HistoryMaxEntries             int          `json:"history_max_entries"`              // oldest entries are dropped beyond this many
+	SaveRecordings                bool         `json:"save_recordings"`                  // debug: save each recording as a WAV file before transcription
+	RecordingsRetentionDays       int          `json:"recordings_retention_days"`        // saved recordings older than this are deleted
+	AppLanguages                  AppLanguages `json:"app_languages"`                    // per-app recognition language, keyed by frontmost app bundle identifier
+	AutoFallbackLanguages         []string     `json:"auto_fallback_languages"`          // with "auto", languages to pick from (in order) when detection is unsure, e.g. ["ja","en"]
+	IdleUnloadMinutes             int          `json:"idle_unload_minutes"`              // free the model after this many minutes without transcription, 0 = never
+	ScreenLockedPolicy            string       `json:"screen_locked_policy"`             // "ignore", "clipboard-only" or "normal": hotkey dictation while the screen is locked
+	AutoSelectRecommended         bool         `json:"auto_select_recommended"`          // a model rescan selects and loads the recommended model when model_path is empty or invalid
+	ShowTimings                   bool         `json:"show_timings"`                     // debug: add the recording length, transcription time and realtime factor to a notification
+	mu                            sync.RWMutex
 }
 
 // HotkeyConfig holds hotkey configuration
 type HotkeyConfig struct {
-	Ctrl   bool   `json:"ctrl"`
-	Shift  bool   `json:"shift"`
-	Alt    bool   `json:"alt"`
-	Cmd    bool   `json:"cmd"`
-	Key    string `json:"key"` // e.g., "Space"
+	Ctrl  bool   `json:"ctrl"`
+	Shift bool   `json:"shift"`
+	Alt   bool   `json:"alt"`
+	Cmd   bool   `json:"cmd"`
+	Key   string `json:"key"` // e.g., "Space"
+
+	Options HotkeyOptions `json:"options,omitzero"` // overrides for recordings started by this hotkey
+}
+
+// WithKeys returns the key combination of keys with the options of h. Changing
+// or resetting the keys of a hotkey goes through it so the per-hotkey options
+// are only ever changed through the settings.
+func (h HotkeyConfig) WithKeys(keys HotkeyConfig) HotkeyConfig {
+	keys.Options = h.Options
+	return keys
+}
+
+// HotkeyOptions overrides recognition for the recordings started by one hotkey
+type HotkeyOptions struct {
+	Translate bool   `json:"translate,omitempty"` // translate the speech to English
+	Language  string `json:"language,omitempty"`  // spoken language, "" = the usual language resolution
+}
+
+// SameCombination reports whether h and other are the same key combination, ignoring options
+func (h HotkeyConfig) SameCombination(other HotkeyConfig) bool {
+	return h.Ctrl == other.Ctrl && h.Shift == other.Shift && h.Alt == other.Alt && h.Cmd == other.Cmd && h.Key == other.Key
 }
 
+// AppIntervals maps a frontmost app bundle identifier (e.g. "com.tinyspeck.slackmacgap")
+// to the wait between its split pastes in milliseconds
+type AppIntervals map[string]int
+
+// AppLanguages maps a frontmost app bundle identifier (e.g. "com.tinyspeck.slackmacgap")
+// to the recognition language used while it is in front
+type AppLanguages map[string]string
+
+// AppOutputs maps a frontmost app bundle identifier (e.g. "com.apple.Terminal")
+// to the output mode used while it is in front
+type AppOutputs map[string]string
+
 // IsValidModelExtension checks if the file has a valid Whisper model extension
 // Supports both .bin (current official format) and .gguf (future format)
 func IsValidModelExtension(path string) bool {
@@ -38,26 +123,214 @@ func IsValidModelExtension(path string) bool {
 	return ext == ".bin" || ext == ".gguf"
 }
 
+// IsValidRecordingMode checks if the value is a supported recording mode
+func IsValidRecordingMode(mode string) bool {
+	return mode == "press-to-hold" || mode == "toggle"
+}
+
+// IsValidDecodingPreset checks if the value is a supported decoding preset
+func IsValidDecodingPreset(preset string) bool {
+	switch preset {
+	case "auto", "fast", "balanced", "accurate":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidLanguage checks if the value is "auto" or looks like a Whisper
+// language code ("ja", "en", "yue", ...). Values from the user are passed
+// through NormalizeLanguage first.
+func IsValidLanguage(language string) bool {
+	if language == "auto" {
+		return true
+	}
+	if len(language) < 2 || len(language) > 3 {
+		return false
+	}
+	for _, r := range language {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Translation modes for recognition
+const (
+	TranslationTranscribe = "transcribe" // The text is in the spoken language
+	TranslationTranslate  = "translate"  // The speech is translated to English
+)
+
+// IsValidTranslationMode checks if the value is a supported translation mode
+func IsValidTranslationMode(mode string) bool {
+	return mode == TranslationTranscribe || mode == TranslationTranslate
+}
+
+// Output modes for transcriptions
+const (
+	OutputPaste = "paste" // The result is pasted into the frontmost app
+	OutputCopy  = "copy"  // The result is only copied to the clipboard
+)
+
+// IsValidOutputMode checks if the value is a supported output mode
+func IsValidOutputMode(mode string) bool {
+	return mode == OutputPaste || mode == OutputCopy
+}
+
+// Screen lock policies for hotkey dictation
+const (
+	ScreenLockedIgnore        = "ignore"         // Hotkey presses are ignored
+	ScreenLockedClipboardOnly = "clipboard-only" // The result is copied to the clipboard instead of pasted
+	ScreenLockedNormal        = "normal"         // Dictation works as usual
+)
+
+// IsValidScreenLockedPolicy checks if the value is a supported screen lock policy
+func IsValidScreenLockedPolicy(policy string) bool {
+	switch policy {
+	case ScreenLockedIgnore, ScreenLockedClipboardOnly, ScreenLockedNormal:
+		return true
+	default:
+		return false
+	}
+}
+
+// isHTTPURL reports whether s is an http or https URL
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// isSpaceKeyVariant reports whether r is a whitespace character that may be
+// captured instead of a regular space when the space key is pressed
+func isSpaceKeyVariant(r rune) bool {
+	switch r {
+	case ' ', '\u00a0', '\u2007', '\u202f', '\u3000':
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeKeyName maps whitespace variants of the space key to "Space".
+// The macOS IME and some browsers send NBSP (U+00A0), figure space (U+2007),
+// narrow NBSP (U+202F) or an ideographic space (U+3000) instead of a regular space.
+func NormalizeKeyName(key string) string {
+	if key != "" && strings.TrimFunc(key, isSpaceKeyVariant) == "" {
+		return "Space"
+	}
+	return key
+}
+
+// MaxInitialPromptChars is the longest accepted initial_prompt (before placeholders are expanded)
+const MaxInitialPromptChars = 500
+
+// MaxPasteTimestampChars is the longest accepted paste_timestamp layout
+const MaxPasteTimestampChars = 64
+
+// IsValidTimestampLayout checks if layout is empty (disabled) or a Go time
+// layout with at least one time element, so that a layout made only of
+// literal text (e.g. "time") is rejected
+func IsValidTimestampLayout(layout string) bool {
+	if layout == "" {
+		return true
+	}
+	if utf8.RuneCountInString(layout) > MaxPasteTimestampChars {
+		return false
+	}
+	// Not the reference time itself, which would format every layout unchanged
+	sample := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return sample.Format(layout) != layout
+}
+
+// MaxPasteSplitIntervalMs is the longest accepted wait between split pastes
+const MaxPasteSplitIntervalMs = 5000
+
+// MaxHistoryEntries is the largest accepted history_max_entries
+const MaxHistoryEntries = 100000
+
+// MaxRecordingsRetentionDays is the longest accepted recordings_retention_days
+const MaxRecordingsRetentionDays = 365
+
+// MaxAdaptiveDecodingSeconds is the longest accepted adaptive_decoding_seconds (the longest recording)
+const MaxAdaptiveDecodingSeconds = 300
+
+// MaxSilenceThreshold is the largest accepted silence_threshold (-20 dBFS, louder than quiet speech)
+const MaxSilenceThreshold = 0.1
+
+// MaxIdleUnloadMinutes is the longest accepted idle time before the model is freed (one day)
+const MaxIdleUnloadMinutes = 1440
+
+// DefaultUpdateManifestURL is the GitHub releases API endpoint for the latest release
+const DefaultUpdateManifestURL = "https://api.github.com/repos/yok-tottii/EzS2T-Whisper/releases/latest"
+
 // GetRecommendedModelName returns the recommended model filename
 func GetRecommendedModelName() string {
 	return "ggml-large-v3-turbo-q5_0.bin"
 }
 
+// DefaultHotkey returns the default recording hotkey (Ctrl+Option+Space)
+func DefaultHotkey() HotkeyConfig {
+	return HotkeyConfig{
+		Ctrl: true,
+		Alt:  true,
+		Key:  "Space",
+	}
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Hotkey: HotkeyConfig{
-			Ctrl: true,
-			Alt:  true,
-			Key:  "Space",
-		},
-		RecordingMode:  "press-to-hold",
-		ModelPath:      "", // Empty by default - user must specify
-		Language:       "auto", // Automatic language detection
-		AudioDeviceID:  -1, // -1 means use system default device
-		UILanguage:     "ja",
-		MaxRecordTime:  60, // 60 seconds
-		PasteSplitSize: 500, // 500 characters
+		Hotkey:                        DefaultHotkey(),
+		RecordingMode:                 "press-to-hold",
+		ModelPath:                     "",     // Empty by default - user must specify
+		Language:                      "auto", // Automatic language detection
+		AudioDeviceID:                 -1,     // -1 means use system default device
+		AudioBackend:                  "portaudio",
+		UILanguage:                    "ja",
+		TranslationMode:               TranslationTranscribe,
+		MaxRecordTime:                 60,  // 60 seconds
+		ToggleMaxRecordTime:           0,   // same as MaxRecordTime
+		PasteSplitSize:                500, // 500 characters
+		PasteSplitIntervalMs:          50,  // 50 milliseconds
+		PasteAppIntervalsMs:           AppIntervals{},
+		MaxPasteChars:                 10000, // 10000 characters
+		PasteTimestamp:                "",
+		PasteWaitModifiers:            true,
+		RestoreFocusBeforePaste:       false, // Paste into whatever is frontmost
+		OutputMode:                    OutputPaste,
+		AppOutputModes:                AppOutputs{},
+		AudioTrimSilence:              false,
+		AudioNormalize:                false,
+		StartBeep:                     false,
+		PreventSleepWhileRecording:    true,
+		Streaming:                     false,
+		Threads:                       0,      // Auto-tune for the loaded model
+		DecodingPreset:                "auto", // Auto-tune for the loaded model
+		AdaptiveDecoding:              false,  // Use decoding_preset
+		AdaptiveDecodingSeconds:       10,     // Dictated sentences stay greedy
+		UseGPU:                        true,   // Same as whisper.cpp
+		ToggleGraceMs:                 300,    // 300 milliseconds
+		MinRecordMs:                   300,    // Shorter than any word
+		SilenceThreshold:              0.005,  // Below quiet speech, above a typical microphone's noise floor
+		RepetitionMaxRepeats:          4,
+		RepetitionMaxCompressionRatio: 2.4,
+		DedupeSegments:                false,
+		StripLeadingSpace:             true,
+		InitialPrompt:                 "",
+		TrayShowText:                  false, // Icon only
+		CheckUpdates:                  false,
+		UpdateManifestURL:             DefaultUpdateManifestURL,
+		LogTranscriptionText:          false, // Dictated text stays out of the logs
+		HistoryEnabled:                false, // Dictated text is not kept unless the user opts in
+		HistoryMaxEntries:             1000,
+		SaveRecordings:                false,
+		RecordingsRetentionDays:       7,
+		AppLanguages:                  AppLanguages{},
+		AutoFallbackLanguages:         []string{},
+		IdleUnloadMinutes:             0, // Keep the model loaded
+		ScreenLockedPolicy:            ScreenLockedIgnore,
+		AutoSelectRecommended:         false, // The model is only changed by the user
+		ShowTimings:                   false, // Timings are only logged
 	}
 }
 
@@ -74,18 +347,38 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse JSON
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Parse JSON on top of defaults so fields missing from older files keep their default values
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	// ホットキー設定の検証と修正
+	config.Hotkey.Key = NormalizeKeyName(config.Hotkey.Key)
 	if config.Hotkey.Key == "" {
 		config.Hotkey.Key = "Space" // デフォルト値で補完
 	}
+	config.ClipboardTranscribeHotkey.Key = NormalizeKeyName(config.ClipboardTranscribeHotkey.Key)
+	config.SecondHotkey.Key = NormalizeKeyName(config.SecondHotkey.Key)
+
+	// 手で編集された "Japanese" や " EN " などの言語指定をコードに揃える
+	config.normalizeLanguages()
+
+	return config, nil
+}
 
-	return &config, nil
+// normalizeLanguages applies NormalizeLanguage to every recognition language
+func (c *Config) normalizeLanguages() {
+	c.Language = NormalizeLanguage(c.Language)
+	for bundleID, language := range c.AppLanguages {
+		c.AppLanguages[bundleID] = NormalizeLanguage(language)
+	}
+	for i, language := range c.AutoFallbackLanguages {
+		c.AutoFallbackLanguages[i] = NormalizeLanguage(language)
+	}
+	c.Hotkey.Options.Language = NormalizeLanguage(c.Hotkey.Options.Language)
+	c.ClipboardTranscribeHotkey.Options.Language = NormalizeLanguage(c.ClipboardTranscribeHotkey.Options.Language)
+	c.SecondHotkey.Options.Language = NormalizeLanguage(c.SecondHotkey.Options.Language)
 }
 
 // Save saves configuration to the specified path
@@ -119,72 +412,501 @@ func GetConfigPath() string {
 	return filepath.Join(homeDir, "Library", "Application Support", "EzS2T-Whisper", "config.json")
 }
 
-// Update updates configuration fields
+// Update updates configuration fields.
+// Every update is checked; valid fields are applied and all invalid ones are
+// returned together as ValidationErrors.
 func (c *Config) Update(updates map[string]interface{}) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Apply updates
-	for key, value := range updates {
-		switch key {
-		case "recording_mode":
-			if v, ok := value.(string); ok {
-				if v != "press-to-hold" && v != "toggle" {
-					return fmt.Errorf("invalid recording_mode: %s", v)
-				}
-				c.RecordingMode = v
+	return c.applyUpdates(updates).orNil()
+}
+
+// ValidateUpdates applies updates to a copy of the configuration and reports
+// every problem found by the per-field checks in Update and by Validate.
+// The configuration itself is never modified.
+func (c *Config) ValidateUpdates(updates map[string]interface{}) ValidationErrors {
+	clone := c.Clone()
+	errs := clone.applyUpdates(updates)
+
+	// Fields that failed their own check were not applied, so Validate would
+	// only repeat an older problem (or none) for them
+	for _, fieldErr := range clone.validate() {
+		if !errs.Has(fieldErr.Field) {
+			errs = append(errs, fieldErr)
+		}
+	}
+
+	return errs
+}
+
+// applyUpdates applies updates in key order and collects the invalid ones.
+// The caller must hold the write lock.
+func (c *Config) applyUpdates(updates map[string]interface{}) ValidationErrors {
+	keys := make([]string, 0, len(updates))
+	for key := range updates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs ValidationErrors
+	for _, key := range keys {
+		// null leaves the field unchanged
+		if updates[key] == nil {
+			continue
+		}
+		errs = append(errs, c.applyUpdate(key, updates[key])...)
+	}
+	return errs
+}
+
+// applyUpdate applies a single update. Unknown keys are ignored.
+func (c *Config) applyUpdate(key string, value interface{}) ValidationErrors {
+	var err *FieldError
+
+	switch key {
+	case "recording_mode":
+		err = setString(key, value, &c.RecordingMode, func(v string) *FieldError {
+			if !IsValidRecordingMode(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid recording_mode: %s", v)
+			}
+			return nil
+		})
+	case "model_path":
+		err = setString(key, value, &c.ModelPath, nil)
+	case "language":
+		// Allow any language code - Whisper.cpp supports 100+ languages
+		// "auto" enables automatic language detection
+		err = setString(key, value, &c.Language, func(v string) *FieldError {
+			v = NormalizeLanguage(v)
+			if v == "" {
+				return newFieldError(key, CodeRequired, "language cannot be empty")
+			}
+			if !IsValidLanguage(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid language: %s", v)
 			}
-		case "model_path":
-			if v, ok := value.(string); ok {
-				c.ModelPath = v
+			return nil
+		})
+		c.Language = NormalizeLanguage(c.Language)
+	case "audio_device_id":
+		err = setInt(key, value, &c.AudioDeviceID)
+	case "audio_backend":
+		err = setString(key, value, &c.AudioBackend, func(v string) *FieldError {
+			if v != "portaudio" && v != "fake" {
+				return newFieldError(key, CodeInvalidValue, "invalid audio_backend: %s", v)
 			}
-		case "language":
-			if v, ok := value.(string); ok {
-				// Allow any language code - Whisper.cpp supports 100+ languages
-				// "auto" enables automatic language detection
-				c.Language = v
+			return nil
+		})
+	case "fake_audio_source":
+		err = setString(key, value, &c.FakeAudioSource, nil)
+	case "ui_language":
+		err = setString(key, value, &c.UILanguage, func(v string) *FieldError {
+			if v != "ja" && v != "en" {
+				return newFieldError(key, CodeInvalidValue, "invalid ui_language: %s", v)
 			}
-		case "audio_device_id":
-			if v, ok := value.(float64); ok {
-				c.AudioDeviceID = int(v)
+			return nil
+		})
+	case "max_record_time":
+		err = setInt(key, value, &c.MaxRecordTime)
+	case "toggle_max_record_time":
+		err = setInt(key, value, &c.ToggleMaxRecordTime)
+	case "paste_split_size":
+		err = setInt(key, value, &c.PasteSplitSize)
+	case "paste_split_interval_ms":
+		err = setInt(key, value, &c.PasteSplitIntervalMs)
+	case "paste_app_intervals_ms":
+		return c.applyAppIntervalsUpdate(value)
+	case "app_languages":
+		return c.applyAppLanguagesUpdate(value)
+	case "auto_fallback_languages":
+		return c.applyAutoFallbackLanguagesUpdate(value)
+	case "translation_mode":
+		err = setString(key, value, &c.TranslationMode, func(v string) *FieldError {
+			if !IsValidTranslationMode(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid translation_mode: %s", v)
+			}
+			return nil
+		})
+	case "output_mode":
+		err = setString(key, value, &c.OutputMode, func(v string) *FieldError {
+			if !IsValidOutputMode(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid output_mode: %s", v)
+			}
+			return nil
+		})
+	case "app_output_modes":
+		return c.applyAppOutputModesUpdate(value)
+	case "idle_unload_minutes":
+		err = setInt(key, value, &c.IdleUnloadMinutes)
+	case "screen_locked_policy":
+		err = setString(key, value, &c.ScreenLockedPolicy, func(v string) *FieldError {
+			if !IsValidScreenLockedPolicy(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid screen_locked_policy: %s", v)
+			}
+			return nil
+		})
+	case "max_paste_chars":
+		err = setInt(key, value, &c.MaxPasteChars)
+	case "paste_timestamp":
+		err = setString(key, value, &c.PasteTimestamp, func(v string) *FieldError {
+			if !IsValidTimestampLayout(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid paste_timestamp: %q (must be a Go time layout such as \"[15:04]\", at most %d characters)", v, MaxPasteTimestampChars)
+			}
+			return nil
+		})
+	case "paste_wait_modifiers":
+		err = setBool(key, value, &c.PasteWaitModifiers)
+	case "restore_focus_before_paste":
+		err = setBool(key, value, &c.RestoreFocusBeforePaste)
+	case "threads":
+		err = setInt(key, value, &c.Threads)
+	case "use_gpu":
+		err = setBool(key, value, &c.UseGPU)
+	case "decoding_preset":
+		err = setString(key, value, &c.DecodingPreset, func(v string) *FieldError {
+			if !IsValidDecodingPreset(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid decoding_preset: %s", v)
+			}
+			return nil
+		})
+	case "adaptive_decoding":
+		err = setBool(key, value, &c.AdaptiveDecoding)
+	case "adaptive_decoding_seconds":
+		err = setInt(key, value, &c.AdaptiveDecodingSeconds)
+	case "toggle_grace_ms":
+		err = setInt(key, value, &c.ToggleGraceMs)
+	case "min_record_ms":
+		err = setInt(key, value, &c.MinRecordMs)
+	case "silence_threshold":
+		v, ok := value.(float64)
+		if !ok {
+			return ValidationErrors{typeError(key, "a number")}
+		}
+		c.SilenceThreshold = v
+	case "repetition_max_repeats":
+		err = setInt(key, value, &c.RepetitionMaxRepeats)
+	case "repetition_max_compression_ratio":
+		v, ok := value.(float64)
+		if !ok {
+			return ValidationErrors{typeError(key, "a number")}
+		}
+		c.RepetitionMaxCompressionRatio = v
+	case "dedupe_segments":
+		err = setBool(key, value, &c.DedupeSegments)
+	case "strip_leading_space":
+		err = setBool(key, value, &c.StripLeadingSpace)
+	case "initial_prompt":
+		err = setString(key, value, &c.InitialPrompt, func(v string) *FieldError {
+			if n := utf8.RuneCountInString(v); n > MaxInitialPromptChars {
+				return newFieldError(key, CodeOutOfRange, "invalid initial_prompt: %d characters (must be at most %d)", n, MaxInitialPromptChars)
+			}
+			return nil
+		})
+	case "audio_trim_silence":
+		err = setBool(key, value, &c.AudioTrimSilence)
+	case "audio_normalize":
+		err = setBool(key, value, &c.AudioNormalize)
+	case "start_beep":
+		err = setBool(key, value, &c.StartBeep)
+	case "prevent_sleep_while_recording":
+		err = setBool(key, value, &c.PreventSleepWhileRecording)
+	case "streaming":
+		err = setBool(key, value, &c.Streaming)
+	case "tray_show_text":
+		err = setBool(key, value, &c.TrayShowText)
+	case "check_updates":
+		err = setBool(key, value, &c.CheckUpdates)
+	case "log_transcription_text":
+		err = setBool(key, value, &c.LogTranscriptionText)
+	case "history_enabled":
+		err = setBool(key, value, &c.HistoryEnabled)
+	case "history_max_entries":
+		err = setInt(key, value, &c.HistoryMaxEntries)
+	case "save_recordings":
+		err = setBool(key, value, &c.SaveRecordings)
+	case "recordings_retention_days":
+		err = setInt(key, value, &c.RecordingsRetentionDays)
+	case "auto_select_recommended":
+		err = setBool(key, value, &c.AutoSelectRecommended)
+	case "show_timings":
+		err = setBool(key, value, &c.ShowTimings)
+	case "update_manifest_url":
+		err = setString(key, value, &c.UpdateManifestURL, func(v string) *FieldError {
+			if v != "" && !isHTTPURL(v) {
+				return newFieldError(key, CodeInvalidValue, "invalid update_manifest_url: %q (must be an http or https URL)", v)
+			}
+			return nil
+		})
+	case "hotkey":
+		return applyHotkeyUpdate(key, value, &c.Hotkey, false)
+	case "clipboard_transcribe_hotkey":
+		return applyHotkeyUpdate(key, value, &c.ClipboardTranscribeHotkey, true)
+	case "second_hotkey":
+		return applyHotkeyUpdate(key, value, &c.SecondHotkey, true)
+	}
+
+	if err != nil {
+		return ValidationErrors{err}
+	}
+	return nil
+}
+
+// applyHotkeyUpdate updates the HotkeyConfig fields present in a nested hotkey object.
+// An empty key is accepted only if optional (the hotkey is disabled).
+func applyHotkeyUpdate(field string, value interface{}, target *HotkeyConfig, optional bool) ValidationErrors {
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return ValidationErrors{typeError(field, "an object")}
+	}
+
+	// 一部でも不正なら修飾キーとキーの組み合わせが崩れるので、全て検証してから反映する
+	hotkey := *target
+	var errs ValidationErrors
+	for name, flag := range map[string]*bool{
+		"ctrl":  &hotkey.Ctrl,
+		"shift": &hotkey.Shift,
+		"alt":   &hotkey.Alt,
+		"cmd":   &hotkey.Cmd,
+	} {
+		if raw, present := v[name]; present && raw != nil {
+			if err := setBool(field+"."+name, raw, flag); err != nil {
+				errs = append(errs, err)
 			}
-		case "ui_language":
-			if v, ok := value.(string); ok {
-				if v != "ja" && v != "en" {
-					return fmt.Errorf("invalid ui_language: %s", v)
-				}
-				c.UILanguage = v
+		}
+	}
+	if raw, present := v["key"]; present && raw != nil {
+		err := setString(field+".key", raw, &hotkey.Key, func(key string) *FieldError {
+			if NormalizeKeyName(key) == "" && !optional {
+				return newFieldError(field+".key", CodeRequired, "%s key cannot be empty", field)
 			}
-		case "max_record_time":
-			if v, ok := value.(float64); ok {
-				c.MaxRecordTime = int(v)
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		hotkey.Key = NormalizeKeyName(hotkey.Key)
+	}
+	if raw, present := v["options"]; present && raw != nil {
+		options, optionErrs := parseHotkeyOptions(field+".options", raw)
+		errs = append(errs, optionErrs...)
+		hotkey.Options = options
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return errs
+	}
+
+	*target = hotkey
+	return nil
+}
+
+// parseHotkeyOptions reads a {"translate": bool, "language": "en"} object.
+// Missing fields keep their zero value, so an empty object removes all overrides.
+func parseHotkeyOptions(field string, value interface{}) (HotkeyOptions, ValidationErrors) {
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return HotkeyOptions{}, ValidationErrors{typeError(field, "an object")}
+	}
+
+	var options HotkeyOptions
+	var errs ValidationErrors
+	if raw, present := v["translate"]; present && raw != nil {
+		if err := setBool(field+".translate", raw, &options.Translate); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if raw, present := v["language"]; present && raw != nil {
+		if err := setString(field+".language", raw, &options.Language, nil); err != nil {
+			errs = append(errs, err)
+		}
+		options.Language = NormalizeLanguage(options.Language)
+	}
+	return options, errs
+}
+
+// applyAppIntervalsUpdate replaces paste_app_intervals_ms with a {"bundle id": ms} object
+func (c *Config) applyAppIntervalsUpdate(value interface{}) ValidationErrors {
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return ValidationErrors{typeError("paste_app_intervals_ms", "an object")}
+	}
+
+	intervals := make(AppIntervals, len(v))
+	var errs ValidationErrors
+	for bundleID, raw := range v {
+		field := "paste_app_intervals_ms." + bundleID
+		if strings.TrimSpace(bundleID) == "" {
+			errs = append(errs, newFieldError(field, CodeRequired, "paste_app_intervals_ms bundle identifier cannot be empty"))
+			continue
+		}
+		var ms int
+		if err := setInt(field, raw, &ms); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ms < 0 || ms > MaxPasteSplitIntervalMs {
+			errs = append(errs, newFieldError(field, CodeOutOfRange, "invalid paste_app_intervals_ms for %q: %d (must be between 0 and %d milliseconds)", bundleID, ms, MaxPasteSplitIntervalMs))
+			continue
+		}
+		intervals[bundleID] = ms
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return errs
+	}
+
+	c.PasteAppIntervalsMs = intervals
+	return nil
+}
+
+// applyAppLanguagesUpdate replaces app_languages with a {"bundle id": "language"} object
+func (c *Config) applyAppLanguagesUpdate(value interface{}) ValidationErrors {
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return ValidationErrors{typeError("app_languages", "an object")}
+	}
+
+	languages := make(AppLanguages, len(v))
+	var errs ValidationErrors
+	for bundleID, raw := range v {
+		field := "app_languages." + bundleID
+		if strings.TrimSpace(bundleID) == "" {
+			errs = append(errs, newFieldError(field, CodeRequired, "app_languages bundle identifier cannot be empty"))
+			continue
+		}
+		var language string
+		err := setString(field, raw, &language, func(v string) *FieldError {
+			if strings.TrimSpace(v) == "" {
+				return newFieldError(field, CodeRequired, "app_languages language for %q cannot be empty", bundleID)
 			}
-		case "paste_split_size":
-			if v, ok := value.(float64); ok {
-				c.PasteSplitSize = int(v)
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		languages[bundleID] = NormalizeLanguage(language)
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return errs
+	}
+
+	c.AppLanguages = languages
+	return nil
+}
+
+// applyAutoFallbackLanguagesUpdate replaces auto_fallback_languages with an array of language codes
+func (c *Config) applyAutoFallbackLanguagesUpdate(value interface{}) ValidationErrors {
+	v, ok := value.([]interface{})
+	if !ok {
+		return ValidationErrors{typeError("auto_fallback_languages", "an array")}
+	}
+
+	languages := make([]string, 0, len(v))
+	var errs ValidationErrors
+	for i, raw := range v {
+		field := fmt.Sprintf("auto_fallback_languages.%d", i)
+		var language string
+		err := setString(field, raw, &language, func(v string) *FieldError {
+			if v = NormalizeLanguage(v); !IsValidLanguage(v) || v == "auto" {
+				return newFieldError(field, CodeInvalidValue, "invalid auto_fallback_languages language: %s", v)
 			}
-		case "hotkey":
-			if v, ok := value.(map[string]interface{}); ok {
-				// HotkeyConfigの各フィールドを更新
-				if ctrl, ok := v["ctrl"].(bool); ok {
-					c.Hotkey.Ctrl = ctrl
-				}
-				if shift, ok := v["shift"].(bool); ok {
-					c.Hotkey.Shift = shift
-				}
-				if alt, ok := v["alt"].(bool); ok {
-					c.Hotkey.Alt = alt
-				}
-				if cmd, ok := v["cmd"].(bool); ok {
-					c.Hotkey.Cmd = cmd
-				}
-				if key, ok := v["key"].(string); ok {
-					c.Hotkey.Key = key
-				}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		languages = append(languages, NormalizeLanguage(language))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	c.AutoFallbackLanguages = languages
+	return nil
+}
+
+// applyAppOutputModesUpdate replaces app_output_modes with a {"bundle id": "paste" | "copy"} object
+func (c *Config) applyAppOutputModesUpdate(value interface{}) ValidationErrors {
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return ValidationErrors{typeError("app_output_modes", "an object")}
+	}
+
+	modes := make(AppOutputs, len(v))
+	var errs ValidationErrors
+	for bundleID, raw := range v {
+		field := "app_output_modes." + bundleID
+		if strings.TrimSpace(bundleID) == "" {
+			errs = append(errs, newFieldError(field, CodeRequired, "app_output_modes bundle identifier cannot be empty"))
+			continue
+		}
+		var mode string
+		err := setString(field, raw, &mode, func(v string) *FieldError {
+			if !IsValidOutputMode(v) {
+				return newFieldError(field, CodeInvalidValue, "invalid app_output_modes for %q: %s", bundleID, v)
 			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		modes[bundleID] = mode
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return errs
+	}
+
+	c.AppOutputModes = modes
+	return nil
+}
+
+// typeError reports a value of the wrong JSON type
+func typeError(field, expected string) *FieldError {
+	return newFieldError(field, CodeInvalidType, "invalid %s: expected %s", field, expected)
+}
+
+// setString assigns a string value after an optional check
+func setString(field string, value interface{}, target *string, check func(string) *FieldError) *FieldError {
+	v, ok := value.(string)
+	if !ok {
+		return typeError(field, "a string")
+	}
+	if check != nil {
+		if err := check(v); err != nil {
+			return err
 		}
 	}
+	*target = v
+	return nil
+}
+
+// setInt assigns a whole JSON number
+func setInt(field string, value interface{}, target *int) *FieldError {
+	v, ok := value.(float64)
+	if !ok || v != math.Trunc(v) {
+		return typeError(field, "an integer")
+	}
+	*target = int(v)
+	return nil
+}
 
+// setBool assigns a boolean value
+func setBool(field string, value interface{}, target *bool) *FieldError {
+	v, ok := value.(bool)
+	if !ok {
+		return typeError(field, "a boolean")
+	}
+	*target = v
 	return nil
 }
 
@@ -194,15 +916,103 @@ func (c *Config) Clone() *Config {
 	defer c.mu.RUnlock()
 
 	return &Config{
-		Hotkey:         c.Hotkey,
-		RecordingMode:  c.RecordingMode,
-		ModelPath:      c.ModelPath,
-		Language:       c.Language,
-		AudioDeviceID:  c.AudioDeviceID,
-		UILanguage:     c.UILanguage,
-		MaxRecordTime:  c.MaxRecordTime,
-		PasteSplitSize: c.PasteSplitSize,
+		Hotkey:                        c.Hotkey,
+		RecordingMode:                 c.RecordingMode,
+		ModelPath:                     c.ModelPath,
+		Language:                      c.Language,
+		AudioDeviceID:                 c.AudioDeviceID,
+		AudioBackend:                  c.AudioBackend,
+		FakeAudioSource:               c.FakeAudioSource,
+		UILanguage:                    c.UILanguage,
+		MaxRecordTime:                 c.MaxRecordTime,
+		ToggleMaxRecordTime:           c.ToggleMaxRecordTime,
+		AudioTrimSilence:              c.AudioTrimSilence,
+		AudioNormalize:                c.AudioNormalize,
+		StartBeep:                     c.StartBeep,
+		PreventSleepWhileRecording:    c.PreventSleepWhileRecording,
+		Streaming:                     c.Streaming,
+		PasteSplitSize:                c.PasteSplitSize,
+		PasteSplitIntervalMs:          c.PasteSplitIntervalMs,
+		PasteAppIntervalsMs:           maps.Clone(c.PasteAppIntervalsMs),
+		MaxPasteChars:                 c.MaxPasteChars,
+		PasteTimestamp:                c.PasteTimestamp,
+		PasteWaitModifiers:            c.PasteWaitModifiers,
+		RestoreFocusBeforePaste:       c.RestoreFocusBeforePaste,
+		Threads:                       c.Threads,
+		DecodingPreset:                c.DecodingPreset,
+		AdaptiveDecoding:              c.AdaptiveDecoding,
+		AdaptiveDecodingSeconds:       c.AdaptiveDecodingSeconds,
+		UseGPU:                        c.UseGPU,
+		ToggleGraceMs:                 c.ToggleGraceMs,
+		MinRecordMs:                   c.MinRecordMs,
+		SilenceThreshold:              c.SilenceThreshold,
+		RepetitionMaxRepeats:          c.RepetitionMaxRepeats,
+		RepetitionMaxCompressionRatio: c.RepetitionMaxCompressionRatio,
+		DedupeSegments:                c.DedupeSegments,
+		StripLeadingSpace:             c.StripLeadingSpace,
+		InitialPrompt:                 c.InitialPrompt,
+		ClipboardTranscribeHotkey:     c.ClipboardTranscribeHotkey,
+		SecondHotkey:                  c.SecondHotkey,
+		TrayShowText:                  c.TrayShowText,
+		CheckUpdates:                  c.CheckUpdates,
+		UpdateManifestURL:             c.UpdateManifestURL,
+		LogTranscriptionText:          c.LogTranscriptionText,
+		HistoryEnabled:                c.HistoryEnabled,
+		HistoryMaxEntries:             c.HistoryMaxEntries,
+		SaveRecordings:                c.SaveRecordings,
+		RecordingsRetentionDays:       c.RecordingsRetentionDays,
+		AppLanguages:                  maps.Clone(c.AppLanguages),
+		AutoFallbackLanguages:         slices.Clone(c.AutoFallbackLanguages),
+		OutputMode:                    c.OutputMode,
+		TranslationMode:               c.TranslationMode,
+		AppOutputModes:                maps.Clone(c.AppOutputModes),
+		IdleUnloadMinutes:             c.IdleUnloadMinutes,
+		ScreenLockedPolicy:            c.ScreenLockedPolicy,
+		AutoSelectRecommended:         c.AutoSelectRecommended,
+		ShowTimings:                   c.ShowTimings,
+	}
+}
+
+// LanguageFor returns the recognition language to use while the app with
+// bundleID is in front: its app_languages entry, then language, then "auto"
+func (c *Config) LanguageFor(bundleID string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if language := c.AppLanguages[bundleID]; bundleID != "" && language != "" {
+		return language
+	}
+	if c.Language != "" {
+		return c.Language
+	}
+	return "auto"
+}
+
+// OutputModeFor returns the output mode to use while the app with bundleID is
+// in front: its app_output_modes entry, then output_mode, then "paste"
+func (c *Config) OutputModeFor(bundleID string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if mode := c.AppOutputModes[bundleID]; bundleID != "" && mode != "" {
+		return mode
+	}
+	if c.OutputMode != "" {
+		return c.OutputMode
+	}
+	return OutputPaste
+}
+
+// MaxRecordTimeFor returns the recording limit in seconds for the recording
+// mode: toggle_max_record_time in toggle mode if set, otherwise max_record_time
+func (c *Config) MaxRecordTimeFor(mode string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if mode == "toggle" && c.ToggleMaxRecordTime > 0 {
+		return c.ToggleMaxRecordTime
 	}
+	return c.MaxRecordTime
 }
 
 // ExpandPath expands ~ to home directory in file paths
@@ -273,39 +1083,180 @@ func (c *Config) ValidateModelPath() error {
 	return nil
 }
 
-// Validate validates all configuration fields
+// Validate validates all configuration fields and returns every problem found
+// as ValidationErrors
 func (c *Config) Validate() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	return c.validate().orNil()
+}
+
+// validate checks all configuration fields. The caller must hold the lock
+// (or own an unshared copy).
+func (c *Config) validate() ValidationErrors {
+	var errs ValidationErrors
+
 	// Validate recording mode
-	if c.RecordingMode != "press-to-hold" && c.RecordingMode != "toggle" {
-		return fmt.Errorf("invalid recording_mode: %s (must be 'press-to-hold' or 'toggle')", c.RecordingMode)
+	if !IsValidRecordingMode(c.RecordingMode) {
+		errs = append(errs, newFieldError("recording_mode", CodeInvalidValue, "invalid recording_mode: %s (must be 'press-to-hold' or 'toggle')", c.RecordingMode))
 	}
 
-	// Validate language (allow any non-empty value - Whisper.cpp supports 100+ languages)
+	// Validate language (any Whisper language code - Whisper.cpp supports 100+ languages)
 	// "auto" enables automatic language detection
 	if c.Language == "" {
-		return fmt.Errorf("language cannot be empty")
+		errs = append(errs, newFieldError("language", CodeRequired, "language cannot be empty"))
+	} else if !IsValidLanguage(c.Language) {
+		errs = append(errs, newFieldError("language", CodeInvalidValue, "invalid language: %s (must be 'auto' or a language code such as 'ja')", c.Language))
+	}
+
+	// Validate audio backend
+	if c.AudioBackend != "portaudio" && c.AudioBackend != "fake" {
+		errs = append(errs, newFieldError("audio_backend", CodeInvalidValue, "invalid audio_backend: %s (must be 'portaudio' or 'fake')", c.AudioBackend))
 	}
 
 	// Validate UI language
 	if c.UILanguage != "ja" && c.UILanguage != "en" {
-		return fmt.Errorf("invalid ui_language: %s (must be 'ja' or 'en')", c.UILanguage)
+		errs = append(errs, newFieldError("ui_language", CodeInvalidValue, "invalid ui_language: %s (must be 'ja' or 'en')", c.UILanguage))
 	}
 
 	// Validate max record time
 	if c.MaxRecordTime <= 0 || c.MaxRecordTime > 300 {
-		return fmt.Errorf("invalid max_record_time: %d (must be between 1 and 300 seconds)", c.MaxRecordTime)
+		errs = append(errs, newFieldError("max_record_time", CodeOutOfRange, "invalid max_record_time: %d (must be between 1 and 300 seconds)", c.MaxRecordTime))
+	}
+	if c.ToggleMaxRecordTime < 0 || c.ToggleMaxRecordTime > 300 {
+		errs = append(errs, newFieldError("toggle_max_record_time", CodeOutOfRange, "invalid toggle_max_record_time: %d (must be between 0 and 300 seconds)", c.ToggleMaxRecordTime))
 	}
 
 	// Validate paste split size
 	if c.PasteSplitSize <= 0 || c.PasteSplitSize > 10000 {
-		return fmt.Errorf("invalid paste_split_size: %d (must be between 1 and 10000 characters)", c.PasteSplitSize)
+		errs = append(errs, newFieldError("paste_split_size", CodeOutOfRange, "invalid paste_split_size: %d (must be between 1 and 10000 characters)", c.PasteSplitSize))
+	}
+
+	// Validate paste split intervals
+	if c.PasteSplitIntervalMs < 0 || c.PasteSplitIntervalMs > MaxPasteSplitIntervalMs {
+		errs = append(errs, newFieldError("paste_split_interval_ms", CodeOutOfRange, "invalid paste_split_interval_ms: %d (must be between 0 and %d milliseconds)", c.PasteSplitIntervalMs, MaxPasteSplitIntervalMs))
+	}
+
+	for _, bundleID := range slices.Sorted(maps.Keys(c.PasteAppIntervalsMs)) {
+		if ms := c.PasteAppIntervalsMs[bundleID]; ms < 0 || ms > MaxPasteSplitIntervalMs {
+			errs = append(errs, newFieldError("paste_app_intervals_ms."+bundleID, CodeOutOfRange, "invalid paste_app_intervals_ms for %q: %d (must be between 0 and %d milliseconds)", bundleID, ms, MaxPasteSplitIntervalMs))
+		}
+	}
+
+	for _, bundleID := range slices.Sorted(maps.Keys(c.AppLanguages)) {
+		if strings.TrimSpace(c.AppLanguages[bundleID]) == "" {
+			errs = append(errs, newFieldError("app_languages."+bundleID, CodeRequired, "app_languages language for %q cannot be empty", bundleID))
+		}
+	}
+
+	for i, language := range c.AutoFallbackLanguages {
+		if !IsValidLanguage(language) || language == "auto" {
+			errs = append(errs, newFieldError(fmt.Sprintf("auto_fallback_languages.%d", i), CodeInvalidValue, "invalid auto_fallback_languages language: %s", language))
+		}
+	}
+
+	if !IsValidTranslationMode(c.TranslationMode) {
+		errs = append(errs, newFieldError("translation_mode", CodeInvalidValue, "invalid translation_mode: %s (must be 'transcribe' or 'translate')", c.TranslationMode))
+	}
+
+	if !IsValidOutputMode(c.OutputMode) {
+		errs = append(errs, newFieldError("output_mode", CodeInvalidValue, "invalid output_mode: %s (must be 'paste' or 'copy')", c.OutputMode))
+	}
+	for _, bundleID := range slices.Sorted(maps.Keys(c.AppOutputModes)) {
+		if mode := c.AppOutputModes[bundleID]; !IsValidOutputMode(mode) {
+			errs = append(errs, newFieldError("app_output_modes."+bundleID, CodeInvalidValue, "invalid app_output_modes for %q: %s (must be 'paste' or 'copy')", bundleID, mode))
+		}
+	}
+
+	if c.HistoryMaxEntries < 1 || c.HistoryMaxEntries > MaxHistoryEntries {
+		errs = append(errs, newFieldError("history_max_entries", CodeOutOfRange, "invalid history_max_entries: %d (must be between 1 and %d)", c.HistoryMaxEntries, MaxHistoryEntries))
+	}
+
+	if c.RecordingsRetentionDays < 1 || c.RecordingsRetentionDays > MaxRecordingsRetentionDays {
+		errs = append(errs, newFieldError("recordings_retention_days", CodeOutOfRange, "invalid recordings_retention_days: %d (must be between 1 and %d)", c.RecordingsRetentionDays, MaxRecordingsRetentionDays))
+	}
+
+	// Validate idle model unloading
+	if c.IdleUnloadMinutes < 0 || c.IdleUnloadMinutes > MaxIdleUnloadMinutes {
+		errs = append(errs, newFieldError("idle_unload_minutes", CodeOutOfRange, "invalid idle_unload_minutes: %d (must be between 0 and %d minutes, 0 = never)", c.IdleUnloadMinutes, MaxIdleUnloadMinutes))
+	}
+
+	if !IsValidScreenLockedPolicy(c.ScreenLockedPolicy) {
+		errs = append(errs, newFieldError("screen_locked_policy", CodeInvalidValue, "invalid screen_locked_policy: %s (must be 'ignore', 'clipboard-only' or 'normal')", c.ScreenLockedPolicy))
+	}
+
+	// Validate paste length safeguard
+	if c.MaxPasteChars < 0 {
+		errs = append(errs, newFieldError("max_paste_chars", CodeOutOfRange, "invalid max_paste_chars: %d (must be 0 or positive, 0 = unlimited)", c.MaxPasteChars))
+	}
+
+	if !IsValidTimestampLayout(c.PasteTimestamp) {
+		errs = append(errs, newFieldError("paste_timestamp", CodeInvalidValue, "invalid paste_timestamp: %q (must be a Go time layout such as \"[15:04]\", at most %d characters)", c.PasteTimestamp, MaxPasteTimestampChars))
+	}
+
+	// Validate inference tuning overrides
+	if c.Threads < 0 || c.Threads > 64 {
+		errs = append(errs, newFieldError("threads", CodeOutOfRange, "invalid threads: %d (must be between 0 and 64, 0 = auto)", c.Threads))
+	}
+
+	if !IsValidDecodingPreset(c.DecodingPreset) {
+		errs = append(errs, newFieldError("decoding_preset", CodeInvalidValue, "invalid decoding_preset: %s (must be 'auto', 'fast', 'balanced' or 'accurate')", c.DecodingPreset))
+	}
+
+	if c.AdaptiveDecodingSeconds < 1 || c.AdaptiveDecodingSeconds > MaxAdaptiveDecodingSeconds {
+		errs = append(errs, newFieldError("adaptive_decoding_seconds", CodeOutOfRange, "invalid adaptive_decoding_seconds: %d (must be between 1 and %d)", c.AdaptiveDecodingSeconds, MaxAdaptiveDecodingSeconds))
+	}
+
+	// Validate toggle grace window
+	if c.ToggleGraceMs < 0 || c.ToggleGraceMs > 2000 {
+		errs = append(errs, newFieldError("toggle_grace_ms", CodeOutOfRange, "invalid toggle_grace_ms: %d (must be between 0 and 2000 milliseconds)", c.ToggleGraceMs))
+	}
+
+	// Validate minimum recording length (0 disables the check)
+	if c.MinRecordMs < 0 || c.MinRecordMs > 2000 {
+		errs = append(errs, newFieldError("min_record_ms", CodeOutOfRange, "invalid min_record_ms: %d (must be between 0 and 2000 milliseconds)", c.MinRecordMs))
+	}
+
+	if c.SilenceThreshold < 0 || c.SilenceThreshold > MaxSilenceThreshold {
+		errs = append(errs, newFieldError("silence_threshold", CodeOutOfRange, "invalid silence_threshold: %g (must be between 0 and %g)", c.SilenceThreshold, MaxSilenceThreshold))
+	}
+
+	// Validate repetition detection thresholds (0 disables each check)
+	if c.RepetitionMaxRepeats < 0 || c.RepetitionMaxRepeats == 1 {
+		errs = append(errs, newFieldError("repetition_max_repeats", CodeOutOfRange, "invalid repetition_max_repeats: %d (must be 0 or at least 2)", c.RepetitionMaxRepeats))
+	}
+
+	if c.RepetitionMaxCompressionRatio < 0 {
+		errs = append(errs, newFieldError("repetition_max_compression_ratio", CodeOutOfRange, "invalid repetition_max_compression_ratio: %g (must be 0 or positive)", c.RepetitionMaxCompressionRatio))
+	}
+
+	// Whisper only uses the last part of a long prompt, so keep it short
+	if n := utf8.RuneCountInString(c.InitialPrompt); n > MaxInitialPromptChars {
+		errs = append(errs, newFieldError("initial_prompt", CodeOutOfRange, "invalid initial_prompt: %d characters (must be at most %d)", n, MaxInitialPromptChars))
+	}
+
+	// The clipboard transcription hotkey cannot replace the recording hotkey
+	if c.ClipboardTranscribeHotkey.Key != "" && c.ClipboardTranscribeHotkey.SameCombination(c.Hotkey) {
+		errs = append(errs, newFieldError("clipboard_transcribe_hotkey", CodeInvalidValue, "invalid clipboard_transcribe_hotkey: same as hotkey"))
+	}
+
+	// The second recording hotkey must differ from both other hotkeys
+	if c.SecondHotkey.Key != "" {
+		if c.SecondHotkey.SameCombination(c.Hotkey) {
+			errs = append(errs, newFieldError("second_hotkey", CodeInvalidValue, "invalid second_hotkey: same as hotkey"))
+		} else if c.SecondHotkey.SameCombination(c.ClipboardTranscribeHotkey) {
+			errs = append(errs, newFieldError("second_hotkey", CodeInvalidValue, "invalid second_hotkey: same as clipboard_transcribe_hotkey"))
+		}
+	}
+
+	// Validate update manifest URL (only needed when checks are enabled)
+	if c.CheckUpdates && !isHTTPURL(c.UpdateManifestURL) {
+		errs = append(errs, newFieldError("update_manifest_url", CodeInvalidValue, "invalid update_manifest_url: %q (must be an http or https URL)", c.UpdateManifestURL))
 	}
 
 	// Model path validation is optional (can be empty for first run)
 	// Use ValidateModelPath() separately when model path is required
 
-	return nil
+	return errs
 }
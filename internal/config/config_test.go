@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -29,8 +31,8 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected RecordingMode 'press-to-hold', got '%s'", config.RecordingMode)
 	}
 
-	if config.Language != "ja" {
-		t.Errorf("Expected Language 'ja', got '%s'", config.Language)
+	if config.Language != "auto" {
+		t.Errorf("Expected Language 'auto', got '%s'", config.Language)
 	}
 
 	if config.UILanguage != "ja" {
@@ -41,11 +43,46 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected MaxRecordTime 60, got %d", config.MaxRecordTime)
 	}
 
+	if !config.PreventSleepWhileRecording {
+		t.Error("Expected PreventSleepWhileRecording to be true")
+	}
+
+	if config.HistoryEnabled || config.HistoryMaxEntries != 1000 {
+		t.Errorf("Expected history disabled with 1000 entries, got %v/%d", config.HistoryEnabled, config.HistoryMaxEntries)
+	}
+
+	if config.SaveRecordings || config.RecordingsRetentionDays != 7 {
+		t.Errorf("Expected recordings not saved, kept 7 days, got %v/%d", config.SaveRecordings, config.RecordingsRetentionDays)
+	}
+
+	if config.AdaptiveDecoding || config.AdaptiveDecodingSeconds != 10 {
+		t.Errorf("Expected adaptive decoding off with 10 seconds, got %v/%d", config.AdaptiveDecoding, config.AdaptiveDecodingSeconds)
+	}
+
+	if config.Streaming {
+		t.Error("Expected Streaming to be false")
+	}
+
+	if config.SilenceThreshold != 0.005 {
+		t.Errorf("Expected SilenceThreshold 0.005, got %g", config.SilenceThreshold)
+	}
+
 	if config.PasteSplitSize != 500 {
 		t.Errorf("Expected PasteSplitSize 500, got %d", config.PasteSplitSize)
 	}
 }
 
+func TestHotkeyConfig_WithKeys(t *testing.T) {
+	current := HotkeyConfig{Cmd: true, Key: "R", Options: HotkeyOptions{Translate: true, Language: "ja"}}
+
+	got := current.WithKeys(DefaultHotkey())
+
+	expected := HotkeyConfig{Ctrl: true, Alt: true, Key: "Space", Options: current.Options}
+	if got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
 func TestSaveAndLoad(t *testing.T) {
 	// Create temporary directory
 	tmpDir := t.TempDir()
@@ -100,14 +137,168 @@ func TestLoadNonexistent(t *testing.T) {
 	}
 }
 
+func TestLoadMissingFieldsUseDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+
+	// Config file written by an older version without repetition settings
+	data := []byte(`{"recording_mode": "toggle", "language": "en"}`)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	loaded, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if loaded.RecordingMode != "toggle" {
+		t.Errorf("Expected RecordingMode 'toggle', got '%s'", loaded.RecordingMode)
+	}
+
+	if loaded.RepetitionMaxRepeats != 4 {
+		t.Errorf("Expected default RepetitionMaxRepeats 4, got %d", loaded.RepetitionMaxRepeats)
+	}
+
+	if loaded.RepetitionMaxCompressionRatio != 2.4 {
+		t.Errorf("Expected default RepetitionMaxCompressionRatio 2.4, got %g", loaded.RepetitionMaxCompressionRatio)
+	}
+}
+
+func TestLoadNormalizesSpaceKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+
+	// NBSP saved by an older version when the key was captured through the macOS IME
+	data := []byte(`{"hotkey": {"ctrl": true, "key": "\u00a0"}}`)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	loaded, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if loaded.Hotkey.Key != "Space" {
+		t.Errorf("Expected Hotkey.Key 'Space', got %q", loaded.Hotkey.Key)
+	}
+}
+
+func TestLoadNormalizesLanguages(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+
+	data := []byte(`{"language": "Japanese", "app_languages": {"com.apple.dt.Xcode": "EN"}, "auto_fallback_languages": ["ja", "English"]}`)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	loaded, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if loaded.Language != "ja" || loaded.AppLanguages["com.apple.dt.Xcode"] != "en" || loaded.AutoFallbackLanguages[1] != "en" {
+		t.Errorf("Expected language names to be stored as codes, got %q %v %v", loaded.Language, loaded.AppLanguages, loaded.AutoFallbackLanguages)
+	}
+	if err := loaded.Validate(); err != nil {
+		t.Errorf("Expected the normalized config to be valid, got %v", err)
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ja", "ja"},
+		{" EN ", "en"},
+		{"Japanese", "ja"},
+		{"cantonese", "yue"},
+		{"Haitian Creole", "ht"},
+		{"AUTO", "auto"},
+		{"klingon", "klingon"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLanguage(tt.input); got != tt.expected {
+			t.Errorf("NormalizeLanguage(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeKeyName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Space", "Space"},
+		{" ", "Space"},
+		{"\u00a0", "Space"},
+		{"\u2007", "Space"},
+		{"\u202f", "Space"},
+		{"\u3000", "Space"},
+		{"A", "A"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeKeyName(tt.input); got != tt.expected {
+			t.Errorf("NormalizeKeyName(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	config := DefaultConfig()
+	if err := config.Update(map[string]interface{}{"hotkey": map[string]interface{}{"key": "\u202f"}}); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	if config.Hotkey.Key != "Space" {
+		t.Errorf("Expected Update to normalize key to 'Space', got %q", config.Hotkey.Key)
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	config := DefaultConfig()
 
 	updates := map[string]interface{}{
-		"recording_mode":  "toggle",
-		"language":        "en",
-		"audio_device_id": float64(1),
-		"max_record_time": float64(90),
+		"recording_mode":             "toggle",
+		"language":                   "en",
+		"audio_device_id":            float64(1),
+		"max_record_time":            float64(90),
+		"tray_show_text":             true,
+		"max_paste_chars":            float64(2000),
+		"paste_timestamp":            "[15:04]",
+		"dedupe_segments":            true,
+		"check_updates":              true,
+		"audio_normalize":            true,
+		"start_beep":                 true,
+		"streaming":                  true,
+		"strip_leading_space":        false,
+		"initial_prompt":             "{app} で入力中",
+		"paste_split_interval_ms":    float64(80),
+		"paste_app_intervals_ms":     map[string]interface{}{"com.tinyspeck.slackmacgap": float64(200)},
+		"log_transcription_text":     true,
+		"app_languages":              map[string]interface{}{"com.apple.dt.Xcode": "en"},
+		"idle_unload_minutes":        float64(30),
+		"history_enabled":            true,
+		"history_max_entries":        float64(200),
+		"save_recordings":            true,
+		"recordings_retention_days":  float64(3),
+		"adaptive_decoding":          true,
+		"adaptive_decoding_seconds":  float64(20),
+		"screen_locked_policy":       "clipboard-only",
+		"auto_select_recommended":    true,
+		"show_timings":               true,
+		"min_record_ms":              float64(150),
+		"silence_threshold":          0.01,
+		"restore_focus_before_paste": true,
+		"use_gpu":                    false,
+		"output_mode":                "copy",
+		"translation_mode":           "translate",
+		"app_output_modes":           map[string]interface{}{"com.apple.Terminal": "copy"},
+		"toggle_max_record_time":     float64(180),
 	}
 
 	if err := config.Update(updates); err != nil {
@@ -129,6 +320,422 @@ func TestUpdate(t *testing.T) {
 	if config.MaxRecordTime != 90 {
 		t.Errorf("Expected MaxRecordTime 90, got %d", config.MaxRecordTime)
 	}
+
+	if config.ToggleMaxRecordTime != 180 {
+		t.Errorf("Expected ToggleMaxRecordTime 180, got %d", config.ToggleMaxRecordTime)
+	}
+
+	if !config.TrayShowText {
+		t.Error("Expected TrayShowText to be true")
+	}
+
+	if !config.LogTranscriptionText {
+		t.Error("Expected LogTranscriptionText to be true")
+	}
+
+	if config.MaxPasteChars != 2000 {
+		t.Errorf("Expected MaxPasteChars 2000, got %d", config.MaxPasteChars)
+	}
+
+	if config.PasteTimestamp != "[15:04]" {
+		t.Errorf("Expected PasteTimestamp [15:04], got %q", config.PasteTimestamp)
+	}
+
+	if !config.DedupeSegments {
+		t.Error("Expected DedupeSegments to be true")
+	}
+
+	if config.StripLeadingSpace {
+		t.Error("Expected StripLeadingSpace to be false")
+	}
+
+	if !config.StartBeep {
+		t.Error("Expected StartBeep to be true")
+	}
+
+	if !config.Streaming {
+		t.Error("Expected Streaming to be true")
+	}
+
+	if err := config.Update(map[string]interface{}{"prevent_sleep_while_recording": false}); err != nil || config.PreventSleepWhileRecording {
+		t.Errorf("Expected PreventSleepWhileRecording to be disabled (err=%v)", err)
+	}
+
+	if !config.CheckUpdates {
+		t.Error("Expected CheckUpdates to be true")
+	}
+
+	if !config.AudioNormalize {
+		t.Error("Expected AudioNormalize to be true")
+	}
+
+	if config.InitialPrompt != "{app} で入力中" {
+		t.Errorf("Expected InitialPrompt to be set, got %q", config.InitialPrompt)
+	}
+
+	if config.PasteSplitIntervalMs != 80 {
+		t.Errorf("Expected PasteSplitIntervalMs 80, got %d", config.PasteSplitIntervalMs)
+	}
+
+	if len(config.PasteAppIntervalsMs) != 1 || config.PasteAppIntervalsMs["com.tinyspeck.slackmacgap"] != 200 {
+		t.Errorf("Expected Slack interval 200, got %v", config.PasteAppIntervalsMs)
+	}
+
+	if config.AppLanguages["com.apple.dt.Xcode"] != "en" {
+		t.Errorf("Expected Xcode language en, got %v", config.AppLanguages)
+	}
+
+	if config.TranslationMode != TranslationTranslate {
+		t.Errorf("Expected TranslationMode 'translate', got '%s'", config.TranslationMode)
+	}
+
+	if config.OutputMode != OutputCopy || config.AppOutputModes["com.apple.Terminal"] != OutputCopy {
+		t.Errorf("Expected copy output, got %q and %v", config.OutputMode, config.AppOutputModes)
+	}
+
+	if config.IdleUnloadMinutes != 30 {
+		t.Errorf("Expected IdleUnloadMinutes 30, got %d", config.IdleUnloadMinutes)
+	}
+
+	if !config.HistoryEnabled || config.HistoryMaxEntries != 200 {
+		t.Errorf("Expected history enabled with 200 entries, got %v/%d", config.HistoryEnabled, config.HistoryMaxEntries)
+	}
+
+	if !config.SaveRecordings || config.RecordingsRetentionDays != 3 {
+		t.Errorf("Expected recordings saved for 3 days, got %v/%d", config.SaveRecordings, config.RecordingsRetentionDays)
+	}
+
+	if config.SilenceThreshold != 0.01 {
+		t.Errorf("Expected SilenceThreshold 0.01, got %g", config.SilenceThreshold)
+	}
+
+	if !config.AdaptiveDecoding || config.AdaptiveDecodingSeconds != 20 {
+		t.Errorf("Expected adaptive decoding from 20 seconds, got %v/%d", config.AdaptiveDecoding, config.AdaptiveDecodingSeconds)
+	}
+
+	if config.ScreenLockedPolicy != ScreenLockedClipboardOnly {
+		t.Errorf("Expected ScreenLockedPolicy 'clipboard-only', got '%s'", config.ScreenLockedPolicy)
+	}
+
+	if !config.AutoSelectRecommended {
+		t.Error("Expected AutoSelectRecommended to be true")
+	}
+
+	if !config.ShowTimings {
+		t.Error("Expected ShowTimings to be true")
+	}
+
+	if config.MinRecordMs != 150 {
+		t.Errorf("Expected MinRecordMs 150, got %d", config.MinRecordMs)
+	}
+
+	if !config.RestoreFocusBeforePaste {
+		t.Error("Expected RestoreFocusBeforePaste to be true")
+	}
+
+	if config.UseGPU {
+		t.Error("Expected UseGPU to be false")
+	}
+}
+
+func TestUpdateClipboardTranscribeHotkey(t *testing.T) {
+	config := DefaultConfig()
+
+	if config.ClipboardTranscribeHotkey.Key != "" {
+		t.Errorf("Expected the clipboard hotkey to be disabled by default, got %+v", config.ClipboardTranscribeHotkey)
+	}
+
+	err := config.Update(map[string]interface{}{
+		"clipboard_transcribe_hotkey": map[string]interface{}{"ctrl": true, "alt": true, "key": "V"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set clipboard hotkey: %v", err)
+	}
+	if hk := config.ClipboardTranscribeHotkey; !hk.Ctrl || !hk.Alt || hk.Key != "V" {
+		t.Errorf("Expected ⌃⌥V, got %+v", hk)
+	}
+
+	// The same combination as the recording hotkey is rejected
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"clipboard_transcribe_hotkey": map[string]interface{}{"key": "Space"},
+	})
+	if !errs.Has("clipboard_transcribe_hotkey") {
+		t.Errorf("Expected a conflict with the recording hotkey, got %v", errs)
+	}
+
+	// An empty key disables it again
+	if err := config.Update(map[string]interface{}{"clipboard_transcribe_hotkey": map[string]interface{}{"key": ""}}); err != nil {
+		t.Errorf("Expected an empty key to be accepted, got %v", err)
+	}
+}
+
+func TestUpdateSecondHotkey(t *testing.T) {
+	config := DefaultConfig()
+
+	if config.SecondHotkey.Key != "" {
+		t.Errorf("Expected the second hotkey to be disabled by default, got %+v", config.SecondHotkey)
+	}
+
+	err := config.Update(map[string]interface{}{
+		"second_hotkey": map[string]interface{}{
+			"ctrl": true, "shift": true, "key": "Space",
+			"options": map[string]interface{}{"translate": true, "language": " ja "},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set second hotkey: %v", err)
+	}
+	if hk := config.SecondHotkey; !hk.Ctrl || !hk.Shift || hk.Key != "Space" {
+		t.Errorf("Expected ⌃⇧Space, got %+v", hk)
+	}
+	if options := config.SecondHotkey.Options; !options.Translate || options.Language != "ja" {
+		t.Errorf("Expected translation from Japanese, got %+v", options)
+	}
+
+	// Updating only the keys keeps the options
+	if err := config.Update(map[string]interface{}{"second_hotkey": map[string]interface{}{"key": "T"}}); err != nil {
+		t.Fatalf("Failed to change the key: %v", err)
+	}
+	if !config.SecondHotkey.Options.Translate {
+		t.Error("Expected options to be kept when they are not part of the update")
+	}
+
+	// Options do not make the same combination a different hotkey
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"second_hotkey": map[string]interface{}{"ctrl": true, "shift": false, "alt": true, "key": "Space"},
+	})
+	if !errs.Has("second_hotkey") {
+		t.Errorf("Expected a conflict with the recording hotkey, got %v", errs)
+	}
+
+	errs = config.ValidateUpdates(map[string]interface{}{
+		"second_hotkey": map[string]interface{}{"options": map[string]interface{}{"translate": "yes", "language": 1}},
+	})
+	if !errs.Has("second_hotkey.options.translate") || !errs.Has("second_hotkey.options.language") {
+		t.Errorf("Expected option type errors, got %v", errs)
+	}
+	if errs := config.ValidateUpdates(map[string]interface{}{"second_hotkey": map[string]interface{}{"options": true}}); !errs.Has("second_hotkey.options") {
+		t.Errorf("Expected type error for non-object options, got %v", errs)
+	}
+}
+
+func TestUpdatePasteAppIntervals(t *testing.T) {
+	config := DefaultConfig()
+
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"paste_app_intervals_ms": map[string]interface{}{"com.tinyspeck.slackmacgap": float64(MaxPasteSplitIntervalMs + 1), "": float64(100), "com.apple.Notes": "slow"},
+	})
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %v", errs)
+	}
+	if !errs.Has("paste_app_intervals_ms.") || !errs.Has("paste_app_intervals_ms.com.apple.Notes") || !errs.Has("paste_app_intervals_ms.com.tinyspeck.slackmacgap") {
+		t.Errorf("Expected per-app errors, got %v", errs)
+	}
+
+	if errs := config.ValidateUpdates(map[string]interface{}{"paste_app_intervals_ms": []interface{}{}}); !errs.Has("paste_app_intervals_ms") {
+		t.Errorf("Expected type error for a non-object, got %v", errs)
+	}
+
+	// An update replaces the whole map, so an empty object removes all overrides
+	config.PasteAppIntervalsMs = AppIntervals{"com.tinyspeck.slackmacgap": 200}
+	if err := config.Update(map[string]interface{}{"paste_app_intervals_ms": map[string]interface{}{}}); err != nil {
+		t.Fatalf("Failed to clear overrides: %v", err)
+	}
+	if len(config.PasteAppIntervalsMs) != 0 {
+		t.Errorf("Expected overrides to be cleared, got %v", config.PasteAppIntervalsMs)
+	}
+}
+
+func TestUpdateAppLanguages(t *testing.T) {
+	config := DefaultConfig()
+
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"app_languages": map[string]interface{}{"": "en", "com.apple.Notes": "", "com.apple.dt.Xcode": float64(1)},
+	})
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %v", errs)
+	}
+	if !errs.Has("app_languages.") || !errs.Has("app_languages.com.apple.Notes") || !errs.Has("app_languages.com.apple.dt.Xcode") {
+		t.Errorf("Expected per-app errors, got %v", errs)
+	}
+
+	if errs := config.ValidateUpdates(map[string]interface{}{"app_languages": "en"}); !errs.Has("app_languages") {
+		t.Errorf("Expected type error for a non-object, got %v", errs)
+	}
+}
+
+func TestUpdateAutoFallbackLanguages(t *testing.T) {
+	config := DefaultConfig()
+
+	if err := config.Update(map[string]interface{}{"auto_fallback_languages": []interface{}{"ja", "en"}}); err != nil {
+		t.Fatalf("Failed to set fallback languages: %v", err)
+	}
+	if len(config.AutoFallbackLanguages) != 2 || config.AutoFallbackLanguages[0] != "ja" || config.AutoFallbackLanguages[1] != "en" {
+		t.Errorf("Expected [ja en], got %v", config.AutoFallbackLanguages)
+	}
+
+	// Names and capitalized codes are stored as codes
+	if err := config.Update(map[string]interface{}{"auto_fallback_languages": []interface{}{"Japanese", " EN "}}); err != nil {
+		t.Fatalf("Failed to set fallback languages by name: %v", err)
+	}
+	if len(config.AutoFallbackLanguages) != 2 || config.AutoFallbackLanguages[0] != "ja" || config.AutoFallbackLanguages[1] != "en" {
+		t.Errorf("Expected [ja en], got %v", config.AutoFallbackLanguages)
+	}
+
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"auto_fallback_languages": []interface{}{"ja", "auto", "Klingon", float64(1)},
+	})
+	if len(errs) != 3 || !errs.Has("auto_fallback_languages.1") || !errs.Has("auto_fallback_languages.2") || !errs.Has("auto_fallback_languages.3") {
+		t.Errorf("Expected errors for the invalid entries, got %v", errs)
+	}
+
+	if errs := config.ValidateUpdates(map[string]interface{}{"auto_fallback_languages": "ja"}); !errs.Has("auto_fallback_languages") {
+		t.Errorf("Expected type error for a non-array, got %v", errs)
+	}
+
+	// Clone does not share the list
+	clone := config.Clone()
+	clone.AutoFallbackLanguages[0] = "fr"
+	if config.AutoFallbackLanguages[0] != "ja" {
+		t.Error("Expected Clone to copy the fallback languages")
+	}
+}
+
+func TestLanguageFor(t *testing.T) {
+	config := DefaultConfig()
+	config.Language = "ja"
+	config.AppLanguages = AppLanguages{"com.apple.dt.Xcode": "en"}
+
+	tests := []struct {
+		name     string
+		global   string
+		bundleID string
+		expected string
+	}{
+		{"per-app override", "ja", "com.apple.dt.Xcode", "en"},
+		{"global for other apps", "ja", "com.tinyspeck.slackmacgap", "ja"},
+		{"global when the app is unknown", "ja", "", "ja"},
+		{"auto without a global language", "", "com.tinyspeck.slackmacgap", "auto"},
+		{"per-app override without a global language", "", "com.apple.dt.Xcode", "en"},
+	}
+
+	for _, tt := range tests {
+		config.Language = tt.global
+		if got := config.LanguageFor(tt.bundleID); got != tt.expected {
+			t.Errorf("%s: LanguageFor(%q) = %q, expected %q", tt.name, tt.bundleID, got, tt.expected)
+		}
+	}
+}
+
+func TestMaxRecordTimeFor(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxRecordTime = 60
+
+	if got := config.MaxRecordTimeFor("toggle"); got != 60 {
+		t.Errorf("Expected toggle mode to use max_record_time by default, got %d", got)
+	}
+
+	config.ToggleMaxRecordTime = 180
+	if got := config.MaxRecordTimeFor("toggle"); got != 180 {
+		t.Errorf("Expected 180 seconds in toggle mode, got %d", got)
+	}
+	if got := config.MaxRecordTimeFor("press-to-hold"); got != 60 {
+		t.Errorf("Expected 60 seconds in press-to-hold mode, got %d", got)
+	}
+
+	errs := config.ValidateUpdates(map[string]interface{}{"toggle_max_record_time": float64(301)})
+	if !errs.Has("toggle_max_record_time") {
+		t.Errorf("Expected an error for toggle_max_record_time, got %v", errs)
+	}
+}
+
+func TestOutputModeFor(t *testing.T) {
+	config := DefaultConfig()
+	config.AppOutputModes = AppOutputs{"com.apple.Terminal": OutputCopy}
+
+	tests := []struct {
+		name     string
+		global   string
+		bundleID string
+		expected string
+	}{
+		{"per-app override", OutputPaste, "com.apple.Terminal", OutputCopy},
+		{"global for other apps", OutputPaste, "com.apple.Notes", OutputPaste},
+		{"global when the app is unknown", OutputCopy, "", OutputCopy},
+		{"paste without a global mode", "", "com.apple.Notes", OutputPaste},
+	}
+
+	for _, tt := range tests {
+		config.OutputMode = tt.global
+		if got := config.OutputModeFor(tt.bundleID); got != tt.expected {
+			t.Errorf("%s: OutputModeFor(%q) = %q, expected %q", tt.name, tt.bundleID, got, tt.expected)
+		}
+	}
+
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"app_output_modes": map[string]interface{}{"": "copy", "com.apple.Notes": "type"},
+	})
+	if !errs.Has("app_output_modes.") || !errs.Has("app_output_modes.com.apple.Notes") {
+		t.Errorf("Expected per-app errors, got %v", errs)
+	}
+}
+
+func TestUpdateInitialPromptTooLong(t *testing.T) {
+	config := DefaultConfig()
+
+	long := strings.Repeat("あ", MaxInitialPromptChars+1)
+	errs := config.ValidateUpdates(map[string]interface{}{"initial_prompt": long})
+	if len(errs) != 1 || errs[0].Field != "initial_prompt" || errs[0].Code != CodeOutOfRange {
+		t.Errorf("Expected out of range error for initial_prompt, got %v", errs)
+	}
+
+	// The limit counts characters, not bytes
+	if err := config.Update(map[string]interface{}{"initial_prompt": strings.Repeat("あ", MaxInitialPromptChars)}); err != nil {
+		t.Errorf("Expected prompt at the limit to be accepted, got %v", err)
+	}
+}
+
+func TestIsValidTimestampLayout(t *testing.T) {
+	tests := []struct {
+		layout   string
+		expected bool
+	}{
+		{"", true},
+		{"[15:04]", true},
+		{"2006-01-02 15:04:05 -", true},
+		{"Mon", true},
+		{"time", false},
+		{strings.Repeat("15:04 ", 20), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValidTimestampLayout(tt.layout); got != tt.expected {
+			t.Errorf("IsValidTimestampLayout(%q) = %v, expected %v", tt.layout, got, tt.expected)
+		}
+	}
+}
+
+func TestUpdateInvalidManifestURL(t *testing.T) {
+	config := DefaultConfig()
+
+	updates := map[string]interface{}{
+		"update_manifest_url": "ftp://example.com/version.json",
+	}
+
+	if err := config.Update(updates); err == nil {
+		t.Error("Expected error for non-HTTP update_manifest_url")
+	}
+
+	// An empty URL is accepted while checks are disabled, but not when enabled
+	config.UpdateManifestURL = ""
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected empty URL to be valid while checks are disabled, got %v", err)
+	}
+
+	config.CheckUpdates = true
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for empty URL while checks are enabled")
+	}
 }
 
 func TestUpdateInvalidValues(t *testing.T) {
@@ -152,6 +759,11 @@ func TestUpdateInvalidValues(t *testing.T) {
 		t.Error("Expected error for invalid language")
 	}
 
+	// A language name is accepted and stored as its code
+	if err := config.Update(map[string]interface{}{"language": " English "}); err != nil || config.Language != "en" {
+		t.Errorf("Expected language 'en', got %q (%v)", config.Language, err)
+	}
+
 	// Test invalid ui_language
 	updates = map[string]interface{}{
 		"ui_language": "invalid",
@@ -162,6 +774,94 @@ func TestUpdateInvalidValues(t *testing.T) {
 	}
 }
 
+func TestValidateUpdates(t *testing.T) {
+	config := DefaultConfig()
+
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"recording_mode":       "invalid",
+		"max_record_time":      float64(301),
+		"threads":              "four",
+		"language":             "",
+		"idle_unload_minutes":  float64(-1),
+		"history_max_entries":  float64(0),
+		"paste_timestamp":      "time",
+		"screen_locked_policy": "paste-later",
+		"min_record_ms":        float64(-100),
+		"silence_threshold":    0.5,
+		"output_mode":          "type",
+		"translation_mode":     "summarize",
+	})
+
+	expected := map[string]string{
+		"recording_mode":       CodeInvalidValue,
+		"max_record_time":      CodeOutOfRange,
+		"threads":              CodeInvalidType,
+		"language":             CodeRequired,
+		"idle_unload_minutes":  CodeOutOfRange,
+		"history_max_entries":  CodeOutOfRange,
+		"paste_timestamp":      CodeInvalidValue,
+		"screen_locked_policy": CodeInvalidValue,
+		"min_record_ms":        CodeOutOfRange,
+		"silence_threshold":    CodeOutOfRange,
+		"output_mode":          CodeInvalidValue,
+		"translation_mode":     CodeInvalidValue,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for _, fieldErr := range errs {
+		if code, ok := expected[fieldErr.Field]; !ok || code != fieldErr.Code {
+			t.Errorf("Unexpected error %s/%s: %s", fieldErr.Field, fieldErr.Code, fieldErr.Message)
+		}
+	}
+
+	// The configuration itself must not change
+	if config.RecordingMode != "press-to-hold" || config.MaxRecordTime != 60 || config.Language != "auto" {
+		t.Errorf("ValidateUpdates modified the configuration: %+v", config)
+	}
+
+	if errs := config.ValidateUpdates(map[string]interface{}{"max_record_time": float64(300)}); len(errs) != 0 {
+		t.Errorf("Expected no errors for max_record_time 300, got %v", errs)
+	}
+}
+
+func TestValidateUpdates_Hotkey(t *testing.T) {
+	config := DefaultConfig()
+
+	errs := config.ValidateUpdates(map[string]interface{}{
+		"hotkey": map[string]interface{}{"ctrl": "yes", "key": ""},
+	})
+	if len(errs) != 2 || errs[0].Field != "hotkey.ctrl" || errs[1].Field != "hotkey.key" {
+		t.Fatalf("Expected errors for hotkey.ctrl and hotkey.key, got %v", errs)
+	}
+
+	// A partially invalid hotkey must not be applied at all
+	original := config.Hotkey
+	if err := config.Update(map[string]interface{}{
+		"hotkey": map[string]interface{}{"shift": true, "key": 5.0},
+	}); err == nil {
+		t.Error("Expected error for non-string hotkey key")
+	}
+	if config.Hotkey != original {
+		t.Errorf("Expected hotkey unchanged, got %+v", config.Hotkey)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxRecordTime = 0
+	config.PasteSplitSize = 0
+
+	err := config.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ValidationErrors, got %T: %v", err, err)
+	}
+	if !errs.Has("max_record_time") || !errs.Has("paste_split_size") {
+		t.Errorf("Expected both invalid fields to be reported, got %v", errs)
+	}
+}
+
 func TestClone(t *testing.T) {
 	original := DefaultConfig()
 	original.RecordingMode = "toggle"
@@ -180,10 +880,15 @@ func TestClone(t *testing.T) {
 
 	// Modify clone and verify original is unaffected
 	cloned.Language = "ja"
+	cloned.PasteAppIntervalsMs["com.tinyspeck.slackmacgap"] = 200
 
 	if original.Language != "en" {
 		t.Error("Modifying clone affected original")
 	}
+
+	if len(original.PasteAppIntervalsMs) != 0 {
+		t.Error("Modifying clone's app intervals affected original")
+	}
 }
 
 func TestGetConfigPath(t *testing.T) {
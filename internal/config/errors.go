@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error codes reported in FieldError.Code
+const (
+	CodeInvalidType  = "invalid_type"  // Value has the wrong JSON type
+	CodeInvalidValue = "invalid_value" // Value is not one of the accepted values
+	CodeOutOfRange   = "out_of_range"  // Numeric value is outside the allowed range
+	CodeRequired     = "required"      // Value must not be empty
+)
+
+// FieldError describes why a single setting is invalid
+type FieldError struct {
+	Field   string `json:"field"`   // JSON key, nested keys joined with "." (e.g. "hotkey.key")
+	Code    string `json:"code"`    // One of the Code* constants
+	Message string `json:"message"` // Human readable description
+}
+
+// Error returns the message
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// newFieldError creates a FieldError with a formatted message
+func newFieldError(field, code, format string, args ...interface{}) *FieldError {
+	return &FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationErrors collects every invalid setting found in one pass
+type ValidationErrors []*FieldError
+
+// Error joins the messages of all field errors
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Has reports whether an error was already recorded for field
+func (e ValidationErrors) Has(field string) bool {
+	for _, fieldErr := range e {
+		if fieldErr.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// orNil converts an empty list to a nil error so it is not returned as a non-nil interface
+func (e ValidationErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,194 @@
+// Package diagnostics assembles the zip bundle users attach to bug reports:
+// recent logs, the configuration with sensitive fields redacted and JSON
+// snapshots of the running app.
+package diagnostics
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+)
+
+// Redacted replaces the value of a sensitive field
+const Redacted = "[redacted]"
+
+// MaxLogFiles is how many of the newest daily log files are included
+const MaxLogFiles = 3
+
+// redactedFields may contain personal text (names, terms from the user's work)
+// and are replaced when set
+var redactedFields = []string{"initial_prompt"}
+
+// Bundle is the content of a diagnostics bundle. Snapshot fields that are nil are left out.
+type Bundle struct {
+	Config      *config.Config // Written to config.json with sensitive fields redacted
+	Version     any            // version.json: version and build information
+	System      any            // system.json: macOS version and architecture
+	Status      any            // status.json: the /api/status snapshot
+	Devices     any            // devices.json: audio input devices
+	Permissions any            // permissions.json: microphone and accessibility state
+	LogDir      string         // The newest MaxLogFiles logs are copied to logs/, "" to skip
+}
+
+// FileName returns the suggested file name of a bundle created at now
+func FileName(now time.Time) string {
+	return fmt.Sprintf("ezs2t-whisper-diagnostics-%s.zip", now.Format("20060102-150405"))
+}
+
+// Write writes b to w as a zip archive
+func (b Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	if b.Config != nil {
+		redacted, err := RedactConfig(b.Config)
+		if err != nil {
+			return err
+		}
+		if err := writeJSON(zw, "config.json", redacted); err != nil {
+			return err
+		}
+	}
+
+	snapshots := []struct {
+		name  string
+		value any
+	}{
+		{"version.json", b.Version},
+		{"system.json", b.System},
+		{"status.json", b.Status},
+		{"devices.json", b.Devices},
+		{"permissions.json", b.Permissions},
+	}
+	for _, s := range snapshots {
+		if s.value == nil {
+			continue
+		}
+		if err := writeJSON(zw, s.name, s.value); err != nil {
+			return err
+		}
+	}
+
+	if b.LogDir != "" {
+		if err := writeLogs(zw, b.LogDir); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// RedactConfig returns the configuration as JSON fields with personal text and
+// URLs redacted. File paths are kept because they are needed to diagnose
+// model and device problems.
+func RedactConfig(cfg *config.Config) (map[string]any, error) {
+	data, err := json.Marshal(cfg.Clone())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	for name, value := range fields {
+		if s, ok := value.(string); ok {
+			fields[name] = redactURL(s)
+		}
+	}
+	for _, name := range redactedFields {
+		if s, ok := fields[name].(string); ok && s != "" {
+			fields[name] = Redacted
+		}
+	}
+
+	return fields, nil
+}
+
+// redactURL keeps only the scheme and host of an http(s) URL, since paths and
+// queries of webhooks and manifests may carry tokens. The default update
+// manifest URL is public and kept as is. Other values are returned unchanged.
+func redactURL(s string) string {
+	if s == config.DefaultUpdateManifestURL {
+		return s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return s
+	}
+	return u.Scheme + "://" + u.Hostname() + "/" + Redacted
+}
+
+// writeJSON adds value to the archive as an indented JSON file
+func writeJSON(zw *zip.Writer, name string, value any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeLogs copies the newest MaxLogFiles log files in dir to logs/.
+// A missing log folder is not an error.
+func writeLogs(zw *zip.Writer, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	// Daily log files are named ezs2t-whisper-YYYYMMDD.log, so the name order is the date order
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	if len(names) > MaxLogFiles {
+		names = names[len(names)-MaxLogFiles:]
+	}
+
+	for _, name := range names {
+		if err := copyFile(zw, "logs/"+name, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile adds the file at path to the archive as name
+func copyFile(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
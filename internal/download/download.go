@@ -0,0 +1,261 @@
+// Package download fetches ggml Whisper models from the official whisper.cpp
+// repository on Hugging Face into the models folder. Interrupted downloads are
+// kept next to the destination and resumed on the next attempt.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is where the official ggml models are published
+const DefaultBaseURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/"
+
+// PartialSuffix is appended to the destination while the download is incomplete
+const PartialSuffix = ".part"
+
+// idleTimeout is how long Fetch waits for the response or the next data before
+// giving up on a stalled connection. The partial file is kept for resuming.
+var idleTimeout = time.Minute
+
+// ErrStalled is returned when the server sends no data for idleTimeout
+var ErrStalled = errors.New("download stalled")
+
+// modelNamePattern matches the file names of the official models, e.g.
+// "ggml-large-v3-turbo-q5_0.bin". It also keeps the name from leaving the models folder.
+var modelNamePattern = regexp.MustCompile(`^ggml-[A-Za-z0-9._-]+\.bin$`)
+
+// checksumPattern matches the SHA-256 checksum Hugging Face sends in X-Linked-Etag
+var checksumPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// IsValidModelName checks if name looks like an official ggml model file name
+func IsValidModelName(name string) bool {
+	return modelNamePattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+// URL returns the download URL of the model name under baseURL
+func URL(baseURL, name string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + name
+}
+
+// Progress is the state of a download, as returned by /api/models/download/progress
+type Progress struct {
+	Model      string `json:"model"`
+	Downloaded int64  `json:"downloaded"` // Bytes written, including a resumed part
+	Total      int64  `json:"total"`      // Size of the model, 0 if the server did not report it
+	Active     bool   `json:"active"`
+	Done       bool   `json:"done"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Fetch downloads url to dest. The data is written to dest+PartialSuffix and
+// renamed once its size and checksum match those reported by the server, so
+// dest never holds a truncated or corrupt model. An existing partial file is resumed with a Range
+// request. progress, if not nil, is called as data arrives. The download fails
+// with ErrStalled if no data arrives for idleTimeout.
+func Fetch(ctx context.Context, client *http.Client, url, dest string, progress func(downloaded, total int64)) error {
+	partial := dest + PartialSuffix
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	idle := time.AfterFunc(idleTimeout, func() { cancel(ErrStalled) })
+	defer idle.Stop()
+
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, stallCause(ctx, err))
+	}
+	defer resp.Body.Close()
+
+	// Without a Content-Length the size reported for the LFS file still tells
+	// whether the download is complete
+	linkedSize, checksum := linkedFile(resp)
+
+	var total int64
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range, start over
+		offset = 0
+		total = resp.ContentLength
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file already holds the whole model, e.g. the previous
+		// attempt failed after the last byte but before the rename
+		if total = contentRangeTotal(resp.Header.Get("Content-Range")); total <= 0 {
+			total = linkedSize
+		}
+		if total > 0 && total == offset {
+			if progress != nil {
+				progress(offset, total)
+			}
+			if err := verifyChecksum(partial, checksum); err != nil {
+				return err
+			}
+			if err := os.Rename(partial, dest); err != nil {
+				return fmt.Errorf("failed to move download to %s: %w", dest, err)
+			}
+			return nil
+		}
+		// The partial file is not a prefix of the model (e.g. it changed upstream)
+		os.Remove(partial)
+		return fmt.Errorf("partial download of %s is invalid and was removed, try again", url)
+	default:
+		return fmt.Errorf("failed to download %s: unexpected status: %s", url, resp.Status)
+	}
+	if total <= 0 {
+		total = linkedSize
+	}
+
+	f, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", partial, err)
+	}
+
+	w := &progressWriter{w: f, written: offset, total: total, progress: progress, idle: idle}
+	if progress != nil {
+		progress(offset, total)
+	}
+	_, copyErr := io.Copy(w, resp.Body)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		// The partial file is kept so the next attempt resumes from here
+		return fmt.Errorf("download of %s interrupted after %d bytes: %w", url, w.written, stallCause(ctx, copyErr))
+	}
+
+	if total > 0 && w.written != total {
+		if w.written > total {
+			os.Remove(partial)
+		}
+		return fmt.Errorf("downloaded size %d does not match expected size %d", w.written, total)
+	}
+	if err := verifyChecksum(partial, checksum); err != nil {
+		return err
+	}
+
+	if err := os.Rename(partial, dest); err != nil {
+		return fmt.Errorf("failed to move download to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// contentRangeTotal returns the complete length from a "bytes 100-199/200"
+// Content-Range header, or 0 if it is unknown
+func contentRangeTotal(header string) int64 {
+	_, size, ok := strings.Cut(header, "/")
+	if !ok {
+		return 0
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return total
+}
+
+// linkedFile returns the size and SHA-256 checksum Hugging Face reports for
+// LFS files in X-Linked-Size and X-Linked-Etag. They are sent with the
+// redirect to the CDN, so the whole redirect chain is searched. Unknown values
+// are returned as 0 and "".
+func linkedFile(resp *http.Response) (size int64, checksum string) {
+	for r := resp; r != nil; {
+		if size == 0 {
+			if s, err := strconv.ParseInt(r.Header.Get("X-Linked-Size"), 10, 64); err == nil && s > 0 {
+				size = s
+			}
+		}
+		if checksum == "" {
+			if etag := strings.Trim(r.Header.Get("X-Linked-Etag"), `"`); checksumPattern.MatchString(etag) {
+				checksum = strings.ToLower(etag)
+			}
+		}
+
+		if r.Request == nil {
+			break
+		}
+		r = r.Request.Response
+	}
+	return size, checksum
+}
+
+// verifyChecksum compares the SHA-256 checksum of the file at path with
+// checksum, skipped if checksum is empty. A mismatching file is removed:
+// resuming it would only append to corrupt data.
+func verifyChecksum(path, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != checksum {
+		os.Remove(path)
+		return fmt.Errorf("checksum %s does not match expected checksum %s, the download was removed", sum, checksum)
+	}
+	return nil
+}
+
+// stallCause returns ErrStalled instead of err if the download was cancelled
+// because it stalled
+func stallCause(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrStalled) {
+		return cause
+	}
+	return err
+}
+
+// progressWriter counts the bytes written, reports them and restarts the idle timer
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(downloaded, total int64)
+	idle     *time.Timer
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.idle.Reset(idleTimeout)
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil {
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
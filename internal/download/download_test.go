@@ -0,0 +1,284 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// modelContent is the file served by newModelServer
+const modelContent = "ggml model data for testing"
+
+// newModelServer serves modelContent with range support and records the Range headers it received
+func newModelServer(t *testing.T, ranges *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "ggml-tiny.bin", time.Time{}, strings.NewReader(modelContent))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newLinkedServer redirects to the model like Hugging Face, sending the size
+// and checksum of the LFS file with the redirect. The model itself is sent
+// without a Content-Length.
+func newLinkedServer(t *testing.T, size, checksum string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ggml-tiny.bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Linked-Size", size)
+		w.Header().Set("X-Linked-Etag", `"`+checksum+`"`)
+		http.Redirect(w, r, "/blob", http.StatusFound)
+	})
+	mux.HandleFunc("/blob", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(modelContent))
+		w.(http.Flusher).Flush()
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// modelChecksum is the SHA-256 checksum of modelContent
+func modelChecksum() string {
+	sum := sha256.Sum256([]byte(modelContent))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIsValidModelName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"ggml-large-v3-turbo-q5_0.bin", true},
+		{"ggml-base.en.bin", true},
+		{"ggml-base.gguf", false},
+		{"base.bin", false},
+		{"ggml-../../config.bin", false},
+		{"ggml-a/b.bin", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValidModelName(tt.name); got != tt.expected {
+			t.Errorf("IsValidModelName(%q) = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	if got := URL(DefaultBaseURL, "ggml-base.bin"); got != "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base.bin" {
+		t.Errorf("Unexpected URL %q", got)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var ranges []string
+	server := newModelServer(t, &ranges)
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	var downloaded, total int64
+	err := Fetch(context.Background(), server.Client(), server.URL, dest, func(d, t int64) {
+		downloaded, total = d, t
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil || string(data) != modelContent {
+		t.Fatalf("Expected the model to be saved, got %q (err=%v)", data, err)
+	}
+	if _, err := os.Stat(dest + PartialSuffix); !os.IsNotExist(err) {
+		t.Error("Expected the partial file to be renamed")
+	}
+	if downloaded != int64(len(modelContent)) || total != int64(len(modelContent)) {
+		t.Errorf("Expected progress %d/%d, got %d/%d", len(modelContent), len(modelContent), downloaded, total)
+	}
+	if len(ranges) != 1 || ranges[0] != "" {
+		t.Errorf("Expected a plain request, got ranges %q", ranges)
+	}
+}
+
+func TestFetch_Resume(t *testing.T) {
+	var ranges []string
+	server := newModelServer(t, &ranges)
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	// An interrupted download left the first 10 bytes behind
+	if err := os.WriteFile(dest+PartialSuffix, []byte(modelContent[:10]), 0644); err != nil {
+		t.Fatalf("Failed to write partial file: %v", err)
+	}
+
+	if err := Fetch(context.Background(), server.Client(), server.URL, dest, nil); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(ranges) != 1 || ranges[0] != "bytes=10-" {
+		t.Errorf("Expected the download to resume at byte 10, got ranges %q", ranges)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != modelContent {
+		t.Errorf("Expected the resumed model to be complete, got %q", data)
+	}
+}
+
+func TestFetch_AlreadyComplete(t *testing.T) {
+	var ranges []string
+	server := newModelServer(t, &ranges)
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	// The previous attempt wrote every byte but did not rename the file
+	if err := os.WriteFile(dest+PartialSuffix, []byte(modelContent), 0644); err != nil {
+		t.Fatalf("Failed to write partial file: %v", err)
+	}
+
+	if err := Fetch(context.Background(), server.Client(), server.URL, dest, nil); err != nil {
+		t.Fatalf("Expected a complete partial file to be accepted, got %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != modelContent {
+		t.Errorf("Expected the model to be moved into place, got %q", data)
+	}
+	if _, err := os.Stat(dest + PartialSuffix); !os.IsNotExist(err) {
+		t.Error("Expected no partial file after the download completed")
+	}
+}
+
+func TestFetch_Stalled(t *testing.T) {
+	previous := idleTimeout
+	idleTimeout = 50 * time.Millisecond
+	defer func() { idleTimeout = previous }()
+
+	// The server sends part of the model and then stops responding
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(modelContent))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	err := Fetch(context.Background(), server.Client(), server.URL, dest, nil)
+	if !errors.Is(err, ErrStalled) {
+		t.Fatalf("Expected ErrStalled, got %v", err)
+	}
+	if data, _ := os.ReadFile(dest + PartialSuffix); string(data) != modelContent {
+		t.Errorf("Expected the received data to be kept for resuming, got %q", data)
+	}
+}
+
+func TestFetch_SizeMismatch(t *testing.T) {
+	// The server announces more data than it sends
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(modelContent))
+	}))
+	defer server.Close()
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	if err := Fetch(context.Background(), server.Client(), server.URL, dest, nil); err == nil {
+		t.Fatal("Expected an error for a truncated download")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Expected no model file for a truncated download")
+	}
+	if _, err := os.Stat(dest + PartialSuffix); err != nil {
+		t.Errorf("Expected the partial file to be kept for resuming: %v", err)
+	}
+}
+
+func TestFetch_LinkedFile(t *testing.T) {
+	server := newLinkedServer(t, strconv.Itoa(len(modelContent)), modelChecksum())
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	var total int64
+	err := Fetch(context.Background(), server.Client(), server.URL+"/ggml-tiny.bin", dest, func(_, t int64) {
+		total = t
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(dest); string(data) != modelContent {
+		t.Errorf("Expected the model to be saved, got %q", data)
+	}
+	if total != int64(len(modelContent)) {
+		t.Errorf("Expected the linked size %d as total, got %d", len(modelContent), total)
+	}
+}
+
+func TestFetch_LinkedSizeMismatch(t *testing.T) {
+	// No Content-Length, but the redirect announces more data than is sent
+	server := newLinkedServer(t, "100", "")
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	if err := Fetch(context.Background(), server.Client(), server.URL+"/ggml-tiny.bin", dest, nil); err == nil {
+		t.Fatal("Expected an error for a truncated download")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Expected no model file for a truncated download")
+	}
+	if _, err := os.Stat(dest + PartialSuffix); err != nil {
+		t.Errorf("Expected the partial file to be kept for resuming: %v", err)
+	}
+}
+
+func TestFetch_ChecksumMismatch(t *testing.T) {
+	server := newLinkedServer(t, "", strings.Repeat("0", 64))
+	dest := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+
+	if err := Fetch(context.Background(), server.Client(), server.URL+"/ggml-tiny.bin", dest, nil); err == nil {
+		t.Fatal("Expected an error for a corrupt download")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Expected no model file for a corrupt download")
+	}
+	if _, err := os.Stat(dest + PartialSuffix); !os.IsNotExist(err) {
+		t.Error("Expected the corrupt partial file to be removed")
+	}
+}
+
+func TestFetch_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	dest := filepath.Join(t.TempDir(), "ggml-missing.bin")
+
+	if err := Fetch(context.Background(), server.Client(), server.URL, dest, nil); err == nil {
+		t.Error("Expected an error for a missing model")
+	}
+}
+
+func TestContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected int64
+	}{
+		{"bytes 10-26/27", 27},
+		{"bytes 10-26/*", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := contentRangeTotal(tt.header); got != tt.expected {
+			t.Errorf("contentRangeTotal(%q) = %d, expected %d", tt.header, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package frontapp
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Activate brings the application with bundleID to the front with osascript.
+// It returns once the activation was requested; the window server may need a
+// moment before the application is actually frontmost.
+func Activate(bundleID string) error {
+	if !validBundleID(bundleID) {
+		return fmt.Errorf("invalid bundle identifier: %q", bundleID)
+	}
+
+	script := fmt.Sprintf(`tell application id "%s" to activate`, bundleID)
+	if output, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to activate %s: %w (%s)", bundleID, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// validBundleID reports whether id only uses the characters allowed in a
+// bundle identifier, so it can be quoted in AppleScript as is
+func validBundleID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
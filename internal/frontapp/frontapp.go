@@ -0,0 +1,58 @@
+package frontapp
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+#include <stdlib.h>
+
+// frontmost_app_name returns a malloc'd copy of the frontmost application's
+// localized name, or NULL if there is none. The caller must free it.
+char* frontmost_app_name() {
+    @autoreleasepool {
+        NSRunningApplication *app = [[NSWorkspace sharedWorkspace] frontmostApplication];
+        if (app == nil || app.localizedName == nil) {
+            return NULL;
+        }
+        return strdup([app.localizedName UTF8String]);
+    }
+}
+
+// frontmost_app_bundle_id returns a malloc'd copy of the frontmost application's
+// bundle identifier, or NULL if there is none. The caller must free it.
+char* frontmost_app_bundle_id() {
+    @autoreleasepool {
+        NSRunningApplication *app = [[NSWorkspace sharedWorkspace] frontmostApplication];
+        if (app == nil || app.bundleIdentifier == nil) {
+            return NULL;
+        }
+        return strdup([app.bundleIdentifier UTF8String]);
+    }
+}
+*/
+import "C"
+import "unsafe"
+
+// Name returns the localized name of the frontmost application (e.g. "Code"),
+// or "" if it cannot be determined
+func Name() string {
+	cName := C.frontmost_app_name()
+	if cName == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cName))
+
+	return C.GoString(cName)
+}
+
+// BundleID returns the bundle identifier of the frontmost application
+// (e.g. "com.microsoft.VSCode"), or "" if it cannot be determined
+func BundleID() string {
+	cID := C.frontmost_app_bundle_id()
+	if cID == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cID))
+
+	return C.GoString(cID)
+}
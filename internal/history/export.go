@@ -0,0 +1,196 @@
+// Package history stores past transcriptions in a JSONL file and renders them
+// as Markdown, plain-text or JSON digests grouped by day.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Entry is a single transcription in the history
+type Entry struct {
+	ID         string    `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Text       string    `json:"text"`
+	DurationMS int64     `json:"duration_ms"` // Length of the recorded audio
+	Model      string    `json:"model"`
+	Language   string    `json:"language,omitempty"` // Language the audio was recognized as, "" if unknown
+	Mode       string    `json:"mode,omitempty"`     // ModeTranscribe or ModeTranslate, "" for entries saved before it was recorded
+	Suspect    bool      `json:"suspect,omitempty"`  // The output looked like a hallucinated repetition loop and was truncated
+}
+
+// Values of Entry.Mode
+const (
+	ModeTranscribe = "transcribe" // The text is in the spoken language
+	ModeTranslate  = "translate"  // The speech was translated to English
+)
+
+// Format is an export document format
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatText     Format = "txt"
+	FormatJSON     Format = "json"
+)
+
+// ParseFormat converts a format name to a Format. An empty name selects Markdown.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatMarkdown:
+		return FormatMarkdown, nil
+	case FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s (must be 'md', 'txt' or 'json')", name)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// Filter returns the entries with from <= Timestamp < to. A zero bound is unbounded.
+func Filter(entries []Entry, from, to time.Time) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if !from.IsZero() && e.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !e.Timestamp.Before(to) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// Export writes entries to w in the given format. Entries are expected in
+// chronological order; Markdown and text output group them by day in loc.
+func Export(w io.Writer, entries []Entry, format Format, loc *time.Location) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if entries == nil {
+			entries = []Entry{}
+		}
+		return encoder.Encode(entries)
+	case FormatText:
+		return writeText(w, entries, loc)
+	case FormatMarkdown:
+		return writeMarkdown(w, entries, loc)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// dayGroup is the entries recorded on one calendar day
+type dayGroup struct {
+	day     string
+	entries []Entry
+}
+
+// groupByDay splits chronologically ordered entries into calendar days in loc
+func groupByDay(entries []Entry, loc *time.Location) []dayGroup {
+	var groups []dayGroup
+	for _, e := range entries {
+		day := e.Timestamp.In(loc).Format("2006-01-02")
+		if len(groups) == 0 || groups[len(groups)-1].day != day {
+			groups = append(groups, dayGroup{day: day})
+		}
+		groups[len(groups)-1].entries = append(groups[len(groups)-1].entries, e)
+	}
+	return groups
+}
+
+// writeText renders a plain-text digest with one indented line per entry
+func writeText(w io.Writer, entries []Entry, loc *time.Location) error {
+	var b strings.Builder
+	for i, group := range groupByDay(entries, loc) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(group.day + "\n")
+		for _, e := range group.entries {
+			// Continuation lines are aligned with the text after the timestamp
+			text := strings.ReplaceAll(e.Text, "\n", "\n            ")
+			fmt.Fprintf(&b, "  %s  %s\n", e.Timestamp.In(loc).Format("15:04:05"), text)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdown renders a Markdown digest with a heading per day and a list item per entry
+func writeMarkdown(w io.Writer, entries []Entry, loc *time.Location) error {
+	var b strings.Builder
+	b.WriteString("# 文字起こし履歴\n")
+
+	for _, group := range groupByDay(entries, loc) {
+		fmt.Fprintf(&b, "\n## %s\n\n", group.day)
+		for _, e := range group.entries {
+			lines := strings.Split(e.Text, "\n")
+			for i, line := range lines {
+				lines[i] = EscapeMarkdown(line)
+			}
+			// Indent continuation lines so multi-line entries stay inside the list item
+			text := strings.Join(lines, "  \n  ")
+			fmt.Fprintf(&b, "- **%s** %s\n", e.Timestamp.In(loc).Format("15:04:05"), text)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownInlineSpecial are characters escaped anywhere in a line
+const markdownInlineSpecial = "\\`*_[]<>|~#"
+
+// EscapeMarkdown escapes a single line of text so it renders literally in Markdown.
+// Inline markup characters (including code fences and table pipes) are escaped
+// everywhere; characters that only start a block (list markers, ordered list
+// numbers, setext underlines) are escaped at the beginning of the line.
+func EscapeMarkdown(line string) string {
+	var b strings.Builder
+	b.Grow(len(line))
+
+	trimmed := strings.TrimLeft(line, " \t")
+	b.WriteString(line[:len(line)-len(trimmed)])
+
+	// Block-level markers at the start of the line
+	switch {
+	case strings.HasPrefix(trimmed, "-"), strings.HasPrefix(trimmed, "+"), strings.HasPrefix(trimmed, "="):
+		b.WriteByte('\\')
+	default:
+		digits := len(trimmed) - len(strings.TrimLeft(trimmed, "0123456789"))
+		if digits > 0 && digits < len(trimmed) && (trimmed[digits] == '.' || trimmed[digits] == ')') {
+			b.WriteString(trimmed[:digits])
+			b.WriteByte('\\')
+			trimmed = trimmed[digits:]
+		}
+	}
+
+	for _, r := range trimmed {
+		if strings.ContainsRune(markdownInlineSpecial, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,183 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEntries = []Entry{
+	{ID: "1", Timestamp: time.Date(2026, 10, 12, 9, 15, 2, 0, time.UTC), Text: "おはようございます。", Model: "ggml-base.bin"},
+	{ID: "2", Timestamp: time.Date(2026, 10, 12, 18, 30, 0, 0, time.UTC), Text: "会議のメモ", Model: "ggml-base.bin"},
+	{ID: "3", Timestamp: time.Date(2026, 10, 13, 8, 0, 0, 0, time.UTC), Text: "翌日の記録", Model: "ggml-base.bin"},
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{"", FormatMarkdown, false},
+		{"md", FormatMarkdown, false},
+		{"txt", FormatText, false},
+		{"json", FormatJSON, false},
+		{"pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q): unexpected error state: %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseFormat(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	from := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)
+
+	result := Filter(testEntries, from, to)
+	if len(result) != 1 || result[0].ID != "2" {
+		t.Errorf("Expected only entry 2, got %+v", result)
+	}
+
+	if all := Filter(testEntries, time.Time{}, time.Time{}); len(all) != len(testEntries) {
+		t.Errorf("Expected all entries with unbounded range, got %d", len(all))
+	}
+}
+
+func TestExportMarkdown_GroupsByDay(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, testEntries, FormatMarkdown, time.UTC); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	expected := `# 文字起こし履歴
+
+## 2026-10-12
+
+- **09:15:02** おはようございます。
+- **18:30:00** 会議のメモ
+
+## 2026-10-13
+
+- **08:00:00** 翌日の記録
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected Markdown output:\n%s", buf.String())
+	}
+}
+
+func TestExportMarkdown_DayInLocation(t *testing.T) {
+	// 18:30 UTC is already the next day in Tokyo
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	var buf bytes.Buffer
+	if err := Export(&buf, testEntries[:2], FormatMarkdown, tokyo); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "## 2026-10-13\n\n- **03:30:00** 会議のメモ") {
+		t.Errorf("Expected entry to be grouped under the local day, got:\n%s", buf.String())
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "こんにちは、世界。", "こんにちは、世界。"},
+		{"code fence", "```go", "\\`\\`\\`go"},
+		{"inline code", "run `make`", "run \\`make\\`"},
+		{"pipes", "a | b | c", "a \\| b \\| c"},
+		{"emphasis", "*bold* and _italic_", "\\*bold\\* and \\_italic\\_"},
+		{"heading", "# title", "\\# title"},
+		{"link", "[text](url)", "\\[text\\](url)"},
+		{"html", "<script>", "\\<script\\>"},
+		{"backslash", "C:\\path", "C:\\\\path"},
+		{"list marker", "- item", "\\- item"},
+		{"plus marker", "+ item", "\\+ item"},
+		{"ordered list", "1. first", "1\\. first"},
+		{"ordered list paren", "12) first", "12\\) first"},
+		{"indented list", "  - item", "  \\- item"},
+		{"mid-line dash", "well-known", "well-known"},
+		{"number without marker", "2026年", "2026年"},
+		{"setext underline", "===", "\\==="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeMarkdown(tt.input); got != tt.expected {
+				t.Errorf("EscapeMarkdown(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExportMarkdown_MultiLineEntry(t *testing.T) {
+	entries := []Entry{
+		{ID: "1", Timestamp: time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), Text: "```\ncode | here\n```"},
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, entries, FormatMarkdown, time.UTC); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	expected := "- **09:00:00** \\`\\`\\`  \n  code \\| here  \n  \\`\\`\\`\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected escaped multi-line entry, got:\n%q", buf.String())
+	}
+}
+
+func TestExportText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, testEntries, FormatText, time.UTC); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	expected := `2026-10-12
+  09:15:02  おはようございます。
+  18:30:00  会議のメモ
+
+2026-10-13
+  08:00:00  翌日の記録
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected text output:\n%s", buf.String())
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, testEntries, FormatJSON, time.UTC); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var decoded []Entry
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON export: %v", err)
+	}
+
+	if len(decoded) != len(testEntries) || decoded[2].Text != "翌日の記録" {
+		t.Errorf("Unexpected JSON export: %+v", decoded)
+	}
+
+	// An empty history is an empty array, not null
+	buf.Reset()
+	if err := Export(&buf, nil, FormatJSON, time.UTC); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected empty array, got %q", buf.String())
+	}
+}
@@ -0,0 +1,203 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is how many transcriptions are kept unless configured otherwise
+const DefaultMaxEntries = 1000
+
+// Store keeps the transcription history in a JSONL file, one Entry per line
+// from oldest to newest. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+// NewStore returns a store backed by the file at path. The file is created by
+// the first Add; a missing file is an empty history.
+func NewStore(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// DefaultPath returns the history file under Application Support
+func DefaultPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, "Library", "Application Support", "EzS2T-Whisper", "history.jsonl")
+}
+
+// Add appends entry, filling in the ID and timestamp when they are empty, and
+// returns it. When the history then holds more than maxEntries, the oldest
+// entries are dropped (0 keeps all).
+func (s *Store) Add(entry Entry, maxEntries int) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return Entry{}, err
+		}
+		entry.ID = id
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = s.now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return Entry{}, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	// Only the user can read the file; it holds everything they dictated
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to open history: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to write history: %w", err)
+	}
+
+	if maxEntries > 0 {
+		entries, err := s.load()
+		if err != nil {
+			return Entry{}, err
+		}
+		if len(entries) > maxEntries {
+			if err := s.save(entries[len(entries)-maxEntries:]); err != nil {
+				return Entry{}, err
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// List returns up to limit entries starting at offset, newest first, and the
+// total number of entries
+func (s *Store) List(offset, limit int) ([]Entry, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return nil, 0, err
+	}
+	slices.Reverse(entries)
+
+	total := len(entries)
+	start := min(max(offset, 0), total)
+	end := min(start+max(limit, 0), total)
+	return entries[start:end], total, nil
+}
+
+// All returns every entry from oldest to newest, e.g. for exporting
+func (s *Store) All() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// Delete removes the entry with id and reports whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	index := slices.IndexFunc(entries, func(e Entry) bool { return e.ID == id })
+	if index < 0 {
+		return false, nil
+	}
+
+	return true, s.save(slices.Delete(entries, index, index+1))
+}
+
+// Clear removes the whole history
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+	return nil
+}
+
+// load reads all entries. Lines that cannot be parsed (e.g. cut off by a
+// crash while writing) are skipped. The caller must hold s.mu.
+func (s *Store) load() ([]Entry, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// save replaces the file with entries. It writes a temporary file and renames
+// it so a crash cannot leave a half-written history. The caller must hold s.mu.
+func (s *Store) save(entries []Entry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// newID returns a random identifier for an entry
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate history id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
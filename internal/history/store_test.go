@@ -0,0 +1,125 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStore returns a store in a temporary directory with a fixed clock
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store := NewStore(filepath.Join(t.TempDir(), "EzS2T-Whisper", "history.jsonl"))
+	store.now = func() time.Time { return time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC) }
+	return store
+}
+
+func TestStore_AddAndList(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, text := range []string{"一", "二", "三"} {
+		if _, err := store.Add(Entry{Text: text, DurationMS: 1500, Model: "ggml-base.bin"}, 0); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	entries, total, err := store.List(0, 2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 entries in total, got %d", total)
+	}
+	if len(entries) != 2 || entries[0].Text != "三" || entries[1].Text != "二" {
+		t.Errorf("Expected the newest entries first, got %+v", entries)
+	}
+	if entries[0].ID == "" || entries[0].ID == entries[1].ID || !entries[0].Timestamp.Equal(store.now()) {
+		t.Errorf("Expected a unique ID and the current time, got %+v", entries)
+	}
+
+	// The next page holds the rest; past the end is empty
+	if entries, _, _ := store.List(2, 2); len(entries) != 1 || entries[0].Text != "一" {
+		t.Errorf("Expected the oldest entry on the second page, got %+v", entries)
+	}
+	if entries, _, _ := store.List(10, 2); len(entries) != 0 {
+		t.Errorf("Expected an empty page past the end, got %+v", entries)
+	}
+
+	// All returns every entry oldest first
+	if all, err := store.All(); err != nil || len(all) != 3 || all[0].Text != "一" || all[2].Text != "三" {
+		t.Errorf("Expected all entries oldest first, got %+v (err=%v)", all, err)
+	}
+
+	// Fields round-trip through the file
+	if _, err := store.Add(Entry{Text: "ループ", Mode: ModeTranslate, Suspect: true}, 0); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if entries, _, _ := store.List(0, 1); len(entries) != 1 || !entries[0].Suspect || entries[0].Mode != ModeTranslate {
+		t.Errorf("Expected the suspect flag and mode to be kept, got %+v", entries)
+	}
+
+	// Only the user can read the file
+	if info, err := os.Stat(store.path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v (err=%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestStore_MaxEntries(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, text := range []string{"一", "二", "三"} {
+		if _, err := store.Add(Entry{Text: text}, 2); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	entries, total, _ := store.List(0, 10)
+	if total != 2 || entries[0].Text != "三" || entries[1].Text != "二" {
+		t.Errorf("Expected the oldest entry to be dropped, got %+v", entries)
+	}
+}
+
+func TestStore_DeleteAndClear(t *testing.T) {
+	store := newTestStore(t)
+
+	first, _ := store.Add(Entry{Text: "一"}, 0)
+	store.Add(Entry{Text: "二"}, 0)
+
+	if deleted, err := store.Delete(first.ID); err != nil || !deleted {
+		t.Fatalf("Expected the entry to be deleted (err=%v)", err)
+	}
+	if deleted, _ := store.Delete(first.ID); deleted {
+		t.Error("Expected a second delete to find nothing")
+	}
+	if entries, total, _ := store.List(0, 10); total != 1 || entries[0].Text != "二" {
+		t.Errorf("Expected only the other entry to remain, got %+v", entries)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, total, _ := store.List(0, 10); total != 0 {
+		t.Errorf("Expected an empty history after Clear, got %d entries", total)
+	}
+
+	// Clearing an empty history is not an error
+	if err := store.Clear(); err != nil {
+		t.Errorf("Expected no error clearing an empty history, got %v", err)
+	}
+}
+
+func TestStore_SkipsBrokenLines(t *testing.T) {
+	store := newTestStore(t)
+	store.Add(Entry{Text: "一"}, 0)
+
+	// A crash while writing left half a line behind
+	f, _ := os.OpenFile(store.path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"id":"broken","te`)
+	f.Close()
+
+	if entries, total, err := store.List(0, 10); err != nil || total != 1 || entries[0].Text != "一" {
+		t.Errorf("Expected the broken line to be skipped, got %+v (err=%v)", entries, err)
+	}
+}
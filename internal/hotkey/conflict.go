@@ -1,6 +1,42 @@
 package hotkey
 
-import "golang.design/x/hotkey"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.design/x/hotkey"
+)
+
+// ErrConflict means the hotkey is already used by the system or another application
+var ErrConflict = errors.New("hotkey conflicts with an existing shortcut")
+
+// ConflictError is returned by Register when the hotkey cannot be used.
+// It matches ErrConflict with errors.Is.
+type ConflictError struct {
+	Conflicts []string // Names of known conflicting shortcuts, empty if the OS refused without saying which
+	Err       error    // Underlying registration error, nil for a known conflict
+}
+
+// Error lists the conflicting shortcuts or the underlying error
+func (e *ConflictError) Error() string {
+	if len(e.Conflicts) > 0 {
+		return fmt.Sprintf("%v: %s", ErrConflict, strings.Join(e.Conflicts, ", "))
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%v: %v", ErrConflict, e.Err)
+	}
+	return ErrConflict.Error()
+}
+
+// Unwrap allows errors.Is to match both ErrConflict and the underlying error
+func (e *ConflictError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrConflict}
+	}
+	return []error{ErrConflict, e.Err}
+}
 
 // ConflictInfo represents information about a known shortcut conflict
 type ConflictInfo struct {
@@ -57,17 +93,68 @@ func CheckConflicts(modifiers []hotkey.Modifier, key hotkey.Key) []ConflictInfo
 	return conflicts
 }
 
+// checkConflictError returns a *ConflictError if the hotkey matches a known shortcut
+func checkConflictError(modifiers []hotkey.Modifier, key hotkey.Key) error {
+	conflicts := CheckConflicts(modifiers, key)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		names[i] = c.Name
+	}
+	return &ConflictError{Conflicts: names}
+}
+
+// suggestionModifiers is the order in which alternative modifier combinations are
+// proposed: two modifiers first (easy to press, rarely taken), then three, then one
+var suggestionModifiers = [][]hotkey.Modifier{
+	{hotkey.ModCtrl, hotkey.ModOption},
+	{hotkey.ModCtrl, hotkey.ModShift},
+	{hotkey.ModOption, hotkey.ModShift},
+	{hotkey.ModCtrl, hotkey.ModCmd},
+	{hotkey.ModOption, hotkey.ModCmd},
+	{hotkey.ModShift, hotkey.ModCmd},
+	{hotkey.ModCtrl, hotkey.ModOption, hotkey.ModShift},
+	{hotkey.ModCtrl, hotkey.ModOption, hotkey.ModCmd},
+	{hotkey.ModCtrl, hotkey.ModShift, hotkey.ModCmd},
+	{hotkey.ModOption, hotkey.ModShift, hotkey.ModCmd},
+	{hotkey.ModCtrl},
+	{hotkey.ModOption},
+}
+
+// SuggestAlternatives returns up to limit modifier combinations for the same key that
+// differ from modifiers and pass the conflict check
+func SuggestAlternatives(modifiers []hotkey.Modifier, key hotkey.Key, limit int) [][]hotkey.Modifier {
+	var suggestions [][]hotkey.Modifier
+
+	for _, candidate := range suggestionModifiers {
+		if len(suggestions) >= limit {
+			break
+		}
+
+		if hotkeyMatches(candidate, key, modifiers, key) {
+			continue
+		}
+
+		if len(CheckConflicts(candidate, key)) > 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, slices.Clone(candidate))
+	}
+
+	return suggestions
+}
+
 // hotkeyMatches checks if two hotkey combinations are identical
 func hotkeyMatches(mods1 []hotkey.Modifier, key1 hotkey.Key, mods2 []hotkey.Modifier, key2 hotkey.Key) bool {
 	if key1 != key2 {
 		return false
 	}
 
-	if len(mods1) != len(mods2) {
-		return false
-	}
-
-	// Create maps for comparison
+	// Compare as sets so that order and duplicates don't matter
 	modMap1 := make(map[hotkey.Modifier]bool)
 	modMap2 := make(map[hotkey.Modifier]bool)
 
@@ -79,6 +166,10 @@ func hotkeyMatches(mods1 []hotkey.Modifier, key1 hotkey.Key, mods2 []hotkey.Modi
 		modMap2[mod] = true
 	}
 
+	if len(modMap1) != len(modMap2) {
+		return false
+	}
+
 	// Check if all modifiers match
 	for mod := range modMap1 {
 		if !modMap2[mod] {
@@ -89,20 +180,25 @@ func hotkeyMatches(mods1 []hotkey.Modifier, key1 hotkey.Key, mods2 []hotkey.Modi
 	return true
 }
 
-// FormatHotkey returns a human-readable string representation of the hotkey
+// modifierSymbols lists modifier symbols in the macOS display order (⌃⌥⇧⌘)
+var modifierSymbols = []struct {
+	mod    hotkey.Modifier
+	symbol string
+}{
+	{hotkey.ModCtrl, "⌃"},
+	{hotkey.ModOption, "⌥"},
+	{hotkey.ModShift, "⇧"},
+	{hotkey.ModCmd, "⌘"},
+}
+
+// FormatHotkey returns a human-readable string representation of the hotkey.
+// Modifiers are always shown in the macOS order (⌃⌥⇧⌘) regardless of the slice order.
 func FormatHotkey(modifiers []hotkey.Modifier, key hotkey.Key) string {
 	result := ""
 
-	for _, mod := range modifiers {
-		switch mod {
-		case hotkey.ModCtrl:
-			result += "⌃"
-		case hotkey.ModShift:
-			result += "⇧"
-		case hotkey.ModOption:
-			result += "⌥"
-		case hotkey.ModCmd:
-			result += "⌘"
+	for _, m := range modifierSymbols {
+		if slices.Contains(modifiers, m.mod) {
+			result += m.symbol
 		}
 	}
 
@@ -112,27 +208,12 @@ func FormatHotkey(modifiers []hotkey.Modifier, key hotkey.Key) string {
 
 // keyToString converts a hotkey.Key to a display string
 func keyToString(key hotkey.Key) string {
-	// Map common keys to their display names
-	keyMap := map[hotkey.Key]string{
-		hotkey.KeySpace:  "Space",
-		hotkey.KeyEscape: "Esc",
-		hotkey.KeyReturn: "Return",
-		hotkey.KeyTab:    "Tab",
-		hotkey.KeyDelete: "Delete",
-	}
-
-	if name, ok := keyMap[key]; ok {
+	if name, ok := keyDisplayNames[key]; ok {
 		return name
 	}
 
-	// For letter keys (A-Z)
-	if key >= hotkey.KeyA && key <= hotkey.KeyZ {
-		return string(rune('A' + int(key-hotkey.KeyA)))
-	}
-
-	// For number keys (0-9)
-	if key >= hotkey.Key0 && key <= hotkey.Key9 {
-		return string(rune('0' + int(key-hotkey.Key0)))
+	if name := KeyName(key); name != "" {
+		return name
 	}
 
 	return "Unknown"
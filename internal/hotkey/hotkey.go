@@ -3,6 +3,7 @@ package hotkey
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"golang.design/x/hotkey"
 )
@@ -17,6 +18,30 @@ const (
 	Toggle
 )
 
+// ParseRecordingMode converts a config value ("press-to-hold" or "toggle") to a RecordingMode
+func ParseRecordingMode(mode string) (RecordingMode, error) {
+	switch mode {
+	case "press-to-hold":
+		return PressToHold, nil
+	case "toggle":
+		return Toggle, nil
+	default:
+		return PressToHold, fmt.Errorf("invalid recording mode: %s", mode)
+	}
+}
+
+// String returns the config representation of the recording mode
+func (m RecordingMode) String() string {
+	switch m {
+	case PressToHold:
+		return "press-to-hold"
+	case Toggle:
+		return "toggle"
+	default:
+		return "unknown"
+	}
+}
+
 // EventType represents the type of hotkey event
 type EventType int
 
@@ -25,6 +50,9 @@ const (
 	Pressed EventType = iota
 	// Released indicates the hotkey was released
 	Released
+	// Cancelled indicates a toggle session was stopped within the grace window
+	// (accidental double-tap) and should be discarded without transcription
+	Cancelled
 )
 
 // Event represents a hotkey event
@@ -37,6 +65,31 @@ type Config struct {
 	Modifiers []hotkey.Modifier
 	Key       hotkey.Key
 	Mode      RecordingMode
+
+	// ToggleGraceWindow treats a stop within this duration of the start as an
+	// accidental double-tap in Toggle mode. 0 disables the check.
+	ToggleGraceWindow time.Duration
+}
+
+// toggleState tracks the recording session in Toggle mode
+type toggleState struct {
+	active    bool
+	startedAt time.Time
+}
+
+// press advances the toggle state for a keydown at now and returns the event to emit
+func (s *toggleState) press(now time.Time, graceWindow time.Duration) EventType {
+	if !s.active {
+		s.active = true
+		s.startedAt = now
+		return Pressed
+	}
+
+	s.active = false
+	if graceWindow > 0 && now.Sub(s.startedAt) < graceWindow {
+		return Cancelled
+	}
+	return Released
 }
 
 // Manager manages global hotkey registration and events
@@ -48,6 +101,9 @@ type Manager struct {
 	wg        sync.WaitGroup
 	mu        sync.Mutex
 	running   bool
+
+	toggleMu sync.Mutex  // Guards toggle; separate from mu, which Close holds while waiting for listen
+	toggle   toggleState // The Toggle mode session, reset by ResetToggle
 }
 
 // New creates a new hotkey manager with default configuration
@@ -69,26 +125,44 @@ func (m *Manager) Register(config Config) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	return m.register(config)
+}
+
+// register implements Register (m.mu held)
+func (m *Manager) register(config Config) error {
 	if m.running {
 		return fmt.Errorf("hotkey is already running, call Close() first")
 	}
 
+	// Refuse shortcuts that are known to be taken by the system or common apps
+	if err := checkConflictError(config.Modifiers, config.Key); err != nil {
+		return err
+	}
+
 	m.config = config
 
-	// Recreate channels (they may have been closed by a previous Close())
-	m.stopChan = make(chan struct{})
+	// Recreate the event channel (it may have been closed by a previous Close())
 	m.eventChan = make(chan Event, 10)
 
+	return m.start(config)
+}
+
+// start registers config with the system and starts the listener (m.mu held)
+func (m *Manager) start(config Config) error {
 	// Create hotkey instance
-	hk := hotkey.New(m.config.Modifiers, m.config.Key)
+	hk := hotkey.New(config.Modifiers, config.Key)
 
 	// Register the hotkey
+	// Registration fails when another application already owns the combination
 	if err := hk.Register(); err != nil {
-		return fmt.Errorf("failed to register hotkey: %w", err)
+		return fmt.Errorf("failed to register hotkey: %w", &ConflictError{Err: err})
 	}
 
 	m.hk = hk
+	m.config = config
+	m.stopChan = make(chan struct{})
 	m.running = true
+	m.ResetToggle()
 
 	// Start listening in a goroutine
 	m.wg.Add(1)
@@ -97,6 +171,63 @@ func (m *Manager) Register(config Config) error {
 	return nil
 }
 
+// stop stops the listener, waits for it to return and unregisters the hotkey.
+// The event channel is left open. (m.mu held)
+func (m *Manager) stop() error {
+	// Signal the listener to stop
+	close(m.stopChan)
+
+	// Wait for the listener goroutine to finish
+	m.wg.Wait()
+
+	m.running = false
+
+	// Unregister the hotkey
+	if m.hk != nil {
+		if err := m.hk.Unregister(); err != nil {
+			return fmt.Errorf("failed to unregister hotkey: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reconfigure replaces the registered hotkey with config under one lock: the
+// listener is stopped and waited for, the old hotkey is unregistered and the
+// new one registered. The event channel stays open, so consumers keep
+// receiving events without subscribing again and no event is delivered twice.
+// If config cannot be registered, the previous hotkey is registered again and
+// the error is returned; if that fails too, the manager is closed.
+// When the manager is not running, Reconfigure is the same as Register.
+func (m *Manager) Reconfigure(config Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return m.register(config)
+	}
+
+	if err := checkConflictError(config.Modifiers, config.Key); err != nil {
+		return err
+	}
+
+	previous := m.config
+	// A failed unregister is not fatal here, as in Close: registering the new
+	// combination is what decides whether the hotkey works
+	m.stop()
+
+	err := m.start(config)
+	if err == nil {
+		return nil
+	}
+
+	if rollbackErr := m.start(previous); rollbackErr != nil {
+		close(m.eventChan)
+		m.eventChan = nil
+		return fmt.Errorf("%w (failed to restore the previous hotkey: %v)", err, rollbackErr)
+	}
+	return err
+}
+
 // RegisterDefault registers the default hotkey (Ctrl+Option+Space)
 func (m *Manager) RegisterDefault() error {
 	return m.Register(m.config)
@@ -106,8 +237,6 @@ func (m *Manager) RegisterDefault() error {
 func (m *Manager) listen() {
 	defer m.wg.Done()
 
-	toggleState := false
-
 	for {
 		select {
 		case <-m.hk.Keydown():
@@ -115,13 +244,7 @@ func (m *Manager) listen() {
 			case PressToHold:
 				m.eventChan <- Event{Type: Pressed}
 			case Toggle:
-				if !toggleState {
-					m.eventChan <- Event{Type: Pressed}
-					toggleState = true
-				} else {
-					m.eventChan <- Event{Type: Released}
-					toggleState = false
-				}
+				m.eventChan <- Event{Type: m.pressToggle(time.Now())}
 			}
 
 		case <-m.hk.Keyup():
@@ -135,6 +258,24 @@ func (m *Manager) listen() {
 	}
 }
 
+// pressToggle advances the Toggle mode session for a keydown at now
+func (m *Manager) pressToggle(now time.Time) EventType {
+	m.toggleMu.Lock()
+	defer m.toggleMu.Unlock()
+
+	return m.toggle.press(now, m.config.ToggleGraceWindow)
+}
+
+// ResetToggle ends the current Toggle mode session so the next keydown starts
+// a new one. Call it when the recording was stopped without the hotkey, e.g.
+// when it reached the maximum recording time.
+func (m *Manager) ResetToggle() {
+	m.toggleMu.Lock()
+	defer m.toggleMu.Unlock()
+
+	m.toggle = toggleState{}
+}
+
 // Events returns the event channel for receiving hotkey events
 func (m *Manager) Events() <-chan Event {
 	return m.eventChan
@@ -149,21 +290,9 @@ func (m *Manager) Close() error {
 		return nil
 	}
 
-	var unregisterErr error
-
-	// Signal the listener to stop
-	close(m.stopChan)
-
-	// Wait for the listener goroutine to finish
-	m.wg.Wait()
-
-	// Unregister the hotkey
+	// Stop listening and unregister the hotkey
 	// 注意: エラーが発生しても続行し、必ずクリーンアップを実行する
-	if m.hk != nil {
-		if err := m.hk.Unregister(); err != nil {
-			unregisterErr = fmt.Errorf("failed to unregister hotkey: %w", err)
-		}
-	}
+	unregisterErr := m.stop()
 
 	// Close event channel to notify consumers of shutdown
 	if m.eventChan != nil {
@@ -1,6 +1,7 @@
 package hotkey
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -27,6 +28,169 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestParseRecordingMode(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  RecordingMode
+		expectErr bool
+	}{
+		{"press-to-hold", PressToHold, false},
+		{"toggle", Toggle, false},
+		{"", PressToHold, true},
+		{"hold", PressToHold, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseRecordingMode(tt.input)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Expected error=%v, got %v", tt.expectErr, err)
+			}
+			if mode != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, mode)
+			}
+			if !tt.expectErr && mode.String() != tt.input {
+				t.Errorf("Expected String() %q, got %q", tt.input, mode.String())
+			}
+		})
+	}
+}
+
+func TestToggleStateGraceWindow(t *testing.T) {
+	start := time.Now()
+	grace := 300 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		grace    time.Duration
+		expected EventType
+	}{
+		{"double-tap within window", 100 * time.Millisecond, grace, Cancelled},
+		{"stop after window", 500 * time.Millisecond, grace, Released},
+		{"stop exactly at window", grace, grace, Released},
+		{"window disabled", 10 * time.Millisecond, 0, Released},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s toggleState
+
+			if got := s.press(start, tt.grace); got != Pressed {
+				t.Fatalf("Expected first press to start recording, got %v", got)
+			}
+
+			if got := s.press(start.Add(tt.elapsed), tt.grace); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+
+			// The session must be closed either way so the next press starts a new one
+			if got := s.press(start.Add(time.Second), tt.grace); got != Pressed {
+				t.Errorf("Expected next press to start a new session, got %v", got)
+			}
+		})
+	}
+}
+
+func TestManagerResetToggle(t *testing.T) {
+	m := New()
+	m.config.Mode = Toggle
+	start := time.Now()
+
+	if got := m.pressToggle(start); got != Pressed {
+		t.Fatalf("Expected first press to start recording, got %v", got)
+	}
+
+	// Recording was stopped without the hotkey, e.g. at the maximum recording time
+	m.ResetToggle()
+
+	if got := m.pressToggle(start.Add(time.Second)); got != Pressed {
+		t.Errorf("Expected the press after a reset to start a new session, got %v", got)
+	}
+	if got := m.pressToggle(start.Add(2 * time.Second)); got != Released {
+		t.Errorf("Expected the next press to stop the session, got %v", got)
+	}
+}
+
+func TestKeyFromString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected hotkey.Key
+	}{
+		{"Space", hotkey.KeySpace},
+		{"\u00a0", hotkey.KeySpace},
+		{"\u202f", hotkey.KeySpace},
+		{"A", hotkey.KeyA},
+		{"9", hotkey.Key9},
+		{"Return", hotkey.KeyReturn},
+		{"F13", hotkey.KeyF13},
+		{"ArrowLeft", hotkey.KeyLeft},
+		{";", keySemicolon},
+		{"b", hotkey.KeyB},
+		{"Unknown", hotkey.KeySpace},
+	}
+
+	for _, tt := range tests {
+		if got := KeyFromString(tt.input); got != tt.expected {
+			t.Errorf("KeyFromString(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLookupKey(t *testing.T) {
+	if key, ok := LookupKey("\u00a0"); !ok || key != hotkey.KeySpace {
+		t.Errorf("Expected NBSP to map to Space, got %v (ok=%v)", key, ok)
+	}
+	if _, ok := LookupKey("F21"); ok {
+		t.Error("Expected an unmapped key to be reported instead of falling back to Space")
+	}
+	if got := StringFromKey(hotkey.KeyEscape); got != "Esc" {
+		t.Errorf("Expected Esc, got %q", got)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if key, err := ParseKey("F19"); err != nil || key != hotkey.KeyF19 {
+		t.Errorf("Expected F19, got %v (err=%v)", key, err)
+	}
+	if _, err := ParseKey("Hyper"); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	// Aliases and lower case letters are saved under one name
+	for input, expected := range map[string]string{"esc": "Escape", "Esc": "Escape", "ArrowUp": "Up", "a": "A", "¥": "¥", "\u00a0": "Space"} {
+		key, err := ParseKey(input)
+		if err != nil {
+			t.Errorf("ParseKey(%q) failed: %v", input, err)
+			continue
+		}
+		if got := KeyName(key); got != expected {
+			t.Errorf("KeyName(ParseKey(%q)) = %q, expected %q", input, got, expected)
+		}
+	}
+	if got := KeyName(0x7F); got != "" {
+		t.Errorf("Expected no name for an unmapped key code, got %q", got)
+	}
+}
+
+func TestStringFromKey(t *testing.T) {
+	// Every configurable key has a name, and it parses back to the same key
+	for name, key := range keyNames {
+		display := StringFromKey(key)
+		if display == "Unknown" {
+			t.Errorf("Expected a display name for %q", name)
+		}
+		if _, ok := keyDisplayNames[key]; !ok && display != name {
+			t.Errorf("StringFromKey(%q) = %q, expected %q", name, display, name)
+		}
+	}
+	if got := StringFromKey(hotkey.KeyLeft); got != "←" {
+		t.Errorf("Expected ←, got %q", got)
+	}
+}
+
 func TestCheckConflicts(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -67,6 +231,91 @@ func TestCheckConflicts(t *testing.T) {
 	}
 }
 
+func TestRegister_KnownConflict(t *testing.T) {
+	m := New()
+
+	err := m.Register(Config{
+		Modifiers: []hotkey.Modifier{hotkey.ModCmd},
+		Key:       hotkey.KeySpace,
+		Mode:      PressToHold,
+	})
+
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ErrConflict, got %v", err)
+	}
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) == 0 || conflictErr.Conflicts[0] != "Spotlight" {
+		t.Errorf("Expected conflicting shortcut names, got %v", err)
+	}
+
+	if m.IsRunning() {
+		t.Error("Manager should not be running after a conflict")
+	}
+}
+
+func TestReconfigure_KnownConflict(t *testing.T) {
+	m := New()
+	before := m.GetConfig()
+
+	// Not running: Reconfigure registers like Register and refuses the same conflicts
+	err := m.Reconfigure(Config{
+		Modifiers: []hotkey.Modifier{hotkey.ModCmd},
+		Key:       hotkey.KeySpace,
+		Mode:      PressToHold,
+	})
+
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ErrConflict, got %v", err)
+	}
+	if m.IsRunning() {
+		t.Error("Manager should not be running after a conflict")
+	}
+	if after := m.GetConfig(); after.Key != before.Key || len(after.Modifiers) != len(before.Modifiers) {
+		t.Errorf("Expected the configuration to be unchanged, got %+v", after)
+	}
+}
+
+func TestConflictError_UnwrapsUnderlying(t *testing.T) {
+	underlying := errors.New("already registered")
+	err := &ConflictError{Err: underlying}
+
+	if !errors.Is(err, ErrConflict) || !errors.Is(err, underlying) {
+		t.Errorf("Expected error to match both ErrConflict and the underlying error")
+	}
+}
+
+func TestSuggestAlternatives(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCmd}
+	suggestions := SuggestAlternatives(mods, hotkey.KeySpace, 3)
+
+	if len(suggestions) != 3 {
+		t.Fatalf("Expected 3 suggestions, got %d", len(suggestions))
+	}
+
+	for _, s := range suggestions {
+		if hotkeyMatches(s, hotkey.KeySpace, mods, hotkey.KeySpace) {
+			t.Errorf("Suggestion %v repeats the requested hotkey", s)
+		}
+		if len(CheckConflicts(s, hotkey.KeySpace)) > 0 {
+			t.Errorf("Suggestion %v conflicts with a known shortcut", s)
+		}
+	}
+
+	// The requested combination itself is skipped
+	suggestions = SuggestAlternatives([]hotkey.Modifier{hotkey.ModOption, hotkey.ModCtrl}, hotkey.KeySpace, 1)
+	if len(suggestions) != 1 || FormatHotkey(suggestions[0], hotkey.KeySpace) == "⌃⌥Space" {
+		t.Errorf("Expected a different combination than ⌃⌥Space, got %v", suggestions)
+	}
+
+	// Known conflicts are never suggested
+	for _, s := range SuggestAlternatives(nil, hotkey.KeyEscape, len(suggestionModifiers)) {
+		if FormatHotkey(s, hotkey.KeyEscape) == "⌥⌘Esc" {
+			t.Error("Expected Force Quit shortcut not to be suggested")
+		}
+	}
+}
+
 func TestFormatHotkey(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -90,7 +339,25 @@ func TestFormatHotkey(t *testing.T) {
 			name:      "Cmd+Shift+A",
 			modifiers: []hotkey.Modifier{hotkey.ModCmd, hotkey.ModShift},
 			key:       hotkey.KeyA,
-			expected:  "⌘⇧A",
+			expected:  "⇧⌘A",
+		},
+		{
+			name:      "Option+Ctrl+Space uses canonical order",
+			modifiers: []hotkey.Modifier{hotkey.ModOption, hotkey.ModCtrl},
+			key:       hotkey.KeySpace,
+			expected:  "⌃⌥Space",
+		},
+		{
+			name:      "All modifiers",
+			modifiers: []hotkey.Modifier{hotkey.ModCmd, hotkey.ModShift, hotkey.ModOption, hotkey.ModCtrl},
+			key:       hotkey.KeyA,
+			expected:  "⌃⌥⇧⌘A",
+		},
+		{
+			name:      "Duplicate modifiers",
+			modifiers: []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModCtrl, hotkey.ModOption},
+			key:       hotkey.KeySpace,
+			expected:  "⌃⌥Space",
 		},
 	}
 
@@ -104,6 +371,22 @@ func TestFormatHotkey(t *testing.T) {
 	}
 }
 
+func TestFormatHotkey_PermutationsMatch(t *testing.T) {
+	permutations := [][]hotkey.Modifier{
+		{hotkey.ModCtrl, hotkey.ModOption, hotkey.ModCmd},
+		{hotkey.ModCmd, hotkey.ModOption, hotkey.ModCtrl},
+		{hotkey.ModOption, hotkey.ModCmd, hotkey.ModCtrl},
+		{hotkey.ModOption, hotkey.ModCtrl, hotkey.ModCmd},
+	}
+
+	expected := FormatHotkey(permutations[0], hotkey.KeySpace)
+	for _, mods := range permutations[1:] {
+		if result := FormatHotkey(mods, hotkey.KeySpace); result != expected {
+			t.Errorf("Expected %q for %v, got %q", expected, mods, result)
+		}
+	}
+}
+
 func TestHotkeyMatches(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -145,6 +428,30 @@ func TestHotkeyMatches(t *testing.T) {
 			key2:     hotkey.KeySpace,
 			expected: true,
 		},
+		{
+			name:     "Duplicate modifier in first",
+			mods1:    []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModCtrl},
+			key1:     hotkey.KeySpace,
+			mods2:    []hotkey.Modifier{hotkey.ModCtrl},
+			key2:     hotkey.KeySpace,
+			expected: true,
+		},
+		{
+			name:     "Duplicate modifier in second",
+			mods1:    []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModOption},
+			key1:     hotkey.KeySpace,
+			mods2:    []hotkey.Modifier{hotkey.ModOption, hotkey.ModCtrl, hotkey.ModOption},
+			key2:     hotkey.KeySpace,
+			expected: true,
+		},
+		{
+			name:     "Duplicates do not hide a missing modifier",
+			mods1:    []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModCtrl},
+			key1:     hotkey.KeySpace,
+			mods2:    []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModOption},
+			key2:     hotkey.KeySpace,
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,195 @@
+package hotkey
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.design/x/hotkey"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/config"
+)
+
+// Virtual key codes of the punctuation keys, which golang.design/x/hotkey does
+// not name. The codes identify physical key positions on the ANSI layout; a
+// JIS or ISO keyboard may print a different symbol on the key at that position.
+// The two keys that only exist on JIS keyboards are listed separately.
+const (
+	keyMinus        hotkey.Key = 0x1B
+	keyEqual        hotkey.Key = 0x18
+	keyLeftBracket  hotkey.Key = 0x21
+	keyRightBracket hotkey.Key = 0x1E
+	keySemicolon    hotkey.Key = 0x29
+	keyQuote        hotkey.Key = 0x27
+	keyBackslash    hotkey.Key = 0x2A
+	keyComma        hotkey.Key = 0x2B
+	keyPeriod       hotkey.Key = 0x2F
+	keySlash        hotkey.Key = 0x2C
+	keyGrave        hotkey.Key = 0x32
+
+	keyJISYen        hotkey.Key = 0x5D // kVK_JIS_Yen, left of Delete
+	keyJISUnderscore hotkey.Key = 0x5E // kVK_JIS_Underscore, left of the right Shift
+)
+
+// keyNames maps configuration key names to key codes
+var keyNames = map[string]hotkey.Key{
+	"Space":  hotkey.KeySpace,
+	"A":      hotkey.KeyA,
+	"B":      hotkey.KeyB,
+	"C":      hotkey.KeyC,
+	"D":      hotkey.KeyD,
+	"E":      hotkey.KeyE,
+	"F":      hotkey.KeyF,
+	"G":      hotkey.KeyG,
+	"H":      hotkey.KeyH,
+	"I":      hotkey.KeyI,
+	"J":      hotkey.KeyJ,
+	"K":      hotkey.KeyK,
+	"L":      hotkey.KeyL,
+	"M":      hotkey.KeyM,
+	"N":      hotkey.KeyN,
+	"O":      hotkey.KeyO,
+	"P":      hotkey.KeyP,
+	"Q":      hotkey.KeyQ,
+	"R":      hotkey.KeyR,
+	"S":      hotkey.KeyS,
+	"T":      hotkey.KeyT,
+	"U":      hotkey.KeyU,
+	"V":      hotkey.KeyV,
+	"W":      hotkey.KeyW,
+	"X":      hotkey.KeyX,
+	"Y":      hotkey.KeyY,
+	"Z":      hotkey.KeyZ,
+	"0":      hotkey.Key0,
+	"1":      hotkey.Key1,
+	"2":      hotkey.Key2,
+	"3":      hotkey.Key3,
+	"4":      hotkey.Key4,
+	"5":      hotkey.Key5,
+	"6":      hotkey.Key6,
+	"7":      hotkey.Key7,
+	"8":      hotkey.Key8,
+	"9":      hotkey.Key9,
+	"Escape": hotkey.KeyEscape,
+	"Return": hotkey.KeyReturn,
+	"Tab":    hotkey.KeyTab,
+	"Delete": hotkey.KeyDelete,
+	"Left":   hotkey.KeyLeft,
+	"Right":  hotkey.KeyRight,
+	"Up":     hotkey.KeyUp,
+	"Down":   hotkey.KeyDown,
+	"F1":     hotkey.KeyF1,
+	"F2":     hotkey.KeyF2,
+	"F3":     hotkey.KeyF3,
+	"F4":     hotkey.KeyF4,
+	"F5":     hotkey.KeyF5,
+	"F6":     hotkey.KeyF6,
+	"F7":     hotkey.KeyF7,
+	"F8":     hotkey.KeyF8,
+	"F9":     hotkey.KeyF9,
+	"F10":    hotkey.KeyF10,
+	"F11":    hotkey.KeyF11,
+	"F12":    hotkey.KeyF12,
+	"F13":    hotkey.KeyF13,
+	"F14":    hotkey.KeyF14,
+	"F15":    hotkey.KeyF15,
+	"F16":    hotkey.KeyF16,
+	"F17":    hotkey.KeyF17,
+	"F18":    hotkey.KeyF18,
+	"F19":    hotkey.KeyF19,
+	"F20":    hotkey.KeyF20,
+	"-":      keyMinus,
+	"=":      keyEqual,
+	"[":      keyLeftBracket,
+	"]":      keyRightBracket,
+	";":      keySemicolon,
+	"'":      keyQuote,
+	"\\":     keyBackslash,
+	",":      keyComma,
+	".":      keyPeriod,
+	"/":      keySlash,
+	"`":      keyGrave,
+	"¥":      keyJISYen,
+	"_":      keyJISUnderscore,
+}
+
+// keyAliases maps other common names of a key (e.g. the browser's
+// KeyboardEvent.key values) to the configuration key name
+var keyAliases = map[string]string{
+	"Esc":        "Escape",
+	"Enter":      "Return",
+	"ArrowLeft":  "Left",
+	"ArrowRight": "Right",
+	"ArrowUp":    "Up",
+	"ArrowDown":  "Down",
+}
+
+// keyDisplayNames overrides the display name of keys whose configuration name is too long
+var keyDisplayNames = map[hotkey.Key]string{
+	hotkey.KeyEscape: "Esc",
+	hotkey.KeyLeft:   "←",
+	hotkey.KeyRight:  "→",
+	hotkey.KeyUp:     "↑",
+	hotkey.KeyDown:   "↓",
+}
+
+// KeyFromString converts a configuration key name to a key code.
+// Whitespace variants of the space key (e.g. NBSP from the macOS IME) are
+// normalized to Space, and unknown names fall back to Space.
+func KeyFromString(keyStr string) hotkey.Key {
+	if key, ok := LookupKey(keyStr); ok {
+		return key
+	}
+
+	// デフォルトはSpace
+	return hotkey.KeySpace
+}
+
+// LookupKey converts a configuration key name to a key code like KeyFromString,
+// but reports unknown names instead of falling back to Space. Names and
+// aliases are matched case-insensitively.
+func LookupKey(keyStr string) (hotkey.Key, bool) {
+	name := config.NormalizeKeyName(keyStr)
+	if key, ok := keyNames[name]; ok {
+		return key, true
+	}
+
+	for alias, target := range keyAliases {
+		if strings.EqualFold(alias, name) {
+			name = target
+			break
+		}
+	}
+	for n, key := range keyNames {
+		if strings.EqualFold(n, name) {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+// KeyName returns the configuration key name of a key code (e.g. "Escape" for
+// the key "Esc" is parsed to), or "" for codes without a name. Saving this name
+// keeps spellings of the same key comparable as plain strings.
+func KeyName(key hotkey.Key) string {
+	for name, k := range keyNames {
+		if k == key {
+			return name
+		}
+	}
+	return ""
+}
+
+// ParseKey converts a configuration key name to a key code, returning an
+// error for names that do not map to a key
+func ParseKey(keyStr string) (hotkey.Key, error) {
+	key, ok := LookupKey(keyStr)
+	if !ok {
+		return 0, fmt.Errorf("unknown key: %q", keyStr)
+	}
+	return key, nil
+}
+
+// StringFromKey returns the display name of a key code (e.g. "Space", "Esc", "A")
+func StringFromKey(key hotkey.Key) string {
+	return keyToString(key)
+}
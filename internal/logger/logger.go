@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -39,6 +41,35 @@ func (l Level) String() string {
 	}
 }
 
+// MarshalText encodes the level as its name, e.g. "WARN"
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// ParseLevel returns the level with the given name (case-insensitive)
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToUpper(name) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level: %q", name)
+	}
+}
+
+// Entry is a log message as delivered to subscribers
+type Entry struct {
+	Seq     uint64    `json:"seq"` // Increases by one per entry, unlike Time which can repeat or go back
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Message string    `json:"message"`
+}
+
 // Logger handles logging to file with rotation
 type Logger struct {
 	mu            sync.RWMutex
@@ -51,8 +82,17 @@ type Logger struct {
 	logDir        string
 	currentDay    string
 	retentionDays int
+
+	subMu       sync.Mutex
+	subscribers map[int]chan Entry
+	nextSubID   int
+	lastSeq     uint64  // Seq of the latest entry, guarded by subMu
+	recent      []Entry // The last RecentEntries entries, oldest first, guarded by subMu
 }
 
+// RecentEntries is how many of the latest entries the logger keeps for Recent
+const RecentEntries = 200
+
 // Config holds logger configuration
 type Config struct {
 	LogDir        string
@@ -205,7 +245,7 @@ func (l *Logger) Debug(format string, v ...interface{}) {
 		debugLog := l.debugLog
 		l.mu.RUnlock()
 		if debugLog != nil {
-			debugLog.Printf(format, v...)
+			l.write(DEBUG, debugLog, format, v)
 		}
 	}
 }
@@ -222,7 +262,7 @@ func (l *Logger) Info(format string, v ...interface{}) {
 		infoLog := l.infoLog
 		l.mu.RUnlock()
 		if infoLog != nil {
-			infoLog.Printf(format, v...)
+			l.write(INFO, infoLog, format, v)
 		}
 	}
 }
@@ -239,7 +279,7 @@ func (l *Logger) Warn(format string, v ...interface{}) {
 		warnLog := l.warnLog
 		l.mu.RUnlock()
 		if warnLog != nil {
-			warnLog.Printf(format, v...)
+			l.write(WARN, warnLog, format, v)
 		}
 	}
 }
@@ -256,7 +296,72 @@ func (l *Logger) Error(format string, v ...interface{}) {
 		errorLog := l.errorLog
 		l.mu.RUnlock()
 		if errorLog != nil {
-			errorLog.Printf(format, v...)
+			l.write(ERROR, errorLog, format, v)
+		}
+	}
+}
+
+// write formats a message, writes it to the log file and delivers it to the subscribers
+func (l *Logger) write(level Level, out *log.Logger, format string, v []interface{}) {
+	message := fmt.Sprintf(format, v...)
+	out.Print(message)
+	l.publish(Entry{Time: time.Now(), Level: level, Message: message})
+}
+
+// Subscribe delivers every entry written from now on to the returned channel,
+// regardless of rotation. Entries are dropped rather than blocking the caller
+// when the channel already holds buffer entries. cancel stops the delivery and
+// closes the channel.
+func (l *Logger) Subscribe(buffer int) (<-chan Entry, func()) {
+	entries := make(chan Entry, buffer)
+
+	l.subMu.Lock()
+	if l.subscribers == nil {
+		l.subscribers = make(map[int]chan Entry)
+	}
+	id := l.nextSubID
+	l.nextSubID++
+	l.subscribers[id] = entries
+	l.subMu.Unlock()
+
+	var once sync.Once
+	return entries, func() {
+		once.Do(func() {
+			l.subMu.Lock()
+			delete(l.subscribers, id)
+			l.subMu.Unlock()
+			close(entries)
+		})
+	}
+}
+
+// Recent returns up to n of the latest entries, oldest first. Like
+// Subscribe it does not depend on the log file, so it spans rotations.
+func (l *Logger) Recent(n int) []Entry {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	n = min(max(n, 0), len(l.recent))
+	return slices.Clone(l.recent[len(l.recent)-n:])
+}
+
+// publish numbers entry, keeps it for Recent and hands it to every subscriber that has room for it
+func (l *Logger) publish(entry Entry) {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	l.lastSeq++
+	entry.Seq = l.lastSeq
+
+	if len(l.recent) == RecentEntries {
+		l.recent = slices.Delete(l.recent, 0, 1)
+	}
+	l.recent = append(l.recent, entry)
+
+	for _, entries := range l.subscribers {
+		select {
+		case entries <- entry:
+		default:
 		}
 	}
 }
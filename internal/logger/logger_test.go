@@ -255,3 +255,102 @@ func TestCleanOldLogs(t *testing.T) {
 		t.Error("Current log file should exist")
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Level
+	}{
+		{"debug", DEBUG},
+		{"INFO", INFO},
+		{"warn", WARN},
+		{"warning", WARN},
+		{"Error", ERROR},
+	}
+
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.name)
+		if err != nil || level != tt.expected {
+			t.Errorf("ParseLevel(%q) = %v, %v; expected %v", tt.name, level, err, tt.expected)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	logger, err := New(Config{LogDir: t.TempDir(), Level: INFO, RetentionDays: 7})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	entries, cancel := logger.Subscribe(2)
+
+	logger.Debug("below the logger level")
+	logger.Warn("disk %s", "full")
+
+	entry := <-entries
+	if entry.Level != WARN || entry.Message != "disk full" || entry.Time.IsZero() {
+		t.Errorf("Expected the WARN entry, got %+v", entry)
+	}
+
+	// Entries keep coming after the log file rotates
+	logger.mu.Lock()
+	logger.currentDay = "19700101"
+	logger.mu.Unlock()
+	logger.Info("after rotation")
+
+	if entry := <-entries; entry.Message != "after rotation" {
+		t.Errorf("Expected the entry written after rotation, got %+v", entry)
+	}
+
+	// A full subscriber drops entries instead of blocking the logger
+	for i := 0; i < 5; i++ {
+		logger.Info("entry %d", i)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected the buffer to hold 2 entries, got %d", len(entries))
+	}
+
+	cancel()
+	cancel()
+	for range entries {
+	}
+	logger.Info("after cancel")
+}
+
+func TestRecent(t *testing.T) {
+	logger, err := New(Config{LogDir: t.TempDir(), Level: INFO, RetentionDays: 7})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	if recent := logger.Recent(10); len(recent) != 0 {
+		t.Errorf("Expected no entries yet, got %v", recent)
+	}
+
+	for i := 0; i < RecentEntries+5; i++ {
+		logger.Info("entry %d", i)
+	}
+
+	recent := logger.Recent(2)
+	if len(recent) != 2 || recent[0].Message != fmt.Sprintf("entry %d", RecentEntries+3) || recent[1].Message != fmt.Sprintf("entry %d", RecentEntries+4) {
+		t.Errorf("Expected the last two entries oldest first, got %+v", recent)
+	}
+
+	all := logger.Recent(RecentEntries * 2)
+	if len(all) != RecentEntries || all[0].Message != "entry 5" {
+		t.Errorf("Expected the last %d entries, got %d starting with %q", RecentEntries, len(all), all[0].Message)
+	}
+
+	// Entries are numbered in the order they were written
+	for i := 1; i < len(all); i++ {
+		if all[i].Seq != all[i-1].Seq+1 {
+			t.Fatalf("Expected consecutive sequence numbers, got %d after %d", all[i].Seq, all[i-1].Seq)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package metrics measures each transcription run (recording length,
+// transcription and paste time) and aggregates recent runs for /api/stats.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is how many recent runs the aggregates are computed over
+const DefaultWindow = 200
+
+// Timing is the measurement of one transcription run
+type Timing struct {
+	Audio      time.Duration // Length of the recording
+	Transcribe time.Duration // Time spent in the recognizer
+	Paste      time.Duration // Time spent pasting, 0 when the result was not pasted
+}
+
+// RTF returns the realtime factor: transcription time divided by audio length.
+// Below 1 the recognizer is faster than realtime. It is 0 without audio.
+func (t Timing) RTF() float64 {
+	if t.Audio <= 0 {
+		return 0
+	}
+	return t.Transcribe.Seconds() / t.Audio.Seconds()
+}
+
+// String returns the run as a single key=value line for the log
+func (t Timing) String() string {
+	return fmt.Sprintf("audio=%dms transcribe=%dms rtf=%.2f paste=%dms",
+		t.Audio.Milliseconds(), t.Transcribe.Milliseconds(), t.RTF(), t.Paste.Milliseconds())
+}
+
+// Summary returns a short description for notifications, e.g. "5.2s audio → 1.1s, RTF 0.21"
+func (t Timing) Summary() string {
+	return fmt.Sprintf("%.1fs audio → %.1fs, RTF %.2f", t.Audio.Seconds(), t.Transcribe.Seconds(), t.RTF())
+}
+
+// Snapshot is the aggregate of the recent runs returned by /api/stats.
+// Durations are in milliseconds.
+type Snapshot struct {
+	Runs             int     `json:"runs"`               // Runs since the app started
+	Window           int     `json:"window"`             // Recent runs the values below are computed over
+	TranscribeP50Ms  int64   `json:"transcribe_p50_ms"`  // Median transcription time
+	TranscribeP95Ms  int64   `json:"transcribe_p95_ms"`  // 95th percentile transcription time
+	PasteP50Ms       int64   `json:"paste_p50_ms"`       // Median paste time of the pasted runs
+	PasteP95Ms       int64   `json:"paste_p95_ms"`       // 95th percentile paste time of the pasted runs
+	AudioTotalMs     int64   `json:"audio_total_ms"`     // Total recording length
+	MeanRTF          float64 `json:"mean_rtf"`           // Total transcription time over total recording length
+	LastTranscribeMs int64   `json:"last_transcribe_ms"` // Transcription time of the latest run
+}
+
+// Stats keeps the most recent runs. It is safe for concurrent use.
+type Stats struct {
+	mu     sync.Mutex
+	window int
+	runs   int
+	recent []Timing // Ring buffer of the latest window runs
+	next   int      // Position of the next run in recent once it is full
+}
+
+// NewStats creates an aggregator over the latest window runs (DefaultWindow if window <= 0)
+func NewStats(window int) *Stats {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Stats{window: window}
+}
+
+// Add records a run
+func (s *Stats) Add(t Timing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs++
+	if len(s.recent) < s.window {
+		s.recent = append(s.recent, t)
+		return
+	}
+	s.recent[s.next] = t
+	s.next = (s.next + 1) % s.window
+}
+
+// Snapshot returns the aggregates of the recorded runs
+func (s *Stats) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := Snapshot{Runs: s.runs, Window: len(s.recent)}
+	if len(s.recent) == 0 {
+		return snapshot
+	}
+
+	var transcribe, paste []time.Duration
+	var audioTotal, transcribeTotal time.Duration
+	for _, t := range s.recent {
+		transcribe = append(transcribe, t.Transcribe)
+		if t.Paste > 0 {
+			paste = append(paste, t.Paste)
+		}
+		audioTotal += t.Audio
+		transcribeTotal += t.Transcribe
+	}
+
+	snapshot.TranscribeP50Ms = Percentile(transcribe, 50).Milliseconds()
+	snapshot.TranscribeP95Ms = Percentile(transcribe, 95).Milliseconds()
+	snapshot.PasteP50Ms = Percentile(paste, 50).Milliseconds()
+	snapshot.PasteP95Ms = Percentile(paste, 95).Milliseconds()
+	snapshot.AudioTotalMs = audioTotal.Milliseconds()
+	snapshot.MeanRTF = Timing{Audio: audioTotal, Transcribe: transcribeTotal}.RTF()
+
+	last := s.recent[len(s.recent)-1]
+	if len(s.recent) == s.window {
+		last = s.recent[(s.next+s.window-1)%s.window]
+	}
+	snapshot.LastTranscribeMs = last.Transcribe.Milliseconds()
+
+	return snapshot
+}
+
+// Percentile returns the p-th percentile (0-100) of values using the
+// nearest-rank method, or 0 for no values. values is not modified.
+func Percentile(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	// Nearest rank: the smallest value with at least p% of the values at or below it
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
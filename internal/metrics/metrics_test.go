@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTiming(t *testing.T) {
+	timing := Timing{Audio: 5200 * time.Millisecond, Transcribe: 1092 * time.Millisecond, Paste: 150 * time.Millisecond}
+
+	if rtf := timing.RTF(); rtf < 0.2099 || rtf > 0.2101 {
+		t.Errorf("Expected RTF 0.21, got %f", rtf)
+	}
+	if got := timing.Summary(); got != "5.2s audio → 1.1s, RTF 0.21" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	if got := timing.String(); got != "audio=5200ms transcribe=1092ms rtf=0.21 paste=150ms" {
+		t.Errorf("Unexpected log line %q", got)
+	}
+
+	if rtf := (Timing{Transcribe: time.Second}).RTF(); rtf != 0 {
+		t.Errorf("Expected RTF 0 without audio, got %f", rtf)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var values []time.Duration
+	for i := 20; i >= 1; i-- {
+		values = append(values, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{50, 10 * time.Millisecond},
+		{95, 19 * time.Millisecond},
+		{100, 20 * time.Millisecond},
+		{0, 1 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got != tt.expected {
+			t.Errorf("Percentile(%v) = %v, expected %v", tt.p, got, tt.expected)
+		}
+	}
+
+	if values[0] != 20*time.Millisecond {
+		t.Error("Percentile modified its input")
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Expected 0 for no values, got %v", got)
+	}
+}
+
+func TestStatsSnapshot(t *testing.T) {
+	stats := NewStats(3)
+
+	if snapshot := stats.Snapshot(); snapshot.Runs != 0 || snapshot.TranscribeP50Ms != 0 {
+		t.Errorf("Expected an empty snapshot, got %+v", snapshot)
+	}
+
+	// The oldest run falls out of the window
+	stats.Add(Timing{Audio: 10 * time.Second, Transcribe: 9 * time.Second, Paste: 900 * time.Millisecond})
+	stats.Add(Timing{Audio: 2 * time.Second, Transcribe: 200 * time.Millisecond, Paste: 100 * time.Millisecond})
+	stats.Add(Timing{Audio: 4 * time.Second, Transcribe: 400 * time.Millisecond})
+	stats.Add(Timing{Audio: 4 * time.Second, Transcribe: 600 * time.Millisecond, Paste: 300 * time.Millisecond})
+
+	snapshot := stats.Snapshot()
+	if snapshot.Runs != 4 || snapshot.Window != 3 {
+		t.Errorf("Expected 4 runs over a window of 3, got %+v", snapshot)
+	}
+	if snapshot.TranscribeP50Ms != 400 || snapshot.TranscribeP95Ms != 600 {
+		t.Errorf("Expected p50 400ms and p95 600ms, got %+v", snapshot)
+	}
+	// Runs that were not pasted do not count towards the paste time
+	if snapshot.PasteP50Ms != 100 || snapshot.PasteP95Ms != 300 {
+		t.Errorf("Expected paste p50 100ms and p95 300ms, got %+v", snapshot)
+	}
+	if snapshot.AudioTotalMs != 10000 || snapshot.MeanRTF != 0.12 {
+		t.Errorf("Expected 10s of audio at RTF 0.12, got %+v", snapshot)
+	}
+	if snapshot.LastTranscribeMs != 600 {
+		t.Errorf("Expected the latest run to be 600ms, got %d", snapshot.LastTranscribeMs)
+	}
+}
@@ -0,0 +1,205 @@
+// Package modelinfo reads metadata from the header of whisper.cpp ggml model files
+// without loading the weights, so model lists can describe each file cheaply.
+package modelinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ggmlMagic is the first four bytes of a whisper.cpp ggml model ("ggml" as little-endian uint32)
+const ggmlMagic = 0x67676d6c
+
+// multilingualVocab is the vocabulary size from which whisper.cpp treats a model as multilingual.
+// English-only (.en) models have 51864 tokens.
+const multilingualVocab = 51865
+
+// qntVersionFactor is the multiplier whisper.cpp uses to store the quantization
+// version in the ftype field
+const qntVersionFactor = 1000
+
+// Speed tiers reported for a model
+const (
+	SpeedFast   = "fast"
+	SpeedMedium = "medium"
+	SpeedSlow   = "slow"
+)
+
+// hparams mirrors the hyperparameters that follow the magic in a ggml model file
+type hparams struct {
+	NVocab      int32
+	NAudioCtx   int32
+	NAudioState int32
+	NAudioHead  int32
+	NAudioLayer int32
+	NTextCtx    int32
+	NTextState  int32
+	NTextHead   int32
+	NTextLayer  int32
+	NMels       int32
+	FType       int32
+}
+
+// Info describes a model as read from its header
+type Info struct {
+	Multilingual bool   `json:"multilingual"`
+	Class        string `json:"class"`                  // "tiny", "base", "small", "medium", "large"
+	Turbo        bool   `json:"turbo"`                  // large-v3-turbo (reduced decoder)
+	Speed        string `json:"speed"`                  // SpeedFast, SpeedMedium or SpeedSlow
+	Quantization string `json:"quantization,omitempty"` // e.g. "q5_0", "f16", empty if unknown
+}
+
+// Read parses the model header from r
+func Read(r io.Reader) (Info, error) {
+	var magic uint32
+	if err := binary.Read(r, binary.LittleEndian, &magic); err != nil {
+		return Info{}, fmt.Errorf("failed to read magic: %w", err)
+	}
+	if magic != ggmlMagic {
+		return Info{}, fmt.Errorf("not a ggml model (magic 0x%08x)", magic)
+	}
+
+	var hp hparams
+	if err := binary.Read(r, binary.LittleEndian, &hp); err != nil {
+		return Info{}, fmt.Errorf("failed to read hyperparameters: %w", err)
+	}
+
+	class := classFromLayers(hp.NAudioLayer)
+	if class == "" {
+		return Info{}, fmt.Errorf("unknown model architecture (%d audio layers)", hp.NAudioLayer)
+	}
+
+	// large-v3-turbo keeps the large encoder but has only 4 decoder layers
+	turbo := class == "large" && hp.NTextLayer < hp.NAudioLayer
+
+	return Info{
+		Multilingual: hp.NVocab >= multilingualVocab,
+		Class:        class,
+		Turbo:        turbo,
+		Speed:        speedTier(class, turbo),
+		Quantization: quantization(hp.FType % qntVersionFactor),
+	}, nil
+}
+
+// ReadFile parses the header of the model file at path
+func ReadFile(path string) (Info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to open model file: %w", err)
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// classFromLayers maps the encoder depth to the model size class
+func classFromLayers(layers int32) string {
+	switch layers {
+	case 4:
+		return "tiny"
+	case 6:
+		return "base"
+	case 12:
+		return "small"
+	case 24:
+		return "medium"
+	case 32:
+		return "large"
+	default:
+		return ""
+	}
+}
+
+// quantization maps a ggml file type to its weight format, "" for unknown types
+func quantization(ftype int32) string {
+	switch ftype {
+	case 0:
+		return "f32"
+	case 1:
+		return "f16"
+	case 2:
+		return "q4_0"
+	case 3, 4:
+		return "q4_1"
+	case 7:
+		return "q8_0"
+	case 8:
+		return "q5_0"
+	case 9:
+		return "q5_1"
+	case 10:
+		return "q2_k"
+	case 11:
+		return "q3_k"
+	case 12:
+		return "q4_k"
+	case 13:
+		return "q5_k"
+	case 14:
+		return "q6_k"
+	default:
+		return ""
+	}
+}
+
+// speedTier gives a rough transcription speed for a model class.
+// Decoding dominates latency, so turbo is fast despite its large encoder.
+func speedTier(class string, turbo bool) string {
+	switch {
+	case turbo, class == "tiny", class == "base":
+		return SpeedFast
+	case class == "small":
+		return SpeedMedium
+	default:
+		return SpeedSlow
+	}
+}
+
+// cacheEntry is a parsed header together with the file state it was read from
+type cacheEntry struct {
+	modTime time.Time
+	size    int64
+	info    Info
+	err     error
+}
+
+// Cache memoizes parsed headers keyed by path, invalidated when the file's
+// modification time or size changes
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache creates an empty metadata cache
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+// Get returns the metadata for the model at path, reading the header only if the
+// file changed since the last call. Parse errors are cached as well.
+func (c *Cache) Get(path string) (Info, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to stat model file: %w", err)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+
+	if ok && entry.modTime.Equal(stat.ModTime()) && entry.size == stat.Size() {
+		return entry.info, entry.err
+	}
+
+	info, err := ReadFile(path)
+
+	c.mu.Lock()
+	c.entries[path] = cacheEntry{modTime: stat.ModTime(), size: stat.Size(), info: info, err: err}
+	c.mu.Unlock()
+
+	return info, err
+}
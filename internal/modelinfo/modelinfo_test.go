@@ -0,0 +1,143 @@
+package modelinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// buildHeader encodes a ggml model header with the given hyperparameters
+func buildHeader(nVocab, audioLayers, textLayers int32) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(ggmlMagic))
+	binary.Write(&buf, binary.LittleEndian, hparams{
+		NVocab:      nVocab,
+		NAudioCtx:   1500,
+		NAudioLayer: audioLayers,
+		NTextCtx:    448,
+		NTextLayer:  textLayers,
+		NMels:       80,
+		FType:       1,
+	})
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []byte
+		expected Info
+	}{
+		{
+			name:     "large-v3-turbo",
+			header:   buildHeader(51866, 32, 4),
+			expected: Info{Multilingual: true, Class: "large", Turbo: true, Speed: SpeedFast, Quantization: "f16"},
+		},
+		{
+			name:     "large-v3",
+			header:   buildHeader(51866, 32, 32),
+			expected: Info{Multilingual: true, Class: "large", Speed: SpeedSlow, Quantization: "f16"},
+		},
+		{
+			name:     "base.en",
+			header:   buildHeader(51864, 6, 6),
+			expected: Info{Multilingual: false, Class: "base", Speed: SpeedFast, Quantization: "f16"},
+		},
+		{
+			name:     "small",
+			header:   buildHeader(51865, 12, 12),
+			expected: Info{Multilingual: true, Class: "small", Speed: SpeedMedium, Quantization: "f16"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Read(bytes.NewReader(tt.header))
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+
+			if info != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, info)
+			}
+		})
+	}
+}
+
+func TestRead_Quantization(t *testing.T) {
+	tests := []struct {
+		ftype    int32
+		expected string
+	}{
+		{0, "f32"},
+		{1, "f16"},
+		{8, "q5_0"},
+		{1008, "q5_0"}, // quantization version 1
+		{2007, "q8_0"}, // quantization version 2
+		{14, "q6_k"},
+		{99, ""},
+	}
+
+	for _, tt := range tests {
+		header := buildHeader(51866, 32, 4)
+		binary.LittleEndian.PutUint32(header[len(header)-4:], uint32(tt.ftype))
+
+		info, err := Read(bytes.NewReader(header))
+		if err != nil {
+			t.Fatalf("Read failed for ftype %d: %v", tt.ftype, err)
+		}
+		if info.Quantization != tt.expected {
+			t.Errorf("ftype %d: expected quantization %q, got %q", tt.ftype, tt.expected, info.Quantization)
+		}
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("GGUF\x03\x00\x00\x00"))); err == nil {
+		t.Error("Expected error for non-ggml file")
+	}
+
+	if _, err := Read(bytes.NewReader(buildHeader(51865, 12, 12)[:20])); err == nil {
+		t.Error("Expected error for truncated header")
+	}
+
+	if _, err := Read(bytes.NewReader(buildHeader(51865, 7, 7))); err == nil {
+		t.Error("Expected error for unknown layer count")
+	}
+}
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(path, buildHeader(51865, 6, 6), 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+
+	cache := NewCache()
+
+	info, err := cache.Get(path)
+	if err != nil || info.Class != "base" {
+		t.Fatalf("Expected base model, got %+v (err: %v)", info, err)
+	}
+
+	// Replace the file without touching the cache key: same size, same mod time
+	stat, _ := os.Stat(path)
+	if err := os.WriteFile(path, buildHeader(51865, 12, 12), 0644); err != nil {
+		t.Fatalf("Failed to rewrite model: %v", err)
+	}
+	os.Chtimes(path, stat.ModTime(), stat.ModTime())
+
+	if info, _ := cache.Get(path); info.Class != "base" {
+		t.Errorf("Expected cached metadata for unchanged mod time, got %+v", info)
+	}
+
+	// A newer mod time invalidates the entry
+	later := stat.ModTime().Add(time.Minute)
+	os.Chtimes(path, later, later)
+
+	if info, _ := cache.Get(path); info.Class != "small" {
+		t.Errorf("Expected metadata to be re-read after modification, got %+v", info)
+	}
+}
@@ -2,9 +2,14 @@ package notification
 
 import (
 	"fmt"
+	"log"
 	"os/exec"
+	"strings"
 )
 
+// dialogTimeoutSeconds closes an unanswered error dialog so dialogs do not pile up
+const dialogTimeoutSeconds = 60
+
 // NotificationType represents the type of notification
 type NotificationType string
 
@@ -29,37 +34,79 @@ type Notification struct {
 
 // NotificationManager handles sending notifications to the user
 type NotificationManager struct {
-	appName string
+	appName   string
+	runScript func(script string) error // Runs an AppleScript (replaced in tests)
 }
 
 // NewNotificationManager creates a new notification manager
 func NewNotificationManager(appName string) *NotificationManager {
 	return &NotificationManager{
-		appName: appName,
+		appName:   appName,
+		runScript: runAppleScript,
 	}
 }
 
-// Send sends a notification to the user via macOS notification center
+// runAppleScript runs script with osascript
+func runAppleScript(script string) error {
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// Send sends a notification to the user via macOS notification center.
+// Errors are shown in a dialog instead: display notification succeeds even
+// when notifications are turned off for osascript or Focus hides them, so
+// delivery cannot be confirmed and a failure would be silently dropped.
 func (nm *NotificationManager) Send(notification *Notification) error {
 	if notification == nil {
 		return fmt.Errorf("notification cannot be nil")
 	}
 
+	if notification.Type == TypeError {
+		go nm.showDialog(notification)
+		return nil
+	}
+
 	// Use osascript to send notification via macOS notification center
 	script := fmt.Sprintf(
 		`display notification "%s" with title "%s"`,
-		notification.Message,
-		notification.Title,
+		escapeAppleScript(notification.Message),
+		escapeAppleScript(notification.Title),
 	)
 
-	cmd := exec.Command("osascript", "-e", script)
-	if err := cmd.Run(); err != nil {
+	if err := nm.runScript(script); err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
 	return nil
 }
 
+// showDialog shows notification in a modal dialog. It blocks until the dialog
+// is answered or closes itself, so callers run it in a goroutine.
+func (nm *NotificationManager) showDialog(notification *Notification) {
+	script := fmt.Sprintf(
+		`display dialog "%s" with title "%s" buttons {"OK"} default button "OK" with icon stop giving up after %d`,
+		escapeAppleScript(notification.Message),
+		escapeAppleScript(notification.Title),
+		dialogTimeoutSeconds,
+	)
+
+	if err := nm.runScript(script); err != nil {
+		log.Printf("Failed to show dialog: %v: %s - %s", err, notification.Title, notification.Message)
+	}
+}
+
+// escapeAppleScript escapes special characters for AppleScript
+func escapeAppleScript(s string) string {
+	// Escape backslashes first to avoid double-escaping
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	// Escape double quotes
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	// Escape control characters
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	s = strings.ReplaceAll(s, "\r", `\r`)
+	s = strings.ReplaceAll(s, "\t", `\t`)
+	return s
+}
+
 // SendInfo sends an informational notification
 func (nm *NotificationManager) SendInfo(title, message string) error {
 	return nm.Send(&Notification{
@@ -150,14 +197,19 @@ func (nm *NotificationManager) TranscriptionFailed(reason string) error {
 	return nm.SendError(nm.appName, message)
 }
 
-// RecordingTimeExceeded sends a notification that recording time has exceeded the limit
-func (nm *NotificationManager) RecordingTimeExceeded() error {
+// RecordingTimeExceeded sends a notification that recording was stopped at the limit of seconds
+func (nm *NotificationManager) RecordingTimeExceeded(seconds int) error {
 	return nm.SendWarning(
 		nm.appName,
-		"録音が60秒に達したため、自動停止しました。",
+		RecordingTimeExceededMessage(seconds),
 	)
 }
 
+// RecordingTimeExceededMessage returns the message shown when recording is stopped at the limit of seconds
+func RecordingTimeExceededMessage(seconds int) string {
+	return fmt.Sprintf("最大録音時間 (%d秒) に達したため録音を停止しました。ここまでの録音を文字起こしします。", seconds)
+}
+
 // DeviceNotFound sends a notification that audio device is not found
 func (nm *NotificationManager) DeviceNotFound() error {
 	return nm.SendError(
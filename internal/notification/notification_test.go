@@ -1,7 +1,10 @@
 package notification
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewNotificationManager(t *testing.T) {
@@ -142,13 +145,21 @@ func TestTranscriptionFailed(t *testing.T) {
 func TestRecordingTimeExceeded(t *testing.T) {
 	nm := NewNotificationManager("TestApp")
 
-	err := nm.RecordingTimeExceeded()
+	err := nm.RecordingTimeExceeded(60)
 
 	if err != nil {
 		t.Logf("RecordingTimeExceeded returned error (expected in test env): %v", err)
 	}
 }
 
+func TestRecordingTimeExceededMessage(t *testing.T) {
+	message := RecordingTimeExceededMessage(90)
+
+	if !strings.Contains(message, "90秒") {
+		t.Errorf("Expected the limit in the message, got %q", message)
+	}
+}
+
 func TestDeviceNotFound(t *testing.T) {
 	nm := NewNotificationManager("TestApp")
 
@@ -205,3 +216,45 @@ func TestCustomNotification(t *testing.T) {
 		t.Logf("Send custom notification returned error (expected in test env): %v", err)
 	}
 }
+
+func TestSendError_ShowsDialog(t *testing.T) {
+	nm := NewNotificationManager("TestApp")
+
+	// display notification reports success even when notifications are disabled,
+	// so errors never go through it
+	scripts := make(chan string, 2)
+	nm.runScript = func(script string) error {
+		scripts <- script
+		return nil
+	}
+
+	if err := nm.SendError("Test Title", `Say "hi"`); err != nil {
+		t.Fatalf("SendError failed: %v", err)
+	}
+
+	select {
+	case script := <-scripts:
+		if !strings.Contains(script, `display dialog "Say \"hi\""`) {
+			t.Errorf("Expected only a dialog, got %q", script)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a dialog for an error")
+	}
+}
+
+func TestSendInfo_NoDialog(t *testing.T) {
+	nm := NewNotificationManager("TestApp")
+
+	var scripts []string
+	nm.runScript = func(script string) error {
+		scripts = append(scripts, script)
+		return errors.New("notifications are disabled")
+	}
+
+	if err := nm.SendInfo("Test Title", "Test Message"); err == nil {
+		t.Error("Expected the delivery failure to be returned")
+	}
+	if len(scripts) != 1 {
+		t.Errorf("Expected no dialog for an informational notification, got %q", scripts)
+	}
+}
@@ -12,6 +12,23 @@ int check_microphone_permission() {
     return (int)status;
 }
 
+// request_microphone_access shows the system microphone dialog when the status is
+// not determined yet and blocks until the user answers. It returns the resulting status.
+int request_microphone_access() {
+    AVAuthorizationStatus status = [AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+    if (status != AVAuthorizationStatusNotDetermined) {
+        return (int)status;
+    }
+
+    dispatch_semaphore_t answered = dispatch_semaphore_create(0);
+    [AVCaptureDevice requestAccessForMediaType:AVMediaTypeAudio completionHandler:^(BOOL granted) {
+        dispatch_semaphore_signal(answered);
+    }];
+    dispatch_semaphore_wait(answered, DISPATCH_TIME_FOREVER);
+
+    return (int)[AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+}
+
 int check_accessibility_permission() {
     Boolean isAccessibilityEnabled = AXIsProcessTrusted();
     return isAccessibilityEnabled ? 1 : 0;
@@ -77,6 +94,18 @@ func (pc *PermissionChecker) RequestMicrophonePermission() error {
 	return cmd.Run()
 }
 
+// RequestMicrophoneAccess shows the macOS microphone permission dialog when the
+// status is NotDetermined and waits for the user's answer, returning the new
+// status. System Settings only lists apps that have asked at least once, so this
+// is the only way to grant access from NotDetermined. For any other status it
+// returns immediately without showing anything.
+// It blocks until the user answers: call it from a goroutine, and only in
+// response to an explicit user action.
+func (pc *PermissionChecker) RequestMicrophoneAccess() PermissionStatus {
+	status := C.request_microphone_access()
+	return PermissionStatus(status)
+}
+
 // RequestAccessibilityPermission opens system settings for accessibility permission
 func (pc *PermissionChecker) RequestAccessibilityPermission() error {
 	url := "x-apple.systempreferences:com.apple.preference.security?Privacy_Accessibility"
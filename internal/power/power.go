@@ -0,0 +1,47 @@
+// Package power keeps the system awake while the app is doing work the user
+// is waiting for.
+package power
+
+/*
+#cgo LDFLAGS: -framework IOKit -framework CoreFoundation
+#include <IOKit/pwr_mgt/IOPMLib.h>
+#include <stdlib.h>
+
+// prevent_idle_sleep creates an assertion named reason that keeps the system
+// from idle sleeping and stores its ID in id. It returns the IOReturn code.
+int prevent_idle_sleep(const char *reason, IOPMAssertionID *id) {
+    CFStringRef name = CFStringCreateWithCString(NULL, reason, kCFStringEncodingUTF8);
+    if (name == NULL) {
+        return kIOReturnBadArgument;
+    }
+
+    IOReturn ret = IOPMAssertionCreateWithName(kIOPMAssertionTypePreventUserIdleSystemSleep,
+        kIOPMAssertionLevelOn, name, id);
+    CFRelease(name);
+    return ret;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// PreventSleep keeps the system from idle sleeping (e.g. an idle laptop on
+// battery) until release is called. reason is shown by `pmset -g assertions`.
+// Calling release more than once has no further effect.
+func PreventSleep(reason string) (release func(), err error) {
+	cReason := C.CString(reason)
+	defer C.free(unsafe.Pointer(cReason))
+
+	var id C.IOPMAssertionID
+	if ret := C.prevent_idle_sleep(cReason, &id); ret != 0 {
+		return nil, fmt.Errorf("failed to create power assertion: IOReturn 0x%x", uint32(ret))
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { C.IOPMAssertionRelease(id) })
+	}, nil
+}
@@ -0,0 +1,36 @@
+package recognition
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"context"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// newAbortData returns the user data for whisperAbortCallback that makes
+// whisper_full stop once ctx is done, and a function that frees it. The handle
+// lives in C memory because whisper.cpp keeps the pointer during inference.
+func newAbortData(ctx context.Context) (unsafe.Pointer, func()) {
+	handle := cgo.NewHandle(ctx)
+	data := C.malloc(C.size_t(unsafe.Sizeof(C.uintptr_t(0))))
+	*(*C.uintptr_t)(data) = C.uintptr_t(handle)
+
+	return data, func() {
+		C.free(data)
+		handle.Delete()
+	}
+}
+
+// whisperAbortCallback is whisper.cpp's abort_callback. It is called between
+// computations and aborts inference when the context passed in data is done.
+//
+//export whisperAbortCallback
+func whisperAbortCallback(data unsafe.Pointer) C.bool {
+	ctx := cgo.Handle(*(*C.uintptr_t)(data)).Value().(context.Context)
+	return C.bool(ctx.Err() != nil)
+}
@@ -0,0 +1,71 @@
+package recognition
+
+/*
+#include "whisper.h"
+#include <dlfcn.h>
+#include <stddef.h>
+
+// whisper_version is only exported by newer whisper.cpp releases, so it is
+// looked up at run time instead of linked: older libraries report no version.
+static const char * ezs2t_whisper_version(void) {
+    const char * (*fn)(void) = (const char * (*)(void)) dlsym(RTLD_DEFAULT, "whisper_version");
+    if (fn == NULL) {
+        return NULL;
+    }
+    return fn();
+}
+*/
+import "C"
+import (
+	"regexp"
+	"strings"
+)
+
+// ExpectedWhisperVersion is the whisper.cpp version whose header the binary
+// was compiled against, set at build time with -ldflags, e.g.
+//
+//	-X github.com/yok-tottii/EzS2T-Whisper/internal/recognition.ExpectedWhisperVersion=$(git -C whisper.cpp describe --tags)
+//
+// Empty disables the check. The commit suffix that git describe adds between
+// releases ("v1.7.6-12-gabc1234", "-dirty") is ignored, since the library
+// reports only the release it was cut from.
+var ExpectedWhisperVersion = ""
+
+// describeSuffix matches what git describe appends to the tag for commits after it
+var describeSuffix = regexp.MustCompile(`(-[0-9]+-g[0-9a-f]+)?(-dirty)?$`)
+
+// BuildInfo describes the whisper.cpp library the binary runs with
+type BuildInfo struct {
+	Version         string `json:"version"`          // Reported by the library, "" if it is too old to tell
+	ExpectedVersion string `json:"expected_version"` // ExpectedWhisperVersion
+	SystemInfo      string `json:"system_info"`      // whisper_print_system_info(): CPU and GPU features in use
+	Mismatch        bool   `json:"mismatch"`         // The library differs from the header the binary was built with
+}
+
+// GetBuildInfo returns the version and system information of the linked whisper.cpp
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		ExpectedVersion: ExpectedWhisperVersion,
+		SystemInfo:      strings.TrimSpace(C.GoString(C.whisper_print_system_info())),
+	}
+	if version := C.ezs2t_whisper_version(); version != nil {
+		info.Version = C.GoString(version)
+	}
+	info.Mismatch = versionMismatch(info.Version, info.ExpectedVersion)
+	return info
+}
+
+// versionMismatch reports whether the library version differs from the
+// expected one. Tags such as "v1.7.6" and "v1.7.6-12-gabc1234" match "1.7.6".
+// Unknown versions never mismatch, since there is nothing to compare.
+func versionMismatch(actual, expected string) bool {
+	if actual == "" || expected == "" {
+		return false
+	}
+	return normalizeVersion(actual) != normalizeVersion(expected)
+}
+
+// normalizeVersion strips the "v" prefix and the git describe suffix
+func normalizeVersion(version string) string {
+	return describeSuffix.ReplaceAllString(strings.TrimPrefix(version, "v"), "")
+}
@@ -0,0 +1,38 @@
+package recognition
+
+import "testing"
+
+func TestVersionMismatch(t *testing.T) {
+	tests := []struct {
+		actual   string
+		expected string
+		mismatch bool
+	}{
+		{"1.7.6", "1.7.6", false},
+		{"1.7.6", "v1.7.6", false},
+		{"1.7.5", "v1.7.6", true},
+		{"1.7.6", "v1.7.6-12-gabc1234", false}, // git describe between releases
+		{"1.7.6", "v1.7.6-dirty", false},
+		{"1.7.6", "v1.7.6-3-g0f9e8d7-dirty", false},
+		{"1.7.5", "v1.7.6-12-gabc1234", true},
+		{"", "1.7.6", false}, // Library too old to report its version
+		{"1.7.6", "", false}, // Check disabled
+	}
+
+	for _, tt := range tests {
+		if got := versionMismatch(tt.actual, tt.expected); got != tt.mismatch {
+			t.Errorf("versionMismatch(%q, %q) = %v, expected %v", tt.actual, tt.expected, got, tt.mismatch)
+		}
+	}
+}
+
+func TestGetBuildInfo(t *testing.T) {
+	info := GetBuildInfo()
+
+	if info.SystemInfo == "" {
+		t.Error("Expected whisper.cpp to report its system information")
+	}
+	if info.ExpectedVersion != ExpectedWhisperVersion {
+		t.Errorf("Expected %q, got %q", ExpectedWhisperVersion, info.ExpectedVersion)
+	}
+}
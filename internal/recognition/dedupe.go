@@ -0,0 +1,85 @@
+package recognition
+
+import (
+	"strings"
+	"unicode"
+)
+
+// dedupeSimilarity is the minimum similarity (1 - edit distance / length) for two
+// adjacent segments to be treated as near-identical duplicates
+const dedupeSimilarity = 0.9
+
+// DedupeSegments collapses adjacent segments that are identical or near-identical
+// into the first one. Segments are compared ignoring whitespace, punctuation and case,
+// so "同じことを言いました。" followed by "同じことを言いました" is a duplicate.
+func DedupeSegments(segments []string) []string {
+	result := make([]string, 0, len(segments))
+	previousKey := ""
+
+	for _, segment := range segments {
+		key := segmentKey(segment)
+		if key != "" && previousKey != "" && similar(key, previousKey) {
+			continue
+		}
+
+		result = append(result, segment)
+		if key != "" {
+			previousKey = key
+		}
+	}
+
+	return result
+}
+
+// segmentKey normalizes a segment for comparison by dropping whitespace and punctuation
+func segmentKey(segment string) string {
+	var b strings.Builder
+	for _, r := range segment {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// similar reports whether two normalized segments are identical or differ by
+// only a small fraction of their characters
+func similar(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+
+	// Short segments must match exactly; one differing character is already a large change
+	if longest < 10 {
+		return false
+	}
+
+	return 1-float64(editDistance(ra, rb))/float64(longest) >= dedupeSimilarity
+}
+
+// editDistance returns the Levenshtein distance between two rune slices
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
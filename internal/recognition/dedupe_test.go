@@ -0,0 +1,82 @@
+package recognition
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupeSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []string
+		expected []string
+	}{
+		{
+			name:     "identical adjacent",
+			segments: []string{"同じことを言いました。", "同じことを言いました。", "次の話題です。"},
+			expected: []string{"同じことを言いました。", "次の話題です。"},
+		},
+		{
+			name:     "differs only in punctuation and spacing",
+			segments: []string{" I said the same thing.", " I said the same thing", " Then I stopped."},
+			expected: []string{" I said the same thing.", " Then I stopped."},
+		},
+		{
+			name:     "near-identical long segment",
+			segments: []string{"本日の会議では来期の予算について議論します。", "本日の会議では来季の予算について議論します。"},
+			expected: []string{"本日の会議では来期の予算について議論します。"},
+		},
+		{
+			name:     "short segments must match exactly",
+			segments: []string{"はい。", "いい。"},
+			expected: []string{"はい。", "いい。"},
+		},
+		{
+			name:     "non-adjacent duplicates are kept",
+			segments: []string{"A案です。", "B案です。", "A案です。"},
+			expected: []string{"A案です。", "B案です。", "A案です。"},
+		},
+		{
+			name:     "run of three",
+			segments: []string{"こんにちは", "こんにちは", "こんにちは"},
+			expected: []string{"こんにちは"},
+		},
+		{
+			name:     "empty segment between duplicates",
+			segments: []string{"こんにちは", " ", "こんにちは"},
+			expected: []string{"こんにちは", " "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DedupeSegments(tt.segments); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetectRepetition_DedupeSegments(t *testing.T) {
+	segments := []string{"雨が降る。", "雨が降る。", "静かな夜に。"}
+
+	result := DetectRepetition(segments, RepetitionConfig{MaxRepeats: 4, DedupeSegments: true})
+
+	if result.Text != "雨が降る。静かな夜に。" {
+		t.Errorf("Expected duplicate segment to be removed, got '%s'", result.Text)
+	}
+
+	if result.Suspect {
+		t.Error("Expected a single duplicate not to be flagged as suspect")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	if d := editDistance([]rune("kitten"), []rune("sitting")); d != 3 {
+		t.Errorf("Expected distance 3, got %d", d)
+	}
+
+	if d := editDistance([]rune("会議"), []rune("会議")); d != 0 {
+		t.Errorf("Expected distance 0, got %d", d)
+	}
+}
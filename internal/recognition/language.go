@@ -0,0 +1,31 @@
+package recognition
+
+// FallbackConfidence is the detection probability below which auto-detection
+// picks from the fallback languages instead of trusting the detected language
+const FallbackConfidence = 0.5
+
+// pickFallbackLanguage chooses the language to transcribe as from whisper's
+// detection probabilities (keyed by language code). A language detected with at
+// least FallbackConfidence is kept; otherwise the most probable of the fallback
+// languages is used, the earlier one on a tie. It returns "" when there are no
+// probabilities or none of the fallback languages is known.
+func pickFallbackLanguage(probs map[string]float32, fallback []string) string {
+	detected := ""
+	for language, p := range probs {
+		if detected == "" || p > probs[detected] || (p == probs[detected] && language < detected) {
+			detected = language
+		}
+	}
+	if detected != "" && probs[detected] >= FallbackConfidence {
+		return detected
+	}
+
+	picked := ""
+	for _, language := range fallback {
+		p, ok := probs[language]
+		if ok && (picked == "" || p > probs[picked]) {
+			picked = language
+		}
+	}
+	return picked
+}
@@ -0,0 +1,27 @@
+package recognition
+
+import "testing"
+
+func TestPickFallbackLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		probs    map[string]float32
+		fallback []string
+		expected string
+	}{
+		{"confident detection is kept", map[string]float32{"fr": 0.9, "ja": 0.05, "en": 0.05}, []string{"ja", "en"}, "fr"},
+		{"unsure picks from the fallback", map[string]float32{"zh": 0.4, "ja": 0.35, "en": 0.25}, []string{"ja", "en"}, "ja"},
+		{"most probable fallback wins", map[string]float32{"zh": 0.4, "ja": 0.1, "en": 0.3}, []string{"ja", "en"}, "en"},
+		{"earlier fallback wins a tie", map[string]float32{"zh": 0.4, "ja": 0.3, "en": 0.3}, []string{"en", "ja"}, "en"},
+		{"unknown fallback languages", map[string]float32{"zh": 0.4, "ja": 0.3}, []string{"xx"}, ""},
+		{"no probabilities", nil, []string{"ja"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickFallbackLanguage(tt.probs, tt.fallback); got != tt.expected {
+				t.Errorf("pickFallbackLanguage() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
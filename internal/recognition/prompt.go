@@ -0,0 +1,39 @@
+package recognition
+
+import (
+	"strings"
+	"time"
+)
+
+// Placeholders recognized in the initial prompt template
+const (
+	PlaceholderDate = "{date}" // Current date as YYYY-MM-DD
+	PlaceholderApp  = "{app}"  // Name of the frontmost application
+)
+
+// PromptContext holds the values substituted into the initial prompt template
+type PromptContext struct {
+	Now time.Time
+	App string // Frontmost application name, "" if unknown
+}
+
+// UsesApp reports whether template needs the frontmost application name, so
+// callers can skip looking it up
+func UsesApp(template string) bool {
+	return strings.Contains(template, PlaceholderApp)
+}
+
+// ExpandPrompt substitutes the placeholders in template. Unknown placeholders
+// are left as they are. The result is trimmed so that an empty {app} at the
+// end does not leave trailing spaces.
+func ExpandPrompt(template string, ctx PromptContext) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	replacer := strings.NewReplacer(
+		PlaceholderDate, ctx.Now.Format("2006-01-02"),
+		PlaceholderApp, ctx.App,
+	)
+	return strings.TrimSpace(replacer.Replace(template))
+}
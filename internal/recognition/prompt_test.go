@@ -0,0 +1,52 @@
+package recognition
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandPrompt(t *testing.T) {
+	ctx := PromptContext{
+		Now: time.Date(2026, 3, 14, 9, 30, 0, 0, time.Local),
+		App: "Code",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"no placeholders", "技術用語を含む会話です。", "技術用語を含む会話です。"},
+		{"date", "{date}の議事録", "2026-03-14の議事録"},
+		{"app", "{app} で入力中。Go, goroutine, struct", "Code で入力中。Go, goroutine, struct"},
+		{"both", "{date} {app}", "2026-03-14 Code"},
+		{"repeated", "{app}/{app}", "Code/Code"},
+		{"unknown placeholder kept", "{user} さん", "{user} さん"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandPrompt(tt.template, ctx); got != tt.expected {
+				t.Errorf("ExpandPrompt(%q) = %q, expected %q", tt.template, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandPrompt_UnknownApp(t *testing.T) {
+	got := ExpandPrompt("日本語の文章です。 {app}", PromptContext{Now: time.Now()})
+
+	if got != "日本語の文章です。" {
+		t.Errorf("Expected trailing space trimmed when the app is unknown, got %q", got)
+	}
+}
+
+func TestUsesApp(t *testing.T) {
+	if !UsesApp("現在のアプリ: {app}") {
+		t.Error("Expected template with {app} to use the app name")
+	}
+	if UsesApp("{date}") {
+		t.Error("Expected template without {app} not to use the app name")
+	}
+}
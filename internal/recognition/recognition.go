@@ -5,15 +5,19 @@ package recognition
 #cgo LDFLAGS: -L${SRCDIR}/../../whisper.cpp/build/src -L${SRCDIR}/../../whisper.cpp/build/ggml/src -lwhisper -lggml -lm -Wl,-rpath,${SRCDIR}/../../whisper.cpp/build/src -Wl,-rpath,${SRCDIR}/../../whisper.cpp/build/ggml/src
 #include "whisper.h"
 #include <stdlib.h>
+extern bool whisperAbortCallback(void *data);
 #pragma GCC diagnostic push
 #pragma GCC diagnostic ignored "-Wdeprecated-declarations"
 */
 import "C"
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -29,19 +33,76 @@ type WhisperRecognizer struct {
 	ctx      *C.struct_whisper_context
 	mu       sync.Mutex
 	language string
+	tuning   Tuning
+	prompt   string // Initial prompt for the decoder, "" for none
+
+	translate bool     // Translate the speech to English instead of transcribing it
+	fallback  []string // Languages auto-detection is limited to when it is unsure, in order of preference
+
+	useGPU       bool      // Let whisper.cpp use the GPU backend (Metal) when loading a model
+	reuseSamples bool      // Keep samples between transcriptions instead of allocating each time
+	samples      []float32 // Scratch buffer for the converted audio, guarded by mu
+
+	silenceThreshold float64 // Amplitude every 100 ms must stay below for audio not to be transcribed, 0 = always transcribe
 }
 
 // Config holds recognition configuration
 type Config struct {
 	Language string // Default: "auto" (automatic language detection)
-	Threads  int    // Number of threads, 0 = auto
+	Threads  int    // Number of threads, 0 = all cores (runtime.NumCPU)
+	Task     Task   // Default: TaskTranscribe
+
+	// InitialPrompt is given to the decoder before each transcription to bias
+	// it toward the vocabulary and spelling it contains, "" for none
+	InitialPrompt string
+
+	// UseGPU lets whisper.cpp run the model on the GPU (Metal). Turning it off
+	// loads the model for the CPU only: slower, but without the GPU backend's
+	// buffers. whisper.cpp has no mmap option; the model file is always read
+	// into memory.
+	UseGPU bool
+
+	// ReuseSamples keeps the float32 sample buffer between transcriptions. It
+	// saves an allocation of 4 bytes per sample on every call, at the cost of
+	// holding the buffer of the longest recording until the model is unloaded.
+	ReuseSamples bool
+
+	// SilenceThreshold is the mean absolute amplitude, as a fraction of full
+	// scale, that every 100 ms of audio must stay below for it to be treated
+	// as silence and transcribed as no text without running whisper. On silence whisper tends to make up phrases
+	// such as "Thank you.". 0 disables the check.
+	SilenceThreshold float64
 }
 
+// TranscribeOptions are the settings of a single TranscribeFull call. They
+// apply to that call only, so concurrent callers never see each other's
+// settings and nothing has to be restored afterwards.
+type TranscribeOptions struct {
+	// Language is the language to recognize the audio as ("auto" for
+	// detection), "" for the one set with SetLanguage
+	Language string
+
+	// Repetition controls hallucination loop detection on the result
+	Repetition RepetitionConfig
+
+	// AdaptiveThreshold chooses the decoding strategy by recording length:
+	// greedy below it, beam search from it on. 0 uses the tuning preset.
+	AdaptiveThreshold time.Duration
+}
+
+// DefaultSilenceThreshold is well below quiet speech but above the noise floor of a typical microphone
+const DefaultSilenceThreshold = 0.005
+
 // DefaultConfig returns the default recognition configuration
 func DefaultConfig() Config {
 	return Config{
-		Language: "auto", // Automatic language detection
-		Threads:  0,      // Auto-detect
+		Language:     "auto", // Automatic language detection
+		Threads:      0,      // All cores
+		Task:         TaskTranscribe,
+		UseGPU:       true,
+		ReuseSamples: true,
+
+		SilenceThreshold: DefaultSilenceThreshold,
 	}
 }
 
@@ -49,9 +110,111 @@ func DefaultConfig() Config {
 func NewWhisperRecognizer(config Config) *WhisperRecognizer {
 	return &WhisperRecognizer{
 		language: config.Language,
+		tuning: Tuning{
+			Threads: config.Threads,
+			Preset:  PresetBalanced,
+		},
+		prompt:       config.InitialPrompt,
+		translate:    config.Task == TaskTranslate,
+		useGPU:       config.UseGPU,
+		reuseSamples: config.ReuseSamples,
+
+		silenceThreshold: config.SilenceThreshold,
 	}
 }
 
+// SetLanguage sets the language used by subsequent transcriptions ("auto" for detection)
+func (r *WhisperRecognizer) SetLanguage(language string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.language = language
+}
+
+// GetLanguage returns the language currently used for transcription
+func (r *WhisperRecognizer) GetLanguage() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.language
+}
+
+// SetTuning sets the thread count and decoding preset used by subsequent transcriptions
+func (r *WhisperRecognizer) SetTuning(tuning Tuning) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tuning = tuning
+}
+
+// SetInitialPrompt sets the initial prompt used by subsequent transcriptions.
+// The prompt biases spelling and vocabulary; "" disables it.
+func (r *WhisperRecognizer) SetInitialPrompt(prompt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prompt = prompt
+}
+
+// GetInitialPrompt returns the initial prompt currently used for transcription
+func (r *WhisperRecognizer) GetInitialPrompt() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.prompt
+}
+
+// SetTask sets whether subsequent transcriptions transcribe the speech or translate it to English
+func (r *WhisperRecognizer) SetTask(task Task) {
+	r.SetTranslate(task == TaskTranslate)
+}
+
+// SetTranslate sets whether subsequent transcriptions translate the speech to English
+func (r *WhisperRecognizer) SetTranslate(translate bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.translate = translate
+}
+
+// SetFallbackLanguages sets the languages, in order of preference, that
+// auto-detection picks from when it is unsure of the spoken language (see
+// pickFallbackLanguage). It has no effect when a language is set; nil disables it.
+func (r *WhisperRecognizer) SetFallbackLanguages(languages []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fallback = slices.Clone(languages)
+}
+
+// SetThreads sets the thread count used by subsequent transcriptions without
+// reloading the model (0 for all cores). Fewer threads leave headroom for other
+// apps at the cost of latency; beyond the performance cores more threads rarely help.
+func (r *WhisperRecognizer) SetThreads(threads int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tuning.Threads = threads
+}
+
+// SetSilenceThreshold sets the amplitude below which subsequent recordings
+// are treated as silence and not transcribed (see Config.SilenceThreshold),
+// 0 to always transcribe
+func (r *WhisperRecognizer) SetSilenceThreshold(threshold float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.silenceThreshold = threshold
+}
+
+// GetTuning returns the thread count and decoding preset currently in use
+func (r *WhisperRecognizer) GetTuning() Tuning {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tuning
+}
+
 // LoadModel loads a Whisper model from the specified path
 func (r *WhisperRecognizer) LoadModel(modelPath string) error {
 	r.mu.Lock()
@@ -67,7 +230,9 @@ func (r *WhisperRecognizer) LoadModel(modelPath string) error {
 	defer C.free(unsafe.Pointer(cModelPath))
 
 	// Load the model
-	ctx := C.whisper_init_from_file(cModelPath)
+	params := C.whisper_context_default_params()
+	params.use_gpu = C.bool(r.useGPU)
+	ctx := C.whisper_init_from_file_with_params(cModelPath, params)
 	if ctx == nil {
 		return fmt.Errorf("failed to load model from: %s", modelPath)
 	}
@@ -81,68 +246,323 @@ func (r *WhisperRecognizer) LoadModel(modelPath string) error {
 	return nil
 }
 
-// Transcribe performs speech recognition on the given audio data
-func (r *WhisperRecognizer) Transcribe(audioData []byte, sampleRate int) (string, error) {
+// selfTestSamples is the length of the silent buffer run by SelfTest (1 second at 16 kHz)
+const selfTestSamples = 16000
+
+// SelfTest runs inference once on a short silent buffer and reports whether the
+// loaded model actually works. A model can load fine and still fail in
+// whisper_full, e.g. when it was converted for an incompatible ggml version.
+func (r *WhisperRecognizer) SelfTest() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if r.ctx == nil {
-		return "", fmt.Errorf("model not loaded")
+		return fmt.Errorf("model not loaded")
+	}
+
+	samples := make([]float32, selfTestSamples)
+
+	// Greedy decoding with a fixed language keeps the run as short as possible
+	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	params.n_threads = C.int(threadCount(r.tuning.Threads))
+
+	cLanguage := C.CString("en")
+	defer C.free(unsafe.Pointer(cLanguage))
+	params.language = cLanguage
+
+	params.translate = C.bool(false)
+	params.no_context = C.bool(true)
+	params.single_segment = C.bool(true)
+	params.print_progress = C.bool(false)
+
+	result := C.whisper_full(
+		r.ctx,
+		params,
+		(*C.float)(unsafe.Pointer(&samples[0])),
+		C.int(len(samples)),
+	)
+	if result != 0 {
+		return fmt.Errorf("inference self-test failed: whisper_full returned code %d", result)
+	}
+
+	return nil
+}
+
+// Transcribe performs speech recognition on the given audio data.
+// It is a convenience wrapper around TranscribeFull that returns only the text,
+// without loop detection.
+func (r *WhisperRecognizer) Transcribe(audioData []byte, sampleRate int) (string, error) {
+	return r.TranscribeContext(context.Background(), audioData, sampleRate)
+}
+
+// TranscribeContext is like Transcribe but aborts inference when ctx is done,
+// returning an error that wraps ctx.Err() (e.g. context.Canceled)
+func (r *WhisperRecognizer) TranscribeContext(ctx context.Context, audioData []byte, sampleRate int) (string, error) {
+	result, err := r.transcribeFull(ctx, audioData, sampleRate, TranscribeOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return result.Text, nil
+}
+
+// TranscribeSegments performs speech recognition and returns the segments in
+// order with their start and end times, e.g. for exporting subtitles
+func (r *WhisperRecognizer) TranscribeSegments(audioData []byte) ([]Segment, error) {
+	segments, _, _, _, err := r.transcribeSegments(context.Background(), audioData, r.GetLanguage(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return segments, nil
+}
+
+// TranscribeFull performs speech recognition with options, runs hallucination
+// loop detection on the resulting segments and returns the text together with
+// segment timing, confidence, the detected language and inference time
+func (r *WhisperRecognizer) TranscribeFull(audioData []byte, sampleRate int, options TranscribeOptions) (Result, error) {
+	return r.transcribeFull(context.Background(), audioData, sampleRate, options)
+}
+
+// TranscribeFloat32 is like TranscribeFull for mono samples normalized to
+// [-1, 1]. whisper consumes them as is, so callers that already hold float32
+// audio skip the round trip through 16-bit PCM.
+func (r *WhisperRecognizer) TranscribeFloat32(samples []float32, sampleRate int, options TranscribeOptions) (Result, error) {
+	language := options.Language
+	if language == "" {
+		language = r.GetLanguage()
+	}
+
+	segments, language, inference, preset, err := r.transcribeSamples(context.Background(), samples, language, options.AdaptiveThreshold)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newTranscribeResult(segments, language, len(samples), sampleRate, inference, preset, options), nil
+}
+
+// transcribeFull implements TranscribeFull until ctx is done
+func (r *WhisperRecognizer) transcribeFull(ctx context.Context, audioData []byte, sampleRate int, options TranscribeOptions) (Result, error) {
+	language := options.Language
+	if language == "" {
+		language = r.GetLanguage()
+	}
+
+	segments, language, inference, preset, err := r.transcribeSegments(ctx, audioData, language, options.AdaptiveThreshold)
+	if err != nil {
+		return Result{}, err
 	}
 
-	if len(audioData) == 0 {
-		return "", fmt.Errorf("audio data is empty")
+	return newTranscribeResult(segments, language, len(audioData)/2, sampleRate, inference, preset, options), nil
+}
+
+// newTranscribeResult builds the Result of transcribing numSamples samples at sampleRate
+func newTranscribeResult(segments []Segment, language string, numSamples, sampleRate int, inference time.Duration, preset Preset, options TranscribeOptions) Result {
+	var durationMS int64
+	if sampleRate > 0 {
+		durationMS = int64(numSamples) * 1000 / int64(sampleRate)
 	}
 
+	result := NewResult(segments, language, durationMS, inference.Milliseconds(), options.Repetition)
+	result.Decoding = preset
+	// Without an error whisper only does not run when the audio is silent
+	result.Silent = preset == ""
+	return result
+}
+
+// transcribeSegments runs whisper inference on 16-bit PCM for language until ctx is done,
+// with adaptive decoding from adaptiveThreshold (0 for the tuning preset), and
+// returns the segments, the detected language, the time spent in inference and
+// the decoding preset used ("" when whisper was skipped because the audio is silent)
+func (r *WhisperRecognizer) transcribeSegments(ctx context.Context, audioData []byte, language string, adaptiveThreshold time.Duration) ([]Segment, string, time.Duration, Preset, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", 0, "", fmt.Errorf("transcription cancelled: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Convert byte array to float32 samples
 	// Assuming audioData is 16-bit PCM (2 bytes per sample)
-	numSamples := len(audioData) / 2
-	samples := make([]float32, numSamples)
+	samples := pcmToFloat32(r.samples, audioData)
+	if r.reuseSamples {
+		r.samples = samples
+	}
+
+	return r.transcribeSamplesLocked(ctx, samples, language, adaptiveThreshold)
+}
 
-	for i := 0; i < numSamples; i++ {
-		// Convert 16-bit PCM to float32 in range [-1.0, 1.0]
-		sample := int16(audioData[i*2]) | (int16(audioData[i*2+1]) << 8)
-		samples[i] = float32(sample) / 32768.0
+// transcribeSamples is transcribeSegments for samples normalized to [-1, 1]
+func (r *WhisperRecognizer) transcribeSamples(ctx context.Context, samples []float32, language string, adaptiveThreshold time.Duration) ([]Segment, string, time.Duration, Preset, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", 0, "", fmt.Errorf("transcription cancelled: %w", err)
 	}
 
-	// Create whisper parameters
-	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	// Set language
-	cLanguage := C.CString(r.language)
-	defer C.free(unsafe.Pointer(cLanguage))
-	params.language = cLanguage
+	return r.transcribeSamplesLocked(ctx, samples, language, adaptiveThreshold)
+}
 
-	// Set task to transcribe (not translate)
-	params.translate = C.bool(false)
+// transcribeSamplesLocked implements transcribeSamples. The caller must hold r.mu.
+func (r *WhisperRecognizer) transcribeSamplesLocked(ctx context.Context, samples []float32, language string, adaptiveThreshold time.Duration) ([]Segment, string, time.Duration, Preset, error) {
+	if r.ctx == nil {
+		return nil, "", 0, "", fmt.Errorf("model not loaded")
+	}
+
+	numSamples := len(samples)
+	if numSamples == 0 {
+		return nil, "", 0, "", fmt.Errorf("audio data is empty")
+	}
+
+	// Skip whisper on silence, where it tends to make up text
+	if isSilent(samples, r.silenceThreshold) {
+		return nil, language, 0, "", nil
+	}
+
+	// Create whisper parameters for the selected preset
+	// (greedy for short recordings and beam search for long ones with adaptive decoding)
+	preset := adaptivePreset(r.tuning.Preset, numSamples, adaptiveThreshold)
+	var params C.struct_whisper_full_params
+	switch preset {
+	case PresetAccurate:
+		params = C.whisper_full_default_params(C.WHISPER_SAMPLING_BEAM_SEARCH)
+		params.beam_search.beam_size = 5
+	case PresetFast:
+		params = C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+		params.greedy.best_of = 1
+	default:
+		params = C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	}
+
+	params.n_threads = C.int(threadCount(r.tuning.Threads))
+
+	// Set language; without one whisper.cpp detects it, unless the fallback
+	// languages decide between the languages the user speaks
+	lang := whisperLanguage(language)
+	if lang == "" && len(r.fallback) > 0 {
+		lang = r.detectLanguage(samples, int(params.n_threads))
+	}
+	params.language = nil
+	if lang != "" {
+		cLanguage := C.CString(lang)
+		defer C.free(unsafe.Pointer(cLanguage))
+		params.language = cLanguage
+	}
+
+	// Set initial prompt; the C string must outlive whisper_full, so it is freed on return
+	if r.prompt != "" {
+		cPrompt := C.CString(r.prompt)
+		defer C.free(unsafe.Pointer(cPrompt))
+		params.initial_prompt = cPrompt
+	}
+
+	// Set task: transcribe, or translate to English
+	params.translate = C.bool(r.translate)
+
+	// Abort inference when ctx is done (context.Background() never is)
+	if ctx.Done() != nil {
+		abortData, freeAbortData := newAbortData(ctx)
+		defer freeAbortData()
+		params.abort_callback = C.ggml_abort_callback(C.whisperAbortCallback)
+		params.abort_callback_user_data = abortData
+	}
 
 	// Run inference
+	start := time.Now()
 	result := C.whisper_full(
 		r.ctx,
 		params,
 		(*C.float)(unsafe.Pointer(&samples[0])),
 		C.int(numSamples),
 	)
+	inference := time.Since(start)
 
 	if result != 0 {
-		return "", fmt.Errorf("whisper_full failed with code: %d", result)
+		if err := ctx.Err(); err != nil {
+			return nil, "", 0, "", fmt.Errorf("transcription cancelled: %w", err)
+		}
+		return nil, "", 0, "", fmt.Errorf("whisper_full failed with code: %d", result)
 	}
 
 	// Get the number of segments
 	nSegments := C.whisper_full_n_segments(r.ctx)
 
-	// Concatenate all segments
-	var transcription string
+	// Collect all segments (whisper timestamps are in 10 ms units)
+	segments := make([]Segment, 0, int(nSegments))
 	for i := 0; i < int(nSegments); i++ {
 		text := C.whisper_full_get_segment_text(r.ctx, C.int(i))
-		transcription += C.GoString(text)
+		segments = append(segments, Segment{
+			Text:       C.GoString(text),
+			StartMS:    int64(C.whisper_full_get_segment_t0(r.ctx, C.int(i))) * 10,
+			EndMS:      int64(C.whisper_full_get_segment_t1(r.ctx, C.int(i))) * 10,
+			Confidence: r.segmentConfidence(i),
+		})
+	}
+
+	// Report the detected language when auto-detection was used
+	if langID := C.whisper_full_lang_id(r.ctx); langID >= 0 {
+		language = C.GoString(C.whisper_lang_str(langID))
 	}
 
-	return transcription, nil
+	return segments, language, inference, preset, nil
+}
+
+// detectLanguage runs whisper's language detection on the start of samples and
+// returns the language to transcribe as given the fallback languages, or "" to
+// leave detection to whisper_full. The caller must hold r.mu.
+func (r *WhisperRecognizer) detectLanguage(samples []float32, threads int) string {
+	// &samples[0] below would panic on an empty recording
+	if len(samples) == 0 {
+		return ""
+	}
+	if C.whisper_pcm_to_mel(r.ctx, (*C.float)(unsafe.Pointer(&samples[0])), C.int(len(samples)), C.int(threads)) != 0 {
+		return ""
+	}
+
+	probs := make([]float32, int(C.whisper_lang_max_id())+1)
+	if C.whisper_lang_auto_detect(r.ctx, 0, C.int(threads), (*C.float)(unsafe.Pointer(&probs[0]))) < 0 {
+		return ""
+	}
+
+	byLanguage := make(map[string]float32, len(probs))
+	for id, p := range probs {
+		byLanguage[C.GoString(C.whisper_lang_str(C.int(id)))] = p
+	}
+	return pickFallbackLanguage(byLanguage, r.fallback)
+}
+
+// segmentConfidence returns the mean probability of the text tokens in a segment,
+// ignoring special tokens (timestamps, end of text, ...). The caller must hold r.mu.
+func (r *WhisperRecognizer) segmentConfidence(segment int) float64 {
+	eot := C.whisper_token_eot(r.ctx)
+	nTokens := int(C.whisper_full_n_tokens(r.ctx, C.int(segment)))
+
+	var sum float64
+	count := 0
+	for j := 0; j < nTokens; j++ {
+		if C.whisper_full_get_token_id(r.ctx, C.int(segment), C.int(j)) >= eot {
+			continue
+		}
+		sum += float64(C.whisper_full_get_token_p(r.ctx, C.int(segment), C.int(j)))
+		count++
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
 }
 
 // Close releases resources
 func (r *WhisperRecognizer) Close() error {
+	r.Unload()
+	return nil
+}
+
+// Unload frees the loaded model to release its memory. Language, tuning and
+// prompt are kept, so a later LoadModel continues with the same settings.
+func (r *WhisperRecognizer) Unload() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -150,8 +570,7 @@ func (r *WhisperRecognizer) Close() error {
 		C.whisper_free(r.ctx)
 		r.ctx = nil
 	}
-
-	return nil
+	r.samples = nil
 }
 
 // GetDefaultModelPath returns the default path for Whisper models
@@ -1,6 +1,8 @@
 package recognition
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -16,6 +18,22 @@ func TestDefaultConfig(t *testing.T) {
 	if config.Threads != 0 {
 		t.Errorf("Expected default threads 0 (auto), got %d", config.Threads)
 	}
+
+	if config.Task != TaskTranscribe {
+		t.Errorf("Expected default task transcribe, got %q", config.Task)
+	}
+
+	if !config.UseGPU {
+		t.Error("Expected the GPU to be used by default")
+	}
+
+	if !config.ReuseSamples {
+		t.Error("Expected the sample buffer to be reused by default")
+	}
+
+	if config.SilenceThreshold != DefaultSilenceThreshold {
+		t.Errorf("Expected silence threshold %v, got %v", DefaultSilenceThreshold, config.SilenceThreshold)
+	}
 }
 
 func TestNewWhisperRecognizer(t *testing.T) {
@@ -31,6 +49,63 @@ func TestNewWhisperRecognizer(t *testing.T) {
 	}
 }
 
+func TestNewWhisperRecognizer_Threads(t *testing.T) {
+	config := DefaultConfig()
+	config.Threads = 2
+	recognizer := NewWhisperRecognizer(config)
+
+	if threads := recognizer.GetTuning().Threads; threads != 2 {
+		t.Errorf("Expected 2 threads, got %d", threads)
+	}
+
+	// The thread count can change without reloading the model
+	recognizer.SetThreads(4)
+	if threads := recognizer.GetTuning().Threads; threads != 4 {
+		t.Errorf("Expected 4 threads after SetThreads, got %d", threads)
+	}
+}
+
+func TestNewWhisperRecognizer_InitialPrompt(t *testing.T) {
+	config := DefaultConfig()
+	config.InitialPrompt = "EzS2T-Whisper, whisper.cpp, Metal"
+	recognizer := NewWhisperRecognizer(config)
+
+	if prompt := recognizer.GetInitialPrompt(); prompt != config.InitialPrompt {
+		t.Errorf("Expected prompt %q, got %q", config.InitialPrompt, prompt)
+	}
+
+	// The prompt can change between transcriptions
+	recognizer.SetInitialPrompt("")
+	if prompt := recognizer.GetInitialPrompt(); prompt != "" {
+		t.Errorf("Expected no prompt after SetInitialPrompt(\"\"), got %q", prompt)
+	}
+}
+
+func TestNewWhisperRecognizer_Task(t *testing.T) {
+	tests := []struct {
+		task      Task
+		translate bool
+	}{
+		{TaskTranscribe, false},
+		{TaskTranslate, true},
+	}
+
+	for _, tt := range tests {
+		config := DefaultConfig()
+		config.Task = tt.task
+		if recognizer := NewWhisperRecognizer(config); recognizer.translate != tt.translate {
+			t.Errorf("%s: expected translate=%v, got %v", tt.task, tt.translate, recognizer.translate)
+		}
+
+		// SetTask switches an existing recognizer the same way
+		recognizer := NewWhisperRecognizer(DefaultConfig())
+		recognizer.SetTask(tt.task)
+		if recognizer.translate != tt.translate {
+			t.Errorf("SetTask(%s): expected translate=%v, got %v", tt.task, tt.translate, recognizer.translate)
+		}
+	}
+}
+
 func TestGetDefaultModelPath(t *testing.T) {
 	modelPath := GetDefaultModelPath()
 
@@ -101,6 +176,56 @@ func TestTranscribe_EmptyAudio(t *testing.T) {
 	}
 }
 
+func TestTranscribeContext_Cancelled(t *testing.T) {
+	config := DefaultConfig()
+	recognizer := NewWhisperRecognizer(config)
+	defer recognizer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := recognizer.TranscribeContext(ctx, make([]byte, 1000), 16000)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTranscribeSegments_ModelNotLoaded(t *testing.T) {
+	config := DefaultConfig()
+	recognizer := NewWhisperRecognizer(config)
+	defer recognizer.Close()
+
+	segments, err := recognizer.TranscribeSegments(make([]byte, 1000))
+	if err == nil || segments != nil {
+		t.Errorf("Expected error and no segments when model not loaded, got %v (err=%v)", segments, err)
+	}
+}
+
+func TestTranscribeFull_LanguageKeepsDefault(t *testing.T) {
+	config := DefaultConfig()
+	config.Language = "ja"
+	recognizer := NewWhisperRecognizer(config)
+	defer recognizer.Close()
+
+	if _, err := recognizer.TranscribeFull(make([]byte, 1000), 16000, TranscribeOptions{Language: "en"}); err == nil {
+		t.Error("Expected error when model not loaded, got nil")
+	}
+
+	// The per-call language must not replace the configured one
+	if recognizer.GetLanguage() != "ja" {
+		t.Errorf("Expected language 'ja', got '%s'", recognizer.GetLanguage())
+	}
+}
+
+func TestTranscribeFloat32_WithoutModel(t *testing.T) {
+	recognizer := NewWhisperRecognizer(DefaultConfig())
+	defer recognizer.Close()
+
+	if _, err := recognizer.TranscribeFloat32(make([]float32, 500), 16000, TranscribeOptions{}); err == nil {
+		t.Error("Expected error when model not loaded, got nil")
+	}
+}
+
 func TestClose_WithoutModel(t *testing.T) {
 	config := DefaultConfig()
 	recognizer := NewWhisperRecognizer(config)
@@ -0,0 +1,159 @@
+package recognition
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+)
+
+// minPhraseRunes is the shortest phrase considered for in-segment loop detection.
+// Shorter units (e.g. "ゆら", "はは") are too often legitimate repetition.
+const minPhraseRunes = 4
+
+// RepetitionConfig controls hallucination loop detection
+type RepetitionConfig struct {
+	MaxRepeats          int     // Consecutive repeats of the same phrase treated as a loop, 0 = disabled
+	MaxCompressionRatio float64 // gzip compression ratio above which output is suspect, 0 = disabled
+	DedupeSegments      bool    // Collapse adjacent identical or near-identical segments before joining
+}
+
+// DefaultRepetitionConfig returns the default loop detection thresholds
+func DefaultRepetitionConfig() RepetitionConfig {
+	return RepetitionConfig{
+		MaxRepeats:          4,
+		MaxCompressionRatio: 2.4,   // Same threshold Whisper uses for its temperature fallback
+		DedupeSegments:      false, // Would also merge intentionally repeated lines
+	}
+}
+
+// RepetitionResult holds the outcome of hallucination loop detection
+type RepetitionResult struct {
+	Text             string  // Output with repeated phrases truncated to their first occurrence
+	Suspect          bool    // True when the output looks like a repetition loop
+	CompressionRatio float64 // gzip compression ratio of the original output
+}
+
+// DetectRepetition checks transcription segments for hallucination loops.
+// Runs of identical consecutive segments and phrases repeated within a segment
+// are collapsed to their first occurrence. A high compression ratio marks the
+// output as suspect even when no exact repetition is found. When DedupeSegments
+// is set, remaining adjacent duplicate segments are also removed.
+func DetectRepetition(segments []string, config RepetitionConfig) RepetitionResult {
+	original := strings.Join(segments, "")
+	result := RepetitionResult{
+		Text:             original,
+		CompressionRatio: compressionRatio(original),
+	}
+
+	if config.MaxRepeats > 1 {
+		collapsed, found := collapseSegments(segments, config.MaxRepeats)
+		for i, segment := range collapsed {
+			text, phraseFound := collapsePhrases(segment, config.MaxRepeats)
+			collapsed[i] = text
+			found = found || phraseFound
+		}
+		if found {
+			segments = collapsed
+			result.Suspect = true
+		}
+	}
+
+	// Occasional verbatim repeats of a segment are a common artifact rather than a loop,
+	// so they are removed without marking the output as suspect
+	if config.DedupeSegments {
+		segments = DedupeSegments(segments)
+	}
+	result.Text = strings.Join(segments, "")
+
+	if config.MaxCompressionRatio > 0 && result.CompressionRatio > config.MaxCompressionRatio {
+		result.Suspect = true
+	}
+
+	return result
+}
+
+// collapseSegments replaces runs of at least maxRepeats identical segments with a single one
+func collapseSegments(segments []string, maxRepeats int) ([]string, bool) {
+	collapsed := make([]string, 0, len(segments))
+	found := false
+
+	for i := 0; i < len(segments); {
+		key := strings.TrimSpace(segments[i])
+		j := i + 1
+		for j < len(segments) && strings.TrimSpace(segments[j]) == key {
+			j++
+		}
+
+		if key != "" && j-i >= maxRepeats {
+			collapsed = append(collapsed, segments[i])
+			found = true
+		} else {
+			collapsed = append(collapsed, segments[i:j]...)
+		}
+		i = j
+	}
+
+	return collapsed, found
+}
+
+// collapsePhrases replaces a phrase repeated at least maxRepeats times in a row with a single one
+func collapsePhrases(text string, maxRepeats int) (string, bool) {
+	runes := []rune(text)
+	var out []rune
+	found := false
+
+	for i := 0; i < len(runes); {
+		repeated := false
+
+		// Prefer the shortest unit so "AB AB AB" collapses to "AB " rather than a longer multiple
+		for size := minPhraseRunes; i+size*maxRepeats <= len(runes); size++ {
+			count := 1
+			for i+(count+1)*size <= len(runes) && equalRunes(runes[i:i+size], runes[i+count*size:i+(count+1)*size]) {
+				count++
+			}
+
+			if count >= maxRepeats && strings.TrimSpace(string(runes[i:i+size])) != "" {
+				out = append(out, runes[i:i+size]...)
+				i += count * size
+				repeated = true
+				found = true
+				break
+			}
+		}
+
+		if !repeated {
+			out = append(out, runes[i])
+			i++
+		}
+	}
+
+	return string(out), found
+}
+
+// equalRunes reports whether two rune slices of the same length are equal
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compressionRatio returns len(text) / len(gzip(text)); repetitive text compresses well
+func compressionRatio(text string) float64 {
+	if text == "" {
+		return 0
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(text)); err != nil {
+		return 0
+	}
+	if err := zw.Close(); err != nil {
+		return 0
+	}
+
+	return float64(len(text)) / float64(buf.Len())
+}
@@ -0,0 +1,110 @@
+package recognition
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectRepetition_ConsecutiveSegments(t *testing.T) {
+	segments := []string{"今日の会議を始めます。"}
+	for i := 0; i < 15; i++ {
+		segments = append(segments, "ご視聴ありがとうございました。")
+	}
+
+	result := DetectRepetition(segments, DefaultRepetitionConfig())
+
+	if !result.Suspect {
+		t.Error("Expected repeated segments to be flagged as suspect")
+	}
+
+	expected := "今日の会議を始めます。ご視聴ありがとうございました。"
+	if result.Text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result.Text)
+	}
+}
+
+func TestDetectRepetition_RepeatedPhraseInSegment(t *testing.T) {
+	segments := []string{"よろしくお願いします。" + strings.Repeat("ありがとうございました。", 10)}
+
+	result := DetectRepetition(segments, DefaultRepetitionConfig())
+
+	if !result.Suspect {
+		t.Error("Expected repeated phrase to be flagged as suspect")
+	}
+
+	expected := "よろしくお願いします。ありがとうございました。"
+	if result.Text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result.Text)
+	}
+}
+
+func TestDetectRepetition_LegitimateRepetition(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []string
+	}{
+		{
+			name:     "poetry refrain",
+			segments: []string{"さくら さくら やよいの空は", "見わたす限り", "かすみか雲か 匂いぞ出ずる"},
+		},
+		{
+			name:     "repeated line",
+			segments: []string{"雨が降る。", "雨が降る。", "静かな夜に。"},
+		},
+		{
+			name:     "counting",
+			segments: []string{"one, two, three, four, five, six, seven, eight, nine, ten, eleven, twelve"},
+		},
+		{
+			name:     "onomatopoeia",
+			segments: []string{"星がきらきらきらきら光っている。"},
+		},
+		{
+			name:     "short affirmation",
+			segments: []string{"はい、はい、はい、わかりました。"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DetectRepetition(tt.segments, DefaultRepetitionConfig())
+
+			if result.Suspect {
+				t.Errorf("Expected no detection, got suspect (ratio %.2f)", result.CompressionRatio)
+			}
+
+			expected := strings.Join(tt.segments, "")
+			if result.Text != expected {
+				t.Errorf("Expected text to be unchanged, got '%s'", result.Text)
+			}
+		})
+	}
+}
+
+func TestDetectRepetition_CompressionRatio(t *testing.T) {
+	// Two-phrase loop alternating in separate segments escapes exact-run detection
+	var segments []string
+	for i := 0; i < 20; i++ {
+		segments = append(segments, "字幕は自動生成されています。", "チャンネル登録お願いします。")
+	}
+
+	result := DetectRepetition(segments, RepetitionConfig{MaxCompressionRatio: 2.4})
+
+	if !result.Suspect {
+		t.Errorf("Expected high compression ratio to be flagged, got %.2f", result.CompressionRatio)
+	}
+}
+
+func TestDetectRepetition_Disabled(t *testing.T) {
+	segments := []string{strings.Repeat("ありがとうございました。", 10)}
+
+	result := DetectRepetition(segments, RepetitionConfig{})
+
+	if result.Suspect {
+		t.Error("Expected no detection when thresholds are disabled")
+	}
+
+	if result.Text != segments[0] {
+		t.Error("Expected text to be unchanged when detection is disabled")
+	}
+}
@@ -0,0 +1,75 @@
+package recognition
+
+import "strings"
+
+// Segment is one timed piece of a transcription as produced by the model
+type Segment struct {
+	Text       string  `json:"text"`
+	StartMS    int64   `json:"start_ms"`   // Offset from the start of the audio
+	EndMS      int64   `json:"end_ms"`     // Offset from the start of the audio
+	Confidence float64 `json:"confidence"` // Mean probability of the segment's text tokens (0-1)
+}
+
+// Result is the full outcome of one transcription. It is passed unchanged from
+// the recognizer to everything that consumes a transcription (pasting, logging,
+// notifications) so each consumer sees the same data.
+type Result struct {
+	Text             string    `json:"text"`              // Final text after loop detection and de-duplication
+	Language         string    `json:"language"`          // Detected language code, or the configured one
+	Segments         []Segment `json:"segments"`          // Raw model segments before any post-processing
+	DurationMS       int64     `json:"duration_ms"`       // Length of the transcribed audio
+	InferenceMS      int64     `json:"inference_ms"`      // Time spent running the model
+	AvgConfidence    float64   `json:"avg_confidence"`    // Mean segment confidence weighted by segment length
+	Suspect          bool      `json:"suspect"`           // True when the output looks like a repetition loop
+	CompressionRatio float64   `json:"compression_ratio"` // gzip compression ratio of the raw output
+	Decoding         Preset    `json:"decoding"`          // Decoding preset whisper ran with, "" if it did not run
+	Silent           bool      `json:"silent"`            // True when whisper was skipped because the audio is below the silence threshold
+}
+
+// NewResult builds a Result from raw segments, applying hallucination loop
+// detection to produce the final text
+func NewResult(segments []Segment, language string, durationMS, inferenceMS int64, repetition RepetitionConfig) Result {
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	detected := DetectRepetition(texts, repetition)
+
+	return Result{
+		Text:             detected.Text,
+		Language:         language,
+		Segments:         segments,
+		DurationMS:       durationMS,
+		InferenceMS:      inferenceMS,
+		AvgConfidence:    averageConfidence(segments),
+		Suspect:          detected.Suspect,
+		CompressionRatio: detected.CompressionRatio,
+	}
+}
+
+// StripLeadingSpace removes the single space Whisper puts before the first
+// segment. Only one ASCII space is removed; other whitespace is kept.
+func StripLeadingSpace(text string) string {
+	return strings.TrimPrefix(text, " ")
+}
+
+// averageConfidence weights each segment's confidence by its duration, falling
+// back to a plain mean when the segments carry no timing
+func averageConfidence(segments []Segment) float64 {
+	if len(segments) == 0 {
+		return 0
+	}
+
+	var weighted, totalMS, sum float64
+	for _, segment := range segments {
+		length := float64(max(segment.EndMS-segment.StartMS, 0))
+		weighted += segment.Confidence * length
+		totalMS += length
+		sum += segment.Confidence
+	}
+
+	if totalMS == 0 {
+		return sum / float64(len(segments))
+	}
+	return weighted / totalMS
+}
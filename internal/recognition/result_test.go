@@ -0,0 +1,104 @@
+package recognition
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewResult(t *testing.T) {
+	segments := []Segment{
+		{Text: "こんにちは、", StartMS: 0, EndMS: 1000, Confidence: 0.9},
+		{Text: "世界。", StartMS: 1000, EndMS: 4000, Confidence: 0.5},
+	}
+
+	result := NewResult(segments, "ja", 4200, 350, DefaultRepetitionConfig())
+
+	if result.Text != "こんにちは、世界。" {
+		t.Errorf("Expected joined text, got %q", result.Text)
+	}
+	if result.Language != "ja" || result.DurationMS != 4200 || result.InferenceMS != 350 {
+		t.Errorf("Unexpected metadata: %+v", result)
+	}
+	if len(result.Segments) != 2 {
+		t.Errorf("Expected raw segments to be kept, got %d", len(result.Segments))
+	}
+
+	// (0.9*1000 + 0.5*3000) / 4000
+	if math.Abs(result.AvgConfidence-0.6) > 1e-9 {
+		t.Errorf("Expected duration-weighted confidence 0.6, got %f", result.AvgConfidence)
+	}
+	if result.Suspect {
+		t.Error("Expected normal output not to be suspect")
+	}
+}
+
+func TestNewResult_SegmentOrder(t *testing.T) {
+	segments := []Segment{
+		{Text: " 一つ目。", StartMS: 0, EndMS: 1500},
+		{Text: "二つ目。", StartMS: 1500, EndMS: 2800},
+		{Text: "三つ目。", StartMS: 2800, EndMS: 4100},
+	}
+
+	result := NewResult(segments, "ja", 4100, 100, DefaultRepetitionConfig())
+
+	if result.Text != " 一つ目。二つ目。三つ目。" {
+		t.Errorf("Expected the text joined in segment order, got %q", result.Text)
+	}
+	for i, segment := range result.Segments {
+		if segment != segments[i] {
+			t.Errorf("Segment %d: expected %+v, got %+v", i, segments[i], segment)
+		}
+	}
+}
+
+func TestNewResult_RepetitionLoop(t *testing.T) {
+	segments := make([]Segment, 6)
+	for i := range segments {
+		segments[i] = Segment{Text: "ご視聴ありがとうございました。", Confidence: 0.4}
+	}
+
+	result := NewResult(segments, "ja", 0, 0, DefaultRepetitionConfig())
+
+	if !result.Suspect {
+		t.Error("Expected repeated segments to be marked suspect")
+	}
+	if result.Text != "ご視聴ありがとうございました。" {
+		t.Errorf("Expected loop collapsed to one occurrence, got %q", result.Text)
+	}
+	if len(result.Segments) != 6 {
+		t.Errorf("Expected raw segments to be kept, got %d", len(result.Segments))
+	}
+
+	// No timing: plain mean
+	if math.Abs(result.AvgConfidence-0.4) > 1e-9 {
+		t.Errorf("Expected confidence 0.4, got %f", result.AvgConfidence)
+	}
+}
+
+func TestNewResult_Empty(t *testing.T) {
+	result := NewResult(nil, "en", 1000, 10, DefaultRepetitionConfig())
+
+	if result.Text != "" || result.AvgConfidence != 0 {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+}
+
+func TestStripLeadingSpace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{" Hello", "Hello"},
+		{"Hello", "Hello"},
+		{"  Hello", " Hello"}, // Only one space is removed
+		{" Hello world", "Hello world"},
+		{"\tHello", "\tHello"}, // Other whitespace is kept
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := StripLeadingSpace(tt.input); got != tt.expected {
+			t.Errorf("StripLeadingSpace(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package recognition
+
+import "math"
+
+// pcmToFloat32 converts 16-bit little-endian PCM to float32 samples in
+// [-1.0, 1.0] for whisper. The samples are written to buf, which is grown
+// only when it is too small, so a buffer kept between calls avoids
+// allocating for every transcription. The returned slice has one sample per
+// two bytes of pcm.
+func pcmToFloat32(buf []float32, pcm []byte) []float32 {
+	numSamples := len(pcm) / 2
+	if cap(buf) < numSamples {
+		buf = make([]float32, numSamples)
+	}
+	samples := buf[:numSamples]
+
+	for i := range samples {
+		sample := int16(pcm[i*2]) | (int16(pcm[i*2+1]) << 8)
+		samples[i] = float32(sample) / 32768.0
+	}
+	return samples
+}
+
+// silenceWindow is the number of samples (100 ms at 16 kHz) isSilent
+// averages over, about the length of a short syllable
+const silenceWindow = 1600
+
+// isSilent reports whether the mean absolute amplitude of every window of
+// silenceWindow samples is below threshold, a fraction of full scale. Measuring
+// each window instead of the whole recording keeps a short word surrounded by
+// seconds of silence from being averaged away. A threshold of 0 never reports
+// silence.
+func isSilent(samples []float32, threshold float64) bool {
+	if threshold <= 0 || len(samples) == 0 {
+		return false
+	}
+
+	for start := 0; start < len(samples); start += silenceWindow {
+		window := samples[start:min(start+silenceWindow, len(samples))]
+		var sum float64
+		for _, sample := range window {
+			sum += math.Abs(float64(sample))
+		}
+		if sum/float64(len(window)) >= threshold {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package recognition
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPCMToFloat32(t *testing.T) {
+	// 0, 16384 (0.5), -32768 (-1.0) and a trailing odd byte
+	pcm := []byte{0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x7f}
+
+	samples := pcmToFloat32(nil, pcm)
+	expected := []float32{0, 0.5, -1}
+	if len(samples) != len(expected) {
+		t.Fatalf("Expected %d samples, got %d", len(expected), len(samples))
+	}
+	for i := range expected {
+		if samples[i] != expected[i] {
+			t.Errorf("Sample %d: expected %v, got %v", i, expected[i], samples[i])
+		}
+	}
+
+	// A large enough buffer is reused and shortened to the new recording
+	reused := pcmToFloat32(samples, pcm[:2])
+	if len(reused) != 1 || &reused[0] != &samples[0] {
+		t.Errorf("Expected the buffer to be reused, got %d samples", len(reused))
+	}
+}
+
+func TestIsSilent(t *testing.T) {
+	silence := make([]float32, 16000)
+	if !isSilent(silence, DefaultSilenceThreshold) {
+		t.Error("Expected a zero buffer to be silent")
+	}
+
+	// One second of a 440 Hz sine at half of full scale
+	sine := make([]float32, 16000)
+	for i := range sine {
+		sine[i] = float32(0.5 * math.Sin(2*math.Pi*440*float64(i)/16000))
+	}
+	if isSilent(sine, DefaultSilenceThreshold) {
+		t.Error("Expected a loud sine wave not to be silent")
+	}
+
+	// A 100 ms word in five seconds of silence is speech, although the
+	// mean over the whole recording is below the threshold
+	word := make([]float32, 5*16000)
+	copy(word[40000:], sine[:1600])
+	if isSilent(word, DefaultSilenceThreshold) {
+		t.Error("Expected a short word in long silence not to be silent")
+	}
+
+	// A threshold of 0 disables the check
+	if isSilent(silence, 0) {
+		t.Error("Expected no silence with the check disabled")
+	}
+}
+
+func BenchmarkPCMToFloat32(b *testing.B) {
+	// Ten seconds of 16 kHz audio, a typical dictation
+	pcm := make([]byte, 10*16000*2)
+
+	b.Run("alloc", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			pcmToFloat32(nil, pcm)
+		}
+	})
+
+	b.Run("reuse", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []float32
+		for b.Loop() {
+			buf = pcmToFloat32(buf, pcm)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package recognition
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// StreamConfig controls how often a stream re-runs recognition and over how much audio
+type StreamConfig struct {
+	Interval time.Duration // New audio needed before the next run
+	Window   time.Duration // Longest audio recognized per run, taken from the end of the recording
+}
+
+// DefaultStreamConfig returns settings that keep each run short enough for a live preview
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		Interval: 2 * time.Second,
+		Window:   30 * time.Second,
+	}
+}
+
+// Partial is the transcription of the end of the audio received so far.
+// It is a preview only; the final text comes from transcribing the whole recording.
+type Partial struct {
+	Text     string    `json:"text"`
+	Segments []Segment `json:"segments"` // Timing is relative to the start of the window
+}
+
+// TranscribeStream transcribes 16-bit PCM chunks at sampleRate while they
+// arrive and sends a Partial each time enough new audio has been received.
+// The returned channel is closed after chunks is closed and the last run ended,
+// or as soon as ctx is done. Cancelling ctx aborts the run in progress and
+// drops the chunks that were not transcribed yet.
+func (r *WhisperRecognizer) TranscribeStream(ctx context.Context, sampleRate int, chunks <-chan []byte) <-chan Partial {
+	out := make(chan Partial, 1)
+
+	go func() {
+		defer close(out)
+
+		runStream(ctx, chunks, sampleRate, DefaultStreamConfig(), func(ctx context.Context, audioData []byte) ([]Segment, error) {
+			// Partial results use the tuning preset: they are redone as audio arrives
+			segments, _, _, _, err := r.transcribeSegments(ctx, audioData, r.GetLanguage(), 0)
+			return segments, err
+		}, out)
+	}()
+
+	return out
+}
+
+// runStream collects chunks and, each time config.Interval of new audio has
+// arrived, transcribes the last config.Window of it and sends the result to out.
+// Failed runs are skipped. It returns when chunks is closed or ctx is done,
+// without transcribing the chunks still buffered.
+func runStream(ctx context.Context, chunks <-chan []byte, sampleRate int, config StreamConfig, transcribe func(ctx context.Context, audioData []byte) ([]Segment, error), out chan<- Partial) {
+	interval := pcmLength(config.Interval, sampleRate)
+	window := pcmLength(config.Window, sampleRate)
+
+	var audioData []byte
+	pending := 0
+	for {
+		var chunk []byte
+		select {
+		case <-ctx.Done():
+			return
+		case c, ok := <-chunks:
+			if !ok {
+				return
+			}
+			chunk = c
+		}
+		// A cancelled stream may still have chunks buffered; the select picks either case
+		if ctx.Err() != nil {
+			return
+		}
+
+		audioData = append(audioData, chunk...)
+		pending += len(chunk)
+		if pending < interval {
+			continue
+		}
+		pending = 0
+
+		// Keep only the window so memory and inference time do not grow with the recording
+		if len(audioData) > window {
+			audioData = append(audioData[:0], audioData[len(audioData)-window:]...)
+		}
+
+		segments, err := transcribe(ctx, audioData)
+		if err != nil {
+			continue
+		}
+
+		texts := make([]string, len(segments))
+		for i, segment := range segments {
+			texts[i] = segment.Text
+		}
+		select {
+		case out <- Partial{Text: strings.TrimSpace(strings.Join(texts, "")), Segments: segments}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pcmLength returns the number of bytes of 16-bit mono PCM covering d at sampleRate
+func pcmLength(d time.Duration, sampleRate int) int {
+	return int(d.Seconds()*float64(sampleRate)) * 2
+}
@@ -0,0 +1,124 @@
+package recognition
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunStream(t *testing.T) {
+	// At 100 samples/s one second of audio is 200 bytes
+	config := StreamConfig{Interval: time.Second, Window: 2 * time.Second}
+
+	chunks := make(chan []byte, 10)
+	for range 5 {
+		chunks <- make([]byte, 100) // 0.5s each
+	}
+	close(chunks)
+
+	var lengths []int
+	transcribe := func(ctx context.Context, audioData []byte) ([]Segment, error) {
+		lengths = append(lengths, len(audioData))
+		return []Segment{{Text: " こんにちは"}, {Text: "世界"}}, nil
+	}
+
+	out := make(chan Partial, 10)
+	runStream(context.Background(), chunks, 100, config, transcribe, out)
+	close(out)
+
+	// Runs after 1s and 2s of audio; the last 0.5s is not enough for another run
+	if len(lengths) != 2 || lengths[0] != 200 || lengths[1] != 400 {
+		t.Errorf("Expected runs over 200 and 400 bytes, got %v", lengths)
+	}
+
+	var partials []Partial
+	for partial := range out {
+		partials = append(partials, partial)
+	}
+	if len(partials) != 2 || partials[0].Text != "こんにちは世界" {
+		t.Errorf("Unexpected partials %+v", partials)
+	}
+}
+
+func TestRunStream_Window(t *testing.T) {
+	config := StreamConfig{Interval: time.Second, Window: 2 * time.Second}
+
+	chunks := make(chan []byte, 10)
+	for i := range 3 {
+		chunks <- []byte{byte(i), byte(i)}
+	}
+	close(chunks)
+
+	var last []byte
+	transcribe := func(ctx context.Context, audioData []byte) ([]Segment, error) {
+		last = append([]byte(nil), audioData...)
+		return nil, nil
+	}
+
+	// 1 sample/s: a run after every chunk, keeping the last 2 samples
+	runStream(context.Background(), chunks, 1, config, transcribe, make(chan Partial, 10))
+
+	if string(last) != string([]byte{1, 1, 2, 2}) {
+		t.Errorf("Expected the window to keep the newest audio, got %v", last)
+	}
+}
+
+func TestRunStream_SkipsFailedRuns(t *testing.T) {
+	config := StreamConfig{Interval: time.Second, Window: time.Second}
+
+	chunks := make(chan []byte, 2)
+	chunks <- make([]byte, 2)
+	chunks <- make([]byte, 2)
+	close(chunks)
+
+	calls := 0
+	transcribe := func(ctx context.Context, audioData []byte) ([]Segment, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("model not loaded")
+		}
+		return []Segment{{Text: "ok"}}, nil
+	}
+
+	out := make(chan Partial, 2)
+	runStream(context.Background(), chunks, 1, config, transcribe, out)
+	close(out)
+
+	var partials []Partial
+	for partial := range out {
+		partials = append(partials, partial)
+	}
+	if len(partials) != 1 || partials[0].Text != "ok" {
+		t.Errorf("Expected only the successful run to be sent, got %+v", partials)
+	}
+}
+
+func TestRunStream_Cancel(t *testing.T) {
+	config := StreamConfig{Interval: time.Second, Window: time.Second}
+
+	chunks := make(chan []byte, 3)
+	for range 3 {
+		chunks <- make([]byte, 2)
+	}
+
+	// Stopping the recording during the first run aborts it and drops the rest
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	transcribe := func(ctx context.Context, audioData []byte) ([]Segment, error) {
+		calls++
+		cancel()
+		return nil, ctx.Err()
+	}
+
+	out := make(chan Partial, 3)
+	runStream(ctx, chunks, 1, config, transcribe, out)
+	close(out)
+
+	if calls != 1 {
+		t.Errorf("Expected the buffered chunks to be dropped after cancelling, got %d runs", calls)
+	}
+	if len(out) != 0 {
+		t.Errorf("Expected no partial from the aborted run, got %d", len(out))
+	}
+}
@@ -0,0 +1,25 @@
+package recognition
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// DetectSystem returns the performance core count and physical memory of this Mac
+func DetectSystem() SystemInfo {
+	info := SystemInfo{
+		Cores: runtime.NumCPU(),
+	}
+
+	// Apple Silicon: efficiency cores slow down whisper inference, so count performance cores only
+	if cores, err := unix.SysctlUint32("hw.perflevel0.physicalcpu"); err == nil && cores > 0 {
+		info.Cores = int(cores)
+	}
+
+	if memory, err := unix.SysctlUint64("hw.memsize"); err == nil {
+		info.MemoryBytes = memory
+	}
+
+	return info
+}
@@ -0,0 +1,151 @@
+package recognition
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/yok-tottii/EzS2T-Whisper/internal/modelinfo"
+)
+
+// Task selects whether whisper.cpp keeps the spoken language or translates it
+type Task string
+
+const (
+	// TaskTranscribe outputs the text in the spoken language
+	TaskTranscribe Task = "transcribe"
+	// TaskTranslate outputs the speech translated to English
+	TaskTranslate Task = "translate"
+)
+
+// Preset selects the decoding strategy used for inference
+type Preset string
+
+const (
+	// PresetFast uses greedy decoding with a single candidate
+	PresetFast Preset = "fast"
+	// PresetBalanced uses the whisper.cpp greedy defaults
+	PresetBalanced Preset = "balanced"
+	// PresetAccurate uses beam search
+	PresetAccurate Preset = "accurate"
+)
+
+// whisperSampleRate is the sample rate of the audio given to whisper.cpp
+const whisperSampleRate = 16000
+
+// memoryWarningFraction is the share of physical memory above which a model is considered too large
+const memoryWarningFraction = 0.25
+
+// maxUsefulThreads is the thread count beyond which a model class stops getting faster
+var maxUsefulThreads = map[string]int{
+	"tiny":   4,
+	"base":   4,
+	"small":  6,
+	"medium": 8,
+	"large":  8,
+}
+
+// ModelInfo describes a model file for tuning purposes
+type ModelInfo struct {
+	Class        string // "tiny", "base", "small", "medium", "large"
+	Quantization string // e.g. "q5_0", "f16", empty if unknown
+	SizeBytes    int64
+}
+
+// SystemInfo describes the host resources used for tuning
+type SystemInfo struct {
+	Cores       int    // Performance cores available for inference
+	MemoryBytes uint64 // Physical memory, 0 if unknown
+}
+
+// Tuning holds the thread count and decoding preset used for inference
+type Tuning struct {
+	Threads int    `json:"threads"` // 0 = all cores
+	Preset  Preset `json:"preset"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// adaptivePreset returns the preset for a recording of numSamples samples:
+// greedy decoding below threshold, where beam search adds latency for little
+// gain, and beam search from threshold on. A threshold of 0 keeps preset.
+func adaptivePreset(preset Preset, numSamples int, threshold time.Duration) Preset {
+	if threshold <= 0 {
+		return preset
+	}
+	if time.Duration(numSamples)*time.Second/whisperSampleRate < threshold {
+		return PresetFast
+	}
+	return PresetAccurate
+}
+
+// threadCount returns the number of threads passed to whisper.cpp for a
+// configured count, where 0 means all cores
+func threadCount(threads int) int {
+	if threads > 0 {
+		return threads
+	}
+	return runtime.NumCPU()
+}
+
+// whisperLanguage returns the language code passed to whisper.cpp, or "" when
+// the language should be detected ("auto" or unset)
+func whisperLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "auto" {
+		return ""
+	}
+	return language
+}
+
+// InspectModel reads the class and quantization of a model file from its ggml header
+func InspectModel(modelPath string) (ModelInfo, error) {
+	stat, err := os.Stat(modelPath)
+	if err != nil {
+		return ModelInfo{}, fmt.Errorf("failed to stat model file: %w", err)
+	}
+
+	header, err := modelinfo.ReadFile(modelPath)
+	if err != nil {
+		return ModelInfo{}, err
+	}
+
+	return ModelInfo{
+		Class:        header.Class,
+		Quantization: header.Quantization,
+		SizeBytes:    stat.Size(),
+	}, nil
+}
+
+// RecommendTuning chooses a thread count and preset for the model on this machine.
+// Small models gain little from extra threads and can afford beam search;
+// a model that takes a large share of memory falls back to the fast preset.
+func RecommendTuning(model ModelInfo, system SystemInfo) Tuning {
+	maxThreads, ok := maxUsefulThreads[model.Class]
+	if !ok {
+		maxThreads = 4
+	}
+
+	threads := maxThreads
+	if system.Cores > 0 && system.Cores < threads {
+		threads = system.Cores
+	}
+
+	tuning := Tuning{
+		Threads: threads,
+		Preset:  PresetBalanced,
+	}
+
+	if model.Class == "tiny" || model.Class == "base" {
+		tuning.Preset = PresetAccurate
+	}
+
+	if system.MemoryBytes > 0 && float64(model.SizeBytes) > float64(system.MemoryBytes)*memoryWarningFraction {
+		tuning.Preset = PresetFast
+		tuning.Warning = fmt.Sprintf("model size %.1f GB exceeds %d%% of physical memory (%.1f GB); consider a smaller or quantized model",
+			float64(model.SizeBytes)/(1<<30), int(memoryWarningFraction*100), float64(system.MemoryBytes)/(1<<30))
+	}
+
+	return tuning
+}
@@ -0,0 +1,198 @@
+package recognition
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+const (
+	testMB = 1024 * 1024
+	testGB = 1024 * testMB
+)
+
+// writeModelHeader writes the ggml header of a model with the given encoder
+// and decoder depth and file type to a file named name
+func writeModelHeader(t *testing.T, name string, audioLayers, textLayers, ftype int32) string {
+	t.Helper()
+
+	var header bytes.Buffer
+	binary.Write(&header, binary.LittleEndian, uint32(0x67676d6c))
+	binary.Write(&header, binary.LittleEndian, []int32{51866, 1500, 1280, 20, audioLayers, 448, 1280, 20, textLayers, 128, ftype})
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, header.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+	return path
+}
+
+func TestInspectModel(t *testing.T) {
+	tests := []struct {
+		name         string
+		audioLayers  int32
+		textLayers   int32
+		ftype        int32
+		class        string
+		quantization string
+	}{
+		{"ggml-large-v3-turbo-q5_0.bin", 32, 4, 1008, "large", "q5_0"},
+		{"ggml-medium.en-q8_0.bin", 24, 24, 7, "medium", "q8_0"},
+		{"ggml-base.bin", 6, 6, 1, "base", "f16"},
+		// The header wins over a misleading or missing name
+		{"ggml-tiny.bin", 12, 12, 1, "small", "f16"},
+		{"my-model.bin", 4, 4, 0, "tiny", "f32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeModelHeader(t, tt.name, tt.audioLayers, tt.textLayers, tt.ftype)
+
+			model, err := InspectModel(path)
+			if err != nil {
+				t.Fatalf("InspectModel failed: %v", err)
+			}
+			if model.Class != tt.class {
+				t.Errorf("Expected class '%s', got '%s'", tt.class, model.Class)
+			}
+			if model.Quantization != tt.quantization {
+				t.Errorf("Expected quantization '%s', got '%s'", tt.quantization, model.Quantization)
+			}
+			if model.SizeBytes != 48 {
+				t.Errorf("Expected size 48, got %d", model.SizeBytes)
+			}
+		})
+	}
+}
+
+func TestInspectModel_NotGGML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(path, []byte("GGUF\x03\x00\x00\x00"), 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+
+	if _, err := InspectModel(path); err == nil {
+		t.Error("Expected error for a file without a ggml header")
+	}
+}
+
+func TestRecommendTuning(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      ModelInfo
+		system     SystemInfo
+		threads    int
+		preset     Preset
+		expectWarn bool
+	}{
+		{
+			name:    "tiny on 8-core machine caps threads",
+			model:   ModelInfo{Class: "tiny", SizeBytes: 75 * testMB},
+			system:  SystemInfo{Cores: 8, MemoryBytes: 16 * testGB},
+			threads: 4,
+			preset:  PresetAccurate,
+		},
+		{
+			name:    "small on 8-core machine",
+			model:   ModelInfo{Class: "small", SizeBytes: 466 * testMB},
+			system:  SystemInfo{Cores: 8, MemoryBytes: 16 * testGB},
+			threads: 6,
+			preset:  PresetBalanced,
+		},
+		{
+			name:    "quantized turbo on 8 GB M1",
+			model:   ModelInfo{Class: "large", Quantization: "q5_0", SizeBytes: 574 * testMB},
+			system:  SystemInfo{Cores: 4, MemoryBytes: 8 * testGB},
+			threads: 4,
+			preset:  PresetBalanced,
+		},
+		{
+			name:       "full large-v3 on 8 GB M1 warns",
+			model:      ModelInfo{Class: "large", Quantization: "f16", SizeBytes: 3 * testGB},
+			system:     SystemInfo{Cores: 4, MemoryBytes: 8 * testGB},
+			threads:    4,
+			preset:     PresetFast,
+			expectWarn: true,
+		},
+		{
+			name:    "large on 12-core machine caps threads",
+			model:   ModelInfo{Class: "large", SizeBytes: 3 * testGB},
+			system:  SystemInfo{Cores: 12, MemoryBytes: 32 * testGB},
+			threads: 8,
+			preset:  PresetBalanced,
+		},
+		{
+			name:    "unknown memory skips warning",
+			model:   ModelInfo{Class: "large", SizeBytes: 3 * testGB},
+			system:  SystemInfo{Cores: 8},
+			threads: 8,
+			preset:  PresetBalanced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuning := RecommendTuning(tt.model, tt.system)
+
+			if tuning.Threads != tt.threads {
+				t.Errorf("Expected %d threads, got %d", tt.threads, tuning.Threads)
+			}
+			if tuning.Preset != tt.preset {
+				t.Errorf("Expected preset '%s', got '%s'", tt.preset, tuning.Preset)
+			}
+			if (tuning.Warning != "") != tt.expectWarn {
+				t.Errorf("Expected warning=%v, got '%s'", tt.expectWarn, tuning.Warning)
+			}
+		})
+	}
+}
+
+func TestThreadCount(t *testing.T) {
+	if got := threadCount(2); got != 2 {
+		t.Errorf("Expected 2 threads, got %d", got)
+	}
+	if got := threadCount(0); got != runtime.NumCPU() {
+		t.Errorf("Expected 0 to use all %d cores, got %d", runtime.NumCPU(), got)
+	}
+}
+
+func TestAdaptivePreset(t *testing.T) {
+	tests := []struct {
+		numSamples int
+		threshold  time.Duration
+		expected   Preset
+	}{
+		{5 * whisperSampleRate, 10 * time.Second, PresetFast},
+		{10 * whisperSampleRate, 10 * time.Second, PresetAccurate},
+		{30 * whisperSampleRate, 10 * time.Second, PresetAccurate},
+		{30 * whisperSampleRate, 0, PresetBalanced},
+	}
+
+	for _, tt := range tests {
+		if got := adaptivePreset(PresetBalanced, tt.numSamples, tt.threshold); got != tt.expected {
+			t.Errorf("adaptivePreset(%d samples, %v) = %s, expected %s", tt.numSamples, tt.threshold, got, tt.expected)
+		}
+	}
+}
+
+func TestWhisperLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{"ja", "ja"},
+		{" EN ", "en"},
+		{"auto", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := whisperLanguage(tt.language); got != tt.expected {
+			t.Errorf("whisperLanguage(%q) = %q, expected %q", tt.language, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Package screenlock reports whether the login session's screen is locked.
+package screenlock
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics -framework CoreFoundation
+#include <CoreGraphics/CoreGraphics.h>
+
+// screen_is_locked returns 1 while the screen of the current login session
+// is locked, 0 otherwise or when the session cannot be queried.
+int screen_is_locked() {
+    CFDictionaryRef session = CGSessionCopyCurrentDictionary();
+    if (session == NULL) {
+        return 0;
+    }
+
+    int locked = 0;
+    CFTypeRef value = CFDictionaryGetValue(session, CFSTR("CGSSessionScreenIsLocked"));
+    if (value != NULL && CFGetTypeID(value) == CFBooleanGetTypeID()) {
+        locked = CFBooleanGetValue((CFBooleanRef)value) ? 1 : 0;
+    }
+    CFRelease(session);
+    return locked;
+}
+*/
+import "C"
+
+// Locked reports whether the screen is locked. It returns false when the
+// session state cannot be determined (e.g. no window server connection).
+func Locked() bool {
+	return C.screen_is_locked() == 1
+}
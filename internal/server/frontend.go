@@ -0,0 +1,89 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// frontendFiles returns the files of the settings UI. With an empty dir it is
+// the embedded frontend. Otherwise files are read from dir through an os.Root,
+// so no request can reach a file outside it, not even through a symlink. The
+// returned root must be closed when the server stops (nil for the embedded FS).
+func frontendFiles(dir string) (fs.FS, *os.Root, error) {
+	if dir == "" {
+		sub, err := fs.Sub(frontendFS, "frontend")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create frontend sub-filesystem: %w", err)
+		}
+		return sub, nil, nil
+	}
+
+	if !DevFrontendAllowed {
+		return nil, nil, fmt.Errorf("frontend directory is not supported in release builds")
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open frontend directory: %w", err)
+	}
+	return root.FS(), root, nil
+}
+
+// frontendHash returns a short hash over the names and contents of all files in
+// fsys. It changes whenever an app update changes the settings UI.
+func frontendHash(fsys fs.FS) (string, error) {
+	h := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		// The length keeps "a"+"bc" and "ab"+"c" apart
+		fmt.Fprintf(h, "%s\x00%d\x00", path, len(data))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash frontend: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// frontendVersionHandler handles GET /api/frontend/version
+// Returns {"hash": "..."} so an open settings page can tell that the app was
+// updated since it was loaded and offer a reload
+func frontendVersionHandler(hash func() (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		h, err := hash()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]string{"hash": h})
+	})
+}
+
+// noCache disables browser caching, so edits to a development frontend show up on reload
+func noCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		r.Header.Del("If-Modified-Since")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,7 @@
+//go:build release
+
+package server
+
+// DevFrontendAllowed reports whether Config.FrontendDir may be used. Release
+// builds (-tags release) always serve the embedded frontend.
+const DevFrontendAllowed = false
@@ -4,10 +4,10 @@ import (
 	"context"
 	"embed"
 	"fmt"
-	"io/fs"
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 )
@@ -24,32 +24,40 @@ type Server struct {
 	config     Config
 	mu         sync.Mutex
 	running    bool
+	streams    *StreamHub
+	frontend   *os.Root // Development frontend directory, nil when serving the embedded files
 }
 
 // Config holds server configuration
 type Config struct {
-	Port            int           // Port to listen on (0 = random)
-	ReadTimeout     time.Duration // HTTP read timeout
-	WriteTimeout    time.Duration // HTTP write timeout
-	ShutdownTimeout time.Duration // Graceful shutdown timeout
+	Port              int           // Port to listen on (0 = random)
+	ReadTimeout       time.Duration // HTTP read timeout
+	ReadHeaderTimeout time.Duration // Time allowed to read request headers
+	WriteTimeout      time.Duration // HTTP write timeout
+	IdleTimeout       time.Duration // How long an idle keep-alive connection stays open
+	ShutdownTimeout   time.Duration // Graceful shutdown timeout
+	FrontendDir       string        // Serve the settings UI from this directory instead of the embedded files (development only)
 }
 
 // DefaultConfig returns the default server configuration
 func DefaultConfig() Config {
 	return Config{
-		Port:            0, // 0 = OS assigns available port automatically
-		ReadTimeout:     10 * time.Second,
-		WriteTimeout:    10 * time.Second,
-		ShutdownTimeout: 5 * time.Second,
+		Port:              0, // 0 = OS assigns available port automatically
+		ReadTimeout:       10 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownTimeout:   5 * time.Second,
 	}
 }
 
 // New creates a new HTTP server
 func New(config Config) *Server {
 	return &Server{
-		port:   config.Port,
-		mux:    http.NewServeMux(),
-		config: config,
+		port:    config.Port,
+		mux:     http.NewServeMux(),
+		config:  config,
+		streams: NewStreamHub(),
 	}
 }
 
@@ -73,24 +81,60 @@ func (s *Server) Start() error {
 	s.port = listener.Addr().(*net.TCPAddr).Port
 
 	// Serve frontend static files
-	frontendSubFS, err := fs.Sub(frontendFS, "frontend")
+	frontendSubFS, root, err := frontendFiles(s.config.FrontendDir)
 	if err != nil {
 		listener.Close()
-		return fmt.Errorf("failed to create frontend sub-filesystem: %w", err)
+		return err
+	}
+	s.frontend = root
+
+	// The embedded frontend cannot change while the app runs, so it is hashed
+	// once; a development frontend is hashed on every request to follow edits
+	hash := func() (string, error) { return frontendHash(frontendSubFS) }
+	if root == nil {
+		embeddedHash, err := hash()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		hash = func() (string, error) { return embeddedHash, nil }
+	}
+
+	var static http.Handler = http.FileServer(http.FS(frontendSubFS))
+
+	// The About page is opened from the tray menu as /about
+	var about http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, frontendSubFS, "about.html")
+	})
+
+	if root != nil {
+		log.Printf("WARNING: development frontend, serving %s with caching disabled", s.config.FrontendDir)
+		static = noCache(static)
+		about = noCache(about)
 	}
 
 	// Register static files handler on the mux
-	s.mux.Handle("/", http.FileServer(http.FS(frontendSubFS)))
+	s.mux.Handle("/", static)
+	s.mux.Handle("/about", about)
+	s.mux.Handle("/api/frontend/version", frontendVersionHandler(hash))
 
 	// Add CORS middleware for localhost only and wrap the mux
 	handler := corsMiddleware(s.mux)
 
+	// Long-lived streams (e.g. /api/permissions/events) only end when their
+	// request context is cancelled, which Shutdown does not do by itself
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+
 	// Create HTTP server with configured timeouts
 	s.httpServer = &http.Server{
-		Handler:      handler,
-		ReadTimeout:  s.config.ReadTimeout,
-		WriteTimeout: s.config.WriteTimeout,
+		Handler:           handler,
+		ReadTimeout:       s.config.ReadTimeout,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
 	}
+	s.httpServer.RegisterOnShutdown(cancelRequests)
 
 	// Start server in goroutine
 	go func() {
@@ -117,10 +161,22 @@ func (s *Server) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
 	defer cancel()
 
+	// End the open streams first: Shutdown only returns once their handlers
+	// have exited and their connections have gone idle
+	s.streams.CloseAll()
+	if err := s.streams.Wait(ctx); err != nil {
+		log.Printf("Streams still open at shutdown: %d", s.streams.Active())
+	}
+
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown server: %w", err)
 	}
 
+	if s.frontend != nil {
+		s.frontend.Close()
+		s.frontend = nil
+	}
+
 	s.running = false
 	return nil
 }
@@ -144,6 +200,12 @@ func (s *Server) IsRunning() bool {
 	return s.running
 }
 
+// Streams returns the hub that streaming handlers register with, so that
+// Stop can close them
+func (s *Server) Streams() *StreamHub {
+	return s.streams
+}
+
 // GetMux returns the underlying HTTP multiplexer
 // This allows registering routes directly on the mux with locking
 func (s *Server) GetMux() *http.ServeMux {
@@ -1,10 +1,15 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"maps"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -26,7 +31,7 @@ func TestServerAPIIntegration(t *testing.T) {
 
 	// Create API handler
 	appConfig := config.DefaultConfig()
-	apiHandler := api.New(appConfig)
+	apiHandler := api.New(appConfig, nil, nil, nil, nil)
 
 	// Register API routes BEFORE starting the server
 	// This approach is preferred as it registers routes upfront
@@ -263,3 +268,166 @@ func TestConcurrentHandlerRegistration(t *testing.T) {
 		resp.Body.Close()
 	}
 }
+
+// fakePermissionChecker reports permission states that the test can change
+type fakePermissionChecker struct {
+	mu     sync.Mutex
+	status map[string]bool
+}
+
+func (f *fakePermissionChecker) CheckAllPermissions() map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return maps.Clone(f.status)
+}
+
+func (f *fakePermissionChecker) set(name string, granted bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status[name] = granted
+}
+
+// TestPermissionEventsStream tests that permission changes are pushed over SSE
+func TestPermissionEventsStream(t *testing.T) {
+	serverConfig := DefaultConfig()
+	serverConfig.Port = 0
+	// Shorter than the wait below, so the stream must outlive the write timeout
+	serverConfig.WriteTimeout = 300 * time.Millisecond
+	server := New(serverConfig)
+
+	checker := &fakePermissionChecker{status: map[string]bool{"microphone": false, "accessibility": false}}
+	apiHandler := api.New(config.DefaultConfig(), nil, nil, nil, nil)
+	apiHandler.SetPermissionChecker(checker, 20*time.Millisecond)
+	apiHandler.RegisterRoutes(server.GetMux())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	resp, err := http.Get(server.URL() + "/api/permissions/events")
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", contentType)
+	}
+
+	events := make(chan map[string]api.Permission)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		name := ""
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: ") && name == "permissions":
+				var status map[string]api.Permission
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status); err != nil {
+					t.Errorf("Failed to decode event: %v", err)
+					return
+				}
+				events <- status
+			}
+		}
+	}()
+
+	next := func() map[string]api.Permission {
+		t.Helper()
+		select {
+		case status, ok := <-events:
+			if !ok {
+				t.Fatal("Event stream closed unexpectedly")
+			}
+			return status
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for permissions event")
+		}
+		return nil
+	}
+
+	// Initial snapshot on connect
+	snapshot := next()
+	if snapshot["microphone"].Granted || snapshot["accessibility"].Granted {
+		t.Errorf("Expected nothing granted in the snapshot, got %+v", snapshot)
+	}
+
+	// Granting while the stream is open pushes the full status map
+	time.Sleep(400 * time.Millisecond)
+	checker.set("microphone", true)
+
+	changed := next()
+	if !changed["microphone"].Granted || changed["accessibility"].Granted {
+		t.Errorf("Expected only microphone granted, got %+v", changed)
+	}
+
+	checker.set("accessibility", true)
+
+	changed = next()
+	if !changed["microphone"].Granted || !changed["accessibility"].Granted {
+		t.Errorf("Expected both permissions granted, got %+v", changed)
+	}
+
+	// An open stream must not hold up shutdown
+	start := time.Now()
+	if err := server.Stop(); err != nil {
+		t.Errorf("Failed to stop server with an open stream: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected prompt shutdown, took %v", elapsed)
+	}
+}
+
+// TestStopClosesStreams tests that Stop closes registered event streams itself
+// instead of waiting for the shutdown timeout
+func TestStopClosesStreams(t *testing.T) {
+	serverConfig := DefaultConfig()
+	serverConfig.Port = 0
+	serverConfig.ShutdownTimeout = 5 * time.Second
+	server := New(serverConfig)
+
+	checker := &fakePermissionChecker{status: map[string]bool{"microphone": true, "accessibility": true}}
+	apiHandler := api.New(config.DefaultConfig(), nil, nil, nil, nil)
+	apiHandler.SetPermissionChecker(checker, time.Minute)
+	apiHandler.SetStreamRegistry(server.Streams())
+	apiHandler.RegisterRoutes(server.GetMux())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	resp, err := http.Get(server.URL() + "/api/permissions/events")
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Wait for the snapshot so the stream is registered
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || line != "event: permissions\n" {
+		t.Fatalf("Expected the permissions snapshot, got %q (%v)", line, err)
+	}
+	if active := server.Streams().Active(); active != 1 {
+		t.Fatalf("Expected 1 registered stream, got %d", active)
+	}
+
+	start := time.Now()
+	if err := server.Stop(); err != nil {
+		t.Errorf("Failed to stop server with an open stream: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > serverConfig.ShutdownTimeout/10 {
+		t.Errorf("Expected shutdown well under the %v timeout, took %v", serverConfig.ShutdownTimeout, elapsed)
+	}
+
+	if active := server.Streams().Active(); active != 0 {
+		t.Errorf("Expected no registered streams after Stop, got %d", active)
+	}
+	if _, err := io.ReadAll(reader); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the stream to end, got %v", err)
+	}
+}
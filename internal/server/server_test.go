@@ -1,9 +1,15 @@
 package server
 
 import (
+	"encoding/json"
 	"io"
+	"io/fs"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -22,6 +28,14 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected WriteTimeout 10s, got %v", config.WriteTimeout)
 	}
 
+	if config.ReadHeaderTimeout != 5*time.Second {
+		t.Errorf("Expected ReadHeaderTimeout 5s, got %v", config.ReadHeaderTimeout)
+	}
+
+	if config.IdleTimeout != 60*time.Second {
+		t.Errorf("Expected IdleTimeout 60s, got %v", config.IdleTimeout)
+	}
+
 	if config.ShutdownTimeout != 5*time.Second {
 		t.Errorf("Expected ShutdownTimeout 5s, got %v", config.ShutdownTimeout)
 	}
@@ -144,6 +158,187 @@ func TestServerServesFrontend(t *testing.T) {
 	}
 }
 
+func TestServerServesAboutPage(t *testing.T) {
+	server := New(DefaultConfig())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	resp, err := http.Get(server.URL() + "/about")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+
+	if !strings.Contains(string(body), "/api/about") {
+		t.Error("Expected the About page to load /api/about")
+	}
+}
+
+func TestServerServesFrontendDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<p>dev frontend</p>"), 0644); err != nil {
+		t.Fatalf("Failed to write index.html: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "about.html"), []byte("<p>dev about</p>"), 0644); err != nil {
+		t.Fatalf("Failed to write about.html: %v", err)
+	}
+
+	// A file next to the frontend directory and a symlink pointing at it
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write secret.txt: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.FrontendDir = dir
+	server := New(config)
+
+	// Release builds never serve files from disk
+	if !DevFrontendAllowed {
+		if err := server.Start(); err == nil {
+			server.Stop()
+			t.Fatal("Expected the frontend directory to be rejected in a release build")
+		}
+		return
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	get := func(path string) (int, string, http.Header) {
+		t.Helper()
+		resp, err := http.Get(server.URL() + path)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), resp.Header
+	}
+
+	for _, path := range []string{"/", "/about"} {
+		status, body, header := get(path)
+		if status != http.StatusOK || !strings.Contains(body, "dev ") {
+			t.Errorf("%s: expected the file from the directory, got %d %q", path, status, body)
+		}
+		if header.Get("Cache-Control") != "no-store" {
+			t.Errorf("%s: expected caching disabled, got %q", path, header.Get("Cache-Control"))
+		}
+	}
+
+	// Edits show up without restarting
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<p>dev frontend v2</p>"), 0644); err != nil {
+		t.Fatalf("Failed to update index.html: %v", err)
+	}
+	if _, body, _ := get("/"); !strings.Contains(body, "v2") {
+		t.Errorf("Expected the edited file, got %q", body)
+	}
+
+	for _, path := range []string{"/link.txt", "/../" + filepath.Base(outside), "/..%2fsecret.txt"} {
+		if status, body, _ := get(path); status == http.StatusOK || strings.Contains(body, "secret") {
+			t.Errorf("%s: expected files outside the directory to be unreachable, got %d %q", path, status, body)
+		}
+	}
+}
+
+func TestServerEmbeddedFrontendIsCached(t *testing.T) {
+	server := New(DefaultConfig())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	resp, err := http.Get(server.URL() + "/")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.Header.Get("Cache-Control") != "" {
+		t.Errorf("Expected default caching for the embedded frontend, got %q", resp.Header.Get("Cache-Control"))
+	}
+}
+
+func TestFrontendHash(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html": {Data: []byte("<p>settings</p>")},
+		"about.html": {Data: []byte("<p>about</p>")},
+	}
+
+	first, err := frontendHash(files)
+	if err != nil {
+		t.Fatalf("frontendHash failed: %v", err)
+	}
+	if again, _ := frontendHash(files); again != first {
+		t.Errorf("Expected a stable hash, got %q and %q", first, again)
+	}
+
+	files["index.html"] = &fstest.MapFile{Data: []byte("<p>settings v2</p>")}
+	if changed, _ := frontendHash(files); changed == first {
+		t.Error("Expected the hash to change with the frontend")
+	}
+}
+
+func TestServerFrontendVersion(t *testing.T) {
+	server := New(DefaultConfig())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	resp, err := http.Get(server.URL() + "/api/frontend/version")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var version struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	sub, _ := fs.Sub(frontendFS, "frontend")
+	expected, _ := frontendHash(sub)
+	if version.Hash == "" || version.Hash != expected {
+		t.Errorf("Expected the embedded frontend hash %q, got %q", expected, version.Hash)
+	}
+}
+
+func TestServerFrontendDirMissing(t *testing.T) {
+	config := DefaultConfig()
+	config.FrontendDir = filepath.Join(t.TempDir(), "missing")
+	server := New(config)
+
+	if err := server.Start(); err == nil {
+		server.Stop()
+		t.Fatal("Expected an error for a missing frontend directory")
+	}
+	if server.IsRunning() {
+		t.Error("Expected server to not be running")
+	}
+}
+
 func TestCORSMiddleware(t *testing.T) {
 	// Create a test handler
 	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
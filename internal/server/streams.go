@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// StreamHub tracks long-lived streaming responses (e.g. Server-Sent Events) so
+// that Stop can end them before waiting for the HTTP server to drain
+type StreamHub struct {
+	mu      sync.Mutex
+	streams map[int]context.CancelFunc
+	nextID  int
+	wg      sync.WaitGroup
+}
+
+// NewStreamHub creates an empty stream hub
+func NewStreamHub() *StreamHub {
+	return &StreamHub{streams: make(map[int]context.CancelFunc)}
+}
+
+// Register tracks a stream for the lifetime of parent. The returned context is
+// cancelled when parent is done or the hub closes its streams; the handler must
+// call done when it returns.
+func (h *StreamHub) Register(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	h.mu.Lock()
+	id := h.nextID
+	h.nextID++
+	h.streams[id] = cancel
+	h.wg.Add(1)
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.streams, id)
+			h.mu.Unlock()
+			cancel()
+			h.wg.Done()
+		})
+	}
+}
+
+// Active returns the number of registered streams
+func (h *StreamHub) Active() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.streams)
+}
+
+// CloseAll cancels the context of every registered stream
+func (h *StreamHub) CloseAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, cancel := range h.streams {
+		cancel()
+	}
+}
+
+// Wait blocks until every registered stream has called done or ctx is done
+func (h *StreamHub) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
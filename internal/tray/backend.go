@@ -0,0 +1,27 @@
+package tray
+
+import "github.com/getlantern/systray"
+
+// systrayBackend creates the menu bar items with systray
+type systrayBackend struct{}
+
+func (systrayBackend) AddItem(title, tooltip string) menuHandle {
+	return systrayItem{systray.AddMenuItem(title, tooltip)}
+}
+
+func (systrayBackend) AddSubItem(parent menuHandle, title, tooltip string) menuHandle {
+	return systrayItem{parent.(systrayItem).AddSubMenuItem(title, tooltip)}
+}
+
+func (systrayBackend) AddSeparator() {
+	systray.AddSeparator()
+}
+
+// systrayItem adapts *systray.MenuItem to menuHandle
+type systrayItem struct {
+	*systray.MenuItem
+}
+
+func (i systrayItem) Clicked() <-chan struct{} {
+	return i.ClickedCh
+}
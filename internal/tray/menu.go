@@ -0,0 +1,246 @@
+package tray
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MenuItem declares a menu entry. The menu manager reconciles the declared
+// items against the items already created in the menu bar.
+type MenuItem struct {
+	ID       string // Identifies the item for SetItem and SetChildren, unique within the menu
+	Title    string
+	Tooltip  string
+	Checked  bool
+	Disabled bool
+	Hidden   bool
+	OnClick  func()     // Called from the dispatcher goroutine, nil to ignore clicks
+	Children []MenuItem // Submenu entries
+}
+
+// MenuSection is a group of top-level items. Sections are separated by a separator.
+type MenuSection []MenuItem
+
+// menuHandle is an item created in the menu bar
+type menuHandle interface {
+	SetTitle(title string)
+	SetTooltip(tooltip string)
+	Check()
+	Uncheck()
+	Enable()
+	Disable()
+	Show()
+	Hide()
+	Clicked() <-chan struct{}
+}
+
+// menuBackend creates menu bar items (systray in the app, a fake in tests).
+// Items cannot be removed or inserted, only appended and hidden.
+type menuBackend interface {
+	AddItem(title, tooltip string) menuHandle
+	AddSubItem(parent menuHandle, title, tooltip string) menuHandle
+	AddSeparator()
+}
+
+// menuSlot is a created item and the declaration it currently shows.
+// Slots are never removed; unused ones are hidden and reused later.
+type menuSlot struct {
+	handle   menuHandle
+	item     MenuItem
+	children []*menuSlot
+	inUse    bool
+}
+
+// MenuManager owns the tray menu. The top-level layout is fixed by Build;
+// submenus may grow and shrink with SetChildren. Each slot reuses its menu
+// bar item by position, so a changing list never ends up out of order.
+// All clicks are routed through a single dispatcher goroutine.
+type MenuManager struct {
+	mu      sync.Mutex
+	backend menuBackend
+	root    []*menuSlot
+	byID    map[string]*menuSlot
+	clicks  chan *menuSlot
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewMenuManager creates a menu manager and starts its dispatcher
+func NewMenuManager(backend menuBackend) *MenuManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &MenuManager{
+		backend: backend,
+		byID:    make(map[string]*menuSlot),
+		clicks:  make(chan *menuSlot),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go m.dispatch()
+	return m
+}
+
+// Build creates the top-level items. It must be called once, before any other update.
+func (m *MenuManager) Build(sections []MenuSection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, section := range sections {
+		if i > 0 {
+			m.backend.AddSeparator()
+		}
+		for _, item := range section {
+			slot := m.newSlot(nil, item)
+			m.root = append(m.root, slot)
+			m.apply(slot, item)
+		}
+	}
+}
+
+// SetItem replaces the declaration of the item with item.ID, including its submenu
+func (m *MenuManager) SetItem(item MenuItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	slot, ok := m.byID[item.ID]
+	if !ok {
+		return fmt.Errorf("unknown menu item: %s", item.ID)
+	}
+	m.apply(slot, item)
+	return nil
+}
+
+// SetChildren replaces the submenu of the item with id
+func (m *MenuManager) SetChildren(id string, children []MenuItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	slot, ok := m.byID[id]
+	if !ok {
+		return fmt.Errorf("unknown menu item: %s", id)
+	}
+	item := slot.item
+	item.Children = children
+	m.apply(slot, item)
+	return nil
+}
+
+// Close stops the dispatcher and the click forwarding goroutines
+func (m *MenuManager) Close() {
+	m.cancel()
+}
+
+// newSlot creates a menu bar item under parent (nil for top level) and starts
+// forwarding its clicks to the dispatcher. The caller must hold mu.
+func (m *MenuManager) newSlot(parent *menuSlot, item MenuItem) *menuSlot {
+	var handle menuHandle
+	if parent == nil {
+		handle = m.backend.AddItem(item.Title, item.Tooltip)
+	} else {
+		handle = m.backend.AddSubItem(parent.handle, item.Title, item.Tooltip)
+	}
+
+	slot := &menuSlot{handle: handle}
+	go m.forward(slot)
+	return slot
+}
+
+// apply updates slot to show item and reconciles its submenu. The caller must hold mu.
+func (m *MenuManager) apply(slot *menuSlot, item MenuItem) {
+	if slot.inUse && slot.item.ID != "" && m.byID[slot.item.ID] == slot {
+		delete(m.byID, slot.item.ID)
+	}
+	slot.item = item
+	slot.inUse = true
+	if item.ID != "" {
+		m.byID[item.ID] = slot
+	}
+
+	slot.handle.SetTitle(item.Title)
+	slot.handle.SetTooltip(item.Tooltip)
+	if item.Checked {
+		slot.handle.Check()
+	} else {
+		slot.handle.Uncheck()
+	}
+	if item.Disabled {
+		slot.handle.Disable()
+	} else {
+		slot.handle.Enable()
+	}
+	if item.Hidden {
+		slot.handle.Hide()
+	} else {
+		slot.handle.Show()
+	}
+
+	// Reuse existing children by position, append the missing ones and hide the rest
+	for i, child := range item.Children {
+		if i == len(slot.children) {
+			slot.children = append(slot.children, m.newSlot(slot, child))
+		}
+		m.apply(slot.children[i], child)
+	}
+	for _, unused := range slot.children[len(item.Children):] {
+		m.release(unused)
+	}
+}
+
+// release hides slot and its submenu until they are reused. The caller must hold mu.
+func (m *MenuManager) release(slot *menuSlot) {
+	if !slot.inUse {
+		return
+	}
+	if slot.item.ID != "" && m.byID[slot.item.ID] == slot {
+		delete(m.byID, slot.item.ID)
+	}
+	for _, child := range slot.children {
+		m.release(child)
+	}
+	slot.item = MenuItem{}
+	slot.inUse = false
+	slot.handle.Hide()
+}
+
+// forward sends the clicks of slot to the dispatcher until Close
+func (m *MenuManager) forward(slot *menuSlot) {
+	clicked := slot.handle.Clicked()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-clicked:
+			select {
+			case m.clicks <- slot:
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// dispatch calls the handler of the item each clicked slot currently shows
+func (m *MenuManager) dispatch() {
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case slot := <-m.clicks:
+			if handler := m.handler(slot); handler != nil {
+				handler()
+			}
+		}
+	}
+}
+
+// handler returns the click handler of the item slot shows, or nil when the
+// slot is hidden or disabled
+func (m *MenuManager) handler(slot *menuSlot) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !slot.inUse || slot.item.Hidden || slot.item.Disabled {
+		return nil
+	}
+	return slot.item.OnClick
+}
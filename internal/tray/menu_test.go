@@ -0,0 +1,203 @@
+package tray
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMenuItem records the state systray would show for an item
+type fakeMenuItem struct {
+	title    string
+	tooltip  string
+	checked  bool
+	disabled bool
+	hidden   bool
+	parent   *fakeMenuItem
+	clicked  chan struct{}
+}
+
+func (i *fakeMenuItem) SetTitle(title string)     { i.title = title }
+func (i *fakeMenuItem) SetTooltip(tooltip string) { i.tooltip = tooltip }
+func (i *fakeMenuItem) Check()                    { i.checked = true }
+func (i *fakeMenuItem) Uncheck()                  { i.checked = false }
+func (i *fakeMenuItem) Enable()                   { i.disabled = false }
+func (i *fakeMenuItem) Disable()                  { i.disabled = true }
+func (i *fakeMenuItem) Show()                     { i.hidden = false }
+func (i *fakeMenuItem) Hide()                     { i.hidden = true }
+func (i *fakeMenuItem) Clicked() <-chan struct{}  { return i.clicked }
+
+// fakeMenuBackend appends items like systray: nothing is ever removed or inserted
+type fakeMenuBackend struct {
+	items  []*fakeMenuItem
+	layout []string // Top-level titles at creation, "---" for separators
+}
+
+func (b *fakeMenuBackend) AddItem(title, tooltip string) menuHandle {
+	item := &fakeMenuItem{title: title, tooltip: tooltip, clicked: make(chan struct{})}
+	b.items = append(b.items, item)
+	b.layout = append(b.layout, title)
+	return item
+}
+
+func (b *fakeMenuBackend) AddSubItem(parent menuHandle, title, tooltip string) menuHandle {
+	item := &fakeMenuItem{title: title, tooltip: tooltip, parent: parent.(*fakeMenuItem), clicked: make(chan struct{})}
+	b.items = append(b.items, item)
+	return item
+}
+
+func (b *fakeMenuBackend) AddSeparator() {
+	b.layout = append(b.layout, "---")
+}
+
+// visibleChildren returns the titles of the shown submenu items of parent in menu order
+func (b *fakeMenuBackend) visibleChildren(parent *fakeMenuItem) []string {
+	var titles []string
+	for _, item := range b.items {
+		if item.parent == parent && !item.hidden {
+			titles = append(titles, item.title)
+		}
+	}
+	return titles
+}
+
+// find returns the top-level item with title
+func (b *fakeMenuBackend) find(title string) *fakeMenuItem {
+	for _, item := range b.items {
+		if item.parent == nil && item.title == title {
+			return item
+		}
+	}
+	return nil
+}
+
+func newTestMenu(t *testing.T) (*MenuManager, *fakeMenuBackend) {
+	t.Helper()
+
+	backend := &fakeMenuBackend{}
+	menu := NewMenuManager(backend)
+	t.Cleanup(menu.Close)
+
+	menu.Build([]MenuSection{
+		{{ID: "settings", Title: "Settings"}, {ID: "devices", Title: "Devices"}},
+		{{ID: "quit", Title: "Quit"}},
+	})
+	return menu, backend
+}
+
+// menuItems keeps the declarations in the tests short
+func menuItems(items ...MenuItem) []MenuItem {
+	return items
+}
+
+func TestMenuManagerBuild(t *testing.T) {
+	_, backend := newTestMenu(t)
+
+	if got := strings.Join(backend.layout, ","); got != "Settings,Devices,---,Quit" {
+		t.Errorf("Expected sections separated in order, got %s", got)
+	}
+}
+
+func TestMenuManagerSetChildren_ReusesItems(t *testing.T) {
+	menu, backend := newTestMenu(t)
+	devices := backend.find("Devices")
+
+	if err := menu.SetChildren("devices", menuItems(
+		MenuItem{ID: "a", Title: "A"},
+		MenuItem{ID: "b", Title: "B", Checked: true},
+		MenuItem{ID: "c", Title: "C"},
+	)); err != nil {
+		t.Fatalf("SetChildren failed: %v", err)
+	}
+	created := len(backend.items)
+
+	// Shrinking hides the extra items instead of creating new ones
+	if err := menu.SetChildren("devices", menuItems(
+		MenuItem{ID: "c", Title: "C", Checked: true},
+		MenuItem{ID: "a", Title: "A"},
+	)); err != nil {
+		t.Fatalf("SetChildren failed: %v", err)
+	}
+
+	if len(backend.items) != created {
+		t.Errorf("Expected existing items to be reused, %d created", len(backend.items)-created)
+	}
+	if got := strings.Join(backend.visibleChildren(devices), ","); got != "C,A" {
+		t.Errorf("Expected C,A, got %s", got)
+	}
+	if first := backend.items[created-3]; !first.checked || first.title != "C" {
+		t.Errorf("Expected the first item to show a checked C, got %q (checked=%v)", first.title, first.checked)
+	}
+	if second := backend.items[created-2]; second.checked {
+		t.Error("Expected the checkmark to move with the declaration")
+	}
+
+	// Growing reuses the hidden item before appending
+	if err := menu.SetChildren("devices", menuItems(
+		MenuItem{Title: "1"}, MenuItem{Title: "2"}, MenuItem{Title: "3"}, MenuItem{Title: "4"},
+	)); err != nil {
+		t.Fatalf("SetChildren failed: %v", err)
+	}
+
+	if len(backend.items) != created+1 {
+		t.Errorf("Expected exactly one new item, got %d", len(backend.items)-created)
+	}
+	if got := strings.Join(backend.visibleChildren(devices), ","); got != "1,2,3,4" {
+		t.Errorf("Expected items in declared order, got %s", got)
+	}
+}
+
+func TestMenuManagerSetItem(t *testing.T) {
+	menu, backend := newTestMenu(t)
+
+	if err := menu.SetItem(MenuItem{ID: "settings", Title: "Preferences", Disabled: true}); err != nil {
+		t.Fatalf("SetItem failed: %v", err)
+	}
+
+	item := backend.items[0]
+	if item.title != "Preferences" || !item.disabled {
+		t.Errorf("Expected a disabled Preferences item, got %q (disabled=%v)", item.title, item.disabled)
+	}
+
+	if err := menu.SetItem(MenuItem{ID: "missing"}); err == nil {
+		t.Error("Expected an error for an unknown item")
+	}
+
+	// Hidden children are no longer addressable
+	menu.SetChildren("devices", menuItems(MenuItem{ID: "a", Title: "A"}))
+	menu.SetChildren("devices", nil)
+	if err := menu.SetItem(MenuItem{ID: "a", Title: "A"}); err == nil {
+		t.Error("Expected an error for a released item")
+	}
+}
+
+func TestMenuManagerDispatch(t *testing.T) {
+	menu, backend := newTestMenu(t)
+	clicks := make(chan string, 1)
+
+	menu.SetChildren("devices", menuItems(MenuItem{ID: "a", Title: "A", OnClick: func() { clicks <- "a" }}))
+	menu.SetChildren("devices", menuItems(MenuItem{ID: "b", Title: "B", OnClick: func() { clicks <- "b" }}))
+
+	// The reused item calls the handler of the declaration it currently shows
+	item := backend.items[len(backend.items)-1]
+	item.clicked <- struct{}{}
+
+	select {
+	case got := <-clicks:
+		if got != "b" {
+			t.Errorf("Expected handler b, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the click to be dispatched")
+	}
+
+	// Disabled items ignore clicks
+	menu.SetChildren("devices", menuItems(MenuItem{ID: "b", Title: "B", Disabled: true, OnClick: func() { clicks <- "b" }}))
+	item.clicked <- struct{}{}
+
+	select {
+	case got := <-clicks:
+		t.Errorf("Expected no handler for a disabled item, got %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -1,16 +1,16 @@
 package tray
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/getlantern/systray"
+	"github.com/yok-tottii/EzS2T-Whisper/assets"
+	"github.com/yok-tottii/EzS2T-Whisper/internal/notification"
 )
 
 // State represents the current application state
@@ -20,28 +20,34 @@ const (
 	StateIdle State = iota
 	StateRecording
 	StateProcessing
+	StateDegraded // Idle, but a prerequisite (model, permission) is missing
 )
 
 // Manager manages the system tray icon and menu
 type Manager struct {
-	stateMutex       sync.RWMutex
-	state            State
-	onReadyCallback  func()
-	onSettings       func()
-	onRecordTest     func()
-	onDeviceChange   func(deviceID int) // Called when user selects a device
-	onQuit           func()
-	menuSettings      *systray.MenuItem
-	menuDevices       *systray.MenuItem      // Parent menu for device selection
-	menuRecordTest    *systray.MenuItem
-	menuQuit          *systray.MenuItem
-	deviceMenuItems   []*systray.MenuItem    // Device submenu items
-	deviceCancelFuncs []context.CancelFunc   // Cancel functions for device menu goroutines
+	stateMutex      sync.RWMutex
+	state           State
+	problems        []string // Missing prerequisites shown while idle, e.g. "モデル未設定"
+	partialText     string   // Text transcribed so far, shown in the tooltip while recording
+	onReadyCallback func()
+	onSettings      func()
+	onRecordTest    func()
+	onReloadModel   func()
+	onResetHotkey   func()
+	onDeviceChange  func(deviceID int) // Called when user selects a device
+	onAbout         func()
+	onDiagnostics   func()
+	onHistory       func()
+	onQuit          func()
+	showText        func() bool  // Reports whether to show state text next to the icon
+	menu            *MenuManager // Owns the menu items, nil until systray is ready
+	notifier        *notification.NotificationManager
 
 	// Icon cache
 	iconIdle       []byte
 	iconRecording  []byte
 	iconProcessing []byte
+	iconDegraded   []byte
 }
 
 // Config holds tray manager configuration
@@ -49,8 +55,14 @@ type Config struct {
 	OnReady        func() // Called when systray is ready for initialization
 	OnSettings     func()
 	OnRecordTest   func()
+	OnReloadModel  func() // Called when user requests reloading the configured model
+	OnResetHotkey  func() // Called when user requests restoring the default hotkey
 	OnDeviceChange func(deviceID int) // Called when user selects a device
+	OnAbout        func() // Called when user opens the About page
+	OnDiagnostics  func() // Called when user exports the diagnostics bundle
+	OnHistory      func() // Called when user exports the transcription history
 	OnQuit         func()
+	ShowText       func() bool // Optional: show short state text (e.g. "●REC") next to the icon
 }
 
 // NewManager creates a new tray manager
@@ -60,14 +72,24 @@ func NewManager(config Config) *Manager {
 		onReadyCallback: config.OnReady,
 		onSettings:      config.OnSettings,
 		onRecordTest:    config.OnRecordTest,
+		onReloadModel:   config.OnReloadModel,
+		onResetHotkey:   config.OnResetHotkey,
 		onDeviceChange:  config.OnDeviceChange,
+		onAbout:         config.OnAbout,
+		onDiagnostics:   config.OnDiagnostics,
+		onHistory:       config.OnHistory,
 		onQuit:          config.OnQuit,
+		showText:        config.ShowText,
+		notifier:        notification.NewNotificationManager("EzS2T-Whisper"),
 	}
 
-	// Load icons once at initialization
-	m.iconIdle = loadIconData("speech_to_text_32dp_E3E3E3_FILL0_wght400_GRAD0_opsz40.png", getIdleFallback())
-	m.iconRecording = loadIconData("graphic_eq_32dp_F19E39_FILL0_wght400_GRAD0_opsz40.png", getRecordingFallback())
-	m.iconProcessing = loadIconData("hourglass_empty_32dp_75FB4C_FILL0_wght400_GRAD0_opsz40.png", getProcessingFallback())
+	// Icons are embedded in the binary so they are available wherever it runs.
+	// Files in assets/icon/ next to the executable take precedence when present.
+	overrideDir := iconOverrideDir()
+	m.iconIdle = loadIcon(overrideDir, idleIconFile, getIdleIcon())
+	m.iconRecording = loadIcon(overrideDir, recordingIconFile, getRecordingIcon())
+	m.iconProcessing = loadIcon(overrideDir, processingIconFile, getProcessingIcon())
+	m.iconDegraded = loadIcon(overrideDir, degradedIconFile, getDegradedIcon())
 
 	return m
 }
@@ -84,16 +106,7 @@ func (m *Manager) onReady() {
 	systray.SetTooltip("EzS2T-Whisper")
 
 	// Add menu items
-	m.menuSettings = systray.AddMenuItem("設定を開く...", "Open settings page")
-	m.menuDevices = systray.AddMenuItem("入力デバイス", "Select input device")
-	m.menuRecordTest = systray.AddMenuItem("録音テスト", "Test recording pipeline")
-
-	systray.AddSeparator()
-
-	m.menuQuit = systray.AddMenuItem("終了", "Quit the application")
-
-	// Start event loop
-	go m.handleMenuEvents()
+	m.buildMenu(systrayBackend{})
 
 	// Call the OnReady callback if provided
 	if m.onReadyCallback != nil {
@@ -106,26 +119,46 @@ func (m *Manager) onExit() {
 	// Cleanup if needed
 }
 
-// handleMenuEvents handles menu item clicks
-func (m *Manager) handleMenuEvents() {
-	for {
-		select {
-		case <-m.menuSettings.ClickedCh:
-			if m.onSettings != nil {
-				m.onSettings()
-			}
-		case <-m.menuRecordTest.ClickedCh:
-			if m.onRecordTest != nil {
-				m.onRecordTest()
-			}
-		case <-m.menuQuit.ClickedCh:
-			if m.onQuit != nil {
-				m.onQuit()
-			}
-			systray.Quit()
-			return
-		}
+// Menu item IDs
+const (
+	menuIDSettings    = "settings"
+	menuIDDevices     = "devices" // Parent menu for device selection
+	menuIDRecordTest  = "record-test"
+	menuIDReloadModel = "reload-model"
+	menuIDResetHotkey = "reset-hotkey"
+	menuIDAbout       = "about"
+	menuIDHistory     = "history"
+	menuIDDiagnostics = "diagnostics"
+	menuIDQuit        = "quit"
+)
+
+// buildMenu creates the menu items with backend
+func (m *Manager) buildMenu(backend menuBackend) {
+	m.menu = NewMenuManager(backend)
+	m.menu.Build([]MenuSection{
+		{
+			{ID: menuIDSettings, Title: "設定を開く...", Tooltip: "Open settings page", OnClick: m.onSettings},
+			{ID: menuIDDevices, Title: "入力デバイス", Tooltip: "Select input device"},
+			{ID: menuIDRecordTest, Title: "録音テスト", Tooltip: "Test recording pipeline", OnClick: m.onRecordTest},
+			{ID: menuIDReloadModel, Title: "モデルを再読み込み", Tooltip: "Reload the configured model from disk", OnClick: m.onReloadModel},
+			{ID: menuIDResetHotkey, Title: "ホットキーを初期設定に戻す", Tooltip: "Restore the default hotkey (⌃⌥Space)", OnClick: m.onResetHotkey},
+		},
+		{
+			{ID: menuIDAbout, Title: "バージョン情報", Tooltip: "Show version and license information", OnClick: m.onAbout},
+			{ID: menuIDHistory, Title: "履歴を書き出す…", Tooltip: "Save the transcription history as Markdown", OnClick: m.onHistory},
+			{ID: menuIDDiagnostics, Title: "診断情報を書き出す…", Tooltip: "Save logs, settings and status as a zip for bug reports", OnClick: m.onDiagnostics},
+			{ID: menuIDQuit, Title: "終了", Tooltip: "Quit the application", OnClick: m.handleQuit},
+		},
+	})
+}
+
+// handleQuit handles the Quit menu item
+func (m *Manager) handleQuit() {
+	if m.onQuit != nil {
+		m.onQuit()
 	}
+	m.menu.Close()
+	systray.Quit()
 }
 
 // SetState updates the tray icon based on the current state
@@ -136,18 +169,100 @@ func (m *Manager) SetState(state State) {
 	m.updateIcon()
 }
 
+// SetProblems sets the missing prerequisites. While there are any, the idle
+// state is shown as StateDegraded with the problems in the tooltip.
+// Recording and processing are still shown as usual.
+func (m *Manager) SetProblems(problems []string) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	m.problems = append([]string(nil), problems...)
+	m.updateIcon()
+}
+
+// SetPartialText shows the text transcribed so far in the tooltip while
+// recording. Only the end of long text is shown; "" clears it.
+func (m *Manager) SetPartialText(text string) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	m.partialText = text
+	systray.SetTooltip(m.tooltip())
+}
+
+// displayState returns the state to show: idle becomes StateDegraded while
+// there are problems. The caller must hold stateMutex.
+func (m *Manager) displayState() State {
+	if m.state == StateIdle && len(m.problems) > 0 {
+		return StateDegraded
+	}
+	return m.state
+}
+
+// tooltip returns the tooltip for the displayed state. The caller must hold stateMutex.
+func (m *Manager) tooltip() string {
+	switch m.displayState() {
+	case StateRecording:
+		if m.partialText != "" {
+			return "EzS2T-Whisper - 録音中: " + lastRunes(m.partialText, maxPartialTextRunes)
+		}
+		return "EzS2T-Whisper - 録音中"
+	case StateProcessing:
+		return "EzS2T-Whisper - 処理中"
+	case StateDegraded:
+		if len(m.problems) == 0 {
+			return "EzS2T-Whisper - 利用できません"
+		}
+		return "EzS2T-Whisper - " + strings.Join(m.problems, " / ")
+	default:
+		return "EzS2T-Whisper - 待機中"
+	}
+}
+
+// maxPartialTextRunes is how much of the partial text fits in the tooltip
+const maxPartialTextRunes = 60
+
+// lastRunes returns the last n runes of s, prefixed with "…" when it was cut
+func lastRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return "…" + string(runes[len(runes)-n:])
+}
+
 // updateIcon updates the tray icon based on the current state
 func (m *Manager) updateIcon() {
-	switch m.state {
+	switch m.displayState() {
 	case StateIdle:
 		systray.SetIcon(m.iconIdle)
-		systray.SetTooltip("EzS2T-Whisper - 待機中")
 	case StateRecording:
 		systray.SetIcon(m.iconRecording)
-		systray.SetTooltip("EzS2T-Whisper - 録音中")
 	case StateProcessing:
 		systray.SetIcon(m.iconProcessing)
-		systray.SetTooltip("EzS2T-Whisper - 処理中")
+	case StateDegraded:
+		systray.SetIcon(m.iconDegraded)
+	}
+	systray.SetTooltip(m.tooltip())
+
+	// Always set the title so it is cleared when text is disabled or idle
+	systray.SetTitle(m.stateText())
+}
+
+// stateText returns the short menu bar text for the current state,
+// or "" when text is disabled or the app is idle without problems
+func (m *Manager) stateText() string {
+	if m.showText == nil || !m.showText() {
+		return ""
+	}
+
+	switch m.displayState() {
+	case StateRecording:
+		return "●REC"
+	case StateProcessing:
+		return "…"
+	case StateDegraded:
+		return "⚠"
+	default:
+		return ""
 	}
 }
 
@@ -161,60 +276,39 @@ type Device struct {
 
 // UpdateDeviceMenu updates the device submenu with available devices
 func (m *Manager) UpdateDeviceMenu(devices []Device) {
-	// Cancel existing device menu goroutines
-	for _, cancel := range m.deviceCancelFuncs {
-		if cancel != nil {
-			cancel()
-		}
+	if m.menu == nil {
+		return
 	}
-	m.deviceCancelFuncs = nil
 
-	// Remove existing device menu items
-	for _, item := range m.deviceMenuItems {
-		item.Hide()
+	items := make([]MenuItem, 0, len(devices))
+	for _, device := range devices {
+		items = append(items, m.deviceMenuItem(device))
 	}
-	m.deviceMenuItems = nil
 
-	// Add new device menu items
-	for _, device := range devices {
-		// Create closure to capture device ID
-		deviceID := device.ID
-		deviceName := device.Name
+	if err := m.menu.SetChildren(menuIDDevices, items); err != nil {
+		log.Printf("警告: デバイスメニューを更新できませんでした: %v", err)
+	}
+}
 
-		// Add checkmark if current device
-		prefix := ""
-		if device.IsCurrent {
-			prefix = "✓ "
-		}
+// deviceMenuItem returns the submenu item for device
+func (m *Manager) deviceMenuItem(device Device) MenuItem {
+	item := MenuItem{
+		ID:      fmt.Sprintf("device-%d", device.ID),
+		Title:   device.Name,
+		Checked: device.IsCurrent,
+	}
 
-		// Add tooltip for default device
-		tooltip := ""
-		if device.IsDefault {
-			tooltip = "System default device"
-		}
+	// Add tooltip for default device
+	if device.IsDefault {
+		item.Tooltip = "System default device"
+	}
 
-		menuItem := m.menuDevices.AddSubMenuItem(prefix+deviceName, tooltip)
-		m.deviceMenuItems = append(m.deviceMenuItems, menuItem)
-
-		// Create context for this goroutine
-		ctx, cancel := context.WithCancel(context.Background())
-		m.deviceCancelFuncs = append(m.deviceCancelFuncs, cancel)
-
-		// Handle device selection in a goroutine with cancellation
-		go func(id int, item *systray.MenuItem, ctx context.Context) {
-			for {
-				select {
-				case <-ctx.Done():
-					// Context cancelled, exit goroutine
-					return
-				case <-item.ClickedCh:
-					if m.onDeviceChange != nil {
-						m.onDeviceChange(id)
-					}
-				}
-			}
-		}(deviceID, menuItem, ctx)
+	if m.onDeviceChange != nil {
+		deviceID := device.ID
+		item.OnClick = func() { m.onDeviceChange(deviceID) }
 	}
+
+	return item
 }
 
 // Quit quits the system tray
@@ -222,90 +316,65 @@ func (m *Manager) Quit() {
 	systray.Quit()
 }
 
-// loadIconData loads an icon from the assets directory
-// If the file cannot be loaded, it returns a fallback placeholder icon
-func loadIconData(filename string, fallback []byte) []byte {
-	// Get executable directory
+// Icon file names looked up in the override directory
+const (
+	idleIconFile       = "speech_to_text_32dp_E3E3E3_FILL0_wght400_GRAD0_opsz40.png"
+	recordingIconFile  = "graphic_eq_32dp_F19E39_FILL0_wght400_GRAD0_opsz40.png"
+	processingIconFile = "hourglass_empty_32dp_75FB4C_FILL0_wght400_GRAD0_opsz40.png"
+	degradedIconFile   = "speech_to_text_32dp_EA3323_FILL0_wght400_GRAD0_opsz40.png"
+)
+
+// iconOverrideDir returns assets/icon/ next to the executable, or "" if the
+// executable path cannot be determined
+func iconOverrideDir() string {
 	exe, err := os.Executable()
 	if err != nil {
-		log.Printf("警告: 実行ファイルのパスを取得できませんでした: %v", err)
-		return fallback
+		return ""
+	}
+	return filepath.Join(filepath.Dir(exe), "assets", "icon")
+}
+
+// loadIcon returns the icon file from dir if it exists, otherwise the embedded icon.
+// A missing override is the normal case and is not logged.
+func loadIcon(dir, filename string, embedded []byte) []byte {
+	if dir == "" {
+		return embedded
 	}
-	exeDir := filepath.Dir(exe)
 
-	// Try to load icon from assets/icon/ relative to executable
-	iconPath := filepath.Join(exeDir, "assets", "icon", filename)
+	iconPath := filepath.Join(dir, filename)
 	data, err := os.ReadFile(iconPath)
 	if err != nil {
-		log.Printf("警告: アイコンファイルを読み込めませんでした (%s): %v", iconPath, err)
-		return fallback
+		if !os.IsNotExist(err) {
+			log.Printf("警告: アイコンファイルを読み込めませんでした (%s): %v", iconPath, err)
+		}
+		return embedded
+	}
+
+	if len(data) == 0 {
+		return embedded
 	}
 
 	return data
 }
 
-// getIdleFallback returns the fallback icon data for idle state
-func getIdleFallback() []byte {
-	return []byte{
-		0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a,
-		0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
-		0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x10,
-		0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0xf3, 0xff,
-		0x61, 0x00, 0x00, 0x00, 0x19, 0x74, 0x45, 0x58,
-		0x74, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72,
-		0x65, 0x00, 0x41, 0x64, 0x6f, 0x62, 0x65, 0x20,
-		0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61,
-		0x64, 0x79, 0x71, 0xc9, 0x65, 0x3c, 0x00, 0x00,
-		0x00, 0x18, 0x49, 0x44, 0x41, 0x54, 0x78, 0xda,
-		0x62, 0xfc, 0xff, 0xff, 0x3f, 0x03, 0x00, 0x00,
-		0x00, 0xff, 0xff, 0x03, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x49, 0x45, 0x4e, 0x44, 0xae, 0x42, 0x60,
-		0x82,
-	}
+// getIdleIcon returns the icon data for idle state
+func getIdleIcon() []byte {
+	return assets.IconIdle
 }
 
-// getRecordingFallback returns the fallback icon data for recording state
-func getRecordingFallback() []byte {
-	return []byte{
-		0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a,
-		0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
-		0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x10,
-		0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0xf3, 0xff,
-		0x61, 0x00, 0x00, 0x00, 0x19, 0x74, 0x45, 0x58,
-		0x74, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72,
-		0x65, 0x00, 0x41, 0x64, 0x6f, 0x62, 0x65, 0x20,
-		0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61,
-		0x64, 0x79, 0x71, 0xc9, 0x65, 0x3c, 0x00, 0x00,
-		0x00, 0x20, 0x49, 0x44, 0x41, 0x54, 0x78, 0xda,
-		0x62, 0xfc, 0xcf, 0xc0, 0xc0, 0xc0, 0xf0, 0x9f,
-		0x81, 0x81, 0x81, 0x81, 0xff, 0x19, 0x18, 0x18,
-		0x18, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0x03,
-		0x00, 0x0c, 0x10, 0x02, 0x01, 0x8b, 0xd5, 0xf8,
-		0x23, 0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4e,
-		0x44, 0xae, 0x42, 0x60, 0x82,
-	}
+// getRecordingIcon returns the icon data for recording state
+func getRecordingIcon() []byte {
+	return assets.IconRecording
 }
 
-// getProcessingFallback returns the fallback icon data for processing state
-func getProcessingFallback() []byte {
-	return []byte{
-		0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a,
-		0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
-		0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x10,
-		0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0xf3, 0xff,
-		0x61, 0x00, 0x00, 0x00, 0x19, 0x74, 0x45, 0x58,
-		0x74, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72,
-		0x65, 0x00, 0x41, 0x64, 0x6f, 0x62, 0x65, 0x20,
-		0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61,
-		0x64, 0x79, 0x71, 0xc9, 0x65, 0x3c, 0x00, 0x00,
-		0x00, 0x20, 0x49, 0x44, 0x41, 0x54, 0x78, 0xda,
-		0x62, 0xfc, 0xcf, 0xf0, 0x9f, 0xc1, 0xc8, 0xc0,
-		0xc0, 0xc0, 0xff, 0x0c, 0x0c, 0x0c, 0xfc, 0xcf,
-		0xc0, 0xc0, 0xc0, 0x00, 0x00, 0x00, 0x00, 0xff,
-		0xff, 0x03, 0x00, 0x0c, 0x50, 0x02, 0x01, 0x3e,
-		0x0a, 0xe4, 0x5b, 0x00, 0x00, 0x00, 0x00, 0x49,
-		0x45, 0x4e, 0x44, 0xae, 0x42, 0x60, 0x82,
-	}
+// getProcessingIcon returns the icon data for processing state
+func getProcessingIcon() []byte {
+	return assets.IconProcessing
+}
+
+// getDegradedIcon returns the icon data for degraded state
+func getDegradedIcon() []byte {
+	return assets.IconDegraded
 }
 
 // ShowNotification shows a notification using macOS Notification Center
@@ -313,28 +382,19 @@ func (m *Manager) ShowNotification(title, message string) {
 	log.Printf("Notification: %s - %s", title, message)
 
 	// macOS通知センターを使用
-	script := fmt.Sprintf(`display notification "%s" with title "%s"`,
-		escapeAppleScript(message),
-		escapeAppleScript(title))
-	exec.Command("osascript", "-e", script).Run()
-}
-
-// escapeAppleScript escapes special characters for AppleScript
-func escapeAppleScript(s string) string {
-	// Escape backslashes first to avoid double-escaping
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	// Escape double quotes
-	s = strings.ReplaceAll(s, `"`, `\"`)
-	// Escape control characters
-	s = strings.ReplaceAll(s, "\n", `\n`)
-	s = strings.ReplaceAll(s, "\r", `\r`)
-	s = strings.ReplaceAll(s, "\t", `\t`)
-	return s
+	if err := m.notifier.SendInfo(title, message); err != nil {
+		log.Printf("Failed to show notification: %v", err)
+	}
 }
 
-// ShowError shows an error notification
+// ShowError shows an error notification, falling back to a dialog when
+// Notification Center cannot deliver it
 func (m *Manager) ShowError(message string) {
-	m.ShowNotification("EzS2T-Whisper Error", message)
+	log.Printf("Notification: EzS2T-Whisper Error - %s", message)
+
+	if err := m.notifier.SendError("EzS2T-Whisper Error", message); err != nil {
+		log.Printf("Failed to show error: %v", err)
+	}
 }
 
 // ShowSuccess shows a success notification
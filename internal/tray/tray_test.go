@@ -1,13 +1,15 @@
 package tray
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestNewManager(t *testing.T) {
 	settingsCalled := false
-	rescanCalled := false
 	recordTestCalled := false
 	aboutCalled := false
 	quitCalled := false
@@ -16,9 +18,6 @@ func TestNewManager(t *testing.T) {
 		OnSettings: func() {
 			settingsCalled = true
 		},
-		OnRescanModels: func() {
-			rescanCalled = true
-		},
 		OnRecordTest: func() {
 			recordTestCalled = true
 		},
@@ -48,13 +47,6 @@ func TestNewManager(t *testing.T) {
 		}
 	}
 
-	if manager.onRescanModels != nil {
-		manager.onRescanModels()
-		if !rescanCalled {
-			t.Error("Expected onRescanModels callback to be called")
-		}
-	}
-
 	if manager.onRecordTest != nil {
 		manager.onRecordTest()
 		if !recordTestCalled {
@@ -131,6 +123,156 @@ func TestIconFunctions(t *testing.T) {
 	if string(recordingIcon) == string(processingIcon) {
 		t.Error("Expected recording and processing icons to be different")
 	}
+
+	degradedIcon := getDegradedIcon()
+	if len(degradedIcon) == 0 || string(degradedIcon) == string(idleIcon) {
+		t.Error("Expected a distinct non-empty degraded icon")
+	}
+}
+
+func TestLoadIconOverride(t *testing.T) {
+	embedded := []byte("embedded")
+
+	// No override directory falls back to the embedded icon
+	if got := loadIcon("", idleIconFile, embedded); string(got) != "embedded" {
+		t.Errorf("Expected embedded icon without override dir, got %q", got)
+	}
+
+	// Missing file falls back to the embedded icon
+	dir := t.TempDir()
+	if got := loadIcon(dir, idleIconFile, embedded); string(got) != "embedded" {
+		t.Errorf("Expected embedded icon when override is missing, got %q", got)
+	}
+
+	// Existing file takes precedence
+	if err := os.WriteFile(filepath.Join(dir, idleIconFile), []byte("override"), 0644); err != nil {
+		t.Fatalf("Failed to write override icon: %v", err)
+	}
+	if got := loadIcon(dir, idleIconFile, embedded); string(got) != "override" {
+		t.Errorf("Expected override icon, got %q", got)
+	}
+}
+
+func TestStateText(t *testing.T) {
+	showText := false
+	manager := NewManager(Config{ShowText: func() bool { return showText }})
+
+	manager.state = StateRecording
+	if text := manager.stateText(); text != "" {
+		t.Errorf("Expected no text when disabled, got %q", text)
+	}
+
+	showText = true
+	if text := manager.stateText(); text != "●REC" {
+		t.Errorf("Expected '●REC' while recording, got %q", text)
+	}
+
+	manager.state = StateIdle
+	if text := manager.stateText(); text != "" {
+		t.Errorf("Expected text to be cleared when idle, got %q", text)
+	}
+
+	// Without a ShowText callback the tray is icon-only
+	if text := NewManager(Config{}).stateText(); text != "" {
+		t.Errorf("Expected no text without callback, got %q", text)
+	}
+}
+
+func TestSetProblems(t *testing.T) {
+	manager := NewManager(Config{ShowText: func() bool { return true }})
+
+	manager.SetProblems([]string{"モデル未設定", "マイク権限なし"})
+	if state := manager.displayState(); state != StateDegraded {
+		t.Errorf("Expected idle to be shown as StateDegraded, got %v", state)
+	}
+	if tooltip := manager.tooltip(); tooltip != "EzS2T-Whisper - モデル未設定 / マイク権限なし" {
+		t.Errorf("Expected tooltip to list the problems, got %q", tooltip)
+	}
+	if text := manager.stateText(); text != "⚠" {
+		t.Errorf("Expected '⚠' while degraded, got %q", text)
+	}
+
+	// Activity is still shown while degraded
+	manager.SetState(StateRecording)
+	if state := manager.displayState(); state != StateRecording {
+		t.Errorf("Expected StateRecording while recording, got %v", state)
+	}
+
+	// Going back to idle shows the problems again until they are cleared
+	manager.SetState(StateIdle)
+	if state := manager.displayState(); state != StateDegraded {
+		t.Errorf("Expected StateDegraded after returning to idle, got %v", state)
+	}
+
+	manager.SetProblems(nil)
+	if state := manager.displayState(); state != StateIdle {
+		t.Errorf("Expected StateIdle once problems are cleared, got %v", state)
+	}
+	if tooltip := manager.tooltip(); tooltip != "EzS2T-Whisper - 待機中" {
+		t.Errorf("Expected idle tooltip, got %q", tooltip)
+	}
+}
+
+func TestSetPartialText(t *testing.T) {
+	manager := NewManager(Config{})
+
+	// Only shown while recording
+	manager.SetPartialText("こんにちは")
+	if tooltip := manager.tooltip(); tooltip != "EzS2T-Whisper - 待機中" {
+		t.Errorf("Expected idle tooltip, got %q", tooltip)
+	}
+
+	manager.SetState(StateRecording)
+	if tooltip := manager.tooltip(); tooltip != "EzS2T-Whisper - 録音中: こんにちは" {
+		t.Errorf("Expected the partial text in the tooltip, got %q", tooltip)
+	}
+
+	manager.SetPartialText(strings.Repeat("あ", 10) + strings.Repeat("い", maxPartialTextRunes))
+	if tooltip := manager.tooltip(); tooltip != "EzS2T-Whisper - 録音中: …"+strings.Repeat("い", maxPartialTextRunes) {
+		t.Errorf("Expected only the end of long text, got %q", tooltip)
+	}
+
+	manager.SetPartialText("")
+	if tooltip := manager.tooltip(); tooltip != "EzS2T-Whisper - 録音中" {
+		t.Errorf("Expected the partial text to be cleared, got %q", tooltip)
+	}
+}
+
+func TestUpdateDeviceMenu(t *testing.T) {
+	selected := make(chan int, 1)
+	manager := NewManager(Config{OnDeviceChange: func(deviceID int) { selected <- deviceID }})
+
+	// Before systray is ready there is no menu to update
+	manager.UpdateDeviceMenu([]Device{{ID: 1, Name: "Built-in"}})
+
+	backend := &fakeMenuBackend{}
+	manager.buildMenu(backend)
+	t.Cleanup(manager.menu.Close)
+
+	manager.UpdateDeviceMenu([]Device{
+		{ID: 0, Name: "Built-in Microphone", IsDefault: true},
+		{ID: 3, Name: "USB Microphone", IsCurrent: true},
+	})
+
+	devices := backend.find("入力デバイス")
+	if got := backend.visibleChildren(devices); len(got) != 2 || got[1] != "USB Microphone" {
+		t.Fatalf("Expected both devices in order, got %v", got)
+	}
+
+	usb := backend.items[len(backend.items)-1]
+	if !usb.checked {
+		t.Error("Expected the current device to be checked")
+	}
+
+	usb.clicked <- struct{}{}
+	select {
+	case id := <-selected:
+		if id != 3 {
+			t.Errorf("Expected device 3 to be selected, got %d", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the device selection to be dispatched")
+	}
 }
 
 func TestShowNotification(t *testing.T) {
@@ -154,9 +296,6 @@ func TestCallbacksNil(t *testing.T) {
 	if manager.onSettings != nil {
 		manager.onSettings()
 	}
-	if manager.onRescanModels != nil {
-		manager.onRescanModels()
-	}
 	if manager.onRecordTest != nil {
 		manager.onRecordTest()
 	}
@@ -179,6 +318,9 @@ func TestStateConstants(t *testing.T) {
 	if StateProcessing != 2 {
 		t.Errorf("Expected StateProcessing to be 2, got %d", StateProcessing)
 	}
+	if StateDegraded != 3 {
+		t.Errorf("Expected StateDegraded to be 3, got %d", StateDegraded)
+	}
 }
 
 func TestUpdateIcon(t *testing.T) {
@@ -193,6 +335,9 @@ func TestUpdateIcon(t *testing.T) {
 
 	manager.state = StateProcessing
 	manager.updateIcon()
+
+	manager.state = StateDegraded
+	manager.updateIcon()
 }
 
 func TestConcurrentStateUpdates(t *testing.T) {
@@ -0,0 +1,135 @@
+// Package update checks a small version manifest to tell the user when a newer
+// release is available. It never downloads or installs anything.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxManifestSize bounds how much of the manifest response is read
+const maxManifestSize = 64 * 1024
+
+// Release describes the latest published version
+type Release struct {
+	Version string // e.g. "0.4.0"
+	URL     string // Release page to open, may be empty
+}
+
+// manifest accepts both the plain {"version", "url"} format and the GitHub
+// releases API format ({"tag_name", "html_url"})
+type manifest struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Fetch downloads and parses the version manifest at manifestURL
+func Fetch(ctx context.Context, client *http.Client, manifestURL string) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var m manifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&m); err != nil {
+		return Release{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	release := Release{Version: m.Version, URL: m.URL}
+	if release.Version == "" {
+		release.Version = m.TagName
+	}
+	if release.URL == "" {
+		release.URL = m.HTMLURL
+	}
+
+	if release.Version == "" {
+		return Release{}, fmt.Errorf("manifest has no version")
+	}
+
+	return release, nil
+}
+
+// Check fetches the manifest and reports whether its version is newer than current
+func Check(ctx context.Context, client *http.Client, manifestURL, current string) (Release, bool, error) {
+	release, err := Fetch(ctx, client, manifestURL)
+	if err != nil {
+		return Release{}, false, err
+	}
+
+	newer, err := IsNewer(release.Version, current)
+	if err != nil {
+		return Release{}, false, err
+	}
+
+	return release, newer, nil
+}
+
+// IsNewer reports whether version candidate is greater than current.
+// Versions are dot-separated numbers with an optional "v" prefix; a pre-release
+// suffix ("-beta.1") is ignored.
+func IsNewer(candidate, current string) (bool, error) {
+	a, err := parseVersion(candidate)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := parseVersion(current)
+	if err != nil {
+		return false, err
+	}
+
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if x != y {
+			return x > y, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseVersion splits "v1.2.3-beta" into [1 2 3]
+func parseVersion(version string) ([]int, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version: %q", version)
+		}
+		numbers[i] = n
+	}
+
+	return numbers, nil
+}
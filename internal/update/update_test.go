@@ -0,0 +1,113 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		candidate string
+		current   string
+		expected  bool
+	}{
+		{"0.4.0", "0.3.0", true},
+		{"v0.3.1", "0.3.0", true},
+		{"1.0", "0.9.9", true},
+		{"0.3.0", "0.3.0", false},
+		{"0.3", "0.3.0", false},
+		{"0.2.9", "0.3.0", false},
+		{"0.10.0", "0.9.0", true},
+		{"0.4.0-beta.1", "0.3.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.candidate+"_vs_"+tt.current, func(t *testing.T) {
+			newer, err := IsNewer(tt.candidate, tt.current)
+			if err != nil {
+				t.Fatalf("IsNewer failed: %v", err)
+			}
+
+			if newer != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, newer)
+			}
+		})
+	}
+}
+
+func TestIsNewer_Invalid(t *testing.T) {
+	if _, err := IsNewer("latest", "0.3.0"); err == nil {
+		t.Error("Expected error for non-numeric version")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		expectNewer bool
+		expectURL   string
+	}{
+		{
+			name:        "plain manifest",
+			body:        `{"version": "0.4.0", "url": "https://example.com/release"}`,
+			expectNewer: true,
+			expectURL:   "https://example.com/release",
+		},
+		{
+			name:        "GitHub releases API",
+			body:        `{"tag_name": "v0.4.0", "html_url": "https://example.com/tag"}`,
+			expectNewer: true,
+			expectURL:   "https://example.com/tag",
+		},
+		{
+			name:        "same version",
+			body:        `{"version": "0.3.0"}`,
+			expectNewer: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			release, newer, err := Check(context.Background(), server.Client(), server.URL, "0.3.0")
+			if err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+
+			if newer != tt.expectNewer {
+				t.Errorf("Expected newer=%v, got %v", tt.expectNewer, newer)
+			}
+
+			if newer && release.URL != tt.expectURL {
+				t.Errorf("Expected URL %q, got %q", tt.expectURL, release.URL)
+			}
+		})
+	}
+}
+
+func TestCheck_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/empty":
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`not json`))
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/missing", "/empty", "/invalid"} {
+		if _, _, err := Check(context.Background(), server.Client(), server.URL+path, "0.3.0"); err == nil {
+			t.Errorf("Expected error for %s", path)
+		}
+	}
+}
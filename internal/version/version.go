@@ -0,0 +1,72 @@
+// Package version holds the application version and build information.
+// Release builds can set the variables with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/yok-tottii/EzS2T-Whisper/internal/version.Commit=$(git rev-parse --short HEAD)"
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the application version
+var Version = "0.3.0"
+
+// Commit is the VCS revision the binary was built from. When not set with
+// -ldflags it is taken from the Go build info if available.
+var Commit = ""
+
+// BuildDate is the build time (RFC 3339), empty if not set with -ldflags
+var BuildDate = ""
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified"` // Built from a working tree with uncommitted changes
+}
+
+// Get returns the version and build information
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		applyBuildSettings(&info, buildInfo.Settings)
+	}
+
+	return info
+}
+
+// applyBuildSettings fills fields that were not set with -ldflags from the
+// VCS settings recorded by the Go toolchain
+func applyBuildSettings(info *Info, settings []debug.BuildSetting) {
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = shortRevision(setting.Value)
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+}
+
+// shortRevision abbreviates a full commit hash the way git does by default
+func shortRevision(revision string) string {
+	if len(revision) > 7 {
+		return revision[:7]
+	}
+	return revision
+}
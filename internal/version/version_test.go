@@ -0,0 +1,41 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+
+	if info.Version != Version {
+		t.Errorf("Expected version %q, got %q", Version, info.Version)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %q, got %q", runtime.Version(), info.GoVersion)
+	}
+}
+
+func TestApplyBuildSettings(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef0123456789abcdef01234567"},
+		{Key: "vcs.time", Value: "2026-03-14T09:30:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	var info Info
+	applyBuildSettings(&info, settings)
+
+	if info.Commit != "0123456" || info.BuildDate != "2026-03-14T09:30:00Z" || !info.Modified {
+		t.Errorf("Unexpected info from build settings: %+v", info)
+	}
+
+	// Values set with -ldflags take precedence
+	info = Info{Commit: "release", BuildDate: "2026-01-01T00:00:00Z"}
+	applyBuildSettings(&info, settings)
+
+	if info.Commit != "release" || info.BuildDate != "2026-01-01T00:00:00Z" {
+		t.Errorf("Expected -ldflags values to be kept, got %+v", info)
+	}
+}
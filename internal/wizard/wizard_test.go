@@ -179,6 +179,29 @@ func TestGetProgress(t *testing.T) {
 	}
 }
 
+func TestGetProgress_TestStep(t *testing.T) {
+	wizard, err := NewSetupWizard()
+	if err != nil {
+		t.Fatalf("Failed to create wizard: %v", err)
+	}
+
+	wizard.SetRecordTestResult(true)
+	if progress := wizard.GetProgress(); progress.TestCompleted || !progress.RecordTestPassed {
+		t.Errorf("Expected test step incomplete with only the record test passed, got %+v", progress)
+	}
+
+	wizard.SetPasteTestResult(true)
+	if progress := wizard.GetProgress(); !progress.TestCompleted {
+		t.Errorf("Expected test step complete after both tests passed, got %+v", progress)
+	}
+
+	// A later failure makes the step incomplete again
+	wizard.SetPasteTestResult(false)
+	if progress := wizard.GetProgress(); progress.TestCompleted || progress.PasteTestPassed {
+		t.Errorf("Expected test step incomplete after the paste test failed, got %+v", progress)
+	}
+}
+
 func TestResetSetup(t *testing.T) {
 	wizard, err := NewSetupWizard()
 	if err != nil {